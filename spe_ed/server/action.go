@@ -15,25 +15,58 @@
 
 package main
 
-// Action represents an Action send to the server. It is mainly used to unmarshal JSON answers.
-type Action struct {
-	Action string `json:"action"`
-}
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownAction is returned by ParseAction if the string is not a valid action.
+var ErrUnknownAction = errors.New("unknown action")
+
+// Action represents an action of a player as sent to the server.
+type Action string
 
 const (
 	// ActionTurnLeft represent the action "turn_left".
-	ActionTurnLeft = "turn_left"
+	ActionTurnLeft Action = "turn_left"
 	// ActionTurnRight represent the action "turn_right".
-	ActionTurnRight = "turn_right"
+	ActionTurnRight Action = "turn_right"
 	// ActionSlower represent the action "slow_down".
-	ActionSlower = "slow_down"
+	ActionSlower Action = "slow_down"
 	// ActionFaster represent the action "speed_up".
-	ActionFaster = "speed_up"
+	ActionFaster Action = "speed_up"
 	// ActionNOOP represent the action "change_nothing".
-	ActionNOOP = "change_nothing"
+	ActionNOOP Action = "change_nothing"
 )
 
-// IsValidAction returns whether a string is a valid action.
-func IsValidAction(a string) bool {
-	return a == ActionTurnLeft || a == ActionTurnRight || a == ActionSlower || a == ActionFaster || a == ActionNOOP
+// Actions contains all valid actions in the order change_nothing, turn_left, turn_right, slow_down, speed_up.
+var Actions = [...]Action{ActionNOOP, ActionTurnLeft, ActionTurnRight, ActionSlower, ActionFaster}
+
+// String returns the action as sent to the server.
+func (a Action) String() string {
+	return string(a)
+}
+
+// ParseAction returns the action represented by the string. ErrUnknownAction is returned for all other strings.
+func ParseAction(s string) (Action, error) {
+	a := Action(s)
+	if !IsValidAction(a) {
+		return "", fmt.Errorf("%w %q", ErrUnknownAction, s)
+	}
+	return a, nil
+}
+
+// IsValidAction returns whether a is a valid action.
+func IsValidAction(a Action) bool {
+	switch a {
+	case ActionTurnLeft, ActionTurnRight, ActionSlower, ActionFaster, ActionNOOP:
+		return true
+	default:
+		return false
+	}
+}
+
+// ActionMessage represents an answer sent to the server.
+type ActionMessage struct {
+	Action Action `json:"action"`
 }
@@ -0,0 +1,47 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParseAction(t *testing.T) {
+	for _, a := range Actions {
+		got, err := ParseAction(a.String())
+		if err != nil || got != a {
+			t.Errorf("%s parsed as %q (%v)", a, got, err)
+		}
+
+		b, err := json.Marshal(ActionMessage{Action: a})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != `{"action":"`+a.String()+`"}` {
+			t.Errorf("%s sent as %s", a, b)
+		}
+	}
+	for _, s := range []string{"", "turn left", "TURN_LEFT", "jump"} {
+		if _, err := ParseAction(s); !errors.Is(err, ErrUnknownAction) {
+			t.Errorf("%q: %v", s, err)
+		}
+		if IsValidAction(Action(s)) {
+			t.Errorf("%q is valid", s)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultSearchUtilization contains the default share of the remaining time until the deadline which the search AIs use (see SearchBudget).
+	DefaultSearchUtilization = 0.8
+	// SearchBudgetSmoothing contains the weight of the newest measurement in the exponential moving average of SearchBudget.
+	SearchBudgetSmoothing = 0.3
+)
+
+var searchUtilization = DefaultSearchUtilization
+var searchUtilizationLock sync.Mutex
+
+// SetSearchUtilization sets the utilization used by all SearchBudget without own Utilization. It must be within (0, 1].
+func SetSearchUtilization(u float64) error {
+	if !(u > 0 && u <= 1) {
+		return fmt.Errorf("search utilization %v must be within (0, 1]", u)
+	}
+	searchUtilizationLock.Lock()
+	defer searchUtilizationLock.Unlock()
+	searchUtilization = u
+	return nil
+}
+
+// GetSearchUtilization returns the utilization set by SetSearchUtilization (or DefaultSearchUtilization).
+func GetSearchUtilization() float64 {
+	searchUtilizationLock.Lock()
+	defer searchUtilizationLock.Unlock()
+	return searchUtilization
+}
+
+// SearchBudget adapts the effort of a search (e.g. depth or number of simulations) to the time available in a round.
+// It keeps an exponential moving average of the time per node (a searched position, a simulation, ...) over the recent rounds, so the cost of more effort can be estimated before it is spent.
+// This matters since nodes are cheap on early boards and small boards, but expensive later on large boards.
+// The zero value is ready to use. Not safe for concurrent use.
+type SearchBudget struct {
+	// Utilization is the share of the remaining time until the deadline used for the search. GetSearchUtilization is used if it is not within (0, 1].
+	Utilization float64
+
+	perNode float64 // in nanoseconds, 0 before the first measurement
+}
+
+// Target returns the time the search should take in this round: Utilization of the remaining time until Game.Deadline, but at least margin before the deadline so the answer is sent in time.
+// If the game has no deadline, budget is returned.
+func (b *SearchBudget) Target(g *Game, margin, budget time.Duration) time.Duration {
+	remaining, ok := g.RemainingTime()
+	if !ok {
+		return budget
+	}
+	u := b.Utilization
+	if !(u > 0 && u <= 1) {
+		u = GetSearchUtilization()
+	}
+	target := time.Duration(u * float64(remaining))
+	if target > remaining-margin {
+		target = remaining - margin
+	}
+	return target
+}
+
+// Observe adds a measurement: nodes nodes were searched in d.
+// Measurements without nodes are ignored.
+func (b *SearchBudget) Observe(nodes int, d time.Duration) {
+	if nodes <= 0 {
+		return
+	}
+	v := float64(d) / float64(nodes)
+	if b.perNode == 0 {
+		b.perNode = v
+		return
+	}
+	b.perNode = SearchBudgetSmoothing*v + (1-SearchBudgetSmoothing)*b.perNode
+}
+
+// PerNode returns the average time per node or 0 before the first measurement.
+func (b *SearchBudget) PerNode() time.Duration {
+	return time.Duration(b.perNode)
+}
+
+// Nodes returns the number of nodes which fit into the given time or -1 (unlimited) before the first measurement.
+func (b *SearchBudget) Nodes(t time.Duration) int {
+	if b.perNode == 0 {
+		return -1
+	}
+	if t <= 0 {
+		return 0
+	}
+	return int(float64(t) / b.perNode)
+}
+
+// ExpectedNodes estimates the number of nodes of the next depth of an iterative deepening search from the nodes of the last two depths, assuming the tree grows by the same factor again.
+// If the previous depth is unknown (0), the same number of nodes as in the last depth is assumed.
+func ExpectedNodes(previous, last int) int {
+	if previous <= 0 {
+		return last
+	}
+	return int(float64(last) * float64(last) / float64(previous))
+}
+
+// Fits returns whether searching nodes more nodes is expected to end before target, if elapsed has already been used.
+// Before the first measurement, everything fits.
+func (b *SearchBudget) Fits(elapsed, target time.Duration, nodes int) bool {
+	return elapsed+time.Duration(float64(nodes)*b.perNode) <= target
+}
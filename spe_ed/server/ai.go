@@ -16,15 +16,24 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math/rand"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
 	"sort"
+	"strings"
 	"sync"
+	"time"
 )
 
 var aiMap = make(map[string]AINewFunc)
+var aiLocation = make(map[string]string)
 var aiLock sync.RWMutex
+var aiSeed *rand.Rand
+var aiSeedLock sync.Mutex
 var aiArray = []func() (AI, string){
 	func() (AI, string) { return new(EndRound), GlobalPseudonym.Get("AI-EndRound-1") },
 	func() (AI, string) { return new(HeartAI), GlobalPseudonym.Get("AI-HeartAI-3") },
@@ -97,11 +106,164 @@ var aiArray = []func() (AI, string){
 // In GetState, AIs can only access public fields (and change them) plus stepCounter, privat fields are set to zero.
 // Modification of the game is allowed. The Caller has to make sure that modifications to the provided game can be done without side effects (e.g. by using Game.PublicCopy )
 type AI interface {
-	GetChannel(c chan string)
+	GetChannel(c chan Action)
 	GetState(g *Game)
 	Name() string
 }
 
+// ContextAIMargin contains the time before the deadline of a round at which the context of a ContextAI is cancelled, leaving time to send the answer.
+const ContextAIMargin = 300 * time.Millisecond
+
+// ContextAI is an optional interface for AIs which can be cancelled.
+// If an AI implements ContextAI, GetStateContext is called instead of GetState.
+// The context is cancelled when the answer is needed (usually shortly before the deadline). The AI should then stop its computation and send the best answer found so far.
+// All other rules of GetState apply.
+type ContextAI interface {
+	GetStateContext(ctx context.Context, g *Game)
+}
+
+// ErrAIPanic is returned by SafeGetState if the AI panicked.
+var ErrAIPanic = errors.New("ai panicked")
+
+// SafeGetState calls GetState of the AI, but recovers if the AI panics, so a buggy AI does not crash the whole process.
+// The panic is logged together with the stack and an error wrapping ErrAIPanic is returned. The AI did not answer in this case, so the caller should use a fallback (see FallbackAction).
+func SafeGetState(ai AI, g *Game) (err error) {
+	defer recoverAIPanic(ai, &err)
+	ai.GetState(g)
+	return nil
+}
+
+// recoverAIPanic recovers from a panic of the AI and stores it in err. It must be called deferred.
+func recoverAIPanic(ai AI, err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	log.Printf("ai %s panicked: %v\n%s", ai.Name(), r, debug.Stack())
+	metricPanics.WithLabelValues(ai.Name()).Inc()
+	*err = fmt.Errorf("%w: %s: %v", ErrAIPanic, ai.Name(), r)
+}
+
+// getAIState gives the game to the AI, using GetStateContext if the AI implements ContextAI.
+// If the game is not running or Game.You is not an active player, the AI is not called, since no answer is allowed.
+// If deadline is not zero, the context is cancelled ContextAIMargin before it. The function blocks until the AI returns.
+// Like SafeGetState, a panic of the AI is recovered and returned as error.
+// While profiling is running (see StartProfiling), the time and allocations of the decision are measured.
+func getAIState(ai AI, g *Game, deadline time.Time) (err error) {
+	if p, ok := g.Players[g.You]; !g.Running || !ok || !p.Active {
+		return nil
+	}
+
+	start := time.Now()
+	defer func() { metricDecisionLatency.WithLabelValues(ai.Name()).Observe(time.Since(start).Seconds()) }()
+	if ProfilingEnabled() {
+		defer profileDecision(ai.Name())()
+	}
+
+	cai, ok := ai.(ContextAI)
+	if !ok {
+		return SafeGetState(ai, g)
+	}
+
+	ctx := aiContext
+	if !deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline.Add(-ContextAIMargin))
+		defer cancel()
+	}
+	defer recoverAIPanic(ai, &err)
+	cai.GetStateContext(ctx, g)
+	return nil
+}
+
+// answerSequencer assigns the answers of an AI to the decisions they belong to.
+// An AI sends all answers on the single channel given to GetChannel, so the answer of a decision exceeding its round would otherwise be read as answer of the next round.
+// Therefore, a decision is only started after the previous one has returned, and answers left on the channel by the previous decision are discarded.
+// The decisions of a sequencer must be started one after another by the same goroutine.
+type answerSequencer struct {
+	ai     AI
+	answer chan Action
+	last   chan struct{} // closed once the last decision has returned, nil before the first decision
+}
+
+// newAnswerSequencer returns a sequencer for the AI, which must have got answer through GetChannel.
+func newAnswerSequencer(ai AI, answer chan Action) *answerSequencer {
+	return &answerSequencer{ai: ai, answer: answer}
+}
+
+// decide starts a decision of the AI on the game (see getAIState) once the previous decision has returned.
+// The answer of the decision is sent on the first returned channel, the error if the AI panics on the second. Both are buffered.
+// Closing cancel stops waiting: the decision is not started if the previous decision has not returned yet, and the answer is not read any more.
+// cancel must be closed at the end of the round, since the next decision waits for it if the AI did not answer.
+func (s *answerSequencer) decide(g *Game, deadline time.Time, cancel <-chan struct{}) (<-chan Action, <-chan error) {
+	prev := s.last
+	finished := make(chan struct{})
+	s.last = finished
+	answer := make(chan Action, 1)
+	panicked := make(chan error, 1)
+
+	go func() {
+		defer close(finished)
+		if prev != nil {
+			select {
+			case <-prev:
+			case <-cancel:
+				<-prev
+				return
+			}
+		}
+		// Remove old answers
+		select {
+		case <-s.answer:
+		default:
+		}
+
+		read := make(chan struct{})
+		go func() {
+			defer close(read)
+			select {
+			case a := <-s.answer:
+				answer <- a
+			case <-cancel:
+			}
+		}()
+		err := getAIState(s.ai, g, deadline)
+		if err != nil {
+			panicked <- err
+		}
+		<-read
+	}()
+	return answer, panicked
+}
+
+// SeedableAI is an optional interface for AIs which use randomness.
+// After Seed is called, all random decisions of the AI must be derived from the seed only, so that the same seed in the same game leads to the same actions.
+// Seed is called before GetChannel.
+type SeedableAI interface {
+	Seed(seed int64)
+}
+
+// GameEndAI is an optional interface for AIs which need to know the result of their games, e.g. to collect statistics or to learn.
+// GameEnd is called exactly once after a game of the AI has ended (by the server, the simulator and the client), also if the player crashed or did not answer in time. won is true if the player of the AI won the game. A draw is not won.
+// In the client, a game is also ended (and not won) if the connection is lost and can not be reestablished.
+type GameEndAI interface {
+	GameEnd(won bool)
+}
+
+// notifyGameEnd calls GameEnd if the AI implements GameEndAI.
+func notifyGameEnd(ai AI, won bool) {
+	if gai, ok := ai.(GameEndAI); ok {
+		gai.GameEnd(won)
+	}
+}
+
+// Explainable is an optional interface for AIs which can explain their decisions, e.g. for debugging.
+// Explain returns the score the AI assigns to every action it considers for Game.You in the given state. It must use the same logic as GetState, so the scores explain the chosen action. Which scores are considered better is documented by the AI.
+// Actions not considered (e.g. because they crash immediately) are missing. Explain does not send an answer and does not modify the game.
+type Explainable interface {
+	Explain(g *Game) map[Action]float64
+}
+
 // NewAI provides a new AI with given Name.
 type NewAI struct {
 	AI  AI
@@ -111,17 +273,52 @@ type NewAI struct {
 // AINewFunc must return a new AI
 type AINewFunc func() AI
 
-// RegisterAI registers an AI. Name must be unique or else an error will occur.
+// ErrAIAlreadyRegistered is returned by RegisterAI if an ai with the same name is already registered.
+type ErrAIAlreadyRegistered struct {
+	// Name is the name of the ai.
+	Name string
+	// Registered is the location (file:line) of the existing registration.
+	Registered string
+	// Duplicate is the location (file:line) of the failed registration.
+	Duplicate string
+}
+
+// Error returns the error message.
+func (e *ErrAIAlreadyRegistered) Error() string {
+	return fmt.Sprintf("ai name %s already registered at %s (duplicate at %s)", e.Name, e.Registered, e.Duplicate)
+}
+
+// RegisterAI registers an AI. Name must be unique or else an error of type *ErrAIAlreadyRegistered will occur.
 func RegisterAI(name string, makeai AINewFunc) error {
+	return registerAI(name, makeai, 2)
+}
+
+// MustRegisterAI is like RegisterAI, but panics if the ai can not be registered.
+// It is intended to be used in init functions.
+func MustRegisterAI(name string, makeai AINewFunc) {
+	err := registerAI(name, makeai, 2)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// registerAI registers an AI and remembers the location of the caller skip frames above.
+func registerAI(name string, makeai AINewFunc, skip int) error {
+	location := "unknown location"
+	if _, file, line, ok := runtime.Caller(skip); ok {
+		location = fmt.Sprintf("%s:%d", filepath.Base(file), line)
+	}
+
 	aiLock.Lock()
 	defer aiLock.Unlock()
 	if _, ok := aiMap[name]; ok {
-		return fmt.Errorf("ai name %s already registered", name)
+		return &ErrAIAlreadyRegistered{Name: name, Registered: aiLocation[name], Duplicate: location}
 	}
 	if makeai == nil {
 		return errors.New("AINewFunc must not be nil")
 	}
 	aiMap[name] = makeai
+	aiLocation[name] = location
 	return nil
 }
 
@@ -144,7 +341,7 @@ func UpdateAIPool(ais []string) error {
 	for i := range ais {
 		f, ok := aiMap[ais[i]]
 		if !ok {
-			return fmt.Errorf("ai name %s not known", ais[i])
+			return errUnknownAI(ais[i])
 		}
 		counter[ais[i]]++
 		p := fmt.Sprintf("AI-%s-%d", ais[i], counter[ais[i]])
@@ -154,10 +351,22 @@ func UpdateAIPool(ais []string) error {
 	return nil
 }
 
-// GetAINames returns a list of all known ais in alphabetical order.
-func GetAINames() []string {
+// ListAIs returns a list of all registered ais in alphabetical order.
+func ListAIs() []string {
 	aiLock.RLock()
 	defer aiLock.RUnlock()
+	return listAIs()
+}
+
+// GetAINames returns a list of all known ais in alphabetical order.
+// It is the same as ListAIs.
+func GetAINames() []string {
+	return ListAIs()
+}
+
+// listAIs returns a list of all registered ais in alphabetical order.
+// Caller must hold aiLock.
+func listAIs() []string {
 	s := make([]string, 0, len(aiMap))
 	for k := range aiMap {
 		s = append(s, k)
@@ -166,6 +375,48 @@ func GetAINames() []string {
 	return s
 }
 
+// errUnknownAI returns an error for an unknown ai name listing all known ais.
+// Caller must hold aiLock.
+func errUnknownAI(name string) error {
+	return fmt.Errorf("ai name %s not known (known ais: %s)", name, strings.Join(listAIs(), ", "))
+}
+
+// CreateAI returns a new AI with the given name.
+// If SetAISeed was called before, the AI gets seeded like the AIs returned by GetAI.
+func CreateAI(name string) (AI, error) {
+	aiLock.RLock()
+	f, ok := aiMap[name]
+	if !ok {
+		err := errUnknownAI(name)
+		aiLock.RUnlock()
+		return nil, err
+	}
+	aiLock.RUnlock()
+	ai := f()
+	seedAI(ai)
+	return ai, nil
+}
+
+// SetAISeed sets the seed from which the seeds of all AIs returned by GetAI and CreateAI are derived.
+// AIs not implementing SeedableAI are unaffected.
+func SetAISeed(seed int64) {
+	aiSeedLock.Lock()
+	defer aiSeedLock.Unlock()
+	aiSeed = rand.New(rand.NewSource(seed))
+}
+
+// seedAI seeds the AI if SetAISeed was called before and the AI implements SeedableAI.
+func seedAI(ai AI) {
+	aiSeedLock.Lock()
+	defer aiSeedLock.Unlock()
+	if aiSeed == nil {
+		return
+	}
+	if s, ok := ai.(SeedableAI); ok {
+		s.Seed(aiSeed.Int63())
+	}
+}
+
 // GetAI returns a slice of AIs of specified number out of the current rotation.
 // Function might panic if number is to large. This should only occur if the number is larger than 6.
 func GetAI(num int) []NewAI {
@@ -185,6 +436,7 @@ func GetAI(num int) []NewAI {
 
 	for i := range r {
 		r[i].AI, r[i].API = aiArray[selectArray[i]]()
+		seedAI(r[i].AI)
 	}
 
 	return r
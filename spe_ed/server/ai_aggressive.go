@@ -0,0 +1,190 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "sync"
+
+func init() {
+	MustRegisterAI("AggressiveAI", func() AI { return new(AggressiveAI) })
+}
+
+// AggressiveTrapHorizon contains the number of rounds AggressiveAI requires an opponent to stay cut off (see FindTrappingMove).
+const AggressiveTrapHorizon = 3
+
+// AggressiveAI is an AI which attacks the space of the nearest opponent as long as it has the advantage.
+// A move cutting the nearest opponent off into a smaller region (see FindTrappingMove) is always preferred.
+// The regime is chosen with Voronoi: If the player owns a larger region than the nearest active opponent (by distance of the heads), it chooses the action minimising the region of the opponent.
+// Only actions leaving the player untrapped (see TrapCheck) and not moving into a cell an opponent might reach in the same round are considered, ties are broken towards the larger own region and then towards the lower speed.
+// At a disadvantage, without opponent or without a safe action, it plays for survival like SurvivalAI.
+type AggressiveAI struct {
+	l sync.Mutex
+
+	i  chan Action
+	sv SurvivalAI
+}
+
+// GetChannel receives the answer channel.
+func (a *AggressiveAI) GetChannel(c chan Action) {
+	a.l.Lock()
+	defer a.l.Unlock()
+
+	a.i = c
+	a.sv.GetChannel(c)
+}
+
+// GetState gets the game state and computes an answer.
+func (a *AggressiveAI) GetState(g *Game) {
+	a.l.Lock()
+	defer a.l.Unlock()
+
+	if a.i == nil {
+		return
+	}
+
+	if g.Running && g.Players[g.You].Active {
+		opponent := a.nearestOpponent(g)
+		if opponent != 0 {
+			if action, ok := FindTrappingMove(g, opponent, AggressiveTrapHorizon); ok {
+				LogDecision(g, a.Name(), action, nil, "cutting off nearest opponent")
+				select {
+				case a.i <- action:
+				default:
+				}
+				return
+			}
+		}
+		if opponent == 0 || Voronoi(g, g.You) <= Voronoi(g, opponent) {
+			a.sv.GetState(g)
+			return
+		}
+
+		var action Action
+		bestOpponent := 0
+		bestOwn := 0
+		bestSpeed := 0
+		var scores map[Action]float64
+		if DecisionLogEnabled() {
+			scores = make(map[Action]float64, 4)
+		}
+
+		danger := opponentCells(g)
+		b := NewBitboard(g)
+		// Speeding up always reaches more cells first, but a fast player can not follow the opponent through narrow gaps
+		actions := []Action{ActionNOOP, ActionTurnLeft, ActionTurnRight, ActionSlower}
+	actionLoop:
+		for i := range actions {
+			if b.Crashes(g.Players[g.You], actions[i]) {
+				continue
+			}
+			c, crashed := Simulate(g, g.You, actions[i])
+			if crashed {
+				ReleaseClone(c)
+				continue
+			}
+			for y := range c.Cells {
+				for x := range c.Cells[y] {
+					if c.Cells[y][x] != g.Cells[y][x] && danger[coordinate{x, y}] {
+						// An opponent might move there in the same round
+						ReleaseClone(c)
+						continue actionLoop
+					}
+				}
+			}
+			p := c.Players[c.You]
+			if _, trapped := TrapCheck(c, p.X, p.Y, p.Speed); trapped {
+				ReleaseClone(c)
+				continue
+			}
+			opponentRegion := Voronoi(c, opponent)
+			ownRegion := Voronoi(c, c.You)
+			speed := p.Speed
+			ReleaseClone(c)
+			if ownRegion <= opponentRegion {
+				// The attack must not give up the advantage
+				continue
+			}
+			if scores != nil {
+				scores[actions[i]] = float64(opponentRegion)
+			}
+			if action == "" || opponentRegion < bestOpponent || (opponentRegion == bestOpponent && (ownRegion > bestOwn || (ownRegion == bestOwn && speed < bestSpeed))) {
+				action = actions[i]
+				bestOpponent = opponentRegion
+				bestOwn = ownRegion
+				bestSpeed = speed
+			}
+		}
+
+		if action == "" {
+			// No safe attack - survive instead
+			a.sv.GetState(g)
+			return
+		}
+
+		LogDecision(g, a.Name(), action, scores, "space advantage, attacking nearest opponent")
+
+		select {
+		case a.i <- action:
+		default:
+		}
+	}
+}
+
+// opponentCells returns all cells which an active opponent of Game.You fills with any of its actions in the next round (see ApplyAction).
+func opponentCells(g *Game) map[coordinate]bool {
+	cells := make(map[coordinate]bool)
+	for id, p := range g.Players {
+		if id == g.You || !p.Active {
+			continue
+		}
+		for _, action := range []Action{ActionNOOP, ActionTurnLeft, ActionTurnRight, ActionSlower, ActionFaster} {
+			c := AcquireClone(g)
+			ApplyAction(c, id, action)
+			for y := range c.Cells {
+				for x := range c.Cells[y] {
+					if c.Cells[y][x] != g.Cells[y][x] {
+						cells[coordinate{x, y}] = true
+					}
+				}
+			}
+			ReleaseClone(c)
+		}
+	}
+	return cells
+}
+
+// nearestOpponent returns the active opponent with the smallest manhattan distance to the head of the player or 0 if there is none.
+// Ties are broken towards the lower player number.
+func (a *AggressiveAI) nearestOpponent(g *Game) int {
+	me := g.Players[g.You]
+	nearest := 0
+	nearestDistance := 0
+	for id, p := range g.Players {
+		if id == g.You || !p.Active {
+			continue
+		}
+		d := abs(p.X-me.X) + abs(p.Y-me.Y)
+		if nearest == 0 || d < nearestDistance || (d == nearestDistance && id < nearest) {
+			nearest = id
+			nearestDistance = d
+		}
+	}
+	return nearest
+}
+
+// Name returns the name of the AI.
+func (a *AggressiveAI) Name() string {
+	return "AggressiveAI"
+}
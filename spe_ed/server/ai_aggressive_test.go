@@ -0,0 +1,50 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "testing"
+
+// regionAfter plays the AI against SurvivalAI on an empty 20x20 board, both starting to the right at the given positions, and returns the Voronoi region of the opponent after the given number of rounds.
+func regionAfter(t *testing.T, name string, own, opponent coordinate, rounds int) int {
+	t.Helper()
+	ai, err := CreateAI(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewSimulatorWithAIs(20, 20, 1, ai, &SurvivalAI{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	placePlayer(s, 1, own.X, own.Y, DirectionRight)
+	placePlayer(s, 2, opponent.X, opponent.Y, DirectionRight)
+	for s.Round < rounds {
+		if !s.Step() {
+			t.Fatalf("%s: game ended after %d rounds\n%s", name, s.Round, s.Game)
+		}
+	}
+	return Voronoi(s.Game, 2)
+}
+
+func TestAggressiveAIReducesRegion(t *testing.T) {
+	// The opponent starts close to the border, so the player owns the larger region.
+	for _, start := range [][2]coordinate{{{8, 10}, {15, 3}}, {{5, 5}, {17, 12}}} {
+		aggressive := regionAfter(t, "AggressiveAI", start[0], start[1], 12)
+		survival := regionAfter(t, "SurvivalAI", start[0], start[1], 12)
+		if aggressive*2 > survival {
+			t.Errorf("start %v: opponent region %d with AggressiveAI, %d with SurvivalAI", start, aggressive, survival)
+		}
+	}
+}
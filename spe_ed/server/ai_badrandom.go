@@ -21,25 +21,46 @@ import (
 )
 
 func init() {
-	err := RegisterAI("BadRandomAI", func() AI { return new(BadRandomAI) })
-	if err != nil {
-		panic(err)
-	}
+	MustRegisterAI("BadRandomAI", func() AI { return new(BadRandomAI) })
+	MustRegisterAI("BadRandomAIPessimistic", func() AI { return &BadRandomAI{Pessimistic: true} })
+	MustRegisterAI("BadRandomAICapped", func() AI { return &BadRandomAI{MaxPreferredSpeed: BadRandomAICappedSpeed} })
+	MustRegisterAI("BadRandomAIPessimisticCapped", func() AI { return &BadRandomAI{Pessimistic: true, MaxPreferredSpeed: BadRandomAICappedSpeed} })
 }
 
-// BadRandomAI is an AI that performs random actions. It explicitly does not try to avoid crashes in others, it only avoids crashes in existing filled cells.
+// BadRandomAICappedSpeed contains the preferred maximum speed of BadRandomAICapped.
+const BadRandomAICappedSpeed = 1
+
+// BadRandomAI is an AI that performs random actions. By default, it only avoids crashes in existing filled cells and does not consider the moves of other players.
+// If Pessimistic is set, it additionally avoids all cells other players might reach in the same round.
 type BadRandomAI struct {
 	l sync.Mutex
-	i chan string
+	i chan Action
+	r *rand.Rand
+
+	// Pessimistic enables avoiding all cells other players might reach in the next round (see willCrashPessimistic).
+	// If all actions might crash, the AI falls back to only avoiding existing filled cells.
+	Pessimistic bool
+
+	// MaxPreferredSpeed limits the speed the AI chooses voluntarily if it is not zero.
+	// At or above this speed, ActionFaster is only chosen if all other actions crash. Above this speed, ActionSlower is tried first.
+	MaxPreferredSpeed int
 }
 
 // GetChannel receives the answer channel.
-func (r *BadRandomAI) GetChannel(c chan string) {
+func (r *BadRandomAI) GetChannel(c chan Action) {
 	r.l.Lock()
 	defer r.l.Unlock()
 	r.i = c
 }
 
+// Seed sets the seed used for all random decisions of the AI.
+func (r *BadRandomAI) Seed(seed int64) {
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	r.r = rand.New(rand.NewSource(seed))
+}
+
 // GetState gets the game state and computes an answer.
 func (r *BadRandomAI) GetState(g *Game) {
 	r.l.Lock()
@@ -49,95 +70,48 @@ func (r *BadRandomAI) GetState(g *Game) {
 		return
 	}
 
-	if g.Running {
+	if r.r == nil {
+		r.r = rand.New(rand.NewSource(rand.Int63()))
+	}
+
+	if g.Running && g.Players[g.You].Active {
 		// actions
-		actions := []string{ActionTurnLeft, ActionTurnRight, ActionSlower, ActionFaster, ActionNOOP}
-		rand.Shuffle(len(actions), func(i, j int) { actions[i], actions[j] = actions[j], actions[i] })
+		actions := []Action{ActionTurnLeft, ActionTurnRight, ActionSlower, ActionFaster, ActionNOOP}
+		r.r.Shuffle(len(actions), func(i, j int) { actions[i], actions[j] = actions[j], actions[i] })
+		if r.MaxPreferredSpeed > 0 {
+			speed := g.Players[g.You].Speed
+			if speed >= r.MaxPreferredSpeed {
+				actions = r.moveAction(actions, ActionFaster, len(actions)-1)
+			}
+			if speed > r.MaxPreferredSpeed {
+				actions = r.moveAction(actions, ActionSlower, 0)
+			}
+		}
 
-		// test actions
-		for i := range actions {
-			// do action
-			switch actions[i] {
-			case ActionTurnLeft:
-				switch g.Players[g.You].Direction {
-				case DirectionLeft:
-					g.Players[g.You].Direction = DirectionDown
-				case DirectionRight:
-					g.Players[g.You].Direction = DirectionUp
-				case DirectionUp:
-					g.Players[g.You].Direction = DirectionLeft
-				case DirectionDown:
-					g.Players[g.You].Direction = DirectionRight
-				}
-			case ActionTurnRight:
-				switch g.Players[g.You].Direction {
-				case DirectionLeft:
-					g.Players[g.You].Direction = DirectionUp
-				case DirectionRight:
-					g.Players[g.You].Direction = DirectionDown
-				case DirectionUp:
-					g.Players[g.You].Direction = DirectionRight
-				case DirectionDown:
-					g.Players[g.You].Direction = DirectionLeft
+		occupied := NewBitboard(g)
+		if r.Pessimistic {
+			// Reachable cells are only computed once per round
+			reachable := r.pessimisticBitboard(g)
+			for i := range actions {
+				if !r.willCrashPessimistic(reachable, g, actions[i]) {
+					select {
+					case r.i <- actions[i]:
+					default:
+					}
+					return
 				}
-			case ActionFaster:
-				g.Players[g.You].Speed++
-				if g.Players[g.You].Speed > MaxSpeed {
-					g.Players[g.You].Speed--
-					continue
-				}
-			case ActionSlower:
-				g.Players[g.You].Speed--
-				if g.Players[g.You].Speed < 1 {
-					g.Players[g.You].Speed++
-					continue
-				}
-			case ActionNOOP:
-				// Do nothing
-			default:
-				log.Println("bad random ai:", "unknown action", actions[i])
 			}
+		}
 
-			// test
-			if !r.willCrash(g) {
+		// test actions
+		for i := range actions {
+			if !r.willCrash(occupied, g, actions[i]) {
 				select {
 				case r.i <- actions[i]:
 				default:
 				}
 				return
 			}
-
-			// undo action
-			switch actions[i] {
-			case ActionTurnLeft:
-				switch g.Players[g.You].Direction {
-				case DirectionLeft:
-					g.Players[g.You].Direction = DirectionUp
-				case DirectionRight:
-					g.Players[g.You].Direction = DirectionDown
-				case DirectionUp:
-					g.Players[g.You].Direction = DirectionRight
-				case DirectionDown:
-					g.Players[g.You].Direction = DirectionLeft
-				}
-			case ActionTurnRight:
-				switch g.Players[g.You].Direction {
-				case DirectionLeft:
-					g.Players[g.You].Direction = DirectionDown
-				case DirectionRight:
-					g.Players[g.You].Direction = DirectionUp
-				case DirectionUp:
-					g.Players[g.You].Direction = DirectionLeft
-				case DirectionDown:
-					g.Players[g.You].Direction = DirectionRight
-				}
-			case ActionFaster:
-				g.Players[g.You].Speed--
-			case ActionSlower:
-				g.Players[g.You].Speed++
-			case ActionNOOP:
-				// Do nothing
-			}
 		}
 
 		// no valid actions - pick random
@@ -149,43 +123,82 @@ func (r *BadRandomAI) GetState(g *Game) {
 	}
 }
 
-// willCrash computes whether the given game state will result in a (possible) crash.
-// Not safe for concurrent use on the same game.
-func (r *BadRandomAI) willCrash(g *Game) bool {
-	oldX, oldY := g.Players[g.You].X, g.Players[g.You].Y
-	defer func() {
-		g.Players[g.You].X, g.Players[g.You].Y = oldX, oldY
-	}()
-
-	var dostep func(x, y int) (int, int)
-	switch g.Players[g.You].Direction {
-	case DirectionUp:
-		dostep = func(x, y int) (int, int) { return x, y - 1 }
-	case DirectionDown:
-		dostep = func(x, y int) (int, int) { return x, y + 1 }
-	case DirectionLeft:
-		dostep = func(x, y int) (int, int) { return x - 1, y }
-	case DirectionRight:
-		dostep = func(x, y int) (int, int) { return x + 1, y }
+// moveAction moves the action to the given index, keeping the order of all other actions.
+func (r *BadRandomAI) moveAction(actions []Action, action Action, index int) []Action {
+	result := make([]Action, 0, len(actions))
+	for _, a := range actions {
+		if a != action {
+			result = append(result, a)
+		}
 	}
+	result = append(result, "")
+	copy(result[index+1:], result[index:])
+	result[index] = action
+	return result
+}
 
-	for s := 0; s < g.Players[g.You].Speed; s++ {
-		g.Players[g.You].X, g.Players[g.You].Y = dostep(g.Players[g.You].X, g.Players[g.You].Y)
-		if g.Players[g.You].X < 0 || g.Players[g.You].X >= g.Width || g.Players[g.You].Y < 0 || g.Players[g.You].Y >= g.Height {
-			return true
-		}
-		if g.Players[g.You].Speed >= HoleSpeed && (g.Players[g.You].stepCounter+1)%HolesEachStep == 0 && s != 0 && s != g.Players[g.You].Speed-1 {
+// willCrash computes whether the action results in a crash in an existing filled cell.
+// occupied must be the bitboard of the game (see NewBitboard).
+func (r *BadRandomAI) willCrash(occupied *Bitboard, g *Game, action Action) bool {
+	return occupied.Crashes(g.Players[g.You], action)
+}
+
+// willCrashPessimistic computes whether the action might result in a crash, including cells other players might reach in the same round.
+// reachable must be the bitboard returned by pessimisticBitboard for the game.
+func (r *BadRandomAI) willCrashPessimistic(reachable *Bitboard, g *Game, action Action) bool {
+	return reachable.Crashes(g.Players[g.You], action)
+}
+
+// pessimisticBitboard returns a bitboard of the game with all cells set which other active players might reach in the next round.
+// Testing actions against it avoids all possible crashes, including head-on situations where both players would enter the same cell.
+// Holes are considered, so cells behind a trail an opponent can jump over are set as well.
+func (r *BadRandomAI) pessimisticBitboard(g *Game) *Bitboard {
+	b := NewBitboard(g)
+	marked := b.Clone()
+
+	for k := range g.Players {
+		if k == g.You || !g.Players[k].Active {
 			continue
 		}
-		if g.Cells[g.Players[g.You].Y][g.Players[g.You].X] != 0 {
-			return true
+		p := g.Players[k]
+
+		// All possible actions, an invalid speed can not be reached
+		for _, a := range Actions {
+			direction, speed, err := steer(p.Direction, p.Speed, a)
+			if err != nil {
+				continue
+			}
+			dx, dy, ok := directionStep(direction)
+			if !ok {
+				continue
+			}
+			x, y := p.X, p.Y
+			for s := 0; s < speed; s++ {
+				x, y = x+dx, y+dy
+				if b.InBounds(x, y) && b.Holes.IsHole(speed, p.stepCounter+1, s) {
+					// Jumped over, the player might still reach the cells behind
+					continue
+				}
+				if b.IsOccupied(x, y) {
+					// Filled before this round or outside of the board
+					break
+				}
+				marked.Set(x, y)
+			}
 		}
 	}
 
-	return false
+	return marked
 }
 
-// Name returns the name of the AI.
+// Name returns the name of the AI, which contains every enabled option, so all configurations can be told apart in logs and statistics.
 func (r *BadRandomAI) Name() string {
-	return "BadRandomAI"
+	name := "BadRandomAI"
+	if r.Pessimistic {
+		name += "Pessimistic"
+	}
+	if r.MaxPreferredSpeed > 0 {
+		name += "Capped"
+	}
+	return name
 }
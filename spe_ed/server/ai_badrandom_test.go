@@ -0,0 +1,47 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "testing"
+
+func TestBadRandomAIPessimistic(t *testing.T) {
+	g, avoid, err := loadScenario("scenarios/headon_gap.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for seed := int64(1); seed <= 20; seed++ {
+		ai := &BadRandomAI{Pessimistic: true}
+		ai.Seed(seed)
+		a := decide(t, ai, g.Clone())
+		for _, bad := range avoid {
+			if a == bad {
+				t.Errorf("seed %d: %s enters the cell of the opponent", seed, a)
+			}
+		}
+	}
+}
+
+func TestBadRandomAIPessimisticFallback(t *testing.T) {
+	// Every action might crash, only change_nothing does not crash in a filled cell
+	g := testScenario(t, `{"you": 1, "grid": ["xxxxxxx", "11>.<22", "xxxxxxx"], "players": {"1": {}, "2": {}}}`)
+	for seed := int64(1); seed <= 20; seed++ {
+		ai := &BadRandomAI{Pessimistic: true}
+		ai.Seed(seed)
+		if a := decide(t, ai, g.Clone()); a != ActionNOOP {
+			t.Errorf("seed %d: got %s, want %s", seed, a, ActionNOOP)
+		}
+	}
+}
@@ -21,10 +21,7 @@ import (
 )
 
 func init() {
-	err := RegisterAI("ChristmasAI", func() AI { return new(ChristmasAI) })
-	if err != nil {
-		panic(err)
-	}
+	MustRegisterAI("ChristmasAI", func() AI { return new(ChristmasAI) })
 }
 
 // ChristmasAIActions contains the different forms of the ChristmasAI (in order): Tree, candle, angel, shooting star.
@@ -39,19 +36,28 @@ var ChristmasAIActions = []string{
 type ChristmasAI struct {
 	l sync.Mutex
 
-	i        chan string
+	i        chan Action
+	r        *rand.Rand
 	counter  int
 	selected string
 }
 
 // GetChannel receives the answer channel.
-func (c *ChristmasAI) GetChannel(ch chan string) {
+func (c *ChristmasAI) GetChannel(ch chan Action) {
 	c.l.Lock()
 	defer c.l.Unlock()
 
 	c.i = ch
 }
 
+// Seed sets the seed used for all random decisions of the AI.
+func (c *ChristmasAI) Seed(seed int64) {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	c.r = rand.New(rand.NewSource(seed))
+}
+
 // GetState gets the game state and computes an answer.
 func (c *ChristmasAI) GetState(g *Game) {
 	c.l.Lock()
@@ -61,8 +67,12 @@ func (c *ChristmasAI) GetState(g *Game) {
 		return
 	}
 
+	if c.r == nil {
+		c.r = rand.New(rand.NewSource(rand.Int63()))
+	}
+
 	if c.selected == "" {
-		c.selected = ChristmasAIActions[rand.Intn(len(ChristmasAIActions))]
+		c.selected = ChristmasAIActions[c.r.Intn(len(ChristmasAIActions))]
 	}
 
 	if g.Running {
@@ -0,0 +1,131 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "sync"
+
+func init() {
+	MustRegisterAI("CowardAI", func() AI { return new(CowardAI) })
+}
+
+// CowardAI is an AI which runs away from all opponents.
+// Of all legal actions (see Game.LegalActions), it chooses the one maximising the manhattan distance to the nearest active opponent (see OpponentDistance) after the move.
+// Actions leaving the player trapped (see TrapCheck) are only considered if there is no other action. It never speeds up, so it keeps the starting speed of 1.
+// Ties are broken towards the lower speed and then towards the larger reachable space (see FloodFill). Without opponents, it only maximises the reachable space.
+type CowardAI struct {
+	l sync.Mutex
+	i chan Action
+}
+
+// GetChannel receives the answer channel.
+func (c *CowardAI) GetChannel(ch chan Action) {
+	c.l.Lock()
+	defer c.l.Unlock()
+	c.i = ch
+}
+
+// GetState gets the game state and computes an answer.
+func (c *CowardAI) GetState(g *Game) {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	if c.i == nil {
+		return
+	}
+
+	if g.Running && g.Players[g.You].Active {
+		var scores map[Action]float64
+		if DecisionLogEnabled() {
+			scores = make(map[Action]float64, 4)
+		}
+		action, reason := c.decide(g, scores)
+		LogDecision(g, c.Name(), action, scores, reason)
+
+		select {
+		case c.i <- action:
+		default:
+		}
+	}
+}
+
+// Explain returns the distance to the nearest opponent after every action considered (see Explainable). Without opponents, the reachable space is returned instead.
+func (c *CowardAI) Explain(g *Game) map[Action]float64 {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	scores := make(map[Action]float64, 4)
+	if p, ok := g.Players[g.You]; ok && p.Active {
+		c.decide(g, scores)
+	}
+	return scores
+}
+
+// decide picks the action moving Game.You farthest away from the nearest opponent (see OpponentDistance), preferring actions not trapping the player, then a lower speed and then more free space.
+// Without opponents, only the free space counts. It returns the action together with the reason and adds the distance (or the free space) of every action but speed_up to scores if it is not nil. The game is not modified.
+func (c *CowardAI) decide(g *Game, scores map[Action]float64) (Action, string) {
+	var action Action
+	bestTrapped := true
+	bestDistance := 0
+	bestSpeed := 0
+	bestSpace := 0
+	reason := "maximising distance to nearest opponent"
+	if OpponentDistance(g, g.Players[g.You].X, g.Players[g.You].Y) == -1 {
+		reason = "no opponents, maximising space"
+	}
+
+	for _, a := range g.LegalActions(g.You) {
+		if a == ActionFaster {
+			continue
+		}
+		n, crashed := Simulate(g, g.You, a)
+		if crashed {
+			ReleaseClone(n)
+			continue
+		}
+		p := n.Players[n.You]
+		_, trapped := TrapCheck(n, p.X, p.Y, p.Speed)
+		distance := OpponentDistance(n, p.X, p.Y)
+		space := FloodFill(n, p.X, p.Y)
+		speed := p.Speed
+		ReleaseClone(n)
+		if scores != nil {
+			if distance == -1 {
+				scores[a] = float64(space)
+			} else {
+				scores[a] = float64(distance)
+			}
+		}
+		if action == "" || (bestTrapped && !trapped) || (bestTrapped == trapped && (distance > bestDistance || (distance == bestDistance && (speed < bestSpeed || (speed == bestSpeed && space > bestSpace))))) {
+			action = a
+			bestTrapped = trapped
+			bestDistance = distance
+			bestSpeed = speed
+			bestSpace = space
+		}
+	}
+
+	if action == "" {
+		// speed_up is never considered, so the player might still have survived by speeding up
+		action = ActionNOOP
+		reason = "every action crashes"
+	}
+	return action, reason
+}
+
+// Name returns the name of the AI.
+func (c *CowardAI) Name() string {
+	return "CowardAI"
+}
@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "testing"
+
+func TestCowardAI(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		grid    string
+		players string
+		want    Action
+	}{
+		{
+			name:    "opponent above",
+			grid:    `"..........", "......2...", "......v...", "111>......", "..........", "..........", ".........."`,
+			players: `"1": {}, "2": {}`,
+			want:    ActionTurnRight,
+		},
+		{
+			name:    "opponent below",
+			grid:    `"..........", "..........", "..........", "111>......", "..........", "......^...", "......2..."`,
+			players: `"1": {}, "2": {}`,
+			want:    ActionTurnLeft,
+		},
+		{
+			name:    "opponent ahead, blocked below",
+			grid:    `"..........", "..........", "..........", "111>.....^", "xxxxx....2", "..........", ".........."`,
+			players: `"1": {}, "2": {}`,
+			want:    ActionTurnLeft,
+		},
+		{
+			name:    "no opponent, wall ahead",
+			grid:    `"....x.....", "....x.....", "....x.....", "111>x.....", "..........", "..........", ".........."`,
+			players: `"1": {}`,
+			want:    ActionTurnRight,
+		},
+	} {
+		g := testScenario(t, `{"you": 1, "grid": [`+tc.grid+`], "players": {`+tc.players+`}}`)
+		if got := decide(t, &CowardAI{}, g); got != tc.want {
+			t.Errorf("%s: %s, want %s (scores %v)\n%s", tc.name, got, tc.want, (&CowardAI{}).Explain(g), g)
+		}
+	}
+}
+
+func TestCowardAIExplain(t *testing.T) {
+	g := testScenario(t, `{"you": 1, "grid": ["..........", "......2...", "......v...", "111>......", "..........", "..........", ".........."], "players": {"1": {}, "2": {}}}`)
+	// The opponent head is at (6, 2), the player moves from (3, 3)
+	want := map[Action]float64{ActionNOOP: 3, ActionTurnLeft: 3, ActionTurnRight: 5}
+	got := (&CowardAI{}).Explain(g)
+	if len(got) != len(want) {
+		t.Errorf("scores %v, want %v", got, want)
+	}
+	for a, d := range want {
+		if got[a] != d {
+			t.Errorf("%s: distance %v, want %v", a, got[a], d)
+		}
+	}
+}
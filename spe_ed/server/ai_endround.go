@@ -18,21 +18,18 @@ package main
 import "sync"
 
 func init() {
-	err := RegisterAI("EndRound", func() AI { return new(EndRound) })
-	if err != nil {
-		panic(err)
-	}
+	MustRegisterAI("EndRound", func() AI { return new(EndRound) })
 }
 
 // EndRound is a simple AI that always returns the "change_nothing" action.
 type EndRound struct {
 	l sync.Mutex
 
-	i chan string
+	i chan Action
 }
 
 // GetChannel receives the answer channel.
-func (er *EndRound) GetChannel(c chan string) {
+func (er *EndRound) GetChannel(c chan Action) {
 	er.l.Lock()
 	defer er.l.Unlock()
 
@@ -0,0 +1,138 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "sync"
+
+func init() {
+	MustRegisterAI("FloodFillAI", func() AI { return new(FloodFillAI) })
+}
+
+// FloodFillAI is an AI which chooses the action leaving the largest number of reachable free cells (calculated with a flood fill from the new position).
+// Ties are broken towards the higher speed.
+// Actions trapping the player in a small pocket (see TrapCheck) are avoided. If all actions trap the player, the one leaving the largest pocket is chosen.
+type FloodFillAI struct {
+	l sync.Mutex
+
+	i chan Action
+}
+
+// GetChannel receives the answer channel.
+func (ff *FloodFillAI) GetChannel(c chan Action) {
+	ff.l.Lock()
+	defer ff.l.Unlock()
+
+	ff.i = c
+}
+
+// GetState gets the game state and computes an answer.
+func (ff *FloodFillAI) GetState(g *Game) {
+	ff.l.Lock()
+	defer ff.l.Unlock()
+
+	if ff.i == nil {
+		return
+	}
+
+	if g.Running && g.Players[g.You].Active {
+		var scores map[Action]float64
+		if DecisionLogEnabled() {
+			scores = make(map[Action]float64, 5)
+		}
+		action, reason := ff.decide(g, scores)
+		LogDecision(g, ff.Name(), action, scores, reason)
+
+		select {
+		case ff.i <- action:
+		default:
+		}
+	}
+}
+
+// Explain returns the scores of all actions not crashing immediately (see Explainable).
+// The score is the number of reachable free cells, or the size of the pocket (see TrapCheck) if the action traps the player. Actions not trapping the player are always preferred.
+func (ff *FloodFillAI) Explain(g *Game) map[Action]float64 {
+	ff.l.Lock()
+	defer ff.l.Unlock()
+
+	scores := make(map[Action]float64, 5)
+	if p, ok := g.Players[g.You]; ok && p.Active {
+		ff.decide(g, scores)
+	}
+	return scores
+}
+
+// Name returns the name of the AI.
+func (ff *FloodFillAI) Name() string {
+	return "FloodFillAI"
+}
+
+// decide picks the action leaving Game.You the most reachable free cells (see FloodFill), breaking ties by the higher speed. If every action traps the player, the largest pocket (see TrapCheck) is taken instead.
+// It returns the action together with the reason and adds the free cells or the pocket size of every action to scores if it is not nil. The game is not modified.
+func (ff *FloodFillAI) decide(g *Game, scores map[Action]float64) (Action, string) {
+	var action Action
+	best := -1
+	bestSpeed := 0
+	var trappedAction Action
+	bestPocket := -1
+	reason := "largest reachable space"
+
+	b := NewBitboard(g)
+	actions := []Action{ActionNOOP, ActionTurnLeft, ActionTurnRight, ActionSlower, ActionFaster}
+	for a := range actions {
+		if b.Crashes(g.Players[g.You], actions[a]) {
+			continue
+		}
+		c, crashed := Simulate(g, g.You, actions[a])
+		if !crashed {
+			p := c.Players[c.You]
+			pocket, trapped := TrapCheck(c, p.X, p.Y, p.Speed)
+			if trapped {
+				if scores != nil {
+					scores[actions[a]] = float64(pocket)
+				}
+				if pocket > bestPocket {
+					bestPocket = pocket
+					trappedAction = actions[a]
+				}
+			} else {
+				free := FloodFill(c, p.X, p.Y)
+				if scores != nil {
+					scores[actions[a]] = float64(free)
+				}
+				if free > best || (free == best && p.Speed > bestSpeed) {
+					best = free
+					bestSpeed = p.Speed
+					action = actions[a]
+				}
+			}
+		}
+		ReleaseClone(c)
+	}
+
+	if action == "" {
+		// All actions trap us - take the largest pocket
+		action = trappedAction
+		reason = "trapped, largest pocket"
+	}
+
+	if action == "" {
+		// Not even a pocket is left
+		action = ActionNOOP
+		reason = "every action crashes"
+	}
+	return action, reason
+}
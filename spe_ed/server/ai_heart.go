@@ -18,25 +18,22 @@ package main
 import "sync"
 
 func init() {
-	err := RegisterAI("HeartAI", func() AI { return new(HeartAI) })
-	if err != nil {
-		panic(err)
-	}
+	MustRegisterAI("HeartAI", func() AI { return new(HeartAI) })
 }
 
 // HeartAIActions contains the actions needed to draw a heart onto the game board. The last action will do a crash.
-var HeartAIActions = []string{ActionNOOP, ActionTurnLeft, ActionTurnRight, ActionTurnLeft, ActionTurnRight, ActionTurnLeft, ActionTurnRight, ActionNOOP, ActionTurnRight, ActionTurnLeft, ActionTurnRight, ActionTurnRight, ActionTurnLeft, ActionNOOP, ActionTurnLeft, ActionTurnRight, ActionTurnRight, ActionTurnLeft, ActionTurnRight, ActionNOOP, ActionTurnRight, ActionTurnLeft, ActionTurnRight, ActionTurnLeft, ActionTurnRight}
+var HeartAIActions = []Action{ActionNOOP, ActionTurnLeft, ActionTurnRight, ActionTurnLeft, ActionTurnRight, ActionTurnLeft, ActionTurnRight, ActionNOOP, ActionTurnRight, ActionTurnLeft, ActionTurnRight, ActionTurnRight, ActionTurnLeft, ActionNOOP, ActionTurnLeft, ActionTurnRight, ActionTurnRight, ActionTurnLeft, ActionTurnRight, ActionNOOP, ActionTurnRight, ActionTurnLeft, ActionTurnRight, ActionTurnLeft, ActionTurnRight}
 
 // HeartAI is an AI that draws a heart.
 type HeartAI struct {
 	l sync.Mutex
 
-	i       chan string
+	i       chan Action
 	counter int
 }
 
 // GetChannel receives the answer channel.
-func (h *HeartAI) GetChannel(c chan string) {
+func (h *HeartAI) GetChannel(c chan Action) {
 	h.l.Lock()
 	defer h.l.Unlock()
 
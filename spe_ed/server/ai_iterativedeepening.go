@@ -0,0 +1,186 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+func init() {
+	MustRegisterAI("IterativeDeepeningAI", func() AI { return new(IterativeDeepeningAI) })
+	MustRegisterAI("IterativeDeepeningAIMobility", func() AI {
+		return &IterativeDeepeningAI{Search: &MinimaxAI{Mobility: MinimaxAIMobilityWeight}, name: "IterativeDeepeningAIMobility"}
+	})
+}
+
+const (
+	// IterativeDeepeningAIMargin contains the default time IterativeDeepeningAI reserves before the deadline for sending the answer.
+	IterativeDeepeningAIMargin = 500 * time.Millisecond
+	// IterativeDeepeningAIBudget contains the time IterativeDeepeningAI uses if the game has no deadline.
+	IterativeDeepeningAIBudget = 1 * time.Second
+	// IterativeDeepeningAIMaxDepth contains the default maximum depth of IterativeDeepeningAI.
+	IterativeDeepeningAIMaxDepth = 50
+)
+
+// DepthLimitedSearch is a search with a fixed depth, which can be used by IterativeDeepeningAI.
+type DepthLimitedSearch interface {
+	// Search returns the best action for Game.You searching depth rounds.
+	// If the context is cancelled before the search has finished, Search must return quickly with false as second return value.
+	// Search might modify the game.
+	Search(ctx context.Context, g *Game, depth int) (Action, bool)
+}
+
+// NodeCounter is an optional interface for a DepthLimitedSearch which counts the positions it searched.
+// Nodes returns the number of positions searched by the last call of Search.
+type NodeCounter interface {
+	Nodes() int
+}
+
+// IterativeDeepeningAI is an AI which deepens a depth-limited search one round at a time until the time runs out.
+// The action of the deepest completed search is sent Margin before the deadline, even if the current search has not returned yet.
+// If not even the first depth finishes, the first action not crashing immediately is sent.
+// If the search implements NodeCounter, a depth is not started if it is not expected to finish in time, estimated from the time per position measured in the previous rounds (see SearchBudget).
+// On boards with more cells than allowed by SetMaxBoardCells, it plays like FloodFillAI instead of searching.
+type IterativeDeepeningAI struct {
+	l sync.Mutex
+
+	i     chan Action
+	cheap FloodFillAI
+
+	// Search is the wrapped search. A MinimaxAI is used if it is nil.
+	Search DepthLimitedSearch
+	// Margin is the time reserved before the deadline. IterativeDeepeningAIMargin is used if it is zero.
+	Margin time.Duration
+	// MaxDepth is the maximum depth. IterativeDeepeningAIMaxDepth is used if it is zero.
+	MaxDepth int
+	// Utilization is the share of the remaining time used for the search (see SearchBudget). GetSearchUtilization is used if it is zero.
+	Utilization float64
+
+	// name is the name the AI was registered with, see Name
+	name   string
+	budget SearchBudget
+}
+
+// GetChannel receives the answer channel.
+func (id *IterativeDeepeningAI) GetChannel(c chan Action) {
+	id.l.Lock()
+	defer id.l.Unlock()
+
+	id.i = c
+	id.cheap.GetChannel(c)
+}
+
+// GetState gets the game state and computes an answer.
+func (id *IterativeDeepeningAI) GetState(g *Game) {
+	id.GetStateContext(context.Background(), g)
+}
+
+// GetStateContext gets the game state and computes an answer.
+// If the context is cancelled, the action of the deepest completed search is sent.
+func (id *IterativeDeepeningAI) GetStateContext(ctx context.Context, g *Game) {
+	id.l.Lock()
+	defer id.l.Unlock()
+
+	if id.i == nil {
+		return
+	}
+
+	if g.Running && g.Players[g.You].Active {
+		if degradeOnLargeBoard(g, id.Name()) {
+			id.cheap.GetState(g)
+			return
+		}
+
+		if id.Search == nil {
+			id.Search = new(MinimaxAI)
+		}
+		margin := id.Margin
+		if margin <= 0 {
+			margin = IterativeDeepeningAIMargin
+		}
+		maxDepth := id.MaxDepth
+		if maxDepth <= 0 {
+			maxDepth = IterativeDeepeningAIMaxDepth
+		}
+
+		start := time.Now()
+		id.budget.Utilization = id.Utilization
+		target := id.budget.Target(g, margin, IterativeDeepeningAIBudget)
+		ctx, cancel := context.WithDeadline(ctx, start.Add(target))
+		counter, counting := id.Search.(NodeCounter)
+		defer cancel()
+
+		// Fallback in case not even the first depth finishes in time
+		action := ActionNOOP
+		if legal := g.LegalActions(g.You); len(legal) != 0 {
+			action = legal[0]
+		}
+
+		type result struct {
+			action Action
+			ok     bool
+		}
+
+		prevNodes, nodes := 0, 0
+	deepening:
+		for depth := 1; depth <= maxDepth; depth++ {
+			if counting && depth > 1 && !id.budget.Fits(time.Since(start), target, ExpectedNodes(prevNodes, nodes)) {
+				break
+			}
+			depthStart := time.Now()
+			done := make(chan result, 1)
+			go func(g *Game, depth int) {
+				a, ok := id.Search.Search(ctx, g, depth)
+				done <- result{a, ok}
+			}(g.Clone(), depth)
+
+			select {
+			case r := <-done:
+				if !r.ok {
+					break deepening
+				}
+				action = r.action
+				if counting {
+					prevNodes, nodes = nodes, counter.Nodes()
+					id.budget.Observe(nodes, time.Since(depthStart))
+				}
+			case <-ctx.Done():
+				// Answer first, then wait for the search to stop so it is never run concurrently
+				select {
+				case id.i <- action:
+				default:
+				}
+				<-done
+				return
+			}
+		}
+
+		select {
+		case id.i <- action:
+		default:
+		}
+	}
+}
+
+// Name returns the name the AI was registered with, so the variants can be told apart in logs and statistics.
+func (id *IterativeDeepeningAI) Name() string {
+	if id.name == "" {
+		return "IterativeDeepeningAI"
+	}
+	return id.name
+}
@@ -21,10 +21,7 @@ import (
 )
 
 func init() {
-	err := RegisterAI("JumpAI", func() AI { return new(JumpAI) })
-	if err != nil {
-		panic(err)
-	}
+	MustRegisterAI("JumpAI", func() AI { return new(JumpAI) })
 }
 
 const (
@@ -32,35 +29,31 @@ const (
 	JumpAITries = 100
 )
 
-const (
-	jumpAIprogressNormal = iota
-	jumpAIprogressJump
-	jumpAIprogressCrash
-)
-
-type jumpAIRevert struct {
-	X, Y, Speed, stepCounter int
-	Direction                string
-	Cells                    []struct{ X, Y int }
-}
-
 // JumpAI tries to find a possible jump and then tries to execute it if possible. If no jump is found, it behaves like RandomAI.
 type JumpAI struct {
 	l sync.Mutex
 
-	i    chan string
-	plan []string
+	i    chan Action
+	plan []Action
 	r    *rand.Rand
 }
 
 // GetChannel receives the answer channel.
-func (j *JumpAI) GetChannel(c chan string) {
+func (j *JumpAI) GetChannel(c chan Action) {
 	j.l.Lock()
 	defer j.l.Unlock()
 
 	j.i = c
 }
 
+// Seed sets the seed used for all random decisions of the AI.
+func (j *JumpAI) Seed(seed int64) {
+	j.l.Lock()
+	defer j.l.Unlock()
+
+	j.r = rand.New(rand.NewSource(seed))
+}
+
 // GetState gets the game state and computes an answer.
 func (j *JumpAI) GetState(g *Game) {
 	j.l.Lock()
@@ -86,28 +79,28 @@ func (j *JumpAI) GetState(g *Game) {
 				if x < 0 || x >= g.Width || y < 0 || y >= g.Height {
 					// invalid - do nothing
 				} else {
-					g.Cells[y][x] = -100
+					g.Cells[y][x] = CellDanger
 				}
 
 				x, y = g.Players[k].X-i, g.Players[k].Y
 				if x < 0 || x >= g.Width || y < 0 || y >= g.Height {
 					// invalid - do nothing
 				} else {
-					g.Cells[y][x] = -100
+					g.Cells[y][x] = CellDanger
 				}
 
 				x, y = g.Players[k].X, g.Players[k].Y+i
 				if x < 0 || x >= g.Width || y < 0 || y >= g.Height {
 					// invalid - do nothing
 				} else {
-					g.Cells[y][x] = -100
+					g.Cells[y][x] = CellDanger
 				}
 
 				x, y = g.Players[k].X, g.Players[k].Y-i
 				if x < 0 || x >= g.Width || y < 0 || y >= g.Height {
 					// invalid - do nothing
 				} else {
-					g.Cells[y][x] = -100
+					g.Cells[y][x] = CellDanger
 				}
 			}
 		}
@@ -123,18 +116,19 @@ func (j *JumpAI) GetState(g *Game) {
 				j.r = rand.New(rand.NewSource(rand.Int63()))
 			}
 
-			length := HolesEachStep - (g.Players[g.You].stepCounter % HolesEachStep)
+			length := g.Holes.Interval() - (g.Players[g.You].stepCounter % g.Holes.Interval())
 
 			// Try finding jump
 			j.plan = j.findPlan(length, g.PublicCopy())
 
 			if len(j.plan) == 0 {
 				// Try finding 1 step - reuse RandomAI
-				c := make(chan string, 1)
+				c := make(chan Action, 1)
 				ai := RandomAI{}
+				ai.Seed(j.r.Int63())
 				ai.GetChannel(c)
 				ai.GetState(g)
-				j.plan = []string{<-c}
+				j.plan = []Action{<-c}
 			}
 		}
 		action := j.plan[0]
@@ -151,231 +145,57 @@ func (j *JumpAI) Name() string {
 // findPlan will try to find a plan containing a jump with a maximum of length steps.
 // Function will return nil if no plan is found.
 // Not safe for concurrent use.
-func (j *JumpAI) findPlan(length int, g *Game) []string {
+func (j *JumpAI) findPlan(length int, g *Game) []Action {
 	length--
 	if length < 0 {
 		return nil
 	}
-	actions := []string{ActionTurnLeft, ActionTurnRight, ActionSlower, ActionFaster, ActionNOOP}
+	actions := []Action{ActionTurnLeft, ActionTurnRight, ActionSlower, ActionFaster, ActionNOOP}
 	j.r.Shuffle(len(actions), func(i, j int) { actions[i], actions[j] = actions[j], actions[i] })
 
 	for i := range actions {
-		result, revert := j.progress(g, g.You, actions[i])
-		switch result {
-		case jumpAIprogressCrash:
-			j.revert(g, g.You, revert)
+		move, ok := ApplyMove(g, g.You, actions[i])
+		switch {
+		case !ok:
+			UndoMove(g, move)
 			continue
-		case jumpAIprogressNormal:
+		case move.Jumped():
+			UndoMove(g, move)
+			return []Action{actions[i]}
+		default:
 			plan := j.findPlan(length, g)
-			j.revert(g, g.You, revert)
+			UndoMove(g, move)
 			if plan == nil {
 				continue
 			}
-			plan = append([]string{actions[i]}, plan...)
+			plan = append([]Action{actions[i]}, plan...)
 			return plan
-		case jumpAIprogressJump:
-			j.revert(g, g.You, revert)
-			return []string{actions[i]}
 		}
 	}
 
 	return nil
 }
 
-// progress will progress the game by one step and return the result.
-// Not safe for concurrent use on the same game.
-func (j *JumpAI) progress(g *Game, player int, command string) (int, jumpAIRevert) {
-	p := g.Players[player]
-	r := jumpAIRevert{
-		X:           p.X,
-		Y:           p.Y,
-		Speed:       p.Speed,
-		stepCounter: p.stepCounter,
-		Direction:   p.Direction,
-		Cells:       make([]struct{ X, Y int }, 0, p.Speed),
-	}
-	jump := false
-	switch command {
-	case ActionTurnLeft:
-		switch p.Direction {
-		case DirectionLeft:
-			p.Direction = DirectionDown
-		case DirectionRight:
-			p.Direction = DirectionUp
-		case DirectionUp:
-			p.Direction = DirectionLeft
-		case DirectionDown:
-			p.Direction = DirectionRight
-		}
-	case ActionTurnRight:
-		switch p.Direction {
-		case DirectionLeft:
-			p.Direction = DirectionUp
-		case DirectionRight:
-			p.Direction = DirectionDown
-		case DirectionUp:
-			p.Direction = DirectionRight
-		case DirectionDown:
-			p.Direction = DirectionLeft
-		}
-	case ActionFaster:
-		p.Speed++
-		if p.Speed > MaxSpeed {
-			return jumpAIprogressCrash, r
-		}
-	case ActionSlower:
-		p.Speed--
-		if p.Speed < 1 {
-			return jumpAIprogressCrash, r
-		}
-	case ActionNOOP:
-		// Do nothing
-	default:
-		log.Println("jump ai:", "unknown action", command)
-	}
-
-	var dostep func(x, y int) (int, int)
-	switch p.Direction {
-	case DirectionUp:
-		dostep = func(x, y int) (int, int) { return x, y - 1 }
-	case DirectionDown:
-		dostep = func(x, y int) (int, int) { return x, y + 1 }
-	case DirectionLeft:
-		dostep = func(x, y int) (int, int) { return x - 1, y }
-	case DirectionRight:
-		dostep = func(x, y int) (int, int) { return x + 1, y }
-	}
-
-	p.stepCounter++
-
-	for s := 0; s < p.Speed; s++ {
-		p.X, p.Y = dostep(p.X, p.Y)
-		if p.X < 0 || p.X >= g.Width || p.Y < 0 || p.Y >= g.Height {
-			return jumpAIprogressCrash, r
-		}
-		if p.Speed >= HoleSpeed && p.stepCounter%HolesEachStep == 0 && s != 0 && s != p.Speed-1 {
-			if g.Cells[p.Y][p.X] != 0 {
-				jump = true
-			}
-			continue
-		}
-		if g.Cells[p.Y][p.X] != 0 {
-			return jumpAIprogressCrash, r
-		}
-		r.Cells = append(r.Cells, struct{ X, Y int }{p.X, p.Y})
-		g.Cells[p.Y][p.X] = -33
-	}
-
-	if jump {
-		return jumpAIprogressJump, r
-	}
-	return jumpAIprogressNormal, r
-}
-
-// revert reverts the game state by the revert struct.
-// Not safe for cocurrent use on the same game.
-func (j *JumpAI) revert(g *Game, player int, r jumpAIRevert) {
-	p := g.Players[player]
-	p.X = r.X
-	p.Y = r.Y
-	p.Speed = r.Speed
-	p.stepCounter = r.stepCounter
-	p.Direction = r.Direction
-	for i := range r.Cells {
-		g.Cells[r.Cells[i].Y][r.Cells[i].X] = 0
-	}
-}
-
 // executePlan returns true if given plan jumps over SOMETHING.
 // It is not safe for concurrent usage on the same game, however it will revert the game to the initial state given to the function.
-func (j *JumpAI) executePlan(g *Game, plan []string) bool {
-	revert := make([]struct{ X, Y int }, 0, 60)
+func (j *JumpAI) executePlan(g *Game, plan []Action) bool {
+	moves := make([]Move, 0, len(plan))
 	defer func() {
-		// Revert cells
-		for i := range revert {
-			g.Cells[revert[i].Y][revert[i].X] = 0
-		}
+		// Revert moves
+		undoMoves(g, moves)
 	}()
 
-	sc := g.Players[g.You].stepCounter
-	direction := g.Players[g.You].Direction
-	speed := g.Players[g.You].Speed
-	x, y := g.Players[g.You].X, g.Players[g.You].Y
-
 	// Execute plan
 	jump := false
 	for i := range plan {
-		switch plan[i] {
-		case ActionTurnLeft:
-			switch direction {
-			case DirectionLeft:
-				direction = DirectionDown
-			case DirectionRight:
-				direction = DirectionUp
-			case DirectionUp:
-				direction = DirectionLeft
-			case DirectionDown:
-				direction = DirectionRight
-			}
-		case ActionTurnRight:
-			switch direction {
-			case DirectionLeft:
-				direction = DirectionUp
-			case DirectionRight:
-				direction = DirectionDown
-			case DirectionUp:
-				direction = DirectionRight
-			case DirectionDown:
-				direction = DirectionLeft
-			}
-		case ActionFaster:
-			speed++
-			if speed > MaxSpeed {
-				return false
-			}
-		case ActionSlower:
-			speed--
-			if speed < 1 {
-				return false
-			}
-		case ActionNOOP:
-			// Do nothing
-		default:
-			log.Println("jump ai:", "unknown action", plan[i])
+		move, ok := ApplyMove(g, g.You, plan[i])
+		moves = append(moves, move)
+		if !ok {
+			return false
 		}
-
-		var dostep func(x, y int) (int, int)
-		switch direction {
-		case DirectionUp:
-			dostep = func(x, y int) (int, int) { return x, y - 1 }
-		case DirectionDown:
-			dostep = func(x, y int) (int, int) { return x, y + 1 }
-		case DirectionLeft:
-			dostep = func(x, y int) (int, int) { return x - 1, y }
-		case DirectionRight:
-			dostep = func(x, y int) (int, int) { return x + 1, y }
+		if move.Jumped() {
+			jump = true
 		}
-
-		sc++
-
-		for s := 0; s < speed; s++ {
-			x, y = dostep(x, y)
-			if x < 0 || x >= g.Width || y < 0 || y >= g.Height {
-				return false
-			}
-			if speed >= HoleSpeed && sc%HolesEachStep == 0 && s != 0 && s != speed-1 {
-				if g.Cells[y][x] != 0 {
-					jump = true
-				}
-				continue
-			}
-			if g.Cells[y][x] != 0 {
-				return false
-			}
-			g.Cells[y][x] = -33
-			revert = append(revert, struct{ X, Y int }{x, y})
-		}
-
 	}
 
 	return jump
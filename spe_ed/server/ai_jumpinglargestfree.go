@@ -16,14 +16,12 @@
 package main
 
 import (
+	"math/rand"
 	"sync"
 )
 
 func init() {
-	err := RegisterAI("JumpingLargestFreeAI", func() AI { return new(JumpingLargestFreeAI) })
-	if err != nil {
-		panic(err)
-	}
+	MustRegisterAI("JumpingLargestFreeAI", func() AI { return new(JumpingLargestFreeAI) })
 }
 
 // JumpingLargestFreeAIJumpAtLessThanFree is the number of free cells connected at which the AI tries to jump.
@@ -33,14 +31,15 @@ const JumpingLargestFreeAIJumpAtLessThanFree = 50
 type JumpingLargestFreeAI struct {
 	l sync.Mutex
 
-	i                 chan string
+	i                 chan Action
+	r                 *rand.Rand
 	largestfree       AI
 	jump              AI
 	freeCountingSlice []bool
 }
 
 // GetChannel receives the answer channel.
-func (jlf *JumpingLargestFreeAI) GetChannel(c chan string) {
+func (jlf *JumpingLargestFreeAI) GetChannel(c chan Action) {
 	jlf.l.Lock()
 	defer jlf.l.Unlock()
 
@@ -55,6 +54,14 @@ func (jlf *JumpingLargestFreeAI) GetChannel(c chan string) {
 	}
 }
 
+// Seed sets the seed used for all random decisions of the AI.
+func (jlf *JumpingLargestFreeAI) Seed(seed int64) {
+	jlf.l.Lock()
+	defer jlf.l.Unlock()
+
+	jlf.r = rand.New(rand.NewSource(seed))
+}
+
 // GetState gets the game state and computes an answer.
 func (jlf *JumpingLargestFreeAI) GetState(g *Game) {
 	jlf.l.Lock()
@@ -64,6 +71,10 @@ func (jlf *JumpingLargestFreeAI) GetState(g *Game) {
 		return
 	}
 
+	if jlf.r == nil {
+		jlf.r = rand.New(rand.NewSource(rand.Int63()))
+	}
+
 	if jlf.largestfree == nil {
 		jlf.largestfree = new(LargestFreeAI)
 		jlf.largestfree.GetChannel(jlf.i)
@@ -72,108 +83,26 @@ func (jlf *JumpingLargestFreeAI) GetState(g *Game) {
 	if g.Running && g.Players[g.You].Active {
 		if jlf.freeSpaceConnected(g.Players[g.You].X, g.Players[g.You].Y, JumpingLargestFreeAIJumpAtLessThanFree+1, g) < JumpingLargestFreeAIJumpAtLessThanFree {
 			if jlf.jump == nil {
-				jlf.jump = new(JumpAI)
+				jump := new(JumpAI)
+				jump.Seed(jlf.r.Int63())
+				jlf.jump = jump
 				jlf.jump.GetChannel(jlf.i)
 			}
 			jlf.jump.GetState(g)
 		} else if g.Players[g.You].Speed > 1 {
-			// Check slow_down
-			var dostep func(x, y int) (int, int)
-			possible := true
-			x, y := g.Players[g.You].X, g.Players[g.You].Y
-
-			switch g.Players[g.You].Direction {
-			case DirectionUp:
-				dostep = func(x, y int) (int, int) { return x, y - 1 }
-			case DirectionDown:
-				dostep = func(x, y int) (int, int) { return x, y + 1 }
-			case DirectionLeft:
-				dostep = func(x, y int) (int, int) { return x - 1, y }
-			case DirectionRight:
-				dostep = func(x, y int) (int, int) { return x + 1, y }
-			}
-
-			for i := 0; i < g.Players[g.You].Speed-1; i++ {
-				x, y = dostep(x, y)
-				if x < 0 || x >= g.Width || y < 0 || y >= g.Height || g.Cells[y][x] != 0 {
-					possible = false
+			// Slow down or turn without crashing
+			action := ActionSlower
+			b := NewBitboard(g)
+			for _, a := range []Action{ActionSlower, ActionTurnLeft, ActionTurnRight} {
+				if !b.Crashes(g.Players[g.You], a) {
+					action = a
 					break
 				}
 			}
 
-			if possible {
-				select {
-				case jlf.i <- ActionSlower:
-				default:
-				}
-				return
-			}
-
-			// Check turn_left
-			possible = true
-			x, y = g.Players[g.You].X, g.Players[g.You].Y
-
-			switch g.Players[g.You].Direction {
-			case DirectionLeft:
-				dostep = func(x, y int) (int, int) { return x, y - 1 }
-			case DirectionRight:
-				dostep = func(x, y int) (int, int) { return x, y + 1 }
-			case DirectionDown:
-				dostep = func(x, y int) (int, int) { return x - 1, y }
-			case DirectionUp:
-				dostep = func(x, y int) (int, int) { return x + 1, y }
-			}
-
-			for i := 0; i < g.Players[g.You].Speed; i++ {
-				x, y = dostep(x, y)
-				if x < 0 || x >= g.Width || y < 0 || y >= g.Height || g.Cells[y][x] != 0 {
-					possible = false
-					break
-				}
-			}
-
-			if possible {
-				select {
-				case jlf.i <- ActionTurnLeft:
-				default:
-				}
-				return
-			}
-
-			// Check turn_right
-			possible = true
-			x, y = g.Players[g.You].X, g.Players[g.You].Y
-
-			switch g.Players[g.You].Direction {
-			case DirectionRight:
-				dostep = func(x, y int) (int, int) { return x, y - 1 }
-			case DirectionLeft:
-				dostep = func(x, y int) (int, int) { return x, y + 1 }
-			case DirectionUp:
-				dostep = func(x, y int) (int, int) { return x - 1, y }
-			case DirectionDown:
-				dostep = func(x, y int) (int, int) { return x + 1, y }
-			}
-
-			for i := 0; i < g.Players[g.You].Speed; i++ {
-				x, y = dostep(x, y)
-				if x < 0 || x >= g.Width || y < 0 || y >= g.Height || g.Cells[y][x] != 0 {
-					possible = false
-					break
-				}
-			}
-
-			if possible {
-				select {
-				case jlf.i <- ActionTurnRight:
-				default:
-				}
-				return
-			}
-
-			// nothing to survive, so....
+			// If nothing survives, slow down anyway
 			select {
-			case jlf.i <- ActionSlower:
+			case jlf.i <- action:
 			default:
 			}
 		} else {
@@ -229,7 +158,7 @@ func (jlf *JumpingLargestFreeAI) freeSpaceConnectedInternal(x, y, cutoff, curren
 	}
 	jlf.freeCountingSlice[cell] = true
 
-	if g.Cells[y][x] != 0 {
+	if !IsEmpty(g.Cells[y][x]) {
 		return current
 	}
 	current++
@@ -16,14 +16,12 @@
 package main
 
 import (
+	"math/rand"
 	"sync"
 )
 
 func init() {
-	err := RegisterAI("JumpingSnailAI", func() AI { return new(JumpingSnailAI) })
-	if err != nil {
-		panic(err)
-	}
+	MustRegisterAI("JumpingSnailAI", func() AI { return new(JumpingSnailAI) })
 }
 
 // JumpingSnailAIJumpAtLessThanFree is the number of free cells connected at which the AI tries to jump.
@@ -33,14 +31,15 @@ const JumpingSnailAIJumpAtLessThanFree = 50
 type JumpingSnailAI struct {
 	l sync.Mutex
 
-	i                 chan string
+	i                 chan Action
+	r                 *rand.Rand
 	snail             AI
 	jump              AI
 	freeCountingSlice []bool
 }
 
 // GetChannel receives the answer channel.
-func (js *JumpingSnailAI) GetChannel(c chan string) {
+func (js *JumpingSnailAI) GetChannel(c chan Action) {
 	js.l.Lock()
 	defer js.l.Unlock()
 
@@ -55,6 +54,14 @@ func (js *JumpingSnailAI) GetChannel(c chan string) {
 	}
 }
 
+// Seed sets the seed used for all random decisions of the AI.
+func (js *JumpingSnailAI) Seed(seed int64) {
+	js.l.Lock()
+	defer js.l.Unlock()
+
+	js.r = rand.New(rand.NewSource(seed))
+}
+
 // GetState gets the game state and computes an answer.
 func (js *JumpingSnailAI) GetState(g *Game) {
 	js.l.Lock()
@@ -64,117 +71,41 @@ func (js *JumpingSnailAI) GetState(g *Game) {
 		return
 	}
 
+	if js.r == nil {
+		js.r = rand.New(rand.NewSource(rand.Int63()))
+	}
+
 	if js.snail == nil {
-		js.snail = new(SuperSnailAI)
+		snail := new(SuperSnailAI)
+		snail.Seed(js.r.Int63())
+		js.snail = snail
 		js.snail.GetChannel(js.i)
 	}
 
 	if g.Running && g.Players[g.You].Active {
 		if js.freeSpaceConnected(g.Players[g.You].X, g.Players[g.You].Y, JumpingSnailAIJumpAtLessThanFree+1, g) < JumpingSnailAIJumpAtLessThanFree {
 			if js.jump == nil {
-				js.jump = new(JumpAI)
+				jump := new(JumpAI)
+				jump.Seed(js.r.Int63())
+				js.jump = jump
 				js.jump.GetChannel(js.i)
 			}
 			js.jump.GetState(g)
 		} else if g.Players[g.You].Speed > 1 {
 
-			// Check slow_down
-			var dostep func(x, y int) (int, int)
-			possible := true
-			x, y := g.Players[g.You].X, g.Players[g.You].Y
-
-			switch g.Players[g.You].Direction {
-			case DirectionUp:
-				dostep = func(x, y int) (int, int) { return x, y - 1 }
-			case DirectionDown:
-				dostep = func(x, y int) (int, int) { return x, y + 1 }
-			case DirectionLeft:
-				dostep = func(x, y int) (int, int) { return x - 1, y }
-			case DirectionRight:
-				dostep = func(x, y int) (int, int) { return x + 1, y }
-			}
-
-			for i := 0; i < g.Players[g.You].Speed-1; i++ {
-				x, y = dostep(x, y)
-				if x < 0 || x >= g.Width || y < 0 || y >= g.Height || g.Cells[y][x] != 0 {
-					possible = false
+			// Slow down or turn without crashing
+			action := ActionSlower
+			b := NewBitboard(g)
+			for _, a := range []Action{ActionSlower, ActionTurnLeft, ActionTurnRight} {
+				if !b.Crashes(g.Players[g.You], a) {
+					action = a
 					break
 				}
 			}
 
-			if possible {
-				select {
-				case js.i <- ActionSlower:
-				default:
-				}
-				return
-			}
-
-			// Check turn_left
-			possible = true
-			x, y = g.Players[g.You].X, g.Players[g.You].Y
-
-			switch g.Players[g.You].Direction {
-			case DirectionLeft:
-				dostep = func(x, y int) (int, int) { return x, y - 1 }
-			case DirectionRight:
-				dostep = func(x, y int) (int, int) { return x, y + 1 }
-			case DirectionDown:
-				dostep = func(x, y int) (int, int) { return x - 1, y }
-			case DirectionUp:
-				dostep = func(x, y int) (int, int) { return x + 1, y }
-			}
-
-			for i := 0; i < g.Players[g.You].Speed; i++ {
-				x, y = dostep(x, y)
-				if x < 0 || x >= g.Width || y < 0 || y >= g.Height || g.Cells[y][x] != 0 {
-					possible = false
-					break
-				}
-			}
-
-			if possible {
-				select {
-				case js.i <- ActionTurnLeft:
-				default:
-				}
-				return
-			}
-
-			// Check turn_right
-			possible = true
-			x, y = g.Players[g.You].X, g.Players[g.You].Y
-
-			switch g.Players[g.You].Direction {
-			case DirectionRight:
-				dostep = func(x, y int) (int, int) { return x, y - 1 }
-			case DirectionLeft:
-				dostep = func(x, y int) (int, int) { return x, y + 1 }
-			case DirectionUp:
-				dostep = func(x, y int) (int, int) { return x - 1, y }
-			case DirectionDown:
-				dostep = func(x, y int) (int, int) { return x + 1, y }
-			}
-
-			for i := 0; i < g.Players[g.You].Speed; i++ {
-				x, y = dostep(x, y)
-				if x < 0 || x >= g.Width || y < 0 || y >= g.Height || g.Cells[y][x] != 0 {
-					possible = false
-					break
-				}
-			}
-
-			if possible {
-				select {
-				case js.i <- ActionTurnRight:
-				default:
-				}
-				return
-			}
-
-			// nothing to survive, so....
+			// If nothing survives, slow down anyway
 			select {
-			case js.i <- ActionSlower:
+			case js.i <- action:
 			default:
 			}
 		} else {
@@ -230,7 +161,7 @@ func (js *JumpingSnailAI) freeSpaceConnectedInternal(x, y, cutoff, current int,
 	}
 	js.freeCountingSlice[cell] = true
 
-	if g.Cells[y][x] != 0 {
+	if !IsEmpty(g.Cells[y][x]) {
 		return current
 	}
 	current++
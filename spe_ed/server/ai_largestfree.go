@@ -18,21 +18,18 @@ package main
 import "sync"
 
 func init() {
-	err := RegisterAI("LargestFreeAI", func() AI { return new(LargestFreeAI) })
-	if err != nil {
-		panic(err)
-	}
+	MustRegisterAI("LargestFreeAI", func() AI { return new(LargestFreeAI) })
 }
 
 // LargestFreeAI is an AI which navigates the player in the direction of the largest free area (calculated as a line from the current position).
 type LargestFreeAI struct {
 	l sync.Mutex
 
-	i chan string
+	i chan Action
 }
 
 // GetChannel receives the answer channel.
-func (lf *LargestFreeAI) GetChannel(c chan string) {
+func (lf *LargestFreeAI) GetChannel(c chan Action) {
 	lf.l.Lock()
 	defer lf.l.Unlock()
 
@@ -67,28 +64,28 @@ func (lf *LargestFreeAI) GetState(g *Game) {
 				if x < 0 || x >= g.Width || y < 0 || y >= g.Height {
 					// invalid - do nothing
 				} else {
-					g.Cells[y][x] = -100
+					g.Cells[y][x] = CellDanger
 				}
 
 				x, y = g.Players[k].X-i, g.Players[k].Y
 				if x < 0 || x >= g.Width || y < 0 || y >= g.Height {
 					// invalid - do nothing
 				} else {
-					g.Cells[y][x] = -100
+					g.Cells[y][x] = CellDanger
 				}
 
 				x, y = g.Players[k].X, g.Players[k].Y+i
 				if x < 0 || x >= g.Width || y < 0 || y >= g.Height {
 					// invalid - do nothing
 				} else {
-					g.Cells[y][x] = -100
+					g.Cells[y][x] = CellDanger
 				}
 
 				x, y = g.Players[k].X, g.Players[k].Y-i
 				if x < 0 || x >= g.Width || y < 0 || y >= g.Height {
 					// invalid - do nothing
 				} else {
-					g.Cells[y][x] = -100
+					g.Cells[y][x] = CellDanger
 				}
 			}
 		}
@@ -193,7 +190,7 @@ func (lf *LargestFreeAI) GetFree(dostep func(x, y int) (int, int), g *Game) int
 		if x < 0 || x >= g.Width || y < 0 || y >= g.Height {
 			break
 		}
-		if g.Cells[y][x] != 0 {
+		if !IsEmpty(g.Cells[y][x]) {
 			break
 		}
 		free++
@@ -0,0 +1,318 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+func init() {
+	MustRegisterAI("MCTSAI", func() AI { return new(MCTSAI) })
+}
+
+const (
+	// MCTSAISimulations contains the default maximum number of simulations of MCTSAI per round.
+	MCTSAISimulations = 20000
+	// MCTSAIDepth contains the default maximum number of rounds of a single rollout of MCTSAI.
+	MCTSAIDepth = 30
+	// MCTSAIMargin contains the time MCTSAI reserves before the deadline for sending the answer.
+	MCTSAIMargin = 1 * time.Second
+	// MCTSAIBudget contains the time MCTSAI uses if the game has no deadline.
+	MCTSAIBudget = 1 * time.Second
+)
+
+type mctsAINode struct {
+	parent   *mctsAINode
+	action   Action
+	children []*mctsAINode
+	untried  []Action
+	visits   int
+	reward   float64
+}
+
+type mctsAIPrediction struct {
+	action     Action
+	confidence float64
+}
+
+// MCTSAI is an AI using Monte Carlo tree search (UCT) over its own actions.
+// Each simulation plays random, but not immediately crashing, actions for all active players on a copy of the game until the player dies or the depth is reached.
+// The actions of all players in a round are applied at the same time like on the server, so head-on collisions are part of the simulation.
+// If an OpponentModel is set, opponents play their predicted action with the probability of its confidence instead of a random action.
+// The reward of a simulation is the fraction of rounds survived (or 1 if all opponents died).
+// After the time budget runs out, the action visited most often is chosen.
+// The number of simulations is adapted to the time per simulation measured in the previous rounds (see SearchBudget).
+// On boards with more cells than allowed by SetMaxBoardCells, it plays like FloodFillAI instead of searching.
+type MCTSAI struct {
+	l sync.Mutex
+
+	i           chan Action
+	cheap       FloodFillAI
+	r           *rand.Rand
+	opponents   *OpponentModel
+	predictions map[int]mctsAIPrediction
+	budget      SearchBudget
+
+	// Simulations is the maximum number of simulations per round. MCTSAISimulations is used if it is zero.
+	Simulations int
+	// Exploration is the exploration constant of UCT. math.Sqrt2 is used if it is zero.
+	Exploration float64
+	// Depth is the maximum number of rounds of a single rollout. MCTSAIDepth is used if it is zero.
+	Depth int
+	// Utilization is the share of the remaining time used for the search (see SearchBudget). GetSearchUtilization is used if it is zero.
+	Utilization float64
+}
+
+// GetChannel receives the answer channel.
+func (m *MCTSAI) GetChannel(c chan Action) {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	m.i = c
+	m.cheap.GetChannel(c)
+}
+
+// Seed sets the seed used for all random decisions of the AI.
+func (m *MCTSAI) Seed(seed int64) {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	m.r = rand.New(rand.NewSource(seed))
+}
+
+// SetOpponentModel sets the model used to predict the actions of the opponents.
+func (m *MCTSAI) SetOpponentModel(o *OpponentModel) {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	m.opponents = o
+}
+
+// GetState gets the game state and computes an answer.
+func (m *MCTSAI) GetState(g *Game) {
+	m.GetStateContext(context.Background(), g)
+}
+
+// GetStateContext gets the game state and computes an answer.
+// If the context is cancelled, the search stops and the best action so far is sent.
+func (m *MCTSAI) GetStateContext(ctx context.Context, g *Game) {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	if m.i == nil {
+		return
+	}
+
+	if g.Running && g.Players[g.You].Active {
+		if degradeOnLargeBoard(g, m.Name()) {
+			m.cheap.GetState(g)
+			return
+		}
+
+		if m.r == nil {
+			m.r = rand.New(rand.NewSource(rand.Int63()))
+		}
+
+		start := time.Now()
+		m.budget.Utilization = m.Utilization
+		target := m.budget.Target(g, MCTSAIMargin, MCTSAIBudget)
+		cutoff := start.Add(target)
+
+		m.predictions = make(map[int]mctsAIPrediction, len(g.Players))
+		if m.opponents != nil {
+			for k := range g.Players {
+				if k == g.You {
+					continue
+				}
+				a, c := m.opponents.PredictAction(k)
+				m.predictions[k] = mctsAIPrediction{action: a, confidence: c}
+			}
+		}
+
+		simulations := m.Simulations
+		if simulations <= 0 {
+			simulations = MCTSAISimulations
+		}
+		if fit := m.budget.Nodes(cutoff.Sub(time.Now())); fit >= 0 && fit < simulations {
+			// At least one simulation, so the decision is not blind
+			simulations = fit + 1
+		}
+
+		root := &mctsAINode{untried: []Action{ActionNOOP, ActionTurnLeft, ActionTurnRight, ActionSlower, ActionFaster}}
+		searchStart := time.Now()
+		n := 0
+		for ; n < simulations && time.Now().Before(cutoff) && ctx.Err() == nil; n++ {
+			c := AcquireClone(g)
+			m.simulate(root, c)
+			ReleaseClone(c)
+		}
+		m.budget.Observe(n, time.Since(searchStart))
+
+		action := ActionNOOP
+		visits := -1
+		for _, c := range root.children {
+			if c.visits > visits {
+				visits = c.visits
+				action = c.action
+			}
+		}
+
+		select {
+		case m.i <- action:
+		default:
+		}
+	}
+}
+
+// Name returns the name of the AI.
+func (m *MCTSAI) Name() string {
+	return "MCTSAI"
+}
+
+// simulate runs a single iteration (selection, expansion, rollout, backpropagation) on the given copy of the game.
+// The game is modified.
+func (m *MCTSAI) simulate(root *mctsAINode, g *Game) {
+	depth := m.Depth
+	if depth <= 0 {
+		depth = MCTSAIDepth
+	}
+
+	node := root
+	rounds := 0
+	reward := -1.0
+
+	// Selection
+	for len(node.untried) == 0 && len(node.children) != 0 {
+		node = m.selectChild(node)
+		rounds++
+		if r, ended := m.reward(g, m.round(g, node.action), rounds, depth); ended {
+			reward = r
+			break
+		}
+	}
+
+	// Expansion
+	if reward < 0 && len(node.untried) != 0 {
+		i := m.r.Intn(len(node.untried))
+		child := &mctsAINode{parent: node, action: node.untried[i], untried: []Action{ActionNOOP, ActionTurnLeft, ActionTurnRight, ActionSlower, ActionFaster}}
+		node.untried = append(node.untried[:i], node.untried[i+1:]...)
+		node.children = append(node.children, child)
+		node = child
+		rounds++
+		if r, ended := m.reward(g, m.round(g, node.action), rounds, depth); ended {
+			reward = r
+		}
+	}
+
+	// Rollout
+	for reward < 0 {
+		if rounds >= depth {
+			reward = float64(rounds) / float64(depth)
+			break
+		}
+		rounds++
+		if r, ended := m.reward(g, m.round(g, m.randomAction(g, g.You)), rounds, depth); ended {
+			reward = r
+		}
+	}
+
+	// Backpropagation
+	for ; node != nil; node = node.parent {
+		node.visits++
+		node.reward += reward
+	}
+}
+
+// selectChild returns the child with the highest UCT value.
+func (m *MCTSAI) selectChild(node *mctsAINode) *mctsAINode {
+	exploration := m.Exploration
+	if exploration == 0 {
+		exploration = math.Sqrt2
+	}
+	var best *mctsAINode
+	bestValue := math.Inf(-1)
+	for _, c := range node.children {
+		value := c.reward/float64(c.visits) + exploration*math.Sqrt(math.Log(float64(node.visits))/float64(c.visits))
+		if value > bestValue {
+			bestValue = value
+			best = c
+		}
+	}
+	return best
+}
+
+// round plays one round on the game with the given action for Game.You and random actions for all other active players.
+// The actions of the opponents are chosen in ascending order of their numbers, so a seeded AI is reproducible, and applied together with the action of Game.You at the same time (see ApplyRound).
+// It returns the players crashed in this round, which are set inactive.
+func (m *MCTSAI) round(g *Game, action Action) []int {
+	ids := []int{g.You}
+	actions := []Action{action}
+	for _, k := range activeOpponents(g) {
+		ids = append(ids, k)
+		actions = append(actions, m.opponentAction(g, k))
+	}
+	_, crashed := ApplyRound(g, ids, actions)
+	return crashed
+}
+
+// opponentAction returns the predicted action of the opponent with the probability of the confidence of the prediction, if it does not crash the player immediately, and a random action otherwise (see randomAction).
+// Not safe for concurrent use on the same game, however it will revert the game to the initial state given to the function.
+func (m *MCTSAI) opponentAction(g *Game, player int) Action {
+	if p, ok := m.predictions[player]; ok && m.r.Float64() < p.confidence {
+		move, ok := ApplyMove(g, player, p.action)
+		UndoMove(g, move)
+		if ok {
+			return p.action
+		}
+	}
+	return m.randomAction(g, player)
+}
+
+// randomAction returns a random action which does not crash the player immediately, if such an action exists.
+// Not safe for concurrent use on the same game, however it will revert the game to the initial state given to the function.
+func (m *MCTSAI) randomAction(g *Game, player int) Action {
+	actions := []Action{ActionNOOP, ActionTurnLeft, ActionTurnRight, ActionSlower, ActionFaster}
+	m.r.Shuffle(len(actions), func(i, j int) { actions[i], actions[j] = actions[j], actions[i] })
+	for i := range actions {
+		move, ok := ApplyMove(g, player, actions[i])
+		UndoMove(g, move)
+		if ok {
+			return actions[i]
+		}
+	}
+	return actions[0]
+}
+
+// reward returns the reward of a simulation after the given number of rounds, in the last of which the given players crashed, and whether the simulation has ended.
+// The simulation ends if Game.You crashed, even if all opponents crashed as well, or if all opponents crashed.
+func (m *MCTSAI) reward(g *Game, crashed []int, rounds, depth int) (float64, bool) {
+	for _, k := range crashed {
+		if k == g.You {
+			return float64(rounds-1) / float64(depth), true
+		}
+	}
+	for k := range g.Players {
+		if k != g.You && g.Players[k].Active {
+			return 0, false
+		}
+	}
+	// All opponents dead
+	return 1, true
+}
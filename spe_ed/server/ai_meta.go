@@ -21,28 +21,34 @@ import (
 )
 
 func init() {
-	err := RegisterAI("MetaAI", func() AI { return new(MetaAI) })
-	if err != nil {
-		panic(err)
-	}
+	MustRegisterAI("MetaAI", func() AI { return new(MetaAI) })
 }
 
 // MetaAI is an AI which uses various different AIs.
 type MetaAI struct {
 	l sync.Mutex
 
-	i  chan string
+	i  chan Action
+	r  *rand.Rand
 	ai AI
 }
 
 // GetChannel receives the answer channel.
-func (meta *MetaAI) GetChannel(c chan string) {
+func (meta *MetaAI) GetChannel(c chan Action) {
 	meta.l.Lock()
 	defer meta.l.Unlock()
 
 	meta.i = c
 }
 
+// Seed sets the seed used for all random decisions of the AI.
+func (meta *MetaAI) Seed(seed int64) {
+	meta.l.Lock()
+	defer meta.l.Unlock()
+
+	meta.r = rand.New(rand.NewSource(seed))
+}
+
 // GetState gets the game state and computes an answer.
 func (meta *MetaAI) GetState(g *Game) {
 	meta.l.Lock()
@@ -52,117 +58,40 @@ func (meta *MetaAI) GetState(g *Game) {
 		return
 	}
 
-	if g.Running {
-		if rand.Float64() < 0.1 {
+	if meta.r == nil {
+		meta.r = rand.New(rand.NewSource(rand.Int63()))
+	}
+
+	if g.Running && g.Players[g.You].Active {
+		if meta.r.Float64() < 0.1 {
 			meta.ai = nil
 		}
 
 		if meta.ai == nil {
 			if g.Players[g.You].Speed > 1 {
 
-				// Most AIs don't work with high speeds...
-				var dostep func(x, y int) (int, int)
-				possible := true
-				x, y := g.Players[g.You].X, g.Players[g.You].Y
-
-				switch g.Players[g.You].Direction {
-				case DirectionUp:
-					dostep = func(x, y int) (int, int) { return x, y - 1 }
-				case DirectionDown:
-					dostep = func(x, y int) (int, int) { return x, y + 1 }
-				case DirectionLeft:
-					dostep = func(x, y int) (int, int) { return x - 1, y }
-				case DirectionRight:
-					dostep = func(x, y int) (int, int) { return x + 1, y }
-				}
-
-				for i := 0; i < g.Players[g.You].Speed-1; i++ {
-					x, y = dostep(x, y)
-					if x < 0 || x >= g.Width || y < 0 || y >= g.Height || g.Cells[y][x] != 0 {
-						possible = false
-						break
-					}
-				}
-
-				if possible {
-					select {
-					case meta.i <- ActionSlower:
-					default:
-					}
-					return
-				}
-
-				// Check turn_left
-				possible = true
-				x, y = g.Players[g.You].X, g.Players[g.You].Y
-
-				switch g.Players[g.You].Direction {
-				case DirectionLeft:
-					dostep = func(x, y int) (int, int) { return x, y - 1 }
-				case DirectionRight:
-					dostep = func(x, y int) (int, int) { return x, y + 1 }
-				case DirectionDown:
-					dostep = func(x, y int) (int, int) { return x - 1, y }
-				case DirectionUp:
-					dostep = func(x, y int) (int, int) { return x + 1, y }
-				}
-
-				for i := 0; i < g.Players[g.You].Speed; i++ {
-					x, y = dostep(x, y)
-					if x < 0 || x >= g.Width || y < 0 || y >= g.Height || g.Cells[y][x] != 0 {
-						possible = false
-						break
-					}
-				}
-
-				if possible {
-					select {
-					case meta.i <- ActionTurnLeft:
-					default:
-					}
-					return
-				}
-
-				// Check turn_right
-				possible = true
-				x, y = g.Players[g.You].X, g.Players[g.You].Y
-
-				switch g.Players[g.You].Direction {
-				case DirectionRight:
-					dostep = func(x, y int) (int, int) { return x, y - 1 }
-				case DirectionLeft:
-					dostep = func(x, y int) (int, int) { return x, y + 1 }
-				case DirectionUp:
-					dostep = func(x, y int) (int, int) { return x - 1, y }
-				case DirectionDown:
-					dostep = func(x, y int) (int, int) { return x + 1, y }
-				}
-
-				for i := 0; i < g.Players[g.You].Speed; i++ {
-					x, y = dostep(x, y)
-					if x < 0 || x >= g.Width || y < 0 || y >= g.Height || g.Cells[y][x] != 0 {
-						possible = false
+				// Most AIs don't work with high speeds, so slow down or turn without crashing
+				action := ActionSlower
+				b := NewBitboard(g)
+				for _, a := range []Action{ActionSlower, ActionTurnLeft, ActionTurnRight} {
+					if !b.Crashes(g.Players[g.You], a) {
+						action = a
 						break
 					}
 				}
 
-				if possible {
-					select {
-					case meta.i <- ActionTurnRight:
-					default:
-					}
-					return
-				}
-
-				// nothing to survive, so....
+				// If nothing survives, slow down anyway
 				select {
-				case meta.i <- ActionSlower:
+				case meta.i <- action:
 				default:
 				}
 				return
 			}
 			ais := []AI{&LargestFreeAI{}, &SuperSnailAI{}, &StupidAI{}, &RandomAISlow{}}
-			meta.ai = ais[rand.Intn(len(ais))]
+			meta.ai = ais[meta.r.Intn(len(ais))]
+			if s, ok := meta.ai.(SeedableAI); ok {
+				s.Seed(meta.r.Int63())
+			}
 			meta.ai.GetChannel(meta.i)
 		}
 
@@ -0,0 +1,642 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+func init() {
+	MustRegisterAI("MinimaxAI", func() AI { return new(MinimaxAI) })
+	MustRegisterAI("MinimaxAIMobility", func() AI { return &MinimaxAI{Mobility: MinimaxAIMobilityWeight, name: "MinimaxAIMobility"} })
+	MustRegisterAI("MinimaxAIParanoid", func() AI { return &MinimaxAI{Mode: MinimaxModeParanoid, name: "MinimaxAIParanoid"} })
+	MustRegisterAI("MinimaxAIMaxN", func() AI { return &MinimaxAI{Mode: MinimaxModeMaxN, name: "MinimaxAIMaxN"} })
+}
+
+// MinimaxMode decides which opponents MinimaxAI considers and how they are assumed to play.
+type MinimaxMode int
+
+const (
+	// MinimaxModeNearest searches against the nearest opponent only, all other players are ignored. Since only two players move, this is the cheapest mode.
+	MinimaxModeNearest MinimaxMode = iota
+	// MinimaxModeParanoid searches against all active opponents, which are assumed to form a coalition minimising the value of Game.You.
+	// The game stays zero-sum, so alpha-beta pruning still works, but every additional opponent adds a level to each round of the search.
+	MinimaxModeParanoid
+	// MinimaxModeMaxN searches with all active players, each maximising its own value (see MinimaxAI.evaluateMaxN).
+	// Opponents do not attack Game.You unless it helps them, which is more realistic than MinimaxModeParanoid, but since the values of the players are independent nothing can be pruned.
+	// Only head-on collisions are always assumed to happen, since the opponents do not know the action of Game.You.
+	// Therefore it searches the most positions per depth and reaches the smallest depth in time on crowded boards.
+	MinimaxModeMaxN
+)
+
+// String returns the name of the mode.
+func (mode MinimaxMode) String() string {
+	switch mode {
+	case MinimaxModeParanoid:
+		return "paranoid"
+	case MinimaxModeMaxN:
+		return "max-n"
+	default:
+		return "nearest"
+	}
+}
+
+const (
+	// MinimaxAIDepth contains the default search depth of MinimaxAI in rounds (one action of the player and one of the opponent).
+	MinimaxAIDepth = 3
+	// MinimaxAIMargin contains the time MinimaxAI reserves before the deadline for sending the answer.
+	MinimaxAIMargin = 1 * time.Second
+	// MinimaxAIBudget contains the time MinimaxAI uses if the game has no deadline.
+	MinimaxAIBudget = 1 * time.Second
+	// MinimaxAIMobilityWeight contains the weight of FutureMobility used by MinimaxAIMobility (see MinimaxAI.Mobility).
+	MinimaxAIMobilityWeight = 20
+
+	minimaxAIInfinity = 1 << 30
+)
+
+// MinimaxAI is an AI which searches the game tree against the nearest opponent using minimax with alpha-beta pruning.
+// With Mode set, all active opponents are searched instead, either as a coalition or each playing for itself (see MinimaxMode).
+// The leafs are evaluated by the difference of the reachable free space of both players, optionally plus the own future mobility (see FutureMobility), with the distance to the opponent as a tie breaker.
+// The search is deepened iteratively until Depth is reached or the deadline comes close, in which case the result of the last completed depth is used.
+// A depth is not started if it is not expected to finish in time, estimated from the time per position measured in the previous rounds (see SearchBudget).
+// On boards with more cells than allowed by SetMaxBoardCells, it plays like FloodFillAI instead of searching.
+type MinimaxAI struct {
+	l sync.Mutex
+
+	i     chan Action
+	cheap FloodFillAI
+
+	// Depth is the maximum search depth in rounds. MinimaxAIDepth is used if it is zero.
+	Depth int
+	// Utilization is the share of the remaining time used for the search (see SearchBudget). GetSearchUtilization is used if it is zero.
+	Utilization float64
+	// Mobility is the weight of FutureMobility in the evaluation, a single cell of reachable space has the weight 100. Zero disables it, which keeps the evaluation cheaper and therefore the search deeper.
+	Mobility int
+	// Mode decides which opponents are searched and how they play. MinimaxModeNearest is the default.
+	Mode MinimaxMode
+
+	// name is the name the AI was registered with, see Name
+	name string
+
+	ctx     context.Context
+	aborted bool
+	nodes   int
+	budget  SearchBudget
+	cache   *FloodFillCache
+	workers []*MinimaxAI
+	scores  map[Action]float64
+
+	// Opponents of the current search, see searchDepth
+	opponents []int
+	// Game.You followed by opponents, the players moving in every round of the current search (see resolve)
+	players []int
+}
+
+// GetChannel receives the answer channel.
+func (m *MinimaxAI) GetChannel(c chan Action) {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	m.i = c
+	m.cheap.GetChannel(c)
+}
+
+// GetState gets the game state and computes an answer.
+func (m *MinimaxAI) GetState(g *Game) {
+	m.GetStateContext(context.Background(), g)
+}
+
+// GetStateContext gets the game state and computes an answer.
+// If the context is cancelled, the result of the last completed depth is sent.
+func (m *MinimaxAI) GetStateContext(ctx context.Context, g *Game) {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	if m.i == nil {
+		return
+	}
+
+	if g.Running && g.Players[g.You].Active {
+		if degradeOnLargeBoard(g, m.Name()) {
+			m.cheap.GetState(g)
+			return
+		}
+
+		start := time.Now()
+		m.budget.Utilization = m.Utilization
+		target := m.budget.Target(g, MinimaxAIMargin, MinimaxAIBudget)
+		ctx, cancel := context.WithDeadline(ctx, start.Add(target))
+		defer cancel()
+
+		for _, w := range m.workers {
+			w.cache.Reset()
+		}
+
+		depth := m.Depth
+		if depth <= 0 {
+			depth = MinimaxAIDepth
+		}
+
+		// Fallback in case not even the first depth finishes in time
+		action := ActionNOOP
+		if legal := g.LegalActions(g.You); len(legal) != 0 {
+			action = legal[0]
+		}
+
+		reason := "no depth completed, first legal action"
+		var scores map[Action]float64
+		prevNodes, nodes := 0, 0
+		for d := 1; d <= depth; d++ {
+			if d > 1 && !m.budget.Fits(time.Since(start), target, ExpectedNodes(prevNodes, nodes)) {
+				reason = fmt.Sprintf("completed depth %d, depth %d not expected to finish in time", d-1, d)
+				break
+			}
+			depthStart := time.Now()
+			a, ok := m.searchDepth(ctx, g, d)
+			if !ok {
+				break
+			}
+			m.budget.Observe(m.nodes, time.Since(depthStart))
+			prevNodes, nodes = nodes, m.nodes
+			action = a
+			reason = fmt.Sprintf("completed depth %d", d)
+			scores = m.scores
+		}
+
+		LogDecision(g, m.Name(), action, scores, reason)
+
+		select {
+		case m.i <- action:
+		default:
+		}
+	}
+}
+
+// Search returns the best action for Game.You searching depth rounds against the opponents chosen by Mode. It implements DepthLimitedSearch.
+// If the context is cancelled before the search has finished, the second return value is false.
+// The game is not modified.
+// Flood fill results are cached between calls, the caches are bounded by FloodFillCacheSize.
+func (m *MinimaxAI) Search(ctx context.Context, g *Game, depth int) (Action, bool) {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	return m.searchDepth(ctx, g, depth)
+}
+
+// Nodes returns the number of positions searched by the last search. It implements NodeCounter.
+func (m *MinimaxAI) Nodes() int {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	return m.nodes
+}
+
+// Name returns the name the AI was registered with, so the variants can be told apart in logs and statistics.
+func (m *MinimaxAI) Name() string {
+	if m.name == "" {
+		return "MinimaxAI"
+	}
+	return m.name
+}
+
+// searchDepth runs search against the opponents chosen by Mode with the given context.
+// Caller must hold m.l.
+func (m *MinimaxAI) searchDepth(ctx context.Context, g *Game, depth int) (Action, bool) {
+	m.ctx = ctx
+	m.aborted = false
+	var opponents []int
+	if m.Mode == MinimaxModeNearest {
+		if o := m.nearestOpponent(g); o != 0 {
+			opponents = []int{o}
+		}
+	} else {
+		opponents = activeOpponents(g)
+	}
+	action, ok := m.search(g, opponents, depth)
+	m.ctx = nil
+	return action, ok
+}
+
+// search returns the best action for the given depth.
+// The actions of Game.You are evaluated concurrently (see EvaluateActions), each by its own worker with its own flood fill cache.
+// If the search was aborted because the context was cancelled, the second return value is false.
+// g is not modified.
+func (m *MinimaxAI) search(g *Game, opponents []int, depth int) (Action, bool) {
+	actions := []Action{ActionNOOP, ActionTurnLeft, ActionTurnRight, ActionSlower, ActionFaster}
+	for len(m.workers) < len(actions) {
+		m.workers = append(m.workers, &MinimaxAI{Mobility: m.Mobility, name: m.name, cache: NewFloodFillCache()})
+	}
+	opponent := 0
+	if len(opponents) != 0 {
+		opponent = opponents[0]
+	}
+	players := append([]int{g.You}, opponents...)
+	for _, w := range m.workers {
+		w.nodes = 0
+		w.Mode = m.Mode
+		w.opponents = opponents
+		w.players = players
+	}
+
+	results, err := EvaluateActions(m.ctx, g, actions, func(ctx context.Context, c *Game, index int, action Action) (int, bool) {
+		w := m.workers[index]
+		w.ctx = ctx
+		w.aborted = false
+		defer func() { w.ctx = nil }()
+
+		if crashes(c, c.You, action) {
+			return 0, false
+		}
+		if w.Mode == MinimaxModeMaxN {
+			round := make([]Action, len(players))
+			round[0] = action
+			v := w.maxN(c, 1, depth, round)
+			if w.aborted {
+				return 0, false
+			}
+			return v[c.You], true
+		}
+		v := w.min(c, action, opponent, depth, -minimaxAIInfinity-depth-1, minimaxAIInfinity+depth+1)
+		return v, !w.aborted
+	})
+	m.nodes = 0
+	for _, w := range m.workers {
+		m.nodes += w.nodes
+	}
+	if err != nil {
+		return "", false
+	}
+
+	m.scores = nil
+	if DecisionLogEnabled() {
+		m.scores = make(map[Action]float64, len(results))
+		for _, r := range results {
+			if r.Valid {
+				m.scores[r.Action] = float64(r.Score)
+			}
+		}
+	}
+
+	action := ActionNOOP
+	alpha := -minimaxAIInfinity - depth - 1
+	for _, r := range results {
+		if r.Valid && r.Score > alpha {
+			alpha = r.Score
+			action = r.Action
+		}
+	}
+	return action, true
+}
+
+// max returns the value of the position for the player, who has to choose an action next.
+// Not safe for concurrent use on the same game.
+func (m *MinimaxAI) max(g *Game, opponent, depth, alpha, beta int) int {
+	m.nodes++
+	if m.ctx.Err() != nil {
+		m.aborted = true
+		return 0
+	}
+	if depth == 0 {
+		return m.evaluate(g, opponent)
+	}
+
+	best := -minimaxAIInfinity - depth
+	actions := []Action{ActionNOOP, ActionTurnLeft, ActionTurnRight, ActionSlower, ActionFaster}
+	for a := range actions {
+		if crashes(g, g.You, actions[a]) {
+			// Pruning own crash
+			continue
+		}
+		v := m.min(g, actions[a], opponent, depth, alpha, beta)
+		if m.aborted {
+			return 0
+		}
+		if v > best {
+			best = v
+		}
+		if v > alpha {
+			alpha = v
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+	return best
+}
+
+// min returns the value of the position after the player has chosen the action and the opponent has to choose its action next.
+// Afterwards, both actions are applied at the same time (see resolve), so the opponent knows the action of the player, but can not move out of its way.
+// Not safe for concurrent use on the same game.
+// In MinimaxModeParanoid, all opponents choose their actions before the round is resolved (see paranoidMin).
+func (m *MinimaxAI) min(g *Game, action Action, opponent, depth, alpha, beta int) int {
+	if m.Mode == MinimaxModeParanoid {
+		round := make([]Action, len(m.players))
+		round[0] = action
+		return m.paranoidMin(g, 0, round, depth, alpha, beta)
+	}
+	if opponent == 0 {
+		return m.resolve(g, []Action{action}, opponent, depth, alpha, beta)
+	}
+
+	best := minimaxAIInfinity + depth
+	for _, a := range candidateActions(g, opponent) {
+		v := m.resolve(g, []Action{action, a}, opponent, depth, alpha, beta)
+		if m.aborted {
+			return 0
+		}
+		if v < best {
+			best = v
+		}
+		if v < beta {
+			beta = v
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+	return best
+}
+
+// resolve applies the actions of m.players at the same time like the server (see ApplyRound) and returns the value of the resulting position, searching the remaining depth-1 rounds.
+// If the player crashed, the round is lost, even if opponents crashed as well (e.g. in a head-on collision). Otherwise, the round is won if opponent is not 0 and crashed.
+// The game is restored before returning. Not safe for concurrent use on the same game.
+func (m *MinimaxAI) resolve(g *Game, round []Action, opponent, depth, alpha, beta int) int {
+	moves, _ := ApplyRound(g, m.players, round)
+	var v int
+	switch {
+	case !g.Players[g.You].Active:
+		v = -minimaxAIInfinity - depth
+	case opponent != 0 && !g.Players[opponent].Active:
+		v = minimaxAIInfinity + depth
+	default:
+		v = m.max(g, opponent, depth-1, alpha, beta)
+	}
+	undoMoves(g, moves)
+	return v
+}
+
+// crashes returns whether the action crashes the player on the current board, no matter what the other players do in the same round.
+// The game is restored before returning. Not safe for concurrent use on the same game.
+func crashes(g *Game, player int, action Action) bool {
+	move, ok := ApplyMove(g, player, action)
+	UndoMove(g, move)
+	return !ok
+}
+
+// candidateActions returns the actions of the player searched by MinimaxAI, which are all actions not crashing the player on the current board (see crashes).
+// If every action crashes, all actions are returned, since the player still fills cells on its way and might take other players with it.
+// Not safe for concurrent use on the same game.
+func candidateActions(g *Game, player int) []Action {
+	actions := []Action{ActionNOOP, ActionTurnLeft, ActionTurnRight, ActionSlower, ActionFaster}
+	candidates := make([]Action, 0, len(actions))
+	for _, a := range actions {
+		if !crashes(g, player, a) {
+			candidates = append(candidates, a)
+		}
+	}
+	if len(candidates) == 0 {
+		return actions
+	}
+	return candidates
+}
+
+// evaluate returns the heuristic value of the game for Game.You.
+// In MinimaxModeParanoid, the value is computed against all opponents (see evaluateParanoid).
+func (m *MinimaxAI) evaluate(g *Game, opponent int) int {
+	if m.Mode == MinimaxModeParanoid {
+		return m.evaluateParanoid(g)
+	}
+	p := g.Players[g.You]
+	free := m.cache.FloodFill(g, p.X, p.Y)
+	mobility := 0
+	if m.Mobility != 0 {
+		mobility = FutureMobility(g, FutureMobilityHorizon) * m.Mobility
+	}
+	if opponent == 0 {
+		return free*100 + mobility
+	}
+	o := g.Players[opponent]
+	opponentFree := m.cache.FloodFill(g, o.X, o.Y)
+	distance := abs(p.X-o.X) + abs(p.Y-o.Y)
+	return (free-opponentFree)*100 + mobility + distance
+}
+
+// paranoidMin returns the value of the position for Game.You after the player and all opponents before m.opponents[k] have chosen their actions, which are stored in round (indexed like m.players).
+// The remaining opponents choose one after another, all minimising the value, before the round is resolved (see resolve).
+// Inactive opponents do not move.
+// Not safe for concurrent use on the same game.
+func (m *MinimaxAI) paranoidMin(g *Game, k int, round []Action, depth, alpha, beta int) int {
+	if k == len(m.opponents) {
+		return m.resolve(g, round, 0, depth, alpha, beta)
+	}
+	opponent := m.opponents[k]
+	if !g.Players[opponent].Active {
+		return m.paranoidMin(g, k+1, round, depth, alpha, beta)
+	}
+
+	best := minimaxAIInfinity + depth
+	for _, a := range candidateActions(g, opponent) {
+		round[k+1] = a
+		v := m.paranoidMin(g, k+1, round, depth, alpha, beta)
+		if m.aborted {
+			return 0
+		}
+		if v < best {
+			best = v
+		}
+		if v < beta {
+			beta = v
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+	return best
+}
+
+// evaluateParanoid returns the heuristic value of the game for Game.You against all active opponents.
+// Like evaluate, it is based on the difference between the own reachable free space and the one of the strongest opponent, with the distance to the nearest opponent as a tie breaker.
+func (m *MinimaxAI) evaluateParanoid(g *Game) int {
+	p := g.Players[g.You]
+	v := m.cache.FloodFill(g, p.X, p.Y) * 100
+	if m.Mobility != 0 {
+		v += FutureMobility(g, FutureMobilityHorizon) * m.Mobility
+	}
+	opponentFree := 0
+	distance := -1
+	for _, k := range m.opponents {
+		o := g.Players[k]
+		if !o.Active {
+			continue
+		}
+		if free := m.cache.FloodFill(g, o.X, o.Y); free > opponentFree {
+			opponentFree = free
+		}
+		if d := abs(p.X-o.X) + abs(p.Y-o.Y); distance == -1 || d < distance {
+			distance = d
+		}
+	}
+	if distance == -1 {
+		return v
+	}
+	return v - opponentFree*100 + distance
+}
+
+// maxN returns the values of the position for all players, indexed by the number of the player.
+// Game.You (k == 0) and the players in m.opponents (k > 0, the opponent m.opponents[k-1]) choose their actions one after another, each maximising its own value, and store them in round (indexed like m.players).
+// Once all players have chosen, the round is resolved at the same time (see ApplyRound). If Game.You crashed, the position is evaluated directly since nothing else matters for its value.
+// On the server, the opponents do not know the action of Game.You. Therefore, if an opponent can crash into Game.You in the same round, this is assumed to happen, even if the opponent crashes as well.
+// Inactive players do not move.
+// The returned values might be shared between positions and must not be modified.
+// Not safe for concurrent use on the same game.
+func (m *MinimaxAI) maxN(g *Game, k, depth int, round []Action) []int {
+	if k > len(m.opponents) {
+		moves, _ := ApplyRound(g, m.players, round)
+		var v []int
+		if g.Players[g.You].Active {
+			v = m.maxN(g, 0, depth-1, make([]Action, len(m.players)))
+		} else {
+			v = m.evaluateMaxN(g, depth)
+		}
+		undoMoves(g, moves)
+		return v
+	}
+	if k == 0 {
+		m.nodes++
+		if m.ctx.Err() != nil {
+			m.aborted = true
+			return nil
+		}
+		if depth == 0 {
+			return m.evaluateMaxN(g, depth)
+		}
+	}
+
+	player := m.players[k]
+	if !g.Players[player].Active {
+		return m.maxN(g, k+1, depth, round)
+	}
+
+	var best []int
+	for _, a := range candidateActions(g, player) {
+		round[k] = a
+		v := m.maxN(g, k+1, depth, round)
+		if m.aborted {
+			return nil
+		}
+		if player != g.You && v[g.You] == -minimaxAIInfinity-depth {
+			return v
+		}
+		if best == nil || v[player] > best[player] {
+			best = v
+		}
+	}
+	return best
+}
+
+// evaluateMaxN returns the heuristic values of the game for Game.You and all players in m.opponents, indexed by the number of the player.
+// The value of an active player is the difference between its reachable free space and the one of its strongest opponent. For Game.You, the future mobility (see Mobility) and the distance to the nearest opponent are added like in evaluate.
+// Inactive players get a value below all values of active players, the later they crashed (smaller remaining depth), the higher.
+func (m *MinimaxAI) evaluateMaxN(g *Game, depth int) []int {
+	n := g.You
+	for _, k := range m.opponents {
+		if k > n {
+			n = k
+		}
+	}
+	values := make([]int, n+1)
+	free := make([]int, n+1)
+	players := append([]int{g.You}, m.opponents...)
+	for _, k := range players {
+		if p := g.Players[k]; p.Active {
+			free[k] = m.cache.FloodFill(g, p.X, p.Y)
+		}
+	}
+
+	for _, k := range players {
+		p := g.Players[k]
+		if !p.Active {
+			values[k] = -minimaxAIInfinity - depth
+			continue
+		}
+		opponentFree := 0
+		for _, o := range players {
+			if o != k && g.Players[o].Active && free[o] > opponentFree {
+				opponentFree = free[o]
+			}
+		}
+		values[k] = (free[k] - opponentFree) * 100
+	}
+
+	if p := g.Players[g.You]; p.Active {
+		if m.Mobility != 0 {
+			values[g.You] += FutureMobility(g, FutureMobilityHorizon) * m.Mobility
+		}
+		distance := -1
+		for _, k := range m.opponents {
+			o := g.Players[k]
+			if d := abs(p.X-o.X) + abs(p.Y-o.Y); o.Active && (distance == -1 || d < distance) {
+				distance = d
+			}
+		}
+		if distance != -1 {
+			values[g.You] += distance
+		}
+	}
+	return values
+}
+
+// activeOpponents returns the numbers of all active players except Game.You in ascending order.
+func activeOpponents(g *Game) []int {
+	opponents := make([]int, 0, len(g.Players))
+	for k := range g.Players {
+		if k != g.You && g.Players[k].Active {
+			opponents = append(opponents, k)
+		}
+	}
+	sort.Ints(opponents)
+	return opponents
+}
+
+// nearestOpponent returns the active opponent which is closest to Game.You (manhattan distance) or 0 if there is none.
+func (m *MinimaxAI) nearestOpponent(g *Game) int {
+	nearest := 0
+	distance := 0
+	p := g.Players[g.You]
+	for k := range g.Players {
+		if k == g.You || !g.Players[k].Active {
+			continue
+		}
+		d := abs(p.X-g.Players[k].X) + abs(p.Y-g.Players[k].Y)
+		if nearest == 0 || d < distance || (d == distance && k < nearest) {
+			nearest = k
+			distance = d
+		}
+	}
+	return nearest
+}
+
+// abs returns the absolute value of i.
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMinimaxAIModes(t *testing.T) {
+	g := testScenario(t, `{"you": 1, "round": 5, "grid": [
+		"22>......",
+		"23>......",
+		"23.......",
+		".3...1...",
+		".3...1...",
+		".....1...",
+		".....1...",
+		".....v...",
+		"........."], "players": {"1": {}, "2": {}, "3": {}}}`)
+	actions := make(map[string]Action)
+	for _, name := range []string{"MinimaxAIParanoid", "MinimaxAIMaxN"} {
+		ai, err := CreateAI(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a, ok := ai.(*MinimaxAI).Search(context.Background(), g, 2)
+		if !ok {
+			t.Fatalf("%s: search aborted", name)
+		}
+		c, crashed := Simulate(g, 1, a)
+		if crashed {
+			t.Errorf("%s: %s crashes\n%s", name, a, g)
+		}
+		ReleaseClone(c)
+		actions[name] = a
+	}
+	if actions["MinimaxAIParanoid"] == actions["MinimaxAIMaxN"] {
+		t.Errorf("paranoid and max-n both play %s\n%s", actions["MinimaxAIParanoid"], g)
+	}
+}
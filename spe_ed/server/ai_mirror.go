@@ -16,34 +16,47 @@
 package main
 
 import (
+	"fmt"
 	"math/rand"
+	"sort"
 	"sync"
 )
 
 func init() {
-	err := RegisterAI("MirrorAI", func() AI { return new(MirrorAI) })
-	if err != nil {
-		panic(err)
-	}
+	MustRegisterAI("MirrorAI", func() AI { return new(MirrorAI) })
 }
 
-// MirrorAI is an AI which mirrors the action of an other random (active) player.
+// MirrorAI is an AI which mirrors the last action of an other player, inferred from the previous and the current state (see InferAction).
+// In a game with two players, the target is the opponent. With more players, a random active player is chosen and a new one once the target crashed.
+// The same action turns both players the same way after a point reflection, so after a symmetric start (see PlacementSymmetric) MirrorAI follows the mirrored path of the opponent one round later.
+// This makes it a deterministic sparring partner showing whether another AI can break the symmetry to win.
+// If the action can not be inferred unambiguously (e.g. on the first tick) or the mirrored action would crash, it plays like SurvivalAI instead.
 type MirrorAI struct {
 	l sync.Mutex
 
-	target          int
-	targetSpeed     int
-	targetDirection string
+	target int
+	prev   *Game
 
-	i chan string
+	i  chan Action
+	r  *rand.Rand
+	sv SurvivalAI
 }
 
 // GetChannel receives the answer channel.
-func (m *MirrorAI) GetChannel(c chan string) {
+func (m *MirrorAI) GetChannel(c chan Action) {
 	m.l.Lock()
 	defer m.l.Unlock()
 
 	m.i = c
+	m.sv.GetChannel(c)
+}
+
+// Seed sets the seed used for all random decisions of the AI.
+func (m *MirrorAI) Seed(seed int64) {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	m.r = rand.New(rand.NewSource(seed))
 }
 
 // GetState gets the game state and computes an answer.
@@ -55,84 +68,51 @@ func (m *MirrorAI) GetState(g *Game) {
 		return
 	}
 
+	if m.r == nil {
+		m.r = rand.New(rand.NewSource(rand.Int63()))
+	}
+
 	if g.Running && g.Players[g.You].Active {
+		// On the first tick, the saved data belongs to a previous game (if any)
+		if g.Players[g.You].stepCounter == 0 {
+			m.target = 0
+			m.prev = nil
+		}
+		prev := m.prev
+		m.prev = g.Clone()
+
 		// Is target still active?
-		if m.target != 0 && !g.Players[m.target].Active {
+		if m.target != 0 && (g.Players[m.target] == nil || !g.Players[m.target].Active) {
 			m.target = 0
 		}
 
 		// Do we need new target?
 		if m.target == 0 {
-			// Find target
 			player := make([]int, 0, len(g.Players))
 			for k := range g.Players {
 				if k != g.You && g.Players[k].Active {
 					player = append(player, k)
 				}
 			}
-			m.target = player[rand.Intn(len(player))]
-
-			// Save data
-			m.targetDirection = g.Players[m.target].Direction
-			m.targetSpeed = g.Players[m.target].Speed
+			if len(player) == 0 {
+				m.sv.GetState(g)
+				return
+			}
+			sort.Ints(player)
+			m.target = player[m.r.Intn(len(player))]
+		}
 
-			// Send action
-			m.i <- ActionNOOP
+		action, ok := InferAction(prev, g, m.target)
+		if !ok || NewBitboard(g).Crashes(g.Players[g.You], action) {
+			m.sv.GetState(g)
 			return
 		}
+		LogDecision(g, m.Name(), action, nil, fmt.Sprintf("mirroring player %d", m.target))
 
-		// Mirror target
-
-		action := ActionNOOP
-		// Find action taken
-		switch {
-		// Faster
-		case g.Players[m.target].Speed > m.targetSpeed:
-			m.targetSpeed = g.Players[m.target].Speed
-			if g.Players[g.You].Speed < 10 {
-				action = ActionFaster
-			}
-
-		// Slower
-		case g.Players[m.target].Speed < m.targetSpeed:
-			m.targetSpeed = g.Players[m.target].Speed
-			if g.Players[g.You].Speed > 1 {
-				action = ActionSlower
-			}
-
-		// Turning
-		case g.Players[m.target].Direction != m.targetDirection:
-			switch m.targetDirection {
-			case DirectionUp:
-				if g.Players[m.target].Direction == DirectionLeft {
-					action = ActionTurnLeft
-				} else if g.Players[m.target].Direction == DirectionRight {
-					action = ActionTurnRight
-				}
-			case DirectionDown:
-				if g.Players[m.target].Direction == DirectionRight {
-					action = ActionTurnLeft
-				} else if g.Players[m.target].Direction == DirectionLeft {
-					action = ActionTurnRight
-				}
-			case DirectionLeft:
-				if g.Players[m.target].Direction == DirectionDown {
-					action = ActionTurnLeft
-				} else if g.Players[m.target].Direction == DirectionUp {
-					action = ActionTurnRight
-				}
-			case DirectionRight:
-				if g.Players[m.target].Direction == DirectionUp {
-					action = ActionTurnLeft
-				} else if g.Players[m.target].Direction == DirectionDown {
-					action = ActionTurnRight
-				}
-			}
-			m.targetDirection = g.Players[m.target].Direction
+		select {
+		case m.i <- action:
+		default:
 		}
-
-		// Send action
-		m.i <- action
 	}
 }
 
@@ -0,0 +1,51 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "testing"
+
+func TestMirrorAIFirstTick(t *testing.T) {
+	g := testScenario(t, `{"you": 1, "grid": ["....", "..1>", "....", "<2.."], "players": {"1": {}, "2": {}}}`)
+	ai := new(MirrorAI)
+	ai.Seed(1)
+	a := decide(t, ai, g)
+	c, crashed := Simulate(g, 1, a)
+	ReleaseClone(c)
+	if crashed {
+		t.Errorf("%s crashes into the wall on the first tick", a)
+	}
+}
+
+func TestMirrorAIMirroredMoves(t *testing.T) {
+	script := map[int]Action{1: ActionTurnLeft, 2: ActionNOOP, 3: ActionFaster, 4: ActionTurnRight, 5: ActionSlower, 6: ActionTurnRight, 7: ActionNOOP, 8: ActionTurnLeft}
+	mirror := new(MirrorAI)
+	s, err := NewSimulatorWithPlacement(30, 30, 3, PlacementSymmetric, &scriptedAI{actions: script}, mirror)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for round := 1; round <= len(script)+1; round++ {
+		s.Step()
+		if !s.Game.Players[1].Active || !s.Game.Players[2].Active {
+			t.Fatalf("player crashed in round %d", round)
+		}
+		if round == 1 {
+			continue
+		}
+		if got, want := s.Actions[2], script[round-1]; got != want {
+			t.Errorf("round %d: got %s, want %s", round, got, want)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "sync"
+
+func init() {
+	MustRegisterAI("PhaseAI", func() AI { return new(PhaseAI) })
+}
+
+// PhaseAIAggressiveUntil contains the default fill ratio (see FillRatio) up to which PhaseAI plays aggressively.
+const PhaseAIAggressiveUntil = 0.2
+
+// GamePhase describes the phase of a game as seen by PhaseAI.
+type GamePhase int
+
+const (
+	// GamePhaseAggressive describes the beginning of a game, in which territory is contested (see AggressiveAI).
+	GamePhaseAggressive GamePhase = iota
+	// GamePhaseSpace describes the middle of a game, in which the reachable space is maximised (see FloodFillAI).
+	GamePhaseSpace
+	// GamePhaseSurvival describes the end of a game for a player which is isolated from all opponents (see SurvivalAI).
+	GamePhaseSurvival
+)
+
+// String returns the name of the phase.
+func (p GamePhase) String() string {
+	switch p {
+	case GamePhaseAggressive:
+		return "aggressive"
+	case GamePhaseSpace:
+		return "space"
+	default:
+		return "survival"
+	}
+}
+
+// FillRatio returns the share of the cells of the board which are not free, between 0 and 1. An empty board has a fill ratio of 0.
+func FillRatio(g *Game) float64 {
+	if g.Width <= 0 || g.Height <= 0 {
+		return 0
+	}
+	filled := 0
+	for y := range g.Cells {
+		for x := range g.Cells[y] {
+			if !IsEmpty(g.Cells[y][x]) {
+				filled++
+			}
+		}
+	}
+	return float64(filled) / float64(g.Width*g.Height)
+}
+
+// PhaseAI is an AI which follows a phase schedule (see PhaseAI.Phase): It plays like AggressiveAI early, like FloodFillAI in the middle of the game and like SurvivalAI once it is isolated.
+// Contesting territory pays off while the board is mostly empty, later maximising the own space is safer, and after isolation only filling the own region matters.
+type PhaseAI struct {
+	l sync.Mutex
+
+	i  chan Action
+	ag AggressiveAI
+	ff FloodFillAI
+	sv SurvivalAI
+
+	// AggressiveUntil is the fill ratio (see FillRatio) up to which the AI plays aggressively. PhaseAIAggressiveUntil is used if it is zero, a negative value disables the aggressive phase.
+	AggressiveUntil float64
+}
+
+// GetChannel receives the answer channel.
+func (p *PhaseAI) GetChannel(c chan Action) {
+	p.l.Lock()
+	defer p.l.Unlock()
+
+	p.i = c
+	p.ag.GetChannel(c)
+	p.ff.GetChannel(c)
+	p.sv.GetChannel(c)
+}
+
+// Phase returns the phase of the game for Game.You: GamePhaseSurvival if the player is isolated (see Isolated), GamePhaseAggressive up to a fill ratio of AggressiveUntil and GamePhaseSpace afterwards.
+func (p *PhaseAI) Phase(g *Game) GamePhase {
+	if Isolated(g, g.You) {
+		return GamePhaseSurvival
+	}
+	until := p.AggressiveUntil
+	if until == 0 {
+		until = PhaseAIAggressiveUntil
+	}
+	if FillRatio(g) <= until {
+		return GamePhaseAggressive
+	}
+	return GamePhaseSpace
+}
+
+// GetState gets the game state and computes an answer.
+func (p *PhaseAI) GetState(g *Game) {
+	p.l.Lock()
+	defer p.l.Unlock()
+
+	if p.i == nil {
+		return
+	}
+
+	if g.Running && g.Players[g.You].Active {
+		switch p.Phase(g) {
+		case GamePhaseAggressive:
+			p.ag.GetState(g)
+		case GamePhaseSpace:
+			p.ff.GetState(g)
+		default:
+			p.sv.GetState(g)
+		}
+	}
+}
+
+// Name returns the name of the AI.
+func (p *PhaseAI) Name() string {
+	return "PhaseAI"
+}
@@ -0,0 +1,65 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// phaseBoard returns a 10x10 board with both players on the bottom row and the given number of filled cells in total.
+func phaseBoard(t *testing.T, filled int) *Game {
+	t.Helper()
+	cells := []byte(strings.Repeat("x", filled-4) + strings.Repeat(".", 94-filled) + "1>......<2")
+	grid := make([]string, 10)
+	for y := range grid {
+		grid[y] = string(cells[y*10 : y*10+10])
+	}
+	b, err := json.Marshal(grid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testScenario(t, `{"you": 1, "grid": `+string(b)+`, "players": {"1": {}, "2": {}}}`)
+}
+
+func TestPhaseAI(t *testing.T) {
+	for _, tc := range []struct {
+		filled int
+		until  float64
+		phase  GamePhase
+	}{
+		{4, 0, GamePhaseAggressive},
+		{20, 0, GamePhaseAggressive},
+		{21, 0, GamePhaseSpace},
+		{21, 0.3, GamePhaseAggressive},
+		{31, 0.3, GamePhaseSpace},
+		{4, -1, GamePhaseSpace},
+	} {
+		g := phaseBoard(t, tc.filled)
+		if r := FillRatio(g); r != float64(tc.filled)/100 {
+			t.Fatalf("%d filled cells: fill ratio %f", tc.filled, r)
+		}
+		if p := (&PhaseAI{AggressiveUntil: tc.until}).Phase(g); p != tc.phase {
+			t.Errorf("fill ratio %.2f, aggressive until %.2f: phase %s, want %s", FillRatio(g), tc.until, p, tc.phase)
+		}
+	}
+
+	g := testScenario(t, `{"you": 1, "grid": ["..x..", "1>x<2", "..x.."], "players": {"1": {}, "2": {}}}`)
+	if p := new(PhaseAI).Phase(g); p != GamePhaseSurvival {
+		t.Errorf("isolated player: phase %s, want %s", p, GamePhaseSurvival)
+	}
+}
@@ -0,0 +1,124 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"math"
+	"sync"
+)
+
+func init() {
+	MustRegisterAI("PolicyNetAI", func() AI { return &PolicyNetAI{Net: GetPolicyNet()} })
+}
+
+// PolicyNetAI is an AI playing with a learned policy: a small feed-forward network (see PolicyNet) scores all actions for the encoded board around the head (see Encode).
+// The legal action (see Game.LegalActions) with the highest score is chosen, so the network can never choose an action crashing immediately. Ties are broken by the order of Actions.
+// Without network (see SetPolicyNet), it plays like FloodFillAI.
+type PolicyNetAI struct {
+	l sync.Mutex
+
+	i  chan Action
+	ff FloodFillAI
+
+	// Net is the network scoring the actions. It must be valid (see PolicyNet.Validate).
+	Net *PolicyNet
+}
+
+// GetChannel receives the answer channel.
+func (p *PolicyNetAI) GetChannel(c chan Action) {
+	p.l.Lock()
+	defer p.l.Unlock()
+
+	p.i = c
+	p.ff.GetChannel(c)
+}
+
+// GetState gets the game state and computes an answer.
+func (p *PolicyNetAI) GetState(g *Game) {
+	p.l.Lock()
+	defer p.l.Unlock()
+
+	if p.i == nil {
+		return
+	}
+
+	if g.Running && g.Players[g.You].Active {
+		if p.Net == nil {
+			p.ff.GetState(g)
+			return
+		}
+
+		var scores map[Action]float64
+		if DecisionLogEnabled() {
+			scores = make(map[Action]float64, 5)
+		}
+		action, reason := p.decide(g, scores)
+		LogDecision(g, p.Name(), action, scores, reason)
+
+		select {
+		case p.i <- action:
+		default:
+		}
+	}
+}
+
+// Explain returns the scores of the network for all legal actions (see Explainable and Game.LegalActions).
+// Without network, the scores of FloodFillAI are returned.
+func (p *PolicyNetAI) Explain(g *Game) map[Action]float64 {
+	p.l.Lock()
+	defer p.l.Unlock()
+
+	scores := make(map[Action]float64, 5)
+	if p.Net == nil {
+		p.ff.decide(g, scores)
+		return scores
+	}
+	p.decide(g, scores)
+	return scores
+}
+
+// Name returns the name of the AI.
+func (p *PolicyNetAI) Name() string {
+	return "PolicyNetAI"
+}
+
+// decide picks the action of Game.You with the highest output of the net for the encoded board (see Encode), skipping actions which crash immediately.
+// It returns the action together with the reason and adds the output for every action not crashing to scores if it is not nil. The game is not modified.
+func (p *PolicyNetAI) decide(g *Game, scores map[Action]float64) (Action, string) {
+	out := p.Net.Forward(Encode(g))
+	action := ActionNOOP
+	best := math.Inf(-1)
+	reason := "highest score of policy net"
+
+	b := NewBitboard(g)
+	for i, a := range Actions {
+		if b.Crashes(g.Players[g.You], a) {
+			continue
+		}
+		score := float64(out[i])
+		if scores != nil {
+			scores[a] = score
+		}
+		if score > best {
+			best = score
+			action = a
+		}
+	}
+	if math.IsInf(best, -1) {
+		reason = "every action crashes"
+	}
+	return action, reason
+}
@@ -0,0 +1,55 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+)
+
+// testPolicyNet returns a network with a single layer preferring change_nothing, then turn_left, then turn_right.
+// turn_right gets a bonus of 10 if the cell to the left of the head is filled.
+func testPolicyNet() *PolicyNet {
+	layer := PolicyNetLayer{Weights: make([][]float32, len(Actions)), Biases: []float32{3, 2, 1, 0, 0}}
+	for i := range layer.Weights {
+		layer.Weights[i] = make([]float32, PolicyNetInputs)
+	}
+	left := PolicyNetRadius*PolicyNetWindow + PolicyNetRadius - 1
+	layer.Weights[2][left] = 10
+	return &PolicyNet{Layers: []PolicyNetLayer{layer}}
+}
+
+func TestPolicyNetAI(t *testing.T) {
+	net := testPolicyNet()
+	err := net.Validate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name string
+		grid string
+		want Action
+	}{
+		{"open", `".......", ".......", ".......", ".......", "...^...", "...1...", "<2....."`, ActionNOOP},
+		{"ahead filled", `".......", ".......", ".......", "...x...", "...^...", "...1...", "<2....."`, ActionTurnLeft},
+		{"left filled", `".......", ".......", ".......", ".......", "..x^...", "...1...", "<2....."`, ActionTurnRight},
+	} {
+		g := testScenario(t, `{"you": 1, "grid": [`+tc.grid+`], "players": {"1": {}, "2": {}}}`)
+		if got := decide(t, &PolicyNetAI{Net: net}, g); got != tc.want {
+			t.Errorf("%s: got %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
@@ -21,16 +21,14 @@ import (
 )
 
 func init() {
-	err := RegisterAI("RandomAI", func() AI { return new(RandomAI) })
-	if err != nil {
-		panic(err)
-	}
+	MustRegisterAI("RandomAI", func() AI { return new(RandomAI) })
 }
 
 // RandomAI is an AI that performs random actions. It tries to avoid crashes.
 type RandomAI struct {
 	l sync.Mutex
-	i chan string
+	i chan Action
+	r *rand.Rand
 }
 
 const (
@@ -40,12 +38,20 @@ const (
 )
 
 // GetChannel receives the answer channel.
-func (r *RandomAI) GetChannel(c chan string) {
+func (r *RandomAI) GetChannel(c chan Action) {
 	r.l.Lock()
 	defer r.l.Unlock()
 	r.i = c
 }
 
+// Seed sets the seed used for all random decisions of the AI.
+func (r *RandomAI) Seed(seed int64) {
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	r.r = rand.New(rand.NewSource(seed))
+}
+
 // GetState gets the game state and computes an answer.
 func (r *RandomAI) GetState(g *Game) {
 	r.l.Lock()
@@ -55,8 +61,12 @@ func (r *RandomAI) GetState(g *Game) {
 		return
 	}
 
-	if g.Running {
-		// Fill potential dead zones
+	if r.r == nil {
+		r.r = rand.New(rand.NewSource(rand.Int63()))
+	}
+
+	if g.Running && g.Players[g.You].Active {
+		// Fill potential dead zones, filled cells must stay sure crashes
 		for k := range g.Players {
 			if k == g.You {
 				continue
@@ -70,84 +80,47 @@ func (r *RandomAI) GetState(g *Game) {
 				x, y := g.Players[k].X+i, g.Players[k].Y
 				if x < 0 || x >= g.Width || y < 0 || y >= g.Height {
 					// invalid - do nothing
-				} else {
-					g.Cells[y][x] = -100
+				} else if IsEmpty(g.Cells[y][x]) {
+					g.Cells[y][x] = CellDanger
 				}
 
 				x, y = g.Players[k].X-i, g.Players[k].Y
 				if x < 0 || x >= g.Width || y < 0 || y >= g.Height {
 					// invalid - do nothing
-				} else {
-					g.Cells[y][x] = -100
+				} else if IsEmpty(g.Cells[y][x]) {
+					g.Cells[y][x] = CellDanger
 				}
 
 				x, y = g.Players[k].X, g.Players[k].Y+i
 				if x < 0 || x >= g.Width || y < 0 || y >= g.Height {
 					// invalid - do nothing
-				} else {
-					g.Cells[y][x] = -100
+				} else if IsEmpty(g.Cells[y][x]) {
+					g.Cells[y][x] = CellDanger
 				}
 
 				x, y = g.Players[k].X, g.Players[k].Y-i
 				if x < 0 || x >= g.Width || y < 0 || y >= g.Height {
 					// invalid - do nothing
-				} else {
-					g.Cells[y][x] = -100
+				} else if IsEmpty(g.Cells[y][x]) {
+					g.Cells[y][x] = CellDanger
 				}
 			}
 		}
 
 		// actions
-		actions := []string{ActionTurnLeft, ActionTurnRight, ActionSlower, ActionFaster, ActionNOOP, ActionNOOP, ActionNOOP, ActionNOOP}
-		rand.Shuffle(len(actions), func(i, j int) { actions[i], actions[j] = actions[j], actions[i] })
-		fallbackAction := ""
+		actions := []Action{ActionTurnLeft, ActionTurnRight, ActionSlower, ActionFaster, ActionNOOP, ActionNOOP, ActionNOOP, ActionNOOP}
+		r.r.Shuffle(len(actions), func(i, j int) { actions[i], actions[j] = actions[j], actions[i] })
+		var fallbackAction Action
 
 		// test actions
 		for i := range actions {
-			// do action
-			switch actions[i] {
-			case ActionTurnLeft:
-				switch g.Players[g.You].Direction {
-				case DirectionLeft:
-					g.Players[g.You].Direction = DirectionDown
-				case DirectionRight:
-					g.Players[g.You].Direction = DirectionUp
-				case DirectionUp:
-					g.Players[g.You].Direction = DirectionLeft
-				case DirectionDown:
-					g.Players[g.You].Direction = DirectionRight
-				}
-			case ActionTurnRight:
-				switch g.Players[g.You].Direction {
-				case DirectionLeft:
-					g.Players[g.You].Direction = DirectionUp
-				case DirectionRight:
-					g.Players[g.You].Direction = DirectionDown
-				case DirectionUp:
-					g.Players[g.You].Direction = DirectionRight
-				case DirectionDown:
-					g.Players[g.You].Direction = DirectionLeft
-				}
-			case ActionFaster:
-				g.Players[g.You].Speed++
-				if g.Players[g.You].Speed > MaxSpeed {
-					g.Players[g.You].Speed--
-					continue
-				}
-			case ActionSlower:
-				g.Players[g.You].Speed--
-				if g.Players[g.You].Speed < 1 {
-					g.Players[g.You].Speed++
-					continue
-				}
-			case ActionNOOP:
-				// Do nothing
-			default:
-				log.Println("random ai:", "unknown action", actions[i])
+			direction, speed, err := steer(g.Players[g.You].Direction, g.Players[g.You].Speed, actions[i])
+			if err != nil {
+				continue
 			}
 
 			// test
-			switch r.willCrash(g) {
+			switch randomAIWillCrash(g, direction, speed) {
 			case randomAINoCrash:
 				select {
 				case r.i <- actions[i]:
@@ -157,38 +130,6 @@ func (r *RandomAI) GetState(g *Game) {
 			case randomAIMaybeCrash:
 				fallbackAction = actions[i]
 			}
-
-			// undo action
-			switch actions[i] {
-			case ActionTurnLeft:
-				switch g.Players[g.You].Direction {
-				case DirectionLeft:
-					g.Players[g.You].Direction = DirectionUp
-				case DirectionRight:
-					g.Players[g.You].Direction = DirectionDown
-				case DirectionUp:
-					g.Players[g.You].Direction = DirectionRight
-				case DirectionDown:
-					g.Players[g.You].Direction = DirectionLeft
-				}
-			case ActionTurnRight:
-				switch g.Players[g.You].Direction {
-				case DirectionLeft:
-					g.Players[g.You].Direction = DirectionDown
-				case DirectionRight:
-					g.Players[g.You].Direction = DirectionUp
-				case DirectionUp:
-					g.Players[g.You].Direction = DirectionLeft
-				case DirectionDown:
-					g.Players[g.You].Direction = DirectionRight
-				}
-			case ActionFaster:
-				g.Players[g.You].Speed--
-			case ActionSlower:
-				g.Players[g.You].Speed++
-			case ActionNOOP:
-				// Do nothing
-			}
 		}
 
 		if fallbackAction != "" {
@@ -207,38 +148,28 @@ func (r *RandomAI) GetState(g *Game) {
 	}
 }
 
-// willCrash computes whether the given game state will result in a (possible) crash.
-// Not safe for concurrent use on the same game.
-func (r *RandomAI) willCrash(g *Game) int {
-	oldX, oldY := g.Players[g.You].X, g.Players[g.You].Y
-	defer func() {
-		g.Players[g.You].X, g.Players[g.You].Y = oldX, oldY
-	}()
-
-	var dostep func(x, y int) (int, int)
-	switch g.Players[g.You].Direction {
-	case DirectionUp:
-		dostep = func(x, y int) (int, int) { return x, y - 1 }
-	case DirectionDown:
-		dostep = func(x, y int) (int, int) { return x, y + 1 }
-	case DirectionLeft:
-		dostep = func(x, y int) (int, int) { return x - 1, y }
-	case DirectionRight:
-		dostep = func(x, y int) (int, int) { return x + 1, y }
+// randomAIWillCrash computes whether Game.You moving with the given direction and speed in the next round will result in a (possible) crash.
+// Cells marked with CellDanger are possible crashes. The game is not modified.
+func randomAIWillCrash(g *Game, direction Direction, speed int) int {
+	p := g.Players[g.You]
+	dx, dy, ok := directionStep(direction)
+	if !ok {
+		return randomAISureCrash
 	}
 
-	for s := 0; s < g.Players[g.You].Speed; s++ {
-		g.Players[g.You].X, g.Players[g.You].Y = dostep(g.Players[g.You].X, g.Players[g.You].Y)
-		if g.Players[g.You].X < 0 || g.Players[g.You].X >= g.Width || g.Players[g.You].Y < 0 || g.Players[g.You].Y >= g.Height {
+	x, y := p.X, p.Y
+	for s := 0; s < speed; s++ {
+		x, y = x+dx, y+dy
+		if x < 0 || x >= g.Width || y < 0 || y >= g.Height {
 			return randomAISureCrash
 		}
-		if g.Players[g.You].Speed >= HoleSpeed && (g.Players[g.You].stepCounter+1)%HolesEachStep == 0 && s != 0 && s != g.Players[g.You].Speed-1 {
+		if g.Holes.IsHole(speed, p.stepCounter+1, s) {
 			continue
 		}
-		if g.Cells[g.Players[g.You].Y][g.Players[g.You].X] == -100 {
+		if g.Cells[y][x] == CellDanger {
 			return randomAIMaybeCrash
 		}
-		if g.Cells[g.Players[g.You].Y][g.Players[g.You].X] != 0 {
+		if !IsEmpty(g.Cells[y][x]) {
 			return randomAISureCrash
 		}
 	}
@@ -21,27 +21,33 @@ import (
 )
 
 func init() {
-	err := RegisterAI("SnailAI", func() AI { return new(SnailAI) })
-	if err != nil {
-		panic(err)
-	}
+	MustRegisterAI("SnailAI", func() AI { return new(SnailAI) })
 }
 
 // SnailAI is an AI that tries to maximise space usage by always 'holding one hand to the wall'. It will usually perform a snail-like pattern at the beginning, thus the name.
 type SnailAI struct {
 	l         sync.Mutex
-	i         chan string
-	direction string
+	i         chan Action
+	r         *rand.Rand
+	direction Direction
 }
 
 // GetChannel receives the answer channel.
-func (s *SnailAI) GetChannel(c chan string) {
+func (s *SnailAI) GetChannel(c chan Action) {
 	s.l.Lock()
 	defer s.l.Unlock()
 
 	s.i = c
 }
 
+// Seed sets the seed used for all random decisions of the AI.
+func (s *SnailAI) Seed(seed int64) {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	s.r = rand.New(rand.NewSource(seed))
+}
+
 // GetState gets the game state and computes an answer.
 func (s *SnailAI) GetState(g *Game) {
 	s.l.Lock()
@@ -51,15 +57,19 @@ func (s *SnailAI) GetState(g *Game) {
 		return
 	}
 
+	if s.r == nil {
+		s.r = rand.New(rand.NewSource(rand.Int63()))
+	}
+
 	if s.direction == "" {
-		if rand.Float32() < 0.5 {
+		if s.r.Float32() < 0.5 {
 			s.direction = DirectionLeft
 		} else {
 			s.direction = DirectionRight
 		}
 	}
 
-	if g.Running {
+	if g.Running && g.Players[g.You].Active {
 		if s.direction == DirectionLeft {
 			var nextX, nextY int
 			switch g.Players[g.You].Direction {
@@ -72,7 +82,7 @@ func (s *SnailAI) GetState(g *Game) {
 			case DirectionRight:
 				nextX, nextY = g.Players[g.You].X, g.Players[g.You].Y+1
 			}
-			if nextX >= 0 && nextX < g.Width && nextY >= 0 && nextY < g.Height && g.Cells[nextY][nextX] == 0 {
+			if nextX >= 0 && nextX < g.Width && nextY >= 0 && nextY < g.Height && IsEmpty(g.Cells[nextY][nextX]) {
 				select {
 				case s.i <- ActionTurnRight:
 				default:
@@ -90,7 +100,7 @@ func (s *SnailAI) GetState(g *Game) {
 			case DirectionRight:
 				nextX, nextY = g.Players[g.You].X+1, g.Players[g.You].Y
 			}
-			if nextX >= 0 && nextX < g.Width && nextY >= 0 && nextY < g.Height && g.Cells[nextY][nextX] == 0 {
+			if nextX >= 0 && nextX < g.Width && nextY >= 0 && nextY < g.Height && IsEmpty(g.Cells[nextY][nextX]) {
 				select {
 				case s.i <- ActionNOOP:
 				default:
@@ -108,7 +118,7 @@ func (s *SnailAI) GetState(g *Game) {
 			case DirectionRight:
 				nextX, nextY = g.Players[g.You].X, g.Players[g.You].Y-1
 			}
-			if nextX >= 0 && nextX < g.Width && nextY >= 0 && nextY < g.Height && g.Cells[nextY][nextX] == 0 {
+			if nextX >= 0 && nextX < g.Width && nextY >= 0 && nextY < g.Height && IsEmpty(g.Cells[nextY][nextX]) {
 				select {
 				case s.i <- ActionTurnLeft:
 				default:
@@ -128,7 +138,7 @@ func (s *SnailAI) GetState(g *Game) {
 			case DirectionRight:
 				nextX, nextY = g.Players[g.You].X, g.Players[g.You].Y-1
 			}
-			if nextX >= 0 && nextX < g.Width && nextY >= 0 && nextY < g.Height && g.Cells[nextY][nextX] == 0 {
+			if nextX >= 0 && nextX < g.Width && nextY >= 0 && nextY < g.Height && IsEmpty(g.Cells[nextY][nextX]) {
 				select {
 				case s.i <- ActionTurnLeft:
 				default:
@@ -146,7 +156,7 @@ func (s *SnailAI) GetState(g *Game) {
 			case DirectionRight:
 				nextX, nextY = g.Players[g.You].X+1, g.Players[g.You].Y
 			}
-			if nextX >= 0 && nextX < g.Width && nextY >= 0 && nextY < g.Height && g.Cells[nextY][nextX] == 0 {
+			if nextX >= 0 && nextX < g.Width && nextY >= 0 && nextY < g.Height && IsEmpty(g.Cells[nextY][nextX]) {
 				select {
 				case s.i <- ActionNOOP:
 				default:
@@ -164,7 +174,7 @@ func (s *SnailAI) GetState(g *Game) {
 			case DirectionRight:
 				nextX, nextY = g.Players[g.You].X, g.Players[g.You].Y+1
 			}
-			if nextX >= 0 && nextX < g.Width && nextY >= 0 && nextY < g.Height && g.Cells[nextY][nextX] == 0 {
+			if nextX >= 0 && nextX < g.Width && nextY >= 0 && nextY < g.Height && IsEmpty(g.Cells[nextY][nextX]) {
 				select {
 				case s.i <- ActionTurnRight:
 				default:
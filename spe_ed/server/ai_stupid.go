@@ -21,25 +21,31 @@ import (
 )
 
 func init() {
-	err := RegisterAI("StupidAI", func() AI { return new(StupidAI) })
-	if err != nil {
-		panic(err)
-	}
+	MustRegisterAI("StupidAI", func() AI { return new(StupidAI) })
 }
 
 // StupidAI always sends "change_nothing" except to avoid walls by turning.
 type StupidAI struct {
 	l sync.Mutex
-	i chan string
+	i chan Action
+	r *rand.Rand
 }
 
 // GetChannel receives the answer channel.
-func (s *StupidAI) GetChannel(c chan string) {
+func (s *StupidAI) GetChannel(c chan Action) {
 	s.l.Lock()
 	defer s.l.Unlock()
 	s.i = c
 }
 
+// Seed sets the seed used for all random decisions of the AI.
+func (s *StupidAI) Seed(seed int64) {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	s.r = rand.New(rand.NewSource(seed))
+}
+
 // GetState gets the game state and computes an answer.
 func (s *StupidAI) GetState(g *Game) {
 	s.l.Lock()
@@ -49,7 +55,11 @@ func (s *StupidAI) GetState(g *Game) {
 		return
 	}
 
-	if g.Running {
+	if s.r == nil {
+		s.r = rand.New(rand.NewSource(rand.Int63()))
+	}
+
+	if g.Running && g.Players[g.You].Active {
 		p := g.Players[g.You]
 		if s.isFree(p, g) {
 			select {
@@ -59,19 +69,10 @@ func (s *StupidAI) GetState(g *Game) {
 			return
 		}
 
-		if rand.Float64() < 0.5 {
+		if s.r.Float64() < 0.5 {
 
 			// Turn left
-			switch p.Direction {
-			case DirectionLeft:
-				p.Direction = DirectionDown
-			case DirectionRight:
-				p.Direction = DirectionUp
-			case DirectionUp:
-				p.Direction = DirectionLeft
-			case DirectionDown:
-				p.Direction = DirectionRight
-			}
+			p.Direction = Turn(p.Direction, ActionTurnLeft)
 
 			if s.isFree(p, g) {
 				select {
@@ -88,17 +89,8 @@ func (s *StupidAI) GetState(g *Game) {
 			}
 			return
 		}
-		// Turn left
-		switch p.Direction {
-		case DirectionLeft:
-			p.Direction = DirectionUp
-		case DirectionRight:
-			p.Direction = DirectionDown
-		case DirectionUp:
-			p.Direction = DirectionRight
-		case DirectionDown:
-			p.Direction = DirectionLeft
-		}
+		// Turn right
+		p.Direction = Turn(p.Direction, ActionTurnRight)
 
 		if s.isFree(p, g) {
 			select {
@@ -133,7 +125,7 @@ func (s *StupidAI) isFree(p *Player, g *Game) bool {
 	if x < 0 || x >= g.Width || y < 0 || y >= g.Height {
 		return false
 	}
-	return g.Cells[y][x] == 0
+	return IsEmpty(g.Cells[y][x])
 }
 
 // Name returns the name of the AI.
@@ -21,35 +21,35 @@ import (
 )
 
 func init() {
-	err := RegisterAI("SuperSnailAI", func() AI { return new(SuperSnailAI) })
-	if err != nil {
-		panic(err)
-	}
-}
-
-type supersnailAIRevert struct {
-	X, Y, Speed, stepCounter int
-	Direction                string
-	Cells                    []struct{ X, Y int }
+	MustRegisterAI("SuperSnailAI", func() AI { return new(SuperSnailAI) })
 }
 
 // SuperSnailAI is an AI that tries to maximise space usage by always 'holding one hand to the wall'. It will usually perform a snail-like pattern at the beginning, thus the name.
 // This is an improved version of the SnailAI with a simple dead end prevention.
 type SuperSnailAI struct {
 	l         sync.Mutex
-	i         chan string
-	direction string
+	i         chan Action
+	r         *rand.Rand
+	direction Direction
 	round     int
 }
 
 // GetChannel receives the answer channel.
-func (s *SuperSnailAI) GetChannel(c chan string) {
+func (s *SuperSnailAI) GetChannel(c chan Action) {
 	s.l.Lock()
 	defer s.l.Unlock()
 
 	s.i = c
 }
 
+// Seed sets the seed used for all random decisions of the AI.
+func (s *SuperSnailAI) Seed(seed int64) {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	s.r = rand.New(rand.NewSource(seed))
+}
+
 // GetState gets the game state and computes an answer.
 func (s *SuperSnailAI) GetState(g *Game) {
 	s.l.Lock()
@@ -59,20 +59,24 @@ func (s *SuperSnailAI) GetState(g *Game) {
 		return
 	}
 
+	if s.r == nil {
+		s.r = rand.New(rand.NewSource(rand.Int63()))
+	}
+
 	if s.direction == "" {
-		if rand.Float32() < 0.5 {
+		if s.r.Float32() < 0.5 {
 			s.direction = DirectionLeft
 		} else {
 			s.direction = DirectionRight
 		}
 	}
 
-	if g.Running {
+	if g.Running && g.Players[g.You].Active {
 		snailaction := s.getSnailAction(g)
 		if snailaction != "" {
-			revert := make([]supersnailAIRevert, 0)
-			_, r := s.progress(g, g.You, snailaction)
-			revert = append(revert, r)
+			moves := make([]Move, 0)
+			move, _ := ApplyMove(g, g.You, snailaction)
+			moves = append(moves, move)
 			if !s.isInSmallArea(g) {
 				// Everything ok
 				select {
@@ -83,21 +87,25 @@ func (s *SuperSnailAI) GetState(g *Game) {
 			}
 
 			// Try to find a better action
-			action := []string{ActionNOOP, ActionTurnLeft, ActionTurnRight}
+			action := []Action{ActionNOOP, ActionTurnLeft, ActionTurnRight}
 			test := 0
 
 			for a := range action {
-				s.revertStack(g, g.You, revert)
-				revert = revert[:0]
+				undoMoves(g, moves)
+				moves = moves[:0]
 				newTest := 1
-				alive, r := s.progress(g, g.You, action[a])
-				revert = append(revert, r)
+				move, alive := ApplyMove(g, g.You, action[a])
+				moves = append(moves, move)
 				if !alive {
 					continue
 				}
 				for {
-					alive, r = s.progress(g, g.You, s.getSnailAction(g))
-					revert = append(revert, r)
+					next := s.getSnailAction(g)
+					if next == "" {
+						break
+					}
+					move, alive = ApplyMove(g, g.You, next)
+					moves = append(moves, move)
 					if !alive {
 						break
 					}
@@ -121,7 +129,7 @@ func (s *SuperSnailAI) GetState(g *Game) {
 	}
 }
 
-func (s *SuperSnailAI) getSnailAction(g *Game) string {
+func (s *SuperSnailAI) getSnailAction(g *Game) Action {
 	if s.direction == DirectionLeft {
 		var nextX, nextY int
 		switch g.Players[g.You].Direction {
@@ -134,7 +142,7 @@ func (s *SuperSnailAI) getSnailAction(g *Game) string {
 		case DirectionRight:
 			nextX, nextY = g.Players[g.You].X, g.Players[g.You].Y+1
 		}
-		if nextX >= 0 && nextX < g.Width && nextY >= 0 && nextY < g.Height && g.Cells[nextY][nextX] == 0 {
+		if nextX >= 0 && nextX < g.Width && nextY >= 0 && nextY < g.Height && IsEmpty(g.Cells[nextY][nextX]) {
 			return ActionTurnRight
 		}
 
@@ -148,7 +156,7 @@ func (s *SuperSnailAI) getSnailAction(g *Game) string {
 		case DirectionRight:
 			nextX, nextY = g.Players[g.You].X+1, g.Players[g.You].Y
 		}
-		if nextX >= 0 && nextX < g.Width && nextY >= 0 && nextY < g.Height && g.Cells[nextY][nextX] == 0 {
+		if nextX >= 0 && nextX < g.Width && nextY >= 0 && nextY < g.Height && IsEmpty(g.Cells[nextY][nextX]) {
 			return ActionNOOP
 		}
 
@@ -162,7 +170,7 @@ func (s *SuperSnailAI) getSnailAction(g *Game) string {
 		case DirectionRight:
 			nextX, nextY = g.Players[g.You].X, g.Players[g.You].Y-1
 		}
-		if nextX >= 0 && nextX < g.Width && nextY >= 0 && nextY < g.Height && g.Cells[nextY][nextX] == 0 {
+		if nextX >= 0 && nextX < g.Width && nextY >= 0 && nextY < g.Height && IsEmpty(g.Cells[nextY][nextX]) {
 			return ActionTurnLeft
 		}
 	}
@@ -178,7 +186,7 @@ func (s *SuperSnailAI) getSnailAction(g *Game) string {
 		case DirectionRight:
 			nextX, nextY = g.Players[g.You].X, g.Players[g.You].Y-1
 		}
-		if nextX >= 0 && nextX < g.Width && nextY >= 0 && nextY < g.Height && g.Cells[nextY][nextX] == 0 {
+		if nextX >= 0 && nextX < g.Width && nextY >= 0 && nextY < g.Height && IsEmpty(g.Cells[nextY][nextX]) {
 			return ActionTurnLeft
 		}
 
@@ -192,7 +200,7 @@ func (s *SuperSnailAI) getSnailAction(g *Game) string {
 		case DirectionRight:
 			nextX, nextY = g.Players[g.You].X+1, g.Players[g.You].Y
 		}
-		if nextX >= 0 && nextX < g.Width && nextY >= 0 && nextY < g.Height && g.Cells[nextY][nextX] == 0 {
+		if nextX >= 0 && nextX < g.Width && nextY >= 0 && nextY < g.Height && IsEmpty(g.Cells[nextY][nextX]) {
 			return ActionNOOP
 		}
 
@@ -206,7 +214,7 @@ func (s *SuperSnailAI) getSnailAction(g *Game) string {
 		case DirectionRight:
 			nextX, nextY = g.Players[g.You].X, g.Players[g.You].Y+1
 		}
-		if nextX >= 0 && nextX < g.Width && nextY >= 0 && nextY < g.Height && g.Cells[nextY][nextX] == 0 {
+		if nextX >= 0 && nextX < g.Width && nextY >= 0 && nextY < g.Height && IsEmpty(g.Cells[nextY][nextX]) {
 			return ActionTurnRight
 		}
 	}
@@ -218,112 +226,6 @@ func (s *SuperSnailAI) Name() string {
 	return "SuperSnailAI"
 }
 
-// progress will progress the game by one step and return the result.
-// Not safe for concurrent use on the same game.
-func (s *SuperSnailAI) progress(g *Game, player int, command string) (bool, supersnailAIRevert) {
-	p := g.Players[player]
-	r := supersnailAIRevert{
-		X:           p.X,
-		Y:           p.Y,
-		Speed:       p.Speed,
-		stepCounter: p.stepCounter,
-		Direction:   p.Direction,
-		Cells:       make([]struct{ X, Y int }, 0, p.Speed),
-	}
-	switch command {
-	case ActionTurnLeft:
-		switch p.Direction {
-		case DirectionLeft:
-			p.Direction = DirectionDown
-		case DirectionRight:
-			p.Direction = DirectionUp
-		case DirectionUp:
-			p.Direction = DirectionLeft
-		case DirectionDown:
-			p.Direction = DirectionRight
-		}
-	case ActionTurnRight:
-		switch p.Direction {
-		case DirectionLeft:
-			p.Direction = DirectionUp
-		case DirectionRight:
-			p.Direction = DirectionDown
-		case DirectionUp:
-			p.Direction = DirectionRight
-		case DirectionDown:
-			p.Direction = DirectionLeft
-		}
-	case ActionFaster:
-		p.Speed++
-		if p.Speed > MaxSpeed {
-			return false, r
-		}
-	case ActionSlower:
-		p.Speed--
-		if p.Speed < 1 {
-			return false, r
-		}
-	case ActionNOOP:
-		// Do nothing
-	case "":
-		// Abort
-		return false, r
-	default:
-		log.Println("snail ai:", "unknown action", command)
-	}
-
-	var dostep func(x, y int) (int, int)
-	switch p.Direction {
-	case DirectionUp:
-		dostep = func(x, y int) (int, int) { return x, y - 1 }
-	case DirectionDown:
-		dostep = func(x, y int) (int, int) { return x, y + 1 }
-	case DirectionLeft:
-		dostep = func(x, y int) (int, int) { return x - 1, y }
-	case DirectionRight:
-		dostep = func(x, y int) (int, int) { return x + 1, y }
-	}
-
-	p.stepCounter++
-
-	for s := 0; s < p.Speed; s++ {
-		p.X, p.Y = dostep(p.X, p.Y)
-		if p.X < 0 || p.X >= g.Width || p.Y < 0 || p.Y >= g.Height {
-			return false, r
-		}
-		if p.Speed >= HoleSpeed && p.stepCounter%HolesEachStep == 0 && s != 0 && s != p.Speed-1 {
-			continue
-		}
-		if g.Cells[p.Y][p.X] != 0 {
-			return false, r
-		}
-		r.Cells = append(r.Cells, struct{ X, Y int }{p.X, p.Y})
-		g.Cells[p.Y][p.X] = -33
-	}
-
-	return true, r
-}
-
-// revert reverts the game state by the revert struct.
-// Not safe for cocurrent use on the same game.
-func (s *SuperSnailAI) revert(g *Game, player int, r supersnailAIRevert) {
-	p := g.Players[player]
-	p.X = r.X
-	p.Y = r.Y
-	p.Speed = r.Speed
-	p.stepCounter = r.stepCounter
-	p.Direction = r.Direction
-	for i := range r.Cells {
-		g.Cells[r.Cells[i].Y][r.Cells[i].X] = 0
-	}
-}
-
-func (s *SuperSnailAI) revertStack(g *Game, player int, rs []supersnailAIRevert) {
-	for i := len(rs) - 1; i >= 0; i-- {
-		s.revert(g, player, rs[i])
-	}
-}
-
 func (s *SuperSnailAI) isInSmallArea(g *Game) bool {
 	test := []struct{ X, Y int }{struct {
 		X int
@@ -344,7 +246,7 @@ func (s *SuperSnailAI) isInSmallArea(g *Game) bool {
 			count++
 			continue
 		}
-		if g.Cells[test[i].Y][test[i].X] == 0 {
+		if IsEmpty(g.Cells[test[i].Y][test[i].X]) {
 			count++
 		}
 	}
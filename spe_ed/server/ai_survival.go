@@ -0,0 +1,170 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "sync"
+
+func init() {
+	MustRegisterAI("SurvivalAI", func() AI { return new(SurvivalAI) })
+}
+
+// SurvivalAIDepth contains the number of rounds SurvivalAI looks ahead when it is isolated.
+const SurvivalAIDepth = 6
+
+// SurvivalAI is an AI for the endgame. As long as the player shares a region with an opponent, it plays like FloodFillAI.
+// Once the player is isolated (see Isolated), the game is about filling the own region, so it chooses the action surviving the most rounds, estimated by looking SurvivalAIDepth rounds ahead and adding the pocket left afterwards (see TrapCheck).
+// Ties are broken towards the lower speed, since every round at speed 1 fills exactly one cell, and then towards the position with the most filled neighbours, so the player follows walls and does not split its region.
+type SurvivalAI struct {
+	l sync.Mutex
+
+	i  chan Action
+	ff FloodFillAI
+}
+
+// GetChannel receives the answer channel.
+func (s *SurvivalAI) GetChannel(c chan Action) {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	s.i = c
+	s.ff.GetChannel(c)
+}
+
+// GetState gets the game state and computes an answer.
+func (s *SurvivalAI) GetState(g *Game) {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	if s.i == nil {
+		return
+	}
+
+	if g.Running && g.Players[g.You].Active {
+		if !Isolated(g, g.You) {
+			s.ff.GetState(g)
+			return
+		}
+
+		var scores map[Action]float64
+		if DecisionLogEnabled() {
+			scores = make(map[Action]float64, 5)
+		}
+		action, reason := s.decide(g, scores)
+		LogDecision(g, s.Name(), action, scores, reason)
+
+		select {
+		case s.i <- action:
+		default:
+		}
+	}
+}
+
+// Explain returns the scores of all actions not crashing immediately (see Explainable).
+// As long as the player is not isolated, the scores of FloodFillAI are returned. Otherwise, the score is the estimated number of rounds survived (see SurvivalAIDepth).
+func (s *SurvivalAI) Explain(g *Game) map[Action]float64 {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	scores := make(map[Action]float64, 5)
+	if p, ok := g.Players[g.You]; ok && p.Active {
+		if !Isolated(g, g.You) {
+			s.ff.decide(g, scores)
+		} else {
+			s.decide(g, scores)
+		}
+	}
+	return scores
+}
+
+// decide picks the action letting the isolated Game.You survive the most rounds (see lookahead), preferring a lower speed and then more occupied cells next to the head, so the space is filled without gaps.
+// It returns the action together with the reason and adds the rounds of every action to scores if it is not nil. The game is not modified.
+func (s *SurvivalAI) decide(g *Game, scores map[Action]float64) (Action, string) {
+	var action Action
+	bestRounds := -1
+	bestSpeed := 0
+	bestWalls := -1
+	reason := "isolated, filling space"
+
+	b := NewBitboard(g)
+	actions := []Action{ActionNOOP, ActionTurnLeft, ActionTurnRight, ActionSlower, ActionFaster}
+	for a := range actions {
+		if b.Crashes(g.Players[g.You], actions[a]) {
+			continue
+		}
+		c, crashed := Simulate(g, g.You, actions[a])
+		if crashed {
+			ReleaseClone(c)
+			continue
+		}
+		p := c.Players[c.You]
+		rounds := s.lookahead(c, SurvivalAIDepth)
+		if scores != nil {
+			scores[actions[a]] = float64(rounds)
+		}
+		walls := 0
+		nb := NewBitboard(c)
+		for _, n := range [4]coordinate{{p.X + 1, p.Y}, {p.X - 1, p.Y}, {p.X, p.Y + 1}, {p.X, p.Y - 1}} {
+			if nb.IsOccupied(n.X, n.Y) {
+				walls++
+			}
+		}
+		speed := p.Speed
+		ReleaseClone(c)
+		if rounds > bestRounds || (rounds == bestRounds && (speed < bestSpeed || (speed == bestSpeed && walls > bestWalls))) {
+			bestRounds = rounds
+			bestSpeed = speed
+			bestWalls = walls
+			action = actions[a]
+		}
+	}
+
+	if action == "" {
+		// The space is used up
+		action = ActionNOOP
+		reason = "every action crashes"
+	}
+	return action, reason
+}
+
+// lookahead returns the largest number of rounds survived plus the pocket left at the end (see TrapCheck) over all sequences of up to depth actions without speed changes.
+// The game is modified.
+func (s *SurvivalAI) lookahead(g *Game, depth int) int {
+	p := g.Players[g.You]
+	if depth == 0 {
+		pocket, _ := TrapCheck(g, p.X, p.Y, p.Speed)
+		return pocket
+	}
+	best := 0
+	b := NewBitboard(g)
+	for _, a := range []Action{ActionNOOP, ActionTurnLeft, ActionTurnRight} {
+		if b.Crashes(p, a) {
+			continue
+		}
+		c := AcquireClone(g)
+		ApplyAction(c, c.You, a)
+		v := 1 + s.lookahead(c, depth-1)
+		ReleaseClone(c)
+		if v > best {
+			best = v
+		}
+	}
+	return best
+}
+
+// Name returns the name of the AI.
+func (s *SurvivalAI) Name() string {
+	return "SurvivalAI"
+}
@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "testing"
+
+func TestIsolated(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		grid string
+		want bool
+	}{
+		{"shared region", `"1>......", "........", "......<2"`, false},
+		{"separated by a wall", `"1>..x...", "....x...", "....x.<2"`, true},
+		{"heads next to each other", `"1>xxxxxx", "x^xxxxxx", "x2xxxxxx"`, false},
+		{"head in the wall", `"1>..x...", "....x...", "....<2.."`, false},
+	} {
+		g := testScenario(t, `{"you": 1, "grid": [`+tc.grid+`], "players": {"1": {}, "2": {}}}`)
+		if got := Isolated(g, 1); got != tc.want {
+			t.Errorf("%s: Isolated %t, want %t\n%s", tc.name, got, tc.want, g)
+		}
+	}
+}
+
+func TestSurvivalAIEnclosed(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		grid string
+	}{
+		{
+			name: "rectangle",
+			grid: `"1>.......x....", ".........x....", ".........x....", ".........x....", ".........x....", ".........x..<2"`,
+		},
+		{
+			name: "odd width",
+			grid: `"1>......x.....", "........x.....", "........x.....", "........x.....", "........x.....", "........x...<2"`,
+		},
+		{
+			name: "irregular",
+			grid: `"1>.......x....", ".........x....", "xxxxx....x....", "xxxxx....x....", ".........x....", ".........x..<2"`,
+		},
+		{
+			name: "fast",
+			grid: `"1111>....x....", ".........x....", ".........x....", ".........x....", ".........x....", ".........x..<2"`,
+		},
+	} {
+		g := testScenario(t, `{"you": 1, "grid": [`+tc.grid+`], "players": {"1": {"speed": 3}, "2": {}}}`)
+		if !Isolated(g, 1) {
+			t.Fatalf("%s: player is not isolated", tc.name)
+		}
+		// The region of player 1 is the free part left of the wall.
+		pocket := 0
+		for y := range g.Cells {
+			for x := 0; x < 9; x++ {
+				if IsEmpty(g.Cells[y][x]) {
+					pocket++
+				}
+			}
+		}
+
+		ai := &SurvivalAI{}
+		survived := playAlone(t, ai, g, 1000)
+		// Slowing down from speed 3 costs some cells (at most 3 + 2 for the two rounds needed).
+		t.Logf("%s: %d of %d", tc.name, survived, pocket)
+		if survived < pocket*9/10-5 {
+			t.Errorf("%s: survived %d rounds in a pocket of %d cells\n%s", tc.name, survived, pocket, g)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// playSeeded plays a game between the AIs in the simulator with the given seed and returns the actions of every round.
+func playSeeded(t *testing.T, seed int64, names ...string) []map[int]Action {
+	t.Helper()
+	s, err := NewSimulator(30, 30, seed, names...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return playSimulator(s)
+}
+
+// playSimulator plays the game of the simulator until it ends and returns the actions of every round.
+func playSimulator(s *Simulator) []map[int]Action {
+	var rounds []map[int]Action
+	for s.Step() {
+		rounds = append(rounds, s.Actions)
+	}
+	return append(rounds, s.Actions)
+}
+
+func TestSeedableAIDeterministic(t *testing.T) {
+	for _, name := range []string{"BadRandomAI", "ChristmasAI", "JumpingSnailAI", "MirrorAI", "RandomAI", "SnailAI", "StupidAI", "SuperRandomAI", "SuperSnailAI"} {
+		ai, err := CreateAI(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := ai.(SeedableAI); !ok {
+			t.Errorf("%s does not implement SeedableAI", name)
+			continue
+		}
+		first := playSeeded(t, 7, name, "BadRandomAI")
+		second := playSeeded(t, 7, name, "BadRandomAI")
+		if !reflect.DeepEqual(first, second) {
+			t.Errorf("%s: same seed led to different games (%d and %d rounds)", name, len(first), len(second))
+		}
+	}
+
+	// MCTSAI is only reproducible if the number of simulations and not the time limits the search. Several opponents check that they do not move in map order
+	var games [2][]map[int]Action
+	for i := range games {
+		s, err := NewSimulatorWithAIs(30, 30, 7, &MCTSAI{Simulations: 100}, new(BadRandomAI), new(BadRandomAI), new(BadRandomAI))
+		if err != nil {
+			t.Fatal(err)
+		}
+		games[i] = playSimulator(s)
+	}
+	if !reflect.DeepEqual(games[0], games[1]) {
+		t.Errorf("MCTSAI: same seed led to different games (%d and %d rounds)", len(games[0]), len(games[1]))
+	}
+}
+
+// playAlone lets the AI play Game.You of g until it crashes, while all other players stand still, and returns the number of rounds survived.
+// The game is modified. At most limit rounds are played.
+func playAlone(t testing.TB, ai AI, g *Game, limit int) int {
+	t.Helper()
+	for round := 0; round < limit; round++ {
+		err := ApplyAction(g, g.You, decide(t, ai, g))
+		if err != nil || !g.Players[g.You].Active {
+			return round
+		}
+	}
+	return limit
+}
+
+// freeCells returns the number of empty cells of the game.
+func freeCells(g *Game) int {
+	free := 0
+	for y := range g.Cells {
+		for x := range g.Cells[y] {
+			if IsEmpty(g.Cells[y][x]) {
+				free++
+			}
+		}
+	}
+	return free
+}
+
+func TestAINames(t *testing.T) {
+	for _, name := range ListAIs() {
+		ai, err := CreateAI(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ai.Name() != name {
+			t.Errorf("%s: Name returns %s", name, ai.Name())
+		}
+	}
+}
+
+func TestDeadPlayerNoAnswer(t *testing.T) {
+	g := testScenario(t, `{"you": 1, "grid": ["1>.....", ".......", ".....<2"], "players": {"1": {"active": false}, "2": {}}}`)
+	g.Deadline = ServerNow().Add(time.Second).UTC().Format(time.RFC3339Nano)
+	if !g.Running {
+		t.Fatal("game is not running")
+	}
+
+	answers := make(map[string]chan Action)
+	for _, name := range ListAIs() {
+		for _, direct := range []bool{true, false} {
+			ai, err := CreateAI(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := make(chan Action, 1)
+			ai.GetChannel(c)
+			if direct {
+				answers[name+".GetState"] = c
+				go ai.GetState(g.Clone())
+			} else {
+				answers[name+" in getAIState"] = c
+				go getAIState(ai, g.Clone(), time.Time{})
+			}
+		}
+	}
+	time.Sleep(300 * time.Millisecond)
+	for name, c := range answers {
+		select {
+		case a := <-c:
+			t.Errorf("%s answered %s for a dead player", name, a)
+		default:
+		}
+	}
+}
+
+// panicAI panics in every GetState.
+type panicAI struct{}
+
+func (panicAI) GetChannel(c chan Action) {}
+
+func (panicAI) GetState(g *Game) {
+	panic("panicAI")
+}
+
+func (panicAI) Name() string {
+	return "panicAI"
+}
+
+func TestSafeGetState(t *testing.T) {
+	g := testScenario(t, `{"you": 1, "grid": ["1>..", "...."], "players": {"1": {}}}`)
+	if err := SafeGetState(panicAI{}, g); !errors.Is(err, ErrAIPanic) {
+		t.Errorf("panic returned %v", err)
+	}
+	if err := getAIState(panicAI{}, g, time.Time{}); !errors.Is(err, ErrAIPanic) {
+		t.Errorf("panic in getAIState returned %v", err)
+	}
+
+	ai := &scriptedAI{}
+	c := make(chan Action, 1)
+	ai.GetChannel(c)
+	if err := SafeGetState(ai, g); err != nil || <-c != ActionNOOP {
+		t.Errorf("working ai returned %v", err)
+	}
+}
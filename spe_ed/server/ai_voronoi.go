@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "sync"
+
+func init() {
+	MustRegisterAI("VoronoiAI", func() AI { return new(VoronoiAI) })
+}
+
+// VoronoiAI is an AI which tries to control as much territory as possible.
+// For every action it computes the cells it reaches strictly before all opponents (see Voronoi) and chooses the action maximising them.
+// Ties are broken by the number of reachable free cells.
+type VoronoiAI struct {
+	l sync.Mutex
+
+	i chan Action
+}
+
+// GetChannel receives the answer channel.
+func (v *VoronoiAI) GetChannel(c chan Action) {
+	v.l.Lock()
+	defer v.l.Unlock()
+
+	v.i = c
+}
+
+// GetState gets the game state and computes an answer.
+func (v *VoronoiAI) GetState(g *Game) {
+	v.l.Lock()
+	defer v.l.Unlock()
+
+	if v.i == nil {
+		return
+	}
+
+	if g.Running && g.Players[g.You].Active {
+		var scores map[Action]float64
+		if DecisionLogEnabled() {
+			scores = make(map[Action]float64, 5)
+		}
+		action, reason := v.decide(g, scores)
+		LogDecision(g, v.Name(), action, scores, reason)
+
+		select {
+		case v.i <- action:
+		default:
+		}
+	}
+}
+
+// Explain returns the scores of all actions not crashing immediately (see Explainable).
+// The score is the territory of the player after the action (see Voronoi).
+func (v *VoronoiAI) Explain(g *Game) map[Action]float64 {
+	v.l.Lock()
+	defer v.l.Unlock()
+
+	scores := make(map[Action]float64, 5)
+	if p, ok := g.Players[g.You]; ok && p.Active {
+		v.decide(g, scores)
+	}
+	return scores
+}
+
+// Name returns the name of the AI.
+func (v *VoronoiAI) Name() string {
+	return "VoronoiAI"
+}
+
+// decide picks the action giving Game.You the largest territory (see Voronoi), breaking ties by the reachable free cells.
+// It returns the action together with the reason and adds the territory of every action to scores if it is not nil. The game is not modified.
+func (v *VoronoiAI) decide(g *Game, scores map[Action]float64) (Action, string) {
+	var action Action
+	best := -1
+	bestFree := -1
+	reason := "largest territory"
+
+	actions := []Action{ActionNOOP, ActionTurnLeft, ActionTurnRight, ActionSlower, ActionFaster}
+	for a := range actions {
+		c, crashed := Simulate(g, g.You, actions[a])
+		if !crashed {
+			p := c.Players[c.You]
+			territory := Voronoi(c, c.You)
+			free := FloodFill(c, p.X, p.Y)
+			if scores != nil {
+				scores[actions[a]] = float64(territory)
+			}
+			if territory > best || (territory == best && free > bestFree) {
+				best = territory
+				bestFree = free
+				action = actions[a]
+			}
+		}
+		ReleaseClone(c)
+	}
+
+	if action == "" {
+		// No territory is left to claim
+		action = ActionNOOP
+		reason = "every action crashes"
+	}
+	return action, reason
+}
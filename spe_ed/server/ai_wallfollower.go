@@ -0,0 +1,163 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "sync"
+
+func init() {
+	MustRegisterAI("WallFollowerAI", func() AI { return new(WallFollowerAI) })
+	MustRegisterAI("WallFollowerAIRight", func() AI { return &WallFollowerAI{RightHand: true} })
+}
+
+// WallFollowerAI is an AI following walls (including all trails) with the left-hand rule, or the right-hand rule if RightHand is set.
+// It keeps a wall on the chosen side: When the wall ends, it turns around the corner towards the wall. Otherwise it moves straight on and turns away from the wall only when blocked.
+// Without a wall nearby, it moves straight on until it reaches one. This fills enclosed regions ring by ring and leaves few unusable cells.
+// The rule is only followed as long as it does not split the free space: Of all actions not crashing, the first one (in the order of the rule) leaving the largest pocket (see TrapCheck) is chosen.
+// Actions moving into a cell an opponent might reach in the same round are only used if there is no other action.
+// The speed is kept at 1. A faster player slows down first, and speeding up is only used if everything else crashes.
+type WallFollowerAI struct {
+	// RightHand selects the right-hand rule instead of the left-hand rule.
+	RightHand bool
+
+	l sync.Mutex
+	i chan Action
+}
+
+// GetChannel receives the answer channel.
+func (w *WallFollowerAI) GetChannel(c chan Action) {
+	w.l.Lock()
+	defer w.l.Unlock()
+
+	w.i = c
+}
+
+// GetState gets the game state and computes an answer.
+func (w *WallFollowerAI) GetState(g *Game) {
+	w.l.Lock()
+	defer w.l.Unlock()
+
+	if w.i == nil {
+		return
+	}
+
+	if g.Running && g.Players[g.You].Active {
+		p := g.Players[g.You]
+		b := NewBitboard(g)
+
+		toWall, away := ActionTurnLeft, ActionTurnRight
+		dx, dy := 0, 0
+		switch p.Direction {
+		case DirectionUp:
+			dy = -1
+		case DirectionDown:
+			dy = 1
+		case DirectionLeft:
+			dx = -1
+		case DirectionRight:
+			dx = 1
+		}
+		// (wx, wy) points to the side of the wall
+		wx, wy := dy, -dx
+		if w.RightHand {
+			toWall, away = away, toWall
+			wx, wy = -dy, dx
+		}
+
+		candidates := make([]Action, 0, 5)
+		if p.Speed > 1 {
+			candidates = append(candidates, ActionSlower)
+		}
+		if !b.IsOccupied(p.X+wx, p.Y+wy) && b.IsOccupied(p.X+wx-dx, p.Y+wy-dy) {
+			// The wall ends here - follow it around the corner
+			candidates = append(candidates, toWall, ActionNOOP, away)
+		} else {
+			candidates = append(candidates, ActionNOOP, away, toWall)
+		}
+
+		var action, riskyAction Action
+		bestPocket, bestRiskyPocket := -1, -1
+		danger := opponentCells(g)
+		var scores map[Action]float64
+		if DecisionLogEnabled() {
+			scores = make(map[Action]float64, len(candidates)+1)
+		}
+		evaluate := func(a Action) {
+			if b.Crashes(p, a) {
+				return
+			}
+			c, crashed := Simulate(g, g.You, a)
+			if crashed {
+				ReleaseClone(c)
+				return
+			}
+			risky := false
+			for y := range c.Cells {
+				for x := range c.Cells[y] {
+					if c.Cells[y][x] != g.Cells[y][x] && danger[coordinate{x, y}] {
+						risky = true
+					}
+				}
+			}
+			cp := c.Players[c.You]
+			pocket, _ := TrapCheck(c, cp.X, cp.Y, cp.Speed)
+			ReleaseClone(c)
+			if scores != nil {
+				scores[a] = float64(pocket)
+			}
+			// Candidates are ordered by the rule, so only a larger pocket overrides it
+			switch {
+			case risky && pocket > bestRiskyPocket:
+				bestRiskyPocket = pocket
+				riskyAction = a
+			case !risky && pocket > bestPocket:
+				bestPocket = pocket
+				action = a
+			}
+		}
+		for _, a := range candidates {
+			evaluate(a)
+		}
+		if action == "" && riskyAction == "" && p.Speed < MaxSpeed {
+			evaluate(ActionFaster)
+		}
+
+		reason := "following wall"
+		if action == "" && riskyAction != "" {
+			action = riskyAction
+			reason = "every action might collide with an opponent"
+		}
+		if action == "" {
+			// Neither following the wall nor speeding up avoids a crash
+			action = ActionNOOP
+			reason = "every action crashes"
+		}
+
+		LogDecision(g, w.Name(), action, scores, reason)
+
+		select {
+		case w.i <- action:
+		default:
+		}
+	}
+}
+
+// Name returns the name of the AI.
+func (w *WallFollowerAI) Name() string {
+	if w.RightHand {
+		return "WallFollowerAIRight"
+	}
+	return "WallFollowerAI"
+}
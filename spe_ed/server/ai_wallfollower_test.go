@@ -0,0 +1,44 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "testing"
+
+func TestWallFollowerAIEnclosed(t *testing.T) {
+	// The region of player 1 is left of the wall in column 9.
+	for _, grid := range []string{
+		`"1>.......x....", ".........x....", ".........x....", ".........x....", ".........x....", ".........x..<2"`,
+		`"xxxxxxxxxx....", "x1>......x....", "x........x....", "x...xx...x....", "x........x....", "x........x..<2", "xxxxxxxxxx...."`,
+		`"111>.....x....", ".........x....", "xxx......x....", "xxx......x....", ".........x....", ".........x..<2"`,
+	} {
+		for _, rightHand := range []bool{false, true} {
+			g := testScenario(t, `{"you": 1, "grid": [`+grid+`], "players": {"1": {}, "2": {}}}`)
+			pocket := freeCells(g)
+			for y := range g.Cells {
+				for x := 9; x < g.Width; x++ {
+					if IsEmpty(g.Cells[y][x]) {
+						pocket--
+					}
+				}
+			}
+
+			survived := playAlone(t, &WallFollowerAI{RightHand: rightHand}, g, 1000)
+			if survived*10 < pocket*9 {
+				t.Errorf("right hand %t: survived %d rounds in a pocket of %d cells\n%s", rightHand, survived, pocket, g)
+			}
+		}
+	}
+}
@@ -0,0 +1,282 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"sort"
+	"sync"
+)
+
+func init() {
+	MustRegisterAI("WeightedHeuristicAI", func() AI { return &WeightedHeuristicAI{Weights: GetWeightedHeuristicWeights()} })
+}
+
+// WeightedHeuristicWeights contains the weights of the scoring function of WeightedHeuristicAI.
+// The score of an action is the sum of all terms multiplied by their weight. Negative weights are allowed.
+// Every term is a registered evaluator (see RegisterEvaluator). The built-in terms have their own field named like the evaluator, all other evaluators are weighted by name in Evaluators.
+type WeightedHeuristicWeights struct {
+	// ReachableSpace weights the number of free cells reachable by the player (see FloodFill). Default: 1.
+	ReachableSpace float64 `json:"reachable_space"`
+	// Voronoi weights the number of free cells the player reaches before all opponents (see Voronoi). Default: 0.5.
+	Voronoi float64 `json:"voronoi"`
+	// OpponentDistance weights the manhattan distance to the nearest active opponent (0 without opponents). Default: 0.
+	OpponentDistance float64 `json:"opponent_distance"`
+	// CorridorWidth weights the width of the free corridor directly in front of the player (see corridorWidth). Default: 2.
+	CorridorWidth float64 `json:"corridor_width"`
+	// Chamber weights the free space around the head (see ChamberSize with ChamberRadius), which is small in narrow corridors. Default: 0.
+	Chamber float64 `json:"chamber"`
+	// Evaluators weights further evaluators by their registered name, e.g. "mobility" or "wall_distance". Default: none.
+	Evaluators map[string]float64 `json:"evaluators,omitempty"`
+}
+
+// weightedHeuristicFields contains the names of the evaluators which have their own field in WeightedHeuristicWeights, in the order they are summed.
+var weightedHeuristicFields = []string{"reachable_space", "voronoi", "opponent_distance", "corridor_width", "chamber"}
+
+// isWeightedHeuristicField returns whether the evaluator has its own field in WeightedHeuristicWeights.
+func isWeightedHeuristicField(name string) bool {
+	for _, f := range weightedHeuristicFields {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+// byName returns the weight of all evaluators by their name, including the ones with their own field.
+func (w WeightedHeuristicWeights) byName() map[string]float64 {
+	m := map[string]float64{"reachable_space": w.ReachableSpace, "voronoi": w.Voronoi, "opponent_distance": w.OpponentDistance, "corridor_width": w.CorridorWidth, "chamber": w.Chamber}
+	for name, v := range w.Evaluators {
+		m[name] += v
+	}
+	return m
+}
+
+// Terms returns the evaluators with their weights: first the ones with their own field, afterwards the ones of Evaluators in alphabetical order.
+// Evaluators with a weight of zero are left out. An unknown name in Evaluators is an error.
+func (w WeightedHeuristicWeights) Terms() ([]WeightedEvaluator, error) {
+	weights := w.byName()
+	names := make([]string, 0, len(w.Evaluators))
+	for name := range w.Evaluators {
+		if !isWeightedHeuristicField(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	names = append(append([]string{}, weightedHeuristicFields...), names...)
+
+	terms := make([]WeightedEvaluator, 0, len(names))
+	for _, name := range names {
+		if weights[name] == 0 {
+			continue
+		}
+		fn, err := GetEvaluator(name)
+		if err != nil {
+			return nil, fmt.Errorf("weights: %w", err)
+		}
+		terms = append(terms, WeightedEvaluator{Name: name, Weight: weights[name], Evaluate: fn})
+	}
+	return terms, nil
+}
+
+// DefaultWeightedHeuristicWeights contains the weights WeightedHeuristicAI uses if no weights are loaded.
+// Reachable space dominates, territory and room to manoeuvre break ties between similarly large regions.
+var DefaultWeightedHeuristicWeights = WeightedHeuristicWeights{
+	ReachableSpace:   1,
+	Voronoi:          0.5,
+	OpponentDistance: 0,
+	CorridorWidth:    2,
+	Chamber:          0,
+}
+
+var weightedHeuristicWeights = DefaultWeightedHeuristicWeights
+var weightedHeuristicWeightsLock sync.Mutex
+
+// LoadWeightedHeuristicWeights reads weights from a JSON file, e.g. {"reachable_space": 1, "voronoi": 0.5, "opponent_distance": 0, "corridor_width": 2, "chamber": 0, "evaluators": {"mobility": 1}}.
+// Missing weights keep their default value. Unknown keys, unknown evaluators, evaluators in "evaluators" which have their own key and values which are not finite numbers are an error.
+func LoadWeightedHeuristicWeights(path string) (WeightedHeuristicWeights, error) {
+	w := DefaultWeightedHeuristicWeights
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return w, fmt.Errorf("weights: %w", err)
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.DisallowUnknownFields()
+	err = d.Decode(&w)
+	if err != nil {
+		return DefaultWeightedHeuristicWeights, fmt.Errorf("weights: can not parse %s: %w", path, err)
+	}
+
+	for name := range w.Evaluators {
+		if isWeightedHeuristicField(name) {
+			return DefaultWeightedHeuristicWeights, fmt.Errorf("weights: evaluator %s in %s must be set by its own key", name, path)
+		}
+	}
+	for name, v := range w.byName() {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return DefaultWeightedHeuristicWeights, fmt.Errorf("weights: %s in %s is not a finite number", name, path)
+		}
+	}
+	_, err = w.Terms()
+	if err != nil {
+		return DefaultWeightedHeuristicWeights, fmt.Errorf("%w in %s", err, path)
+	}
+	return w, nil
+}
+
+// SetWeightedHeuristicWeights sets the weights used by all WeightedHeuristicAI created through the AI registry afterwards.
+func SetWeightedHeuristicWeights(w WeightedHeuristicWeights) {
+	weightedHeuristicWeightsLock.Lock()
+	defer weightedHeuristicWeightsLock.Unlock()
+	weightedHeuristicWeights = w
+}
+
+// GetWeightedHeuristicWeights returns the weights set by SetWeightedHeuristicWeights (or the default weights).
+func GetWeightedHeuristicWeights() WeightedHeuristicWeights {
+	weightedHeuristicWeightsLock.Lock()
+	defer weightedHeuristicWeightsLock.Unlock()
+	return weightedHeuristicWeights
+}
+
+// WeightedHeuristicAI is an AI which scores every legal action with a weighted sum of heuristics (see WeightedHeuristicWeights) and chooses the action with the highest score.
+// The weights can be loaded from a file, so the behaviour can be tuned without recompiling.
+type WeightedHeuristicAI struct {
+	l sync.Mutex
+
+	i chan Action
+
+	// Weights contains the weights of the scoring function.
+	Weights WeightedHeuristicWeights
+}
+
+// GetChannel receives the answer channel.
+func (w *WeightedHeuristicAI) GetChannel(c chan Action) {
+	w.l.Lock()
+	defer w.l.Unlock()
+
+	w.i = c
+}
+
+// GetState gets the game state and computes an answer.
+func (w *WeightedHeuristicAI) GetState(g *Game) {
+	w.l.Lock()
+	defer w.l.Unlock()
+
+	if w.i == nil {
+		return
+	}
+
+	if g.Running && g.Players[g.You].Active {
+		var scores map[Action]float64
+		if DecisionLogEnabled() {
+			scores = make(map[Action]float64, 5)
+		}
+		action, reason := w.decide(g, scores)
+		LogDecision(g, w.Name(), action, scores, reason)
+
+		select {
+		case w.i <- action:
+		default:
+		}
+	}
+}
+
+// Explain returns the scores of all legal actions (see Explainable and Game.LegalActions).
+// The score is the weighted sum of all heuristics after the action.
+func (w *WeightedHeuristicAI) Explain(g *Game) map[Action]float64 {
+	w.l.Lock()
+	defer w.l.Unlock()
+
+	scores := make(map[Action]float64, 5)
+	w.decide(g, scores)
+	return scores
+}
+
+// Name returns the name of the AI.
+func (w *WeightedHeuristicAI) Name() string {
+	return "WeightedHeuristicAI"
+}
+
+// decide picks the legal action of Game.You leading to the state with the highest weighted sum of the evaluators (see EvaluateWeighted).
+// It returns the action together with the reason and adds the weighted sum of every legal action to scores if it is not nil. The game is not modified.
+func (w *WeightedHeuristicAI) decide(g *Game, scores map[Action]float64) (Action, string) {
+	action := ActionNOOP
+	best := math.Inf(-1)
+	reason := "highest score"
+
+	// Unknown evaluators are rejected when loading the weights (see LoadWeightedHeuristicWeights)
+	terms, err := w.Weights.Terms()
+	if err != nil {
+		return FallbackAction(g, FallbackFirstLegal), err.Error()
+	}
+
+	for _, a := range g.LegalActions(g.You) {
+		c := AcquireClone(g)
+		ApplyAction(c, c.You, a)
+		score := EvaluateWeighted(c, c.You, terms)
+		ReleaseClone(c)
+		if scores != nil {
+			scores[a] = score
+		}
+		if score > best {
+			best = score
+			action = a
+		}
+	}
+	if math.IsInf(best, -1) {
+		reason = "every action crashes"
+	}
+	return action, reason
+}
+
+// corridorWidth returns the width of the free corridor directly in front of the player.
+// This is the number of consecutive free cells perpendicular to the direction of the player through the cell in front of the head, or 0 if that cell is not free.
+func corridorWidth(g *Game, p *Player) int {
+	dx, dy := 0, 0
+	switch p.Direction {
+	case DirectionUp:
+		dy = -1
+	case DirectionDown:
+		dy = 1
+	case DirectionLeft:
+		dx = -1
+	case DirectionRight:
+		dx = 1
+	}
+
+	free := func(x, y int) bool {
+		return x >= 0 && x < g.Width && y >= 0 && y < g.Height && IsEmpty(g.Cells[y][x])
+	}
+
+	x, y := p.X+dx, p.Y+dy
+	if !free(x, y) {
+		return 0
+	}
+	width := 1
+	// Perpendicular directions are (dy, dx) and (-dy, -dx)
+	for i := 1; free(x+i*dy, y+i*dx); i++ {
+		width++
+	}
+	for i := 1; free(x-i*dy, y-i*dx); i++ {
+		width++
+	}
+	return width
+}
@@ -0,0 +1,48 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadWeightedHeuristicWeights(t *testing.T) {
+	dir := t.TempDir()
+	for name, tc := range map[string]struct {
+		src   string
+		valid bool
+	}{
+		"evaluators":        {`{"voronoi": 2, "evaluators": {"mobility": 1}}`, true},
+		"unknown evaluator": {`{"evaluators": {"test_unknown": 1}}`, false},
+		"own key":           {`{"evaluators": {"voronoi": 1}}`, false},
+		"unknown key":       {`{"mobility": 1}`, false},
+	} {
+		path := filepath.Join(dir, "weights.json")
+		err := os.WriteFile(path, []byte(tc.src), 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w, err := LoadWeightedHeuristicWeights(path)
+		if (err == nil) != tc.valid {
+			t.Errorf("%s: got error %v, want valid %t", name, err, tc.valid)
+		}
+		if tc.valid && (w.Voronoi != 2 || w.ReachableSpace != DefaultWeightedHeuristicWeights.ReachableSpace || w.Evaluators["mobility"] != 1) {
+			t.Errorf("%s: got %+v", name, w)
+		}
+	}
+}
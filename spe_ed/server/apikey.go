@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+const (
+	// APIKeyEnv contains the name of the environment variable containing the API key of the client (see ResolveAPIKey).
+	APIKeyEnv = "SPEED_API_KEY"
+	// APIKeyLegacyEnv contains the name of the environment variable which contained the API key before APIKeyEnv was introduced. It has the lowest precedence.
+	APIKeyLegacyEnv = "KEY"
+)
+
+// ErrNoAPIKey is returned by ResolveAPIKey if none of the sources contains an API key.
+var ErrNoAPIKey = errors.New("no api key")
+
+// APIKeySources contains all sources of the API key of the client. Empty values are not set.
+type APIKeySources struct {
+	// Flag is the key given on the command line (-key).
+	Flag string
+	// File is the path of a file containing the key (-key-file). Surrounding white space in the file is ignored.
+	File string
+	// Env is the value of the environment variable APIKeyEnv.
+	Env string
+	// Config is the key of the configuration file (see Config).
+	Config string
+	// LegacyEnv is the value of the environment variable APIKeyLegacyEnv.
+	LegacyEnv string
+}
+
+// ResolveAPIKey returns the API key with the highest precedence together with the name of its source.
+// The precedence is (highest first): Flag, File, Env, Config, LegacyEnv. A file which can not be read or is empty is an error, even if a key with a lower precedence is available.
+// ErrNoAPIKey is returned if no source contains a key. Errors never contain the key.
+func ResolveAPIKey(s APIKeySources) (key string, source string, err error) {
+	if s.Flag != "" {
+		return s.Flag, "-key", nil
+	}
+	if s.File != "" {
+		b, err := ioutil.ReadFile(s.File)
+		if err != nil {
+			return "", "", fmt.Errorf("can not read key file: %w", err)
+		}
+		key := strings.TrimSpace(string(b))
+		if key == "" {
+			return "", "", fmt.Errorf("key file %s is empty", s.File)
+		}
+		return key, "-key-file", nil
+	}
+	if s.Env != "" {
+		return s.Env, APIKeyEnv, nil
+	}
+	if s.Config != "" {
+		return s.Config, "-config", nil
+	}
+	if s.LegacyEnv != "" {
+		return s.LegacyEnv, APIKeyLegacyEnv, nil
+	}
+	return "", "", fmt.Errorf("%w (use -key-file, %s, -key or the key of -config)", ErrNoAPIKey, APIKeyEnv)
+}
+
+// RedactKey returns a representation of the API key which can be logged: the first 8 hexadecimal digits of its SHA-256 hash.
+// Log messages referring to the same key can still be matched, but the key itself is not revealed. An empty key is returned unchanged.
+func RedactKey(key string) string {
+	if key == "" {
+		return ""
+	}
+	return fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(key)))[:len("sha256:")+8]
+}
@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveAPIKey(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "key")
+	err := os.WriteFile(file, []byte("  file-key\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	empty := filepath.Join(dir, "empty")
+	err = os.WriteFile(empty, []byte(" \n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	all := APIKeySources{Flag: "flag-key", File: file, Env: "env-key", Config: "config-key", LegacyEnv: "legacy-key"}
+	for _, tc := range []struct {
+		sources     APIKeySources
+		key, source string
+	}{
+		{all, "flag-key", "-key"},
+		{APIKeySources{File: file, Env: "env-key", Config: "config-key", LegacyEnv: "legacy-key"}, "file-key", "-key-file"},
+		{APIKeySources{Env: "env-key", Config: "config-key", LegacyEnv: "legacy-key"}, "env-key", APIKeyEnv},
+		{APIKeySources{Config: "config-key", LegacyEnv: "legacy-key"}, "config-key", "-config"},
+		{APIKeySources{LegacyEnv: "legacy-key"}, "legacy-key", APIKeyLegacyEnv},
+	} {
+		key, source, err := ResolveAPIKey(tc.sources)
+		if err != nil || key != tc.key || source != tc.source {
+			t.Errorf("%+v: got %q from %q (error %v), want %q from %q", tc.sources, key, source, err, tc.key, tc.source)
+		}
+	}
+
+	if _, _, err := ResolveAPIKey(APIKeySources{}); !errors.Is(err, ErrNoAPIKey) {
+		t.Errorf("no sources: got error %v, want ErrNoAPIKey", err)
+	}
+	for _, path := range []string{empty, filepath.Join(dir, "missing")} {
+		key, _, err := ResolveAPIKey(APIKeySources{File: path, Env: "env-key"})
+		if err == nil || key != "" {
+			t.Errorf("%s: got %q without error", filepath.Base(path), key)
+		}
+	}
+}
+
+func TestRedactKey(t *testing.T) {
+	const key = "0123456789abcdef"
+	r := RedactKey(key)
+	if !strings.HasPrefix(r, "sha256:") || len(r) != len("sha256:")+8 || strings.Contains(r, key[:8]) {
+		t.Errorf("redacted key %q", r)
+	}
+	if RedactKey(key) != r || RedactKey(key+"x") == r {
+		t.Error("redacted keys can not be matched")
+	}
+	if RedactKey("") != "" {
+		t.Error("empty key changed")
+	}
+}
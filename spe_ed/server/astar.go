@@ -0,0 +1,104 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "container/heap"
+
+// AStar returns the shortest path from start to goal over the free cells of g.Cells using the A* algorithm with the manhattan distance as heuristic.
+// The path contains both start and goal. Start does not need to be free, so the position of a head can be used directly.
+// Any non-zero cell is treated as a wall, as are the bounds of the board.
+// AStar returns nil if goal can not be reached.
+func AStar(g *Game, start, goal coordinate) []coordinate {
+	if start.X < 0 || start.X >= g.Width || start.Y < 0 || start.Y >= g.Height {
+		return nil
+	}
+	if goal.X < 0 || goal.X >= g.Width || goal.Y < 0 || goal.Y >= g.Height {
+		return nil
+	}
+	if start == goal {
+		return []coordinate{start}
+	}
+	if !IsEmpty(g.Cells[goal.Y][goal.X]) {
+		return nil
+	}
+
+	cost := make([]int, g.Width*g.Height)
+	parent := make([]int, g.Width*g.Height)
+	closed := make([]bool, g.Width*g.Height)
+	for i := range cost {
+		cost[i] = -1
+		parent[i] = -1
+	}
+
+	open := &aStarQueue{}
+	cost[start.Y*g.Width+start.X] = 0
+	heap.Push(open, aStarItem{c: start, priority: abs(goal.X-start.X) + abs(goal.Y-start.Y)})
+
+	for open.Len() != 0 {
+		c := heap.Pop(open).(aStarItem).c
+		i := c.Y*g.Width + c.X
+		if closed[i] {
+			continue
+		}
+		closed[i] = true
+
+		if c == goal {
+			path := make([]coordinate, 0, cost[i]+1)
+			for ; i != -1; i = parent[i] {
+				path = append(path, coordinate{i % g.Width, i / g.Width})
+			}
+			for l, r := 0, len(path)-1; l < r; l, r = l+1, r-1 {
+				path[l], path[r] = path[r], path[l]
+			}
+			return path
+		}
+
+		for _, n := range [4]coordinate{{c.X + 1, c.Y}, {c.X - 1, c.Y}, {c.X, c.Y + 1}, {c.X, c.Y - 1}} {
+			if n.X < 0 || n.X >= g.Width || n.Y < 0 || n.Y >= g.Height || !IsEmpty(g.Cells[n.Y][n.X]) {
+				continue
+			}
+			j := n.Y*g.Width + n.X
+			if closed[j] {
+				continue
+			}
+			if cost[j] == -1 || cost[i]+1 < cost[j] {
+				cost[j] = cost[i] + 1
+				parent[j] = i
+				heap.Push(open, aStarItem{c: n, priority: cost[j] + abs(goal.X-n.X) + abs(goal.Y-n.Y)})
+			}
+		}
+	}
+	return nil
+}
+
+type aStarItem struct {
+	c        coordinate
+	priority int
+}
+
+// aStarQueue is a priority queue of aStarItem implementing heap.Interface.
+type aStarQueue []aStarItem
+
+func (q aStarQueue) Len() int            { return len(q) }
+func (q aStarQueue) Less(i, j int) bool  { return q[i].priority < q[j].priority }
+func (q aStarQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *aStarQueue) Push(x interface{}) { *q = append(*q, x.(aStarItem)) }
+func (q *aStarQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "testing"
+
+// checkPath fails the test if path is not a path of the given length from start to goal over free cells.
+func checkPath(t *testing.T, g *Game, path []coordinate, start, goal coordinate, length int) {
+	t.Helper()
+	if len(path) != length {
+		t.Fatalf("path %v has %d cells, want %d", path, len(path), length)
+	}
+	if path[0] != start || path[len(path)-1] != goal {
+		t.Fatalf("path %v does not lead from %v to %v", path, start, goal)
+	}
+	for i := 1; i < len(path); i++ {
+		c := path[i]
+		if abs(c.X-path[i-1].X)+abs(c.Y-path[i-1].Y) != 1 {
+			t.Fatalf("path %v jumps at %d", path, i)
+		}
+		if c.X < 0 || c.X >= g.Width || c.Y < 0 || c.Y >= g.Height || !IsEmpty(g.Cells[c.Y][c.X]) {
+			t.Fatalf("path %v crosses blocked cell %v", path, c)
+		}
+	}
+}
+
+func TestAStar(t *testing.T) {
+	g := testScenario(t, `{"you": 1, "grid": [
+		">.....",
+		"......",
+		"......"
+	], "players": {"1": {"x": 0, "y": 0}}}`)
+	checkPath(t, g, AStar(g, coordinate{0, 0}, coordinate{5, 2}), coordinate{0, 0}, coordinate{5, 2}, 8)
+
+	g = testScenario(t, `{"you": 1, "grid": [
+		">..x...",
+		"...x...",
+		"...x...",
+		".......",
+		"...x..."
+	], "players": {"1": {"x": 0, "y": 0}}}`)
+	// The only way through the wall is at the bottom
+	checkPath(t, g, AStar(g, coordinate{0, 0}, coordinate{6, 0}), coordinate{0, 0}, coordinate{6, 0}, 13)
+
+	g = testScenario(t, `{"you": 1, "grid": [
+		"11>....",
+		"..2222.",
+		"..2.xx.",
+		"..2....",
+		"..2222<"
+	], "players": {"1": {}, "2": {"x": 6, "y": 4}}}`)
+	// The pocket can only be entered from the right
+	checkPath(t, g, AStar(g, coordinate{2, 0}, coordinate{3, 3}), coordinate{2, 0}, coordinate{3, 3}, 11)
+}
+
+func TestAStarUnreachable(t *testing.T) {
+	g := testScenario(t, `{"you": 1, "grid": [
+		">..x.",
+		"...x.",
+		"xxxx.",
+		".x...",
+		"x...."
+	], "players": {"1": {"x": 0, "y": 0}}}`)
+	for name, goal := range map[string]coordinate{
+		"enclosed region": {4, 0},
+		"enclosed corner": {0, 3},
+		"filled goal":     {3, 0},
+		"outside":         {5, 0},
+		"negative":        {-1, 0},
+	} {
+		if path := AStar(g, coordinate{0, 0}, goal); path != nil {
+			t.Errorf("%s: got path %v", name, path)
+		}
+	}
+	if path := AStar(g, coordinate{0, 0}, coordinate{0, 0}); len(path) != 1 || path[0] != (coordinate{0, 0}) {
+		t.Errorf("path to start: got %v", path)
+	}
+}
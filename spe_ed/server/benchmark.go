@@ -0,0 +1,215 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// BenchmarkSizes contains the width and height of the boards used by RunBenchmark.
+var BenchmarkSizes = []int{40, 60, 80}
+
+// BenchmarkStages contains the names of the stages of a game used by RunBenchmark, together with the fraction of the length of the generated game after which the state is taken.
+var BenchmarkStages = []struct {
+	Name     string
+	Fraction float64
+}{
+	{"early", 0.1},
+	{"mid", 0.5},
+	{"late", 0.9},
+}
+
+// BenchmarkConfig contains the configuration of RunBenchmark.
+type BenchmarkConfig struct {
+	// AIs contains the names of the AIs to measure.
+	AIs []string
+	// Runs is the number of decisions measured per AI and board.
+	Runs int
+	// Deadline is the time until the deadline of every state. AIs not answering within it count as timeout.
+	Deadline time.Duration
+	// Seed is used to generate the boards and to seed all AIs implementing SeedableAI.
+	Seed int64
+}
+
+// BenchmarkBoards generates realistic states of a game of the given size for every stage of BenchmarkStages.
+// A game between PlayersPerGame instances of FloodFillAI is simulated and the states at the fractions of the rounds with at least two active players are taken.
+// Game.You is set to the active player with the lowest number.
+func BenchmarkBoards(size int, seed int64) ([]*Game, error) {
+	ais := make([]string, PlayersPerGame)
+	for i := range ais {
+		ais[i] = "FloodFillAI"
+	}
+	s, err := NewSimulator(size, size, seed, ais...)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]*Game, 0)
+	for {
+		active := 0
+		for _, p := range s.Game.Players {
+			if p.Active {
+				active++
+			}
+		}
+		if !s.Game.Running || active < 2 {
+			break
+		}
+		states = append(states, s.Game.PublicCopy())
+		s.Step()
+	}
+	if len(states) == 0 {
+		return nil, errors.New("benchmark: generated game has no rounds")
+	}
+
+	boards := make([]*Game, len(BenchmarkStages))
+	for i := range BenchmarkStages {
+		g := states[int(BenchmarkStages[i].Fraction*float64(len(states)-1))]
+		for id := 1; id <= PlayersPerGame; id++ {
+			if p, ok := g.Players[id]; ok && p.Active {
+				g.You = id
+				break
+			}
+		}
+		boards[i] = g
+	}
+	return boards, nil
+}
+
+// RunBenchmark measures the time every AI needs for a single decision on the boards of BenchmarkBoards for all sizes of BenchmarkSizes and writes the results as CSV to w.
+// Every decision is made by a new instance of the AI, so the measurement does not depend on earlier decisions. The deadline of the state is set, so AIs using their time budget answer shortly before it.
+// Decisions are measured one after another to avoid interference. The same decisions are measured by BenchmarkGetState for go test -bench.
+func RunBenchmark(config BenchmarkConfig, w io.Writer) error {
+	if len(config.AIs) == 0 {
+		return errors.New("benchmark: no ais")
+	}
+	if config.Runs < 1 {
+		return errors.New("benchmark: at least one run is needed")
+	}
+	if config.Deadline <= 0 {
+		return errors.New("benchmark: deadline must be positive")
+	}
+	for _, name := range config.AIs {
+		if _, err := CreateAI(name); err != nil {
+			return fmt.Errorf("benchmark: %w", err)
+		}
+	}
+
+	cw := csv.NewWriter(w)
+	err := cw.Write([]string{"ai", "size", "stage", "filled", "runs", "timeouts", "mean_ms", "max_ms", "decisions_per_second"})
+	if err != nil {
+		return fmt.Errorf("benchmark: %w", err)
+	}
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', 3, 64) }
+
+	for _, size := range BenchmarkSizes {
+		boards, err := BenchmarkBoards(size, config.Seed)
+		if err != nil {
+			return err
+		}
+		for b, board := range boards {
+			filled := 0
+			for y := range board.Cells {
+				for x := range board.Cells[y] {
+					if !IsEmpty(board.Cells[y][x]) {
+						filled++
+					}
+				}
+			}
+
+			for _, name := range config.AIs {
+				var total, max time.Duration
+				timeouts := 0
+				for run := 0; run < config.Runs; run++ {
+					d, ok := benchmarkDecision(name, board, config.Deadline, config.Seed+int64(run))
+					total += d
+					if d > max {
+						max = d
+					}
+					if !ok {
+						timeouts++
+					}
+				}
+
+				err = cw.Write([]string{
+					name,
+					fmt.Sprintf("%dx%d", board.Width, board.Height),
+					BenchmarkStages[b].Name,
+					f(float64(filled) / float64(board.Width*board.Height)),
+					strconv.Itoa(config.Runs),
+					strconv.Itoa(timeouts),
+					f(float64(total) / float64(config.Runs) / float64(time.Millisecond)),
+					f(float64(max) / float64(time.Millisecond)),
+					f(float64(config.Runs) / total.Seconds()),
+				})
+				if err != nil {
+					return fmt.Errorf("benchmark: %w", err)
+				}
+				cw.Flush()
+			}
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("benchmark: %w", err)
+	}
+	return nil
+}
+
+// benchmarkDecision lets a new instance of the AI decide on a copy of the board and returns the time until the answer.
+// If the AI does not answer before the deadline, the deadline and false are returned.
+// Before returning, it waits (up to the deadline) for the AI to finish, so it does not slow down the next measurement.
+func benchmarkDecision(name string, board *Game, deadline time.Duration, seed int64) (time.Duration, bool) {
+	ai, err := CreateAI(name)
+	if err != nil {
+		return 0, false
+	}
+	if sai, ok := ai.(SeedableAI); ok {
+		sai.Seed(seed)
+	}
+	answer := make(chan Action, 1)
+	ai.GetChannel(answer)
+
+	g := board.PublicCopy()
+	g.Deadline = ServerNow().Add(deadline).UTC().Format(time.RFC3339Nano)
+
+	timer := time.NewTimer(deadline)
+	defer timer.Stop()
+	done := make(chan struct{})
+	start := time.Now()
+	go func() {
+		getAIState(ai, g, start.Add(deadline))
+		close(done)
+	}()
+	defer func() {
+		select {
+		case <-done:
+		case <-time.After(deadline):
+		}
+	}()
+
+	select {
+	case <-answer:
+		return time.Since(start), true
+	case <-timer.C:
+		return deadline, false
+	}
+}
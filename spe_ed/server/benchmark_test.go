@@ -0,0 +1,134 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// benchmarkBoards returns the boards of BenchmarkBoards for all sizes of BenchmarkSizes together with a name of the form "<size>/<stage>".
+func benchmarkBoards(b *testing.B) ([]string, []*Game) {
+	b.Helper()
+	names := make([]string, 0, len(BenchmarkSizes)*len(BenchmarkStages))
+	games := make([]*Game, 0, len(BenchmarkSizes)*len(BenchmarkStages))
+	for _, size := range BenchmarkSizes {
+		boards, err := BenchmarkBoards(size, 1)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for i := range boards {
+			names = append(names, fmt.Sprintf("%d/%s", size, BenchmarkStages[i].Name))
+			games = append(games, boards[i])
+		}
+	}
+	return names, games
+}
+
+func BenchmarkApplyAction(b *testing.B) {
+	names, boards := benchmarkBoards(b)
+	for i := range boards {
+		board := boards[i]
+
+		// All legal moves of all active players, together with the cells they change, so the move can be undone.
+		type move struct {
+			id      int
+			action  Action
+			changed []coordinate
+		}
+		var moves []move
+		for id, p := range board.Players {
+			if !p.Active {
+				continue
+			}
+			for _, action := range board.LegalActions(id) {
+				c := board.Clone()
+				ApplyAction(c, id, action)
+				m := move{id: id, action: action}
+				for y := range c.Cells {
+					for x := range c.Cells[y] {
+						if c.Cells[y][x] != board.Cells[y][x] {
+							m.changed = append(m.changed, coordinate{x, y})
+						}
+					}
+				}
+				moves = append(moves, m)
+			}
+		}
+		if len(moves) == 0 {
+			continue
+		}
+
+		b.Run(names[i], func(b *testing.B) {
+			g := board.Clone()
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				m := moves[n%len(moves)]
+				p := g.Players[m.id]
+				x, y, direction, speed, stepCounter := p.X, p.Y, p.Direction, p.Speed, p.stepCounter
+				ApplyAction(g, m.id, m.action)
+				for _, c := range m.changed {
+					g.Cells[c.Y][c.X] = board.Cells[c.Y][c.X]
+				}
+				p.X, p.Y, p.Direction, p.Speed, p.Active, p.stepCounter = x, y, direction, speed, true, stepCounter
+			}
+		})
+	}
+}
+
+func BenchmarkClone(b *testing.B) {
+	names, boards := benchmarkBoards(b)
+	for i := range boards {
+		board := boards[i]
+		b.Run("Clone/"+names[i], func(b *testing.B) {
+			b.ReportAllocs()
+			for n := 0; n < b.N; n++ {
+				board.Clone()
+			}
+		})
+		b.Run("AcquireClone/"+names[i], func(b *testing.B) {
+			b.ReportAllocs()
+			for n := 0; n < b.N; n++ {
+				ReleaseClone(AcquireClone(board))
+			}
+		})
+	}
+}
+
+// BenchmarkGetState measures a single decision of every registered AI on the boards of RunBenchmark (see benchmarkDecision).
+// Run a single AI with -bench 'GetState/<AI>'. AIs using their whole time budget take about one second per decision.
+func BenchmarkGetState(b *testing.B) {
+	names, boards := benchmarkBoards(b)
+	for _, ai := range ListAIs() {
+		for i := range boards {
+			board := boards[i]
+			b.Run(ai+"/"+names[i], func(b *testing.B) {
+				var total time.Duration
+				timeouts := 0
+				for n := 0; n < b.N; n++ {
+					d, ok := benchmarkDecision(ai, board, time.Second, int64(n))
+					total += d
+					if !ok {
+						timeouts++
+					}
+				}
+				b.ReportMetric(float64(b.N)/total.Seconds(), "decisions/s")
+				b.ReportMetric(float64(timeouts)/float64(b.N), "timeouts/op")
+			})
+		}
+	}
+}
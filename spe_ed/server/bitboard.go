@@ -0,0 +1,190 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+// Bitboard is a packed representation of the occupied cells of a game.
+// Each cell is represented by a single bit (row by row), so arbitrary widths are supported.
+// Cells outside of the board are considered occupied.
+type Bitboard struct {
+	Width, Height int
+	// Holes contains the hole rules used by Crashes.
+	Holes HoleRules
+
+	bits []uint64
+}
+
+// NewBitboard returns a bitboard with all non-zero cells of the game set. It uses the hole rules of the game.
+func NewBitboard(g *Game) *Bitboard {
+	b := NewEmptyBitboard(g.Width, g.Height)
+	b.Holes = g.Holes
+	var word uint64
+	i := 0
+	for y := range g.Cells {
+		for _, c := range g.Cells[y] {
+			// Branch-free version of !IsEmpty(c) (CellEmpty is 0), since this loop runs for every cell
+			v := uint64(uint8(c))
+			word |= ((v | -v) >> 63) << (uint(i) & 63)
+			i++
+			if i&63 == 0 {
+				b.bits[(i-1)>>6] = word
+				word = 0
+			}
+		}
+	}
+	if i&63 != 0 {
+		b.bits[i>>6] = word
+	}
+	return b
+}
+
+// NewEmptyBitboard returns a bitboard of the given size with no cell set.
+func NewEmptyBitboard(width, height int) *Bitboard {
+	return &Bitboard{
+		Width:  width,
+		Height: height,
+		bits:   make([]uint64, (width*height+63)/64),
+	}
+}
+
+// InBounds returns whether the cell is on the board.
+func (b *Bitboard) InBounds(x, y int) bool {
+	return x >= 0 && x < b.Width && y >= 0 && y < b.Height
+}
+
+// IsOccupied returns whether the cell is occupied. Cells outside of the board are always occupied.
+func (b *Bitboard) IsOccupied(x, y int) bool {
+	if !b.InBounds(x, y) {
+		return true
+	}
+	i := y*b.Width + x
+	return b.bits[i>>6]&(1<<(uint(i)&63)) != 0
+}
+
+// Set marks the cell as occupied. Cells outside of the board are ignored.
+func (b *Bitboard) Set(x, y int) {
+	if !b.InBounds(x, y) {
+		return
+	}
+	i := y*b.Width + x
+	b.bits[i>>6] |= 1 << (uint(i) & 63)
+}
+
+// Clear marks the cell as free. Cells outside of the board are ignored.
+func (b *Bitboard) Clear(x, y int) {
+	if !b.InBounds(x, y) {
+		return
+	}
+	i := y*b.Width + x
+	b.bits[i>>6] &^= 1 << (uint(i) & 63)
+}
+
+// Clone returns an independent copy of the bitboard.
+func (b *Bitboard) Clone() *Bitboard {
+	c := &Bitboard{Width: b.Width, Height: b.Height, Holes: b.Holes, bits: make([]uint64, len(b.bits))}
+	copy(c.bits, b.bits)
+	return c
+}
+
+// Equal returns whether both bitboards have the same size and the same cells set.
+func (b *Bitboard) Equal(o *Bitboard) bool {
+	if b.Width != o.Width || b.Height != o.Height || len(b.bits) != len(o.bits) {
+		return false
+	}
+	for i := range b.bits {
+		if b.bits[i] != o.bits[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// FloodFill returns the number of free cells reachable from (x, y). It works like the function FloodFill.
+func (b *Bitboard) FloodFill(x, y int) int {
+	if !b.InBounds(x, y) {
+		return 0
+	}
+
+	visited := b.Clone()
+	visited.Set(x, y)
+	queue := make([]int, 1, 64)
+	queue[0] = y*b.Width + x
+	count := 0
+
+	for len(queue) != 0 {
+		i := queue[0]
+		queue = queue[1:]
+		cx, cy := i%b.Width, i/b.Width
+		for _, n := range [4]coordinate{{cx + 1, cy}, {cx - 1, cy}, {cx, cy + 1}, {cx, cy - 1}} {
+			if visited.IsOccupied(n.X, n.Y) {
+				continue
+			}
+			visited.Set(n.X, n.Y)
+			count++
+			queue = append(queue, n.Y*b.Width+n.X)
+		}
+	}
+	return count
+}
+
+// Crashes returns whether the player crashes when performing the action on the bitboard, following the rules of ApplyAction.
+// Neither the bitboard nor the player are modified.
+func (b *Bitboard) Crashes(p *Player, action Action) bool {
+	return !b.move(p, action, false)
+}
+
+// Apply performs the action for the player on the bitboard, following the rules of ApplyAction.
+// Direction, speed, position and step counter of the player are updated (see Player.AdvanceTurn) and all traversed cells except holes are set.
+// It returns false if the player crashes, in which case the movement stops at the crash. Unlike ApplyAction, the player is not set inactive and its history is not updated.
+func (b *Bitboard) Apply(p *Player, action Action) bool {
+	return b.move(p, action, true)
+}
+
+// move checks the action for the player and performs it if apply is set (see Crashes and Apply). It returns false if the player crashes.
+func (b *Bitboard) move(p *Player, action Action, apply bool) bool {
+	direction, speed, err := steer(p.Direction, p.Speed, action)
+	if err != nil {
+		return false
+	}
+	dx, dy, ok := directionStep(direction)
+	if !ok {
+		return false
+	}
+
+	stepCounter := p.stepCounter + 1
+	x, y := p.X, p.Y
+	if apply {
+		p.Direction, p.Speed, p.stepCounter = direction, speed, stepCounter
+	}
+	for s := 0; s < speed; s++ {
+		x, y = x+dx, y+dy
+		if apply {
+			p.X, p.Y = x, y
+		}
+		if !b.InBounds(x, y) {
+			return false
+		}
+		if b.Holes.IsHole(speed, stepCounter, s) {
+			continue
+		}
+		if b.IsOccupied(x, y) {
+			return false
+		}
+		if apply {
+			b.Set(x, y)
+		}
+	}
+	return true
+}
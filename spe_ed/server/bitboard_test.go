@@ -0,0 +1,209 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// randomGame returns a game of the given size with a single player at the centre and about fill of the other cells filled randomly.
+func randomGame(r *rand.Rand, width, height int, fill float64) *Game {
+	g := &Game{
+		Width:   width,
+		Height:  height,
+		Cells:   make([][]int8, height),
+		Players: map[int]*Player{1: {X: width / 2, Y: height / 2, Direction: DirectionRight, Speed: 1, Active: true}},
+		You:     1,
+		Running: true,
+	}
+	for y := range g.Cells {
+		g.Cells[y] = make([]int8, width)
+		for x := range g.Cells[y] {
+			if r.Float64() < fill {
+				g.Cells[y][x] = PlayerCell(1 + r.Intn(PlayersPerGame))
+			}
+		}
+	}
+	g.Cells[height/2][width/2] = PlayerCell(1)
+	return g
+}
+
+// floodFillCells is a flood fill over g.Cells without a bitboard, used as reference for Bitboard.FloodFill.
+func floodFillCells(g *Game, x, y int) int {
+	visited := make([][]bool, g.Height)
+	for i := range visited {
+		visited[i] = make([]bool, g.Width)
+	}
+	visited[y][x] = true
+	queue := []coordinate{{x, y}}
+	count := 0
+	for len(queue) != 0 {
+		c := queue[0]
+		queue = queue[1:]
+		for _, n := range [4]coordinate{{c.X + 1, c.Y}, {c.X - 1, c.Y}, {c.X, c.Y + 1}, {c.X, c.Y - 1}} {
+			if n.X < 0 || n.X >= g.Width || n.Y < 0 || n.Y >= g.Height || visited[n.Y][n.X] || !IsEmpty(g.Cells[n.Y][n.X]) {
+				continue
+			}
+			visited[n.Y][n.X] = true
+			count++
+			queue = append(queue, n)
+		}
+	}
+	return count
+}
+
+func TestBitboard(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, width := range []int{1, 7, 63, 64, 65, 127, 128, 130} {
+		for _, height := range []int{1, 3, 10} {
+			g := randomGame(r, width, height, 0.3)
+			b := NewBitboard(g)
+			for y := -1; y <= height; y++ {
+				for x := -1; x <= width; x++ {
+					inside := x >= 0 && x < width && y >= 0 && y < height
+					if b.InBounds(x, y) != inside {
+						t.Fatalf("%dx%d: InBounds(%d, %d) is %t", width, height, x, y, !inside)
+					}
+					if !inside {
+						if !b.IsOccupied(x, y) {
+							t.Fatalf("%dx%d: (%d, %d) outside of the board is free", width, height, x, y)
+						}
+						continue
+					}
+					if b.IsOccupied(x, y) != !IsEmpty(g.Cells[y][x]) {
+						t.Fatalf("%dx%d: (%d, %d) differs from the game", width, height, x, y)
+					}
+				}
+			}
+
+			// Toggle every cell, including the cells next to word boundaries
+			for y := 0; y < height; y++ {
+				for x := 0; x < width; x++ {
+					c := b.Clone()
+					occupied := c.IsOccupied(x, y)
+					if occupied {
+						c.Clear(x, y)
+					} else {
+						c.Set(x, y)
+					}
+					if c.IsOccupied(x, y) == occupied || c.Equal(b) {
+						t.Fatalf("%dx%d: (%d, %d) not toggled", width, height, x, y)
+					}
+					if occupied {
+						c.Set(x, y)
+					} else {
+						c.Clear(x, y)
+					}
+					if !c.Equal(b) {
+						t.Fatalf("%dx%d: toggling (%d, %d) changed other cells", width, height, x, y)
+					}
+				}
+			}
+			c := b.Clone()
+			c.Set(-1, 0)
+			c.Set(width, height-1)
+			c.Clear(0, height)
+			if !c.Equal(b) {
+				t.Fatalf("%dx%d: cells outside of the board changed the bitboard", width, height)
+			}
+		}
+	}
+}
+
+func TestBitboardFloodFill(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	for i := 0; i < 200; i++ {
+		g := randomGame(r, 1+r.Intn(90), 1+r.Intn(30), r.Float64()*0.6)
+		b := NewBitboard(g)
+		for j := 0; j < 5; j++ {
+			x, y := r.Intn(g.Width), r.Intn(g.Height)
+			if got, want := b.FloodFill(x, y), floodFillCells(g, x, y); got != want {
+				t.Fatalf("%dx%d: flood fill from (%d, %d) is %d, want %d", g.Width, g.Height, x, y, got, want)
+			}
+		}
+	}
+	if b := NewEmptyBitboard(3, 3); b.FloodFill(3, 0) != 0 || b.FloodFill(1, 1) != 8 {
+		t.Error("wrong flood fill on empty bitboard")
+	}
+}
+
+func TestBitboardCrashes(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	for i := 0; i < 2000; i++ {
+		g := randomGame(r, 1+r.Intn(70), 1+r.Intn(20), r.Float64()*0.4)
+		p := g.Players[1]
+		p.Direction = Directions[r.Intn(len(Directions))]
+		p.Speed = 1 + r.Intn(MaxSpeed)
+		p.stepCounter = r.Intn(2 * HolesEachStep)
+		b := NewBitboard(g)
+		for _, a := range []Action{ActionNOOP, ActionTurnLeft, ActionTurnRight, ActionSlower, ActionFaster} {
+			_, crashed := Simulate(g, 1, a)
+			if b.Crashes(p, a) != crashed {
+				t.Fatalf("%s at speed %d: Crashes is %t, ApplyAction crashed %t\n%s", a, p.Speed, !crashed, crashed, g)
+			}
+		}
+		if !b.Equal(NewBitboard(g)) {
+			t.Fatal("Crashes modified the bitboard")
+		}
+	}
+}
+
+func TestBitboardApply(t *testing.T) {
+	r := rand.New(rand.NewSource(5))
+	for i := 0; i < 2000; i++ {
+		g := randomGame(r, 1+r.Intn(70), 1+r.Intn(20), r.Float64()*0.4)
+		p := g.Players[1]
+		p.Direction = Directions[r.Intn(len(Directions))]
+		p.Speed = 1 + r.Intn(MaxSpeed)
+		p.stepCounter = r.Intn(2 * HolesEachStep)
+		a := Actions[r.Intn(len(Actions))]
+		sim, crashed := Simulate(g, 1, a)
+		sp := sim.Players[1]
+		bp := mobilityPlayer(p)
+		b := NewBitboard(g)
+		if b.Apply(bp, a) == crashed {
+			t.Fatalf("%s at speed %d: Apply returned %t, ApplyAction crashed %t\n%s", a, p.Speed, crashed, crashed, g)
+		}
+		if crashed {
+			ReleaseClone(sim)
+			continue
+		}
+		if bp.X != sp.X || bp.Y != sp.Y || bp.Speed != sp.Speed || bp.Direction != sp.Direction || bp.stepCounter != sp.stepCounter {
+			t.Fatalf("%s at speed %d: Apply moved to (%d,%d) %s speed %d step %d, ApplyAction to (%d,%d) %s speed %d step %d\n%s", a, p.Speed, bp.X, bp.Y, bp.Direction, bp.Speed, bp.stepCounter, sp.X, sp.Y, sp.Direction, sp.Speed, sp.stepCounter, g)
+		}
+		if !b.Equal(NewBitboard(sim)) {
+			t.Fatalf("%s at speed %d: filled cells differ from ApplyAction\n%s", a, p.Speed, g)
+		}
+		ReleaseClone(sim)
+	}
+}
+
+func BenchmarkFloodFillCells(b *testing.B) {
+	g := randomGame(rand.New(rand.NewSource(4)), 80, 80, 0.2)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		floodFillCells(g, g.Width/2, g.Height/2)
+	}
+}
+
+func BenchmarkFloodFillBitboard(b *testing.B) {
+	g := randomGame(rand.New(rand.NewSource(4)), 80, 80, 0.2)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		FloodFill(g, g.Width/2, g.Height/2)
+	}
+}
@@ -0,0 +1,66 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultMaxBoardCells contains the default maximum number of cells (width times height) of a board on which the search AIs search (see SetMaxBoardCells).
+// It is far above the largest official board of 80x80 cells.
+const DefaultMaxBoardCells = 40000
+
+var (
+	maxBoardCells     int64 = DefaultMaxBoardCells
+	loggedLargeBoards sync.Map
+)
+
+// SetMaxBoardCells sets the maximum number of cells of a board on which MinimaxAI, IterativeDeepeningAI and MCTSAI search.
+// On larger boards, they play like FloodFillAI instead, whose effort only grows linearly with the size of the board, so a huge board sent by a server does not lead to timeouts.
+// Zero disables the limit, negative values are an error.
+func SetMaxBoardCells(n int) error {
+	if n < 0 {
+		return fmt.Errorf("negative maximum board size %d", n)
+	}
+	atomic.StoreInt64(&maxBoardCells, int64(n))
+	return nil
+}
+
+// GetMaxBoardCells returns the maximum number of cells of a board on which the search AIs search (see SetMaxBoardCells).
+func GetMaxBoardCells() int {
+	return int(atomic.LoadInt64(&maxBoardCells))
+}
+
+// BoardTooLarge returns whether the board has more cells than allowed by SetMaxBoardCells.
+func BoardTooLarge(g *Game) bool {
+	limit := atomic.LoadInt64(&maxBoardCells)
+	return limit != 0 && int64(g.Width)*int64(g.Height) > limit
+}
+
+// degradeOnLargeBoard returns whether the AI should use a cheap heuristic instead of searching (see BoardTooLarge).
+// Every combination of AI and board size is logged the first time it is seen.
+func degradeOnLargeBoard(g *Game, ai string) bool {
+	if !BoardTooLarge(g) {
+		return false
+	}
+	key := fmt.Sprintf("%s %dx%d", ai, g.Width, g.Height)
+	if _, seen := loggedLargeBoards.LoadOrStore(key, true); !seen {
+		log.Printf("%s: board %dx%d has more than %d cells, playing like FloodFillAI", ai, g.Width, g.Height, GetMaxBoardCells())
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBoardTooLarge(t *testing.T) {
+	t.Cleanup(func() { SetMaxBoardCells(DefaultMaxBoardCells) })
+	if SetMaxBoardCells(-1) == nil {
+		t.Error("negative limit accepted")
+	}
+	for _, tc := range []struct {
+		limit, width, height int
+		large                bool
+	}{
+		{DefaultMaxBoardCells, 80, 80, false},
+		{DefaultMaxBoardCells, 300, 300, true},
+		{100, 10, 10, false},
+		{100, 11, 10, true},
+		{0, 300, 300, false},
+	} {
+		err := SetMaxBoardCells(tc.limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := BoardTooLarge(&Game{Width: tc.width, Height: tc.height}); got != tc.large {
+			t.Errorf("limit %d, board %dx%d: too large %t, want %t", tc.limit, tc.width, tc.height, got, tc.large)
+		}
+	}
+}
+
+func TestLargeBoardFallback(t *testing.T) {
+	var logged strings.Builder
+	oldLogger := log.current()
+	SetLogger(NewTextLogger(&logged, "", LogLevelInfo))
+	t.Cleanup(func() {
+		SetLogger(oldLogger)
+		SetMaxBoardCells(DefaultMaxBoardCells)
+	})
+	// A small limit keeps the test fast, the fallback does not depend on the size
+	err := SetMaxBoardCells(200)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewSimulator(21, 19, 10, "SurvivalAI", "SurvivalAI")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := s.Game.ViewFor(1)
+	want := decide(t, new(FloodFillAI), g)
+	for _, ai := range []AI{new(MinimaxAI), new(IterativeDeepeningAI), new(MCTSAI)} {
+		for round := 0; round < 2; round++ {
+			if a := decide(t, ai, g); a != want {
+				t.Errorf("%s: got %s, FloodFillAI plays %s", ai.Name(), a, want)
+			}
+		}
+		if n := strings.Count(logged.String(), ai.Name()+": board 21x19"); n != 1 {
+			t.Errorf("%s: degradation logged %d times, want once\n%s", ai.Name(), n, logged.String())
+		}
+	}
+
+	// decide leaves MinimaxAI no time to search (see MinimaxAIMargin), without a deadline it has MinimaxAIBudget
+	g.Deadline = ""
+	m := &MinimaxAI{Depth: 1}
+	m.GetChannel(make(chan Action, 2))
+	m.GetState(g)
+	if m.Nodes() != 0 {
+		t.Errorf("MinimaxAI searched %d positions on a large board", m.Nodes())
+	}
+	err = SetMaxBoardCells(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.GetState(g)
+	if m.Nodes() == 0 {
+		t.Error("MinimaxAI did not search without a limit")
+	}
+}
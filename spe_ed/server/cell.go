@@ -0,0 +1,51 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "math"
+
+const (
+	// CellEmpty contains the value of a free cell in Game.Cells.
+	CellEmpty int8 = 0
+	// CellCrash contains the value of a cell in which a player crashed (the same value as on the official server).
+	CellCrash int8 = -1
+	// CellDanger contains the value AIs use on their own copy of the game for free cells an opponent might reach in the next round.
+	// Like CellCrash, it blocks a player and belongs to no player. It never appears in states of the server.
+	CellDanger int8 = -100
+	// MaxPlayerID contains the largest player number which can be stored in Game.Cells.
+	MaxPlayerID = math.MaxInt8
+)
+
+// IsEmpty returns whether the value of a cell marks a free cell.
+// All other values (player numbers and CellCrash) block a player.
+func IsEmpty(v int8) bool {
+	return v == CellEmpty
+}
+
+// CellOwner returns the number of the player who filled the cell with the given value.
+// It returns 0 for free cells and cells of a crash, which belong to no player.
+func CellOwner(v int8) int {
+	if v <= 0 {
+		return 0
+	}
+	return int(v)
+}
+
+// PlayerCell returns the value of a cell filled by the given player. It is the inverse of CellOwner.
+// The number must be within 1..MaxPlayerID (see Game.Validate), larger numbers do not fit into a cell.
+func PlayerCell(id int) int8 {
+	return int8(id)
+}
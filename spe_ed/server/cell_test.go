@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "testing"
+
+func TestPlayerCell(t *testing.T) {
+	for _, id := range []int{1, 2, 3, 4, 5, 6, MaxPlayerID} {
+		v := PlayerCell(id)
+		if IsEmpty(v) || v == CellCrash {
+			t.Errorf("player %d: cell %d is empty or a crash", id, v)
+		}
+		if owner := CellOwner(v); owner != id {
+			t.Errorf("player %d: cell %d belongs to %d", id, v, owner)
+		}
+	}
+	if CellOwner(CellEmpty) != 0 || CellOwner(CellCrash) != 0 || CellOwner(CellDanger) != 0 {
+		t.Error("free, crashed or dangerous cell has an owner")
+	}
+	if IsEmpty(CellDanger) {
+		t.Error("dangerous cell is free")
+	}
+}
+
+func TestSixPlayers(t *testing.T) {
+	ais := make([]AI, PlayersPerGame)
+	for i := range ais {
+		ais[i] = &scriptedAI{}
+	}
+	s, err := NewSimulatorWithPlacement(41, 41, 1, PlacementCorners, ais...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for round := 0; round < 3; round++ {
+		s.Step()
+	}
+	for id := 1; id <= PlayersPerGame; id++ {
+		p := s.Game.Players[id]
+		if !p.Active || CellOwner(s.Game.Cells[p.Y][p.X]) != id {
+			t.Errorf("player %d at (%d, %d) active %t in cell %d\n%s", id, p.X, p.Y, p.Active, s.Game.Cells[p.Y][p.X], s.Game)
+		}
+	}
+	view := s.Game.ViewFor(6)
+	if err := view.Validate(); err != nil {
+		t.Error(err)
+	}
+	b, err := view.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var g Game
+	if err := g.UnmarshalJSON(b); err != nil {
+		t.Fatal(err)
+	}
+	if g.You != 6 || len(g.Players) != PlayersPerGame || g.Cells[view.Players[6].Y][view.Players[6].X] != PlayerCell(6) {
+		t.Errorf("six players not kept by the wire format\n%s", &g)
+	}
+}
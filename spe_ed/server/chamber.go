@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+// ChamberRadius contains the default radius used with ChamberSize.
+const ChamberRadius = 3
+
+// ChamberSize returns the number of free cells connected to (x, y) without leaving the square of the given radius around it (the cells with a distance of at most radius in both axes).
+// Unlike FloodFill, it only looks at the neighbourhood, so it distinguishes an open area (up to (2*radius+1)²-1 cells) from a thin corridor (about 2*radius cells) even if both lead into the same large region.
+// Near the border, the square is clipped to the board. The start cell does not need to be free and is not counted.
+func ChamberSize(g *Game, x, y, radius int) int {
+	if x < 0 || x >= g.Width || y < 0 || y >= g.Height || radius < 1 {
+		return 0
+	}
+
+	minX, maxX, minY, maxY := x-radius, x+radius, y-radius, y+radius
+	if minX < 0 {
+		minX = 0
+	}
+	if maxX >= g.Width {
+		maxX = g.Width - 1
+	}
+	if minY < 0 {
+		minY = 0
+	}
+	if maxY >= g.Height {
+		maxY = g.Height - 1
+	}
+
+	size := 2*radius + 1
+	visited := make([]bool, size*size)
+	index := func(c coordinate) int { return (c.Y-y+radius)*size + c.X - x + radius }
+	visited[index(coordinate{x, y})] = true
+	queue := []coordinate{{x, y}}
+	count := 0
+	for len(queue) != 0 {
+		c := queue[0]
+		queue = queue[1:]
+		for _, n := range [4]coordinate{{c.X + 1, c.Y}, {c.X - 1, c.Y}, {c.X, c.Y + 1}, {c.X, c.Y - 1}} {
+			if n.X < minX || n.X > maxX || n.Y < minY || n.Y > maxY || visited[index(n)] || !IsEmpty(g.Cells[n.Y][n.X]) {
+				continue
+			}
+			visited[index(n)] = true
+			count++
+			queue = append(queue, n)
+		}
+	}
+	return count
+}
@@ -0,0 +1,452 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// ClientSafetyMargin contains the time before the deadline at which the client sends the fallback action if the AI has not answered yet.
+	ClientSafetyMargin = 200 * time.Millisecond
+	// ClientReconnectBackoff contains the waiting time before the first reconnect of the client. It is doubled for each further attempt.
+	ClientReconnectBackoff = 1 * time.Second
+	// ClientReconnectMaxBackoff contains the maximum waiting time between two reconnects of the client.
+	ClientReconnectMaxBackoff = 30 * time.Second
+)
+
+// ClientConfig contains the configuration of the client.
+type ClientConfig struct {
+	// URL is the websocket URL of the server.
+	URL string
+	// Key is the API key. It is not sent if empty.
+	Key string
+	// Name is the name of our player, sent to the server on connect (see ValidatePlayerName). This server reveals it instead of the pseudonym at the end of the game if started with -chosennames, other servers might ignore it. It is not sent if empty.
+	Name string
+	// AI is the name of the AI playing the game.
+	AI string
+	// TimeURL is the URL of the time endpoint of the server. If it is empty, it is derived from URL (e.g. wss://example.com/spe_ed becomes https://example.com/spe_ed_time).
+	TimeURL string
+	// Reconnect is the maximum number of reconnects.
+	Reconnect int
+	// Replay is the path of a replay file (see ReplayRecorder). No replay is recorded if it is empty.
+	Replay string
+	// Fallback is the action sent if the AI does not answer in time, panics, answers with an invalid action (see FallbackAction) or the received state is invalid (see StaticFallbackAction). ActionNOOP is used if it is empty.
+	Fallback string
+	// Budget is the time the AI should need at most for a decision. If it is not zero, the AI is wrapped by TimedAI and the statistics are logged after the game.
+	Budget time.Duration
+	// SafetyMargin is the time before the deadline at which the fallback action is sent. ClientSafetyMargin is used if it is zero.
+	SafetyMargin time.Duration
+	// MeasureLatency enables measuring the round trip time to the server with a websocket ping after every answer.
+	// The estimated latency (see LatencyEstimator) is subtracted from the deadline in addition to SafetyMargin (see EffectiveDeadline).
+	MeasureLatency bool
+	// FillTimes enables tracking in which round each cell was filled. The fill times are given to the AI in Game.FillTimes (see FillTimeTracker).
+	FillTimes bool
+	// Watchdog is the number of tick intervals the client waits for the next state before the server is considered stalled (see StateWatchdog).
+	// A stalled server is treated like a lost connection, so the client reconnects as configured by Reconnect. The watchdog is disabled if it is zero.
+	Watchdog int
+}
+
+// RunClient connects to a spe_ed server and plays a single game with the configured AI.
+// The AI gets every state through GetState. Its answer is sent to the server, but the client guarantees an answer before the deadline: If the AI does not answer SafetyMargin before the deadline, the fallback action is sent instead.
+// If the connection can not be established or is lost before the game has ended, the client reconnects up to Reconnect times in total with exponential backoff.
+// The same AI is used after a reconnect. Since the step counter is counted per connection, holes might be predicted wrong by the AI for the rest of a resumed game. For the same reason, the OpponentModel given to an OpponentModelAI and the fill times are reset for every connection.
+// Before each connection, the clock is synchronised with the time endpoint (see SyncServerTime).
+// If Replay is set, all received states are recorded together with the actions and the time the AI needed to answer.
+// An AI implementing GameEndAI is notified once the game has ended or the connection is lost finally.
+// If Watchdog is set, a server not sending the next state in time (see StateWatchdog) is treated as lost connection with ErrServerStalled.
+// While connected, the client is ready (see InitHealth).
+// RunClient returns nil after the game has ended (including a game ended by the server because of the reconnect) and an error if the connection was lost before.
+func RunClient(config ClientConfig) error {
+	ai, err := CreateAI(config.AI)
+	if err != nil {
+		return err
+	}
+	if config.SafetyMargin < 0 {
+		return fmt.Errorf("negative safety margin %s", config.SafetyMargin)
+	}
+	margin := config.SafetyMargin
+	if margin == 0 {
+		margin = ClientSafetyMargin
+	}
+	if config.Watchdog < 0 {
+		return fmt.Errorf("negative watchdog factor %d", config.Watchdog)
+	}
+	if config.Fallback != "" {
+		err = ValidateFallback(config.Fallback)
+		if err != nil {
+			return err
+		}
+	}
+	if config.Name != "" {
+		err = ValidatePlayerName(config.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	u, err := url.Parse(config.URL)
+	if err != nil {
+		return fmt.Errorf("can not parse url: %w", err)
+	}
+	if config.Key != "" || config.Name != "" {
+		q := u.Query()
+		if config.Key != "" {
+			q.Set("key", config.Key)
+		}
+		if config.Name != "" {
+			q.Set("name", config.Name)
+		}
+		u.RawQuery = q.Encode()
+	}
+
+	timeURL := config.TimeURL
+	if timeURL == "" {
+		t := *u
+		t.RawQuery = ""
+		switch t.Scheme {
+		case "ws":
+			t.Scheme = "http"
+		case "wss":
+			t.Scheme = "https"
+		}
+		if strings.HasSuffix(t.Path, "spe_ed") {
+			t.Path += "_time"
+		} else {
+			t.Path = "/spe_ed_time"
+		}
+		timeURL = t.String()
+	}
+
+	var recorder *ReplayRecorder
+	if config.Replay != "" {
+		recorder, err = NewReplayRecorder(config.Replay)
+		if err != nil {
+			return fmt.Errorf("can not create replay: %w", err)
+		}
+		defer func() {
+			err := recorder.Close()
+			if err != nil {
+				log.Println("client: can not close replay:", err)
+			}
+		}()
+	}
+
+	if config.Budget > 0 {
+		ai = TimedAI(ai, config.Budget)
+		defer func() {
+			s := ai.(*TimedAIWrapper).Stats()
+			log.Printf("client: %d decisions, %d over budget %s, mean %s, max %s", s.Calls, s.Exceeded, config.Budget, s.Mean().Round(time.Microsecond), s.Max.Round(time.Microsecond))
+		}()
+	}
+
+	var latency *LatencyEstimator
+	if config.MeasureLatency {
+		latency = new(LatencyEstimator)
+		defer func() {
+			log.Printf("client: estimated latency %s from %d round trips", latency.Latency().Round(time.Microsecond), latency.Samples())
+		}()
+	}
+
+	answer := make(chan Action, 1)
+	ai.GetChannel(answer)
+	// Shared by all connections, so a decision exceeding the connection loss does not answer in the new connection
+	decider := newAnswerSequencer(ai, answer)
+
+	opponents := NewOpponentModel()
+	if mai, ok := ai.(OpponentModelAI); ok {
+		mai.SetOpponentModel(opponents)
+	}
+
+	var fillTimes *FillTimeTracker
+	if config.FillTimes {
+		fillTimes = NewFillTimeTracker()
+	}
+
+	backoff := ClientReconnectBackoff
+	var lost time.Time
+	played := false
+	for attempt := 0; ; attempt++ {
+		if attempt != 0 {
+			if attempt > config.Reconnect {
+				if played {
+					// The game is lost for us
+					notifyGameEnd(ai, false)
+				}
+				return err
+			}
+			log.Printf("client: %s, reconnecting in %s (attempt %d/%d, %s since connection loss)", err, backoff, attempt, config.Reconnect, time.Since(lost).Round(time.Millisecond))
+			time.Sleep(backoff)
+			backoff *= 2
+			if backoff > ClientReconnectMaxBackoff {
+				backoff = ClientReconnectMaxBackoff
+			}
+		}
+
+		syncErr := SyncServerTime(timeURL)
+		if syncErr != nil {
+			log.Println("client: can not synchronise time:", syncErr)
+		}
+
+		ws, _, dialErr := websocket.DefaultDialer.Dial(u.String(), nil)
+		if dialErr != nil {
+			err = fmt.Errorf("can not connect to server: %w", dialErr)
+			if lost.IsZero() {
+				lost = time.Now()
+			}
+			continue
+		}
+
+		played = true
+		opponents.Reset()
+		if fillTimes != nil {
+			fillTimes.Reset()
+		}
+		setReady(true)
+		// The tick interval might change with the connection
+		watchdog := &StateWatchdog{Factor: config.Watchdog}
+		connectionLost, playErr := clientPlay(ws, ai, decider, recorder, config.Fallback, margin, latency, opponents, fillTimes, watchdog)
+		setReady(false)
+		ws.Close()
+		if !connectionLost {
+			return playErr
+		}
+		err = playErr
+		lost = time.Now()
+		backoff = ClientReconnectBackoff
+	}
+}
+
+// clientPlay plays on an established connection until the game ends.
+// recorder might be nil. fallback is the action sent if the AI does not answer margin before the deadline (see FallbackAction). Every state is observed by opponents.
+// If latency is not nil, a ping is sent after every answer and the estimated latency is subtracted from the deadline as well (see EffectiveDeadline).
+// If fillTimes is not nil, it observes every state and its fill times are set in Game.FillTimes.
+// The arrival of every state is observed by watchdog, a state not arriving in time is returned as lost connection with ErrServerStalled.
+// The AI decides through decider, so an answer arriving after its round is discarded instead of being sent in the next round. If the AI panics (see SafeGetState), the fallback is sent without waiting for the deadline. Actions of the AI are checked as set by SetMoveAssertion.
+// States which can not be read or are inconsistent (see Game.Validate) are not given to the AI, instead the fallback is sent directly (see StaticFallbackAction).
+// It returns whether the connection was lost and an error if the game did not end normally.
+func clientPlay(ws *websocket.Conn, ai AI, decider *answerSequencer, recorder *ReplayRecorder, fallback string, margin time.Duration, latency *LatencyEstimator, opponents *OpponentModel, fillTimes *FillTimeTracker, watchdog *StateWatchdog) (bool, error) {
+	turn := 0
+	dead := false
+
+	// The pong handler is called by ReadMessage, so no synchronisation is needed
+	var pingSent time.Time
+	pingID := 0
+	if latency != nil {
+		ws.SetPongHandler(func(data string) error {
+			if !pingSent.IsZero() && data == strconv.Itoa(pingID) {
+				latency.Observe(time.Since(pingSent))
+				pingSent = time.Time{}
+			}
+			return nil
+		})
+	}
+
+	for {
+		_, b, err := ws.ReadMessage()
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				return true, fmt.Errorf("%w: no state within %s (last tick interval %s)", ErrServerStalled, watchdog.Timeout().Round(time.Millisecond), watchdog.Interval().Round(time.Millisecond))
+			}
+			return true, fmt.Errorf("connection lost: %w", err)
+		}
+		turn++
+		arrival := time.Now()
+
+		g := new(Game)
+		err = json.Unmarshal(b, g)
+		if err == nil {
+			err = g.Validate()
+		}
+		var remaining time.Duration
+		if err == nil {
+			remaining, _ = g.RemainingTime()
+		}
+		if next := watchdog.Observe(arrival, remaining); !next.IsZero() {
+			deadlineErr := ws.SetReadDeadline(next)
+			if deadlineErr != nil {
+				return true, fmt.Errorf("can not set read deadline: %w", deadlineErr)
+			}
+		}
+		if err != nil {
+			// Answer anyway, the state might only be broken in this round
+			action := StaticFallbackAction(fallback)
+			log.Printf("client: invalid game state: %s, sending %s", err.Error(), action)
+			metricActions.WithLabelValues(action.String()).Inc()
+			err = ws.WriteJSON(ActionMessage{Action: action})
+			if err != nil {
+				return true, fmt.Errorf("can not send action: %w", err)
+			}
+			continue
+		}
+
+		// The wire format does not contain the step counter, but it is needed for holes
+		for k := range g.Players {
+			g.Players[k].stepCounter = turn - 1
+		}
+		opponents.Observe(g)
+		if fillTimes != nil {
+			fillTimes.Observe(g, turn)
+			g.FillTimes = fillTimes.Grid()
+		}
+
+		if !g.Running {
+			if recorder != nil {
+				err = recorder.Record(turn, g, nil, nil)
+				if err != nil {
+					log.Println("client: can not record replay:", err)
+				}
+			}
+			metricGamesPlayed.Inc()
+			logPlayerNames(g)
+			if g.Players[g.You].Active {
+				metricGameResults.WithLabelValues(ai.Name(), "won").Inc()
+				log.Println("client: game ended - you won")
+				notifyGameEnd(ai, true)
+			} else if g.Result().Outcome == OutcomeDraw {
+				metricGameResults.WithLabelValues(ai.Name(), "lost").Inc()
+				log.Println("client: game ended - draw, all remaining players crashed in the same round")
+				notifyGameEnd(ai, false)
+			} else {
+				metricGameResults.WithLabelValues(ai.Name(), "lost").Inc()
+				log.Println("client: game ended - you lost")
+				notifyGameEnd(ai, false)
+			}
+			return false, nil
+		}
+		if !g.Players[g.You].Active {
+			// Wait for the end of the game
+			if !dead {
+				dead = true
+				log.Println("client: we are dead, waiting for the end of the game")
+			}
+			if recorder != nil {
+				err = recorder.Record(turn, g, nil, nil)
+				if err != nil {
+					log.Println("client: can not record replay:", err)
+				}
+			}
+			continue
+		}
+
+		var timeout <-chan time.Time
+		var timer *time.Timer
+		var deadline time.Time
+		if remaining, ok := g.RemainingTime(); ok {
+			var l time.Duration
+			if latency != nil {
+				l = latency.Latency()
+			}
+			// Cancel ContextAI before the answer is sent
+			deadline = EffectiveDeadline(time.Now().Add(remaining), margin, l)
+			timer = time.NewTimer(time.Until(deadline))
+			timeout = timer.C
+		}
+
+		// The AI is allowed to modify the game
+		state := g.PublicCopy()
+		start := time.Now()
+		cancel := make(chan struct{})
+		answer, panicked := decider.decide(g, deadline, cancel)
+
+		var action Action
+		select {
+		case a := <-answer:
+			if IsValidAction(a) {
+				action = a
+				err = assertMove(state, ai.Name(), action)
+				if err != nil {
+					return false, fmt.Errorf("move assertion: %w", err)
+				}
+			} else {
+				action = FallbackAction(state, fallback)
+				log.Printf("client: invalid action from ai: %s, sending %s", a, action)
+				LogDecision(state, "client", action, nil, fmt.Sprintf("invalid action %q from ai", a))
+			}
+		case err := <-panicked:
+			// Do not wait for the deadline, the AI will not answer
+			action = FallbackAction(state, fallback)
+			log.Printf("client: %s, sending %s", err.Error(), action)
+			LogDecision(state, "client", action, nil, err.Error())
+		case <-timeout:
+			action = FallbackAction(state, fallback)
+			log.Println("client: ai did not answer in time, sending", action)
+			metricTimeouts.WithLabelValues(ai.Name()).Inc()
+			LogDecision(state, "client", action, nil, "ai did not answer in time")
+		}
+		close(cancel)
+		duration := time.Since(start)
+		if timer != nil {
+			timer.Stop()
+		}
+
+		if recorder != nil {
+			err = recorder.Record(turn, state, map[int]Action{g.You: action}, map[int]time.Duration{g.You: duration})
+			if err != nil {
+				log.Println("client: can not record replay:", err)
+			}
+		}
+
+		metricActions.WithLabelValues(action.String()).Inc()
+		err = ws.WriteJSON(ActionMessage{Action: action})
+		if err != nil {
+			return true, fmt.Errorf("can not send action: %w", err)
+		}
+
+		if latency != nil && pingSent.IsZero() {
+			// Only one ping at a time, a server not answering pings is simply not measured
+			pingID++
+			pingSent = time.Now()
+			err = ws.WriteControl(websocket.PingMessage, []byte(strconv.Itoa(pingID)), pingSent.Add(time.Second))
+			if err != nil {
+				return true, fmt.Errorf("can not send ping: %w", err)
+			}
+		}
+	}
+}
+
+// logPlayerNames logs the names of all players of the game, if the server sent any.
+func logPlayerNames(g *Game) {
+	ids := make([]int, 0, len(g.Players))
+	named := false
+	for id := range g.Players {
+		ids = append(ids, id)
+		named = named || g.Players[id].Name != ""
+	}
+	if !named {
+		return
+	}
+	sort.Ints(ids)
+	names := make([]string, len(ids))
+	for i, id := range ids {
+		names[i] = fmt.Sprintf("%d %q", id, g.Players[id].Name)
+		if id == g.You {
+			names[i] += " (you)"
+		}
+	}
+	log.Println("client: players:", strings.Join(names, ", "))
+}
@@ -0,0 +1,244 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// fakeServer starts a websocket server running play for every connection and returns a connection to it.
+// The server is closed at the end of the test.
+func fakeServer(t *testing.T, play func(ws *websocket.Conn)) *websocket.Conn {
+	t.Helper()
+	upgrader := websocket.Upgrader{}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer ws.Close()
+		play(ws)
+	}))
+	t.Cleanup(s.Close)
+
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(s.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ws.Close() })
+	return ws
+}
+
+// serverState returns the recorded server state with the given deadline from now on. A deadline of zero ends the game.
+func serverState(t *testing.T, deadline time.Duration) []byte {
+	t.Helper()
+	var g Game
+	err := json.Unmarshal(serverMessage(t), &g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.Running = deadline > 0
+	g.Deadline = ServerNow().Add(deadline).UTC().Format(time.RFC3339Nano)
+	b, err := json.Marshal(&g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// playClient plays the game of the fake server with the AI and returns the result of clientPlay.
+func playClient(t *testing.T, ai AI, ws *websocket.Conn, watchdog *StateWatchdog) (bool, error) {
+	t.Helper()
+	answer := make(chan Action, 1)
+	ai.GetChannel(answer)
+	return clientPlay(ws, ai, newAnswerSequencer(ai, answer), nil, string(ActionNOOP), 50*time.Millisecond, nil, NewOpponentModel(), nil, watchdog)
+}
+
+func TestClientLateAnswer(t *testing.T) {
+	answers := make(chan Action, 2)
+	ws := fakeServer(t, func(ws *websocket.Conn) {
+		for _, deadline := range []time.Duration{150 * time.Millisecond, time.Second} {
+			err := ws.WriteMessage(websocket.TextMessage, serverState(t, deadline))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			var a ActionMessage
+			err = ws.ReadJSON(&a)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			answers <- a.Action
+		}
+		ws.WriteMessage(websocket.TextMessage, serverState(t, 0))
+	})
+
+	ai := &scriptedAI{
+		actions: map[int]Action{1: ActionFaster, 2: ActionTurnRight},
+		delays:  map[int]time.Duration{1: 300 * time.Millisecond},
+	}
+	lost, err := playClient(t, ai, ws, new(StateWatchdog))
+	if lost || err != nil {
+		t.Fatalf("connection lost %t, error %v", lost, err)
+	}
+	if a := <-answers; a != ActionNOOP {
+		t.Errorf("first round sent %s instead of the fallback", a)
+	}
+	// The decision of the first round exceeds its deadline, its answer must not be sent in the second round
+	if a := <-answers; a != ActionTurnRight {
+		t.Errorf("second round sent %s instead of the answer of the second round", a)
+	}
+	if ai.round != 2 {
+		t.Errorf("ai decided %d times", ai.round)
+	}
+}
+
+func TestClientDead(t *testing.T) {
+	var dead Game
+	err := json.Unmarshal(serverState(t, time.Second), &dead)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dead.Players[dead.You].Active = false
+	state, err := json.Marshal(&dead)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sent := make(chan error, 1)
+	ws := fakeServer(t, func(ws *websocket.Conn) {
+		err := ws.WriteMessage(websocket.TextMessage, state)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		ws.SetReadDeadline(time.Now().Add(300 * time.Millisecond))
+		_, _, err = ws.ReadMessage()
+		sent <- err
+		ws.WriteMessage(websocket.TextMessage, serverState(t, 0))
+	})
+
+	lost, err := playClient(t, &scriptedAI{}, ws, new(StateWatchdog))
+	if lost || err != nil {
+		t.Fatalf("connection lost %t, error %v", lost, err)
+	}
+	if err := <-sent; err == nil {
+		t.Error("client answered for a dead player")
+	} else if ne, ok := err.(net.Error); !ok || !ne.Timeout() {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestClientInvalidState(t *testing.T) {
+	answers := make(chan Action, 1)
+	ws := fakeServer(t, func(ws *websocket.Conn) {
+		state := strings.Replace(string(serverState(t, time.Second)), `"width":10`, `"width":11`, 1)
+		err := ws.WriteMessage(websocket.TextMessage, []byte(state))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		var a ActionMessage
+		err = ws.ReadJSON(&a)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		answers <- a.Action
+		ws.WriteMessage(websocket.TextMessage, serverState(t, 0))
+	})
+
+	ai := &scriptedAI{actions: map[int]Action{1: ActionTurnLeft}}
+	lost, err := playClient(t, ai, ws, new(StateWatchdog))
+	if lost || err != nil {
+		t.Fatalf("connection lost %t, error %v", lost, err)
+	}
+	if a := <-answers; a != ActionNOOP {
+		t.Errorf("invalid state answered with %s instead of the fallback", a)
+	}
+	if ai.round != 0 {
+		t.Errorf("ai decided %d times on an invalid state", ai.round)
+	}
+}
+
+func TestClientGameEnd(t *testing.T) {
+	ws := fakeServer(t, func(ws *websocket.Conn) {
+		err := ws.WriteMessage(websocket.TextMessage, serverState(t, time.Second))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		var a ActionMessage
+		err = ws.ReadJSON(&a)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		ws.WriteMessage(websocket.TextMessage, serverState(t, 0))
+	})
+
+	ai := &endingAI{}
+	lost, err := playClient(t, ai, ws, new(StateWatchdog))
+	if lost || err != nil {
+		t.Fatalf("connection lost %t, error %v", lost, err)
+	}
+	// The recorded player 1 is still active in the final state
+	if len(ai.results) != 1 || !ai.results[0] {
+		t.Errorf("ai notified with %v", ai.results)
+	}
+}
+
+func TestClientPanic(t *testing.T) {
+	answers := make(chan Action, 1)
+	ws := fakeServer(t, func(ws *websocket.Conn) {
+		err := ws.WriteMessage(websocket.TextMessage, serverState(t, 2*time.Second))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		var a ActionMessage
+		err = ws.ReadJSON(&a)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		answers <- a.Action
+		ws.WriteMessage(websocket.TextMessage, serverState(t, 0))
+	})
+
+	start := time.Now()
+	lost, err := playClient(t, panicAI{}, ws, new(StateWatchdog))
+	if lost || err != nil {
+		t.Fatalf("connection lost %t, error %v", lost, err)
+	}
+	if a := <-answers; a != ActionNOOP {
+		t.Errorf("sent %s instead of the fallback", a)
+	}
+	// The fallback is sent right away instead of at the deadline
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("fallback sent after %s", d)
+	}
+}
@@ -0,0 +1,201 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"runtime"
+	"sync"
+)
+
+// CompareConfidenceZ contains the z-score of the confidence interval reported by RunCompare (95%).
+const CompareConfidenceZ = 1.96
+
+// CompareConfig contains the configuration of a head-to-head comparison of two AIs (see RunCompare).
+type CompareConfig struct {
+	// AIs contains the names of both AIs.
+	AIs [2]string
+	// Games is the number of games played.
+	Games int
+	// MinWidth, MaxWidth, MinHeight and MaxHeight limit the width and height of the boards. Both are chosen independently for every pair of games.
+	MinWidth, MaxWidth, MinHeight, MaxHeight int
+	// Seed is used to derive the seeds and board sizes of all games.
+	Seed int64
+	// Placement is the placement of the players at the start of every game (see NewSimulatorWithPlacement). PlacementRandom is used if it is empty.
+	Placement string
+	// Workers is the number of games run in parallel. runtime.GOMAXPROCS(0) is used if it is zero.
+	Workers int
+	// Holes contains the hole rules of all games. The zero value contains the official rules.
+	Holes HoleRules
+	// SelfPlay exports the decisions of the first AI in all games as training samples if it is not nil (see SelfPlayExporter).
+	SelfPlay *SelfPlayExporter
+}
+
+// CompareResult contains the outcome of RunCompare.
+type CompareResult struct {
+	// Games is the number of games played.
+	Games int
+	// Wins contains the number of games won by each AI.
+	Wins [2]int
+	// Draws is the number of games in which both AIs crashed in the same round.
+	Draws int
+}
+
+// WinRate returns the share of games won by the AI with the given index (0 or 1) together with the lower and upper bound of its confidence interval (Wilson score interval, see CompareConfidenceZ).
+func (r CompareResult) WinRate(ai int) (float64, float64, float64) {
+	if r.Games == 0 {
+		return 0, 0, 0
+	}
+	n := float64(r.Games)
+	p := float64(r.Wins[ai]) / n
+	z := CompareConfidenceZ
+	center := (p + z*z/(2*n)) / (1 + z*z/n)
+	margin := z / (1 + z*z/n) * math.Sqrt(p*(1-p)/n+z*z/(4*n*n))
+	return p, math.Max(0, center-margin), math.Min(1, center+margin)
+}
+
+// RunCompare plays config.Games games between both AIs in the simulator and writes the aggregated result to w.
+// The games are played in pairs with the same seed and board size, where the AIs swap their player numbers in the second game. Since the start positions only depend on the seed, each AI gets every start position once, which removes positional luck (for an even number of games).
+// Games are run in parallel.
+func RunCompare(config CompareConfig, w io.Writer) (CompareResult, error) {
+	if config.Games < 1 {
+		return CompareResult{}, errors.New("compare: at least one game is needed")
+	}
+	if config.MinWidth < 2 || config.MaxWidth < config.MinWidth || config.MinHeight < 2 || config.MaxHeight < config.MinHeight {
+		return CompareResult{}, fmt.Errorf("compare: invalid board size range %dx%d-%dx%d", config.MinWidth, config.MinHeight, config.MaxWidth, config.MaxHeight)
+	}
+	for i := range config.AIs {
+		if _, err := CreateAI(config.AIs[i]); err != nil {
+			return CompareResult{}, fmt.Errorf("compare: %w", err)
+		}
+	}
+	placement := config.Placement
+	if placement == "" {
+		placement = PlacementRandom
+	}
+	if err := ValidatePlacement(placement); err != nil {
+		return CompareResult{}, fmt.Errorf("compare: %w", err)
+	}
+	if err := config.Holes.Validate(); err != nil {
+		return CompareResult{}, fmt.Errorf("compare: %w", err)
+	}
+	workers := config.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	type game struct {
+		seed          int64
+		width, height int
+		swapped       bool
+	}
+	r := rand.New(rand.NewSource(config.Seed))
+	games := make([]game, config.Games)
+	for i := range games {
+		if i%2 == 1 {
+			games[i] = games[i-1]
+			games[i].swapped = true
+			continue
+		}
+		games[i] = game{
+			seed:   r.Int63(),
+			width:  config.MinWidth + r.Intn(config.MaxWidth-config.MinWidth+1),
+			height: config.MinHeight + r.Intn(config.MaxHeight-config.MinHeight+1),
+		}
+	}
+
+	result := CompareResult{Games: config.Games}
+	var resultLock sync.Mutex
+	var firstErr error
+
+	jobs := make(chan game)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for g := range jobs {
+				ais := make([]AI, 2)
+				var err error
+				for i := range ais {
+					ais[i], err = CreateAI(config.AIs[i])
+					if err != nil {
+						break
+					}
+				}
+				if g.swapped {
+					ais[0], ais[1] = ais[1], ais[0]
+				}
+				var s *Simulator
+				if err == nil {
+					s, err = NewSimulatorWithPlacement(g.width, g.height, g.seed, placement, ais...)
+				}
+				if err != nil {
+					resultLock.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					resultLock.Unlock()
+					continue
+				}
+				s.Game.Holes = config.Holes
+				if config.SelfPlay != nil {
+					first := 1
+					if g.swapped {
+						first = 2
+					}
+					s.SelfPlay = config.SelfPlay.NewGame(first)
+				}
+				s.Run()
+				r := s.Result()
+
+				resultLock.Lock()
+				switch {
+				case r.Outcome == OutcomeDraw:
+					result.Draws++
+				case r.Outcome != OutcomeWin:
+					// Can not happen in a finished game between two AIs
+				case (r.Winner == 1) != g.swapped:
+					result.Wins[0]++
+				default:
+					result.Wins[1]++
+				}
+				resultLock.Unlock()
+			}
+		}()
+	}
+	for i := range games {
+		jobs <- games[i]
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return CompareResult{}, fmt.Errorf("compare: %w", firstErr)
+	}
+
+	fmt.Fprintf(w, "%s vs %s: %d games\n", config.AIs[0], config.AIs[1], result.Games)
+	for i := range config.AIs {
+		p, low, high := result.WinRate(i)
+		fmt.Fprintf(w, "%s: %d wins, win rate %.3f (95%% confidence interval %.3f-%.3f)\n", config.AIs[i], result.Wins[i], p, low, high)
+	}
+	fmt.Fprintf(w, "draws (both crashed in the same round): %d\n", result.Draws)
+	return result, nil
+}
@@ -0,0 +1,143 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+)
+
+const (
+	// LogLevelNone contains the log level discarding all log messages.
+	LogLevelNone = "none"
+	// LogLevelInfo contains the default log level.
+	LogLevelInfo = "info"
+	// LogLevelDebug contains the log level which additionally logs the decisions of the AIs to the log if no decision log is set (see SetDecisionLog).
+	LogLevelDebug = "debug"
+)
+
+// ValidateLogLevel returns an error if the level is not LogLevelNone, LogLevelInfo or LogLevelDebug.
+func ValidateLogLevel(level string) error {
+	switch level {
+	case LogLevelNone, LogLevelInfo, LogLevelDebug:
+		return nil
+	default:
+		return fmt.Errorf("unknown log level %q (must be %s, %s or %s)", level, LogLevelNone, LogLevelInfo, LogLevelDebug)
+	}
+}
+
+// ConfigDuration is a time.Duration which is read from a JSON string like "200ms" (see time.ParseDuration).
+type ConfigDuration time.Duration
+
+// UnmarshalJSON parses the duration from a JSON string.
+func (d *ConfigDuration) UnmarshalJSON(b []byte) error {
+	var s string
+	err := json.Unmarshal(b, &s)
+	if err != nil {
+		return fmt.Errorf("duration must be a string: %w", err)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = ConfigDuration(v)
+	return nil
+}
+
+// Config contains the settings of the client which can be read from a file instead of being given as flags (see LoadConfig).
+// Empty values are not set, so the flags (or their defaults) are used.
+type Config struct {
+	// URL is the websocket URL of the server (see ClientConfig).
+	URL string `json:"url"`
+	// Key is the API key (see ClientConfig).
+	Key string `json:"key"`
+	// Name is the name of our player (see ClientConfig).
+	Name string `json:"name"`
+	// AI is the name of the AI playing the game. It must be registered (see ListAIs).
+	AI string `json:"ai"`
+	// SafetyMargin is the time before the deadline at which the fallback action is sent (see ClientConfig). Must not be negative.
+	SafetyMargin ConfigDuration `json:"safety_margin"`
+	// MeasureLatency enables subtracting the measured latency to the server from the deadline (see ClientConfig).
+	MeasureLatency bool `json:"measure_latency"`
+	// Seed is the seed of all random decisions of the AIs (see SetAISeed).
+	Seed int64 `json:"seed"`
+	// LogLevel is the log level (see ValidateLogLevel).
+	LogLevel string `json:"log_level"`
+	// LogFormat is the log format (see ValidateLogFormat).
+	LogFormat string `json:"log_format"`
+	// MaxBoardCells is the maximum number of cells of a board on which the search AIs search (see SetMaxBoardCells). Zero keeps the default.
+	MaxBoardCells int `json:"max_board_cells"`
+	// Watchdog is the number of tick intervals the client waits for the next state (see ClientConfig). Zero keeps the default, negative values disable the watchdog.
+	Watchdog int `json:"watchdog"`
+}
+
+// LoadConfig reads a configuration from a JSON file, e.g. {"url": "wss://example.com/spe_ed", "key": "secret", "name": "spe_ed bot", "ai": "FloodFillAI", "safety_margin": "200ms", "measure_latency": true, "seed": 1, "log_level": "info", "log_format": "text", "max_board_cells": 40000, "watchdog": 10}.
+// Unknown keys, invalid names, unknown AIs, unknown log levels, unknown log formats, negative safety margins and negative maximum board sizes are an error.
+func LoadConfig(path string) (Config, error) {
+	var c Config
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return c, fmt.Errorf("config: %w", err)
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.DisallowUnknownFields()
+	err = d.Decode(&c)
+	if err != nil {
+		return Config{}, fmt.Errorf("config: can not parse %s: %w", path, err)
+	}
+
+	if c.Name != "" {
+		err = ValidatePlayerName(c.Name)
+		if err != nil {
+			return Config{}, fmt.Errorf("config: %s: %w", path, err)
+		}
+	}
+	if c.AI != "" {
+		known := false
+		for _, name := range ListAIs() {
+			if name == c.AI {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return Config{}, fmt.Errorf("config: unknown ai %q in %s (known ais: %v)", c.AI, path, ListAIs())
+		}
+	}
+	if c.SafetyMargin < 0 {
+		return Config{}, fmt.Errorf("config: negative safety_margin in %s", path)
+	}
+	if c.MaxBoardCells < 0 {
+		return Config{}, fmt.Errorf("config: negative max_board_cells in %s", path)
+	}
+	if c.LogLevel != "" {
+		err = ValidateLogLevel(c.LogLevel)
+		if err != nil {
+			return Config{}, fmt.Errorf("config: %s: %w", path, err)
+		}
+	}
+	if c.LogFormat != "" {
+		err = ValidateLogFormat(c.LogFormat)
+		if err != nil {
+			return Config{}, fmt.Errorf("config: %s: %w", path, err)
+		}
+	}
+	return c, nil
+}
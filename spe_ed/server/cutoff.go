@@ -0,0 +1,139 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+// FindTrappingMove searches an action of Game.You which cuts the opponent off: afterwards, the free region of the opponent is separated from the own region and strictly smaller.
+// Players which are already separated can not be cut off.
+// The cut must hold for every reply of the opponent for horizon rounds (at least 1), so an opponent jumping over a trail through a hole is considered to escape (see HoleRules).
+// The own player is assumed to stay inside its region meanwhile, the replies of the opponent are simulated with ApplyAction and an opponent crashing counts as trapped.
+// Only actions which do not crash, do not move into a cell the opponents might fill in the same round (see opponentCells) and do not leave the own player trapped (see TrapCheck) are considered.
+// If several actions cut the opponent off, the one with the largest difference between the own region and the region of the opponent is returned, ties are broken in the order change_nothing, turn_left, turn_right, slow_down, speed_up.
+// The second return value is false if no such action exists or the players are unknown or inactive. The game is not modified.
+func FindTrappingMove(g *Game, opponentID int, horizon int) (Action, bool) {
+	me, ok := g.Players[g.You]
+	if !ok || !me.Active || opponentID == g.You {
+		return "", false
+	}
+	if o, ok := g.Players[opponentID]; !ok || !o.Active {
+		return "", false
+	}
+	if horizon < 1 {
+		horizon = 1
+	}
+	if _, _, separated := separatedRegions(g, g.You, opponentID); separated {
+		// Nothing left to cut
+		return "", false
+	}
+
+	danger := opponentCells(g)
+	b := NewBitboard(g)
+	var best Action
+	bestMargin := 0
+actionLoop:
+	for _, action := range []Action{ActionNOOP, ActionTurnLeft, ActionTurnRight, ActionSlower, ActionFaster} {
+		if b.Crashes(me, action) {
+			continue
+		}
+		c, crashed := Simulate(g, g.You, action)
+		if crashed {
+			ReleaseClone(c)
+			continue
+		}
+		for y := range c.Cells {
+			for x := range c.Cells[y] {
+				if c.Cells[y][x] != g.Cells[y][x] && danger[coordinate{x, y}] {
+					ReleaseClone(c)
+					continue actionLoop
+				}
+			}
+		}
+		p := c.Players[c.You]
+		if _, trapped := TrapCheck(c, p.X, p.Y, p.Speed); trapped {
+			ReleaseClone(c)
+			continue
+		}
+
+		own, opponent, cut := separatedRegions(c, c.You, opponentID)
+		stays := cut && opponent < own && staysCutOff(c, opponentID, horizon)
+		ReleaseClone(c)
+		if !stays {
+			continue
+		}
+		if best == "" || own-opponent > bestMargin {
+			best = action
+			bestMargin = own - opponent
+		}
+	}
+	return best, best != ""
+}
+
+// staysCutOff returns whether the opponent stays separated from the region of Game.You after each of its possible actions for the next depth rounds.
+// Only the opponent moves. An opponent crashing can not escape.
+func staysCutOff(g *Game, opponentID int, depth int) bool {
+	if depth == 0 {
+		return true
+	}
+	for _, action := range []Action{ActionNOOP, ActionTurnLeft, ActionTurnRight, ActionSlower, ActionFaster} {
+		c, crashed := Simulate(g, opponentID, action)
+		if crashed {
+			ReleaseClone(c)
+			continue
+		}
+		_, _, cut := separatedRegions(c, c.You, opponentID)
+		ok := cut && staysCutOff(c, opponentID, depth-1)
+		ReleaseClone(c)
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// separatedRegions returns the number of free cells reachable from the heads of the player and the opponent and whether both regions are separated.
+// The regions are not separated if they share a cell or the heads are next to each other or to the region of the other player. Holes are not considered.
+func separatedRegions(g *Game, player, opponent int) (own, theirs int, separated bool) {
+	p := g.Players[player]
+	o := g.Players[opponent]
+	b := NewBitboard(g)
+
+	// Mark the region of the player including its head
+	reached := NewEmptyBitboard(g.Width, g.Height)
+	if b.InBounds(p.X, p.Y) {
+		reached.Set(p.X, p.Y)
+	}
+	visited := b.Clone()
+	queue := []coordinate{{p.X, p.Y}}
+	for len(queue) != 0 {
+		c := queue[0]
+		queue = queue[1:]
+		for _, n := range [4]coordinate{{c.X + 1, c.Y}, {c.X - 1, c.Y}, {c.X, c.Y + 1}, {c.X, c.Y - 1}} {
+			if visited.IsOccupied(n.X, n.Y) {
+				continue
+			}
+			visited.Set(n.X, n.Y)
+			reached.Set(n.X, n.Y)
+			own++
+			queue = append(queue, n)
+		}
+	}
+
+	for _, n := range [5]coordinate{{o.X, o.Y}, {o.X + 1, o.Y}, {o.X - 1, o.Y}, {o.X, o.Y + 1}, {o.X, o.Y - 1}} {
+		if reached.InBounds(n.X, n.Y) && reached.IsOccupied(n.X, n.Y) {
+			return own, 0, false
+		}
+	}
+	return own, b.FloodFill(o.X, o.Y), true
+}
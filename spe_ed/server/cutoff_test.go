@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "testing"
+
+func TestFindTrappingMove(t *testing.T) {
+	// Moving into the gap of the wall leaves the opponent alone on the smaller side
+	g := testScenario(t, `{"you": 1, "grid": [
+		"...x......",
+		".<2x......",
+		"...x......",
+		".1>.......",
+		"...x......",
+		"...x......",
+		"...x......"], "players": {"1": {}, "2": {}}}`)
+	a, ok := FindTrappingMove(g, 2, AggressiveTrapHorizon)
+	if !ok || a != ActionNOOP {
+		t.Fatalf("got %s (found %t), want %s\n%s", a, ok, ActionNOOP, g)
+	}
+	if a := decide(t, &AggressiveAI{}, g); a != ActionNOOP {
+		t.Errorf("AggressiveAI plays %s, want %s", a, ActionNOOP)
+	}
+
+	// Afterwards, there is nothing left to cut
+	c, crashed := Simulate(g, 1, ActionNOOP)
+	if crashed {
+		t.Fatal("crashed moving into the gap")
+	}
+	if a, ok := FindTrappingMove(c, 2, AggressiveTrapHorizon); ok {
+		t.Errorf("separated players: got %s", a)
+	}
+	ReleaseClone(c)
+
+	for name, src := range map[string]string{
+		"larger side": `{"you": 1, "grid": [
+			"...x......",
+			"...x..2>..",
+			"...x......",
+			"....<1....",
+			"...x......",
+			"...x......",
+			"...x......"], "players": {"1": {}, "2": {}}}`,
+		"open board": `{"you": 1, "grid": [
+			"..........",
+			".<2.......",
+			"..........",
+			".1>.......",
+			"..........",
+			"..........",
+			".........."], "players": {"1": {}, "2": {}}}`,
+	} {
+		g := testScenario(t, src)
+		if a, ok := FindTrappingMove(g, 2, AggressiveTrapHorizon); ok {
+			t.Errorf("%s: got %s", name, a)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var decisionLogEnabled int32
+var decisionLogLock sync.Mutex
+var decisionLogEncoder *json.Encoder
+
+// Decision represents a single logged decision. It is written as one JSON object per line.
+type Decision struct {
+	Time      time.Time `json:"time"`
+	Turn      int       `json:"turn"`
+	Player    int       `json:"player"`
+	AI        string    `json:"ai"`
+	Action    Action    `json:"action"`
+	Reason    string    `json:"reason,omitempty"`
+	X         int       `json:"x"`
+	Y         int       `json:"y"`
+	Speed     int       `json:"speed"`
+	Direction Direction `json:"direction"`
+	// Scores contains the score of every evaluated action. Actions which were not evaluated or have no finite score are missing.
+	Scores map[Action]float64 `json:"scores,omitempty"`
+	// Deadline is the deadline of the game, if it has one.
+	Deadline string `json:"deadline,omitempty"`
+	// RemainingMS contains the time left until the deadline in milliseconds, if the game has a deadline.
+	RemainingMS *float64 `json:"remaining_ms,omitempty"`
+}
+
+// SetDecisionLog sets the writer all decisions are logged to. A nil writer disables the decision log, which is the default.
+func SetDecisionLog(w io.Writer) {
+	decisionLogLock.Lock()
+	defer decisionLogLock.Unlock()
+
+	if w == nil {
+		decisionLogEncoder = nil
+		atomic.StoreInt32(&decisionLogEnabled, 0)
+		return
+	}
+	decisionLogEncoder = json.NewEncoder(w)
+	atomic.StoreInt32(&decisionLogEnabled, 1)
+}
+
+// DecisionLogEnabled returns whether decisions are logged, either to the decision log or to the log at LogLevelDebug if no decision log is set.
+// It is cheap, so AIs can use it to skip collecting information for LogDecision.
+func DecisionLogEnabled() bool {
+	return atomic.LoadInt32(&decisionLogEnabled) == 1 || log.DebugEnabled()
+}
+
+// LogDecision logs the action chosen for Game.You together with the current state of the player and the time left.
+// scores might be nil. If no decision log is set, the decision is logged as JSON at LogLevelDebug. Safe for concurrent use.
+func LogDecision(g *Game, ai string, action Action, scores map[Action]float64, reason string) {
+	if !DecisionLogEnabled() {
+		return
+	}
+
+	d := Decision{
+		Time:     time.Now(),
+		Player:   g.You,
+		AI:       ai,
+		Action:   action,
+		Reason:   reason,
+		Deadline: g.Deadline,
+	}
+	if p, ok := g.Players[g.You]; ok {
+		d.Turn = p.stepCounter + 1
+		d.X, d.Y, d.Speed, d.Direction = p.X, p.Y, p.Speed, p.Direction
+	}
+	if remaining, ok := g.RemainingTime(); ok {
+		ms := float64(remaining) / float64(time.Millisecond)
+		d.RemainingMS = &ms
+	}
+	if len(scores) != 0 {
+		d.Scores = make(map[Action]float64, len(scores))
+		for k, v := range scores {
+			// encoding/json can not encode infinite values
+			if !math.IsInf(v, 0) && !math.IsNaN(v) {
+				d.Scores[k] = v
+			}
+		}
+	}
+
+	decisionLogLock.Lock()
+	defer decisionLogLock.Unlock()
+	if decisionLogEncoder == nil {
+		b, err := json.Marshal(d)
+		if err != nil {
+			log.Println("decision log:", err)
+			return
+		}
+		log.Debugf("decision: %s", b)
+		return
+	}
+	err := decisionLogEncoder.Encode(d)
+	if err != nil {
+		log.Println("decision log:", err)
+	}
+}
@@ -0,0 +1,32 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+// OpponentDistance returns the smallest manhattan distance between the cell (x, y) and the head of an active opponent of Game.You.
+// If there is no active opponent, -1 is returned.
+func OpponentDistance(g *Game, x, y int) int {
+	distance := -1
+	for k := range g.Players {
+		if k == g.You || !g.Players[k].Active {
+			continue
+		}
+		d := abs(x-g.Players[k].X) + abs(y-g.Players[k].Y)
+		if distance == -1 || d < distance {
+			distance = d
+		}
+	}
+	return distance
+}
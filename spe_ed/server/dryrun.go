@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"time"
+)
+
+// DryRunConfig contains the configuration of RunDryRun.
+type DryRunConfig struct {
+	// AI is the name of the AI.
+	AI string
+	// Ticks is the maximum number of rounds played.
+	Ticks int
+	// MinWidth, MaxWidth, MinHeight and MaxHeight limit the width and height of the board. Both are chosen independently.
+	MinWidth, MaxWidth, MinHeight, MaxHeight int
+	// Seed is used to generate the board and to seed the AI if it implements SeedableAI.
+	Seed int64
+	// Holes contains the hole rules of the game. The zero value contains the official rules.
+	Holes HoleRules
+}
+
+// RunDryRun lets a single AI play alone on a random board without a server and writes the board (see Game.String) and the chosen action of every round to w.
+// The moves are applied with ApplyAction. The AI has SimulatorAnswerTimeout for every answer, AIs not answering in time crash like on the server.
+// The run stops after config.Ticks rounds or as soon as the AI crashes. It returns the number of rounds the AI survived.
+func RunDryRun(config DryRunConfig, w io.Writer) (int, error) {
+	if config.Ticks < 1 {
+		return 0, errors.New("dryrun: at least one tick is needed")
+	}
+	if config.MinWidth < 1 || config.MaxWidth < config.MinWidth || config.MinHeight < 1 || config.MaxHeight < config.MinHeight {
+		return 0, fmt.Errorf("dryrun: invalid board size range %dx%d-%dx%d", config.MinWidth, config.MinHeight, config.MaxWidth, config.MaxHeight)
+	}
+	if err := config.Holes.Validate(); err != nil {
+		return 0, fmt.Errorf("dryrun: %w", err)
+	}
+	ai, err := CreateAI(config.AI)
+	if err != nil {
+		return 0, fmt.Errorf("dryrun: %w", err)
+	}
+
+	seed := config.Seed
+	if seed == 0 {
+		seed = rand.Int63()
+	}
+	r := rand.New(rand.NewSource(seed))
+	if sai, ok := ai.(SeedableAI); ok {
+		sai.Seed(r.Int63())
+	}
+	opponents := NewOpponentModel()
+	if mai, ok := ai.(OpponentModelAI); ok {
+		mai.SetOpponentModel(opponents)
+	}
+	answer := make(chan Action, 1)
+	ai.GetChannel(answer)
+
+	width := config.MinWidth + r.Intn(config.MaxWidth-config.MinWidth+1)
+	height := config.MinHeight + r.Intn(config.MaxHeight-config.MinHeight+1)
+	g := &Game{
+		Width:   width,
+		Height:  height,
+		Cells:   make([][]int8, height),
+		Players: make(map[int]*Player, 1),
+		You:     1,
+		Running: true,
+		Holes:   config.Holes,
+	}
+	for i := range g.Cells {
+		g.Cells[i] = make([]int8, width)
+	}
+	place, err := newPlacer(PlacementRandom, g, 1, r)
+	if err != nil {
+		return 0, fmt.Errorf("dryrun: %w", err)
+	}
+	x, y, direction := place(0)
+	g.Cells[y][x] = PlayerCell(1)
+	g.Players[1] = &Player{
+		X:         x,
+		Y:         y,
+		Direction: direction,
+		Speed:     1,
+		Active:    true,
+		Name:      ai.Name(),
+	}
+
+	fmt.Fprintf(w, "dryrun: %s on %dx%d (seed %d)\n%s", ai.Name(), width, height, seed, g.String())
+	for tick := 1; tick <= config.Ticks; tick++ {
+		opponents.Observe(g)
+
+		// Remove old answers
+		select {
+		case <-answer:
+		default:
+		}
+		start := time.Now()
+		go getAIState(ai, g.ViewFor(1), start.Add(SimulatorAnswerTimeout))
+		timer := time.NewTimer(SimulatorAnswerTimeout)
+		var action Action
+		select {
+		case action = <-answer:
+		case <-timer.C:
+		}
+		timer.Stop()
+		if action == "" {
+			fmt.Fprintf(w, "tick %d: %s did not answer in time, crashed\n", tick, ai.Name())
+			return tick - 1, nil
+		}
+
+		err = ApplyAction(g, 1, action)
+		if err != nil {
+			fmt.Fprintf(w, "tick %d: %s (%s): %s\n", tick, action, time.Since(start).Round(time.Microsecond), err.Error())
+		} else {
+			fmt.Fprintf(w, "tick %d: %s (%s)\n", tick, action, time.Since(start).Round(time.Microsecond))
+		}
+		if !g.Players[1].Active {
+			g.Running = false
+			fmt.Fprint(w, g.String())
+			fmt.Fprintf(w, "dryrun: %s crashed in tick %d\n", ai.Name(), tick)
+			return tick - 1, nil
+		}
+		fmt.Fprint(w, g.String())
+	}
+	fmt.Fprintf(w, "dryrun: %s survived %d ticks\n", ai.Name(), config.Ticks)
+	return config.Ticks, nil
+}
@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRunDryRun(t *testing.T) {
+	var b bytes.Buffer
+	survived, err := RunDryRun(DryRunConfig{AI: "SurvivalAI", Ticks: 20, MinWidth: 10, MaxWidth: 12, MinHeight: 10, MaxHeight: 12, Seed: 3}, &b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if survived != 20 {
+		t.Errorf("survived %d ticks, want 20\n%s", survived, b.String())
+	}
+	out := b.String()
+	if !strings.HasPrefix(out, "dryrun: SurvivalAI on ") || !strings.HasSuffix(out, "dryrun: SurvivalAI survived 20 ticks\n") {
+		t.Errorf("unexpected output\n%s", out)
+	}
+	for tick := 1; tick <= 20; tick++ {
+		if !strings.Contains(out, fmt.Sprintf("\ntick %d: ", tick)) {
+			t.Errorf("tick %d missing\n%s", tick, out)
+		}
+	}
+}
+
+func TestRunDryRunCrash(t *testing.T) {
+	var b bytes.Buffer
+	survived, err := RunDryRun(DryRunConfig{AI: "SurvivalAI", Ticks: 20, MinWidth: 1, MaxWidth: 1, MinHeight: 1, MaxHeight: 1, Seed: 3}, &b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if survived != 0 || !strings.HasSuffix(b.String(), "dryrun: SurvivalAI crashed in tick 1\n") {
+		t.Errorf("survived %d ticks on a 1x1 board\n%s", survived, b.String())
+	}
+}
+
+func TestRunDryRunErrors(t *testing.T) {
+	for _, config := range []DryRunConfig{
+		{AI: "SurvivalAI", Ticks: 0, MinWidth: 10, MaxWidth: 10, MinHeight: 10, MaxHeight: 10},
+		{AI: "SurvivalAI", Ticks: 1, MinWidth: 0, MaxWidth: 10, MinHeight: 10, MaxHeight: 10},
+		{AI: "SurvivalAI", Ticks: 1, MinWidth: 10, MaxWidth: 10, MinHeight: 0, MaxHeight: 10},
+		{AI: "SurvivalAI", Ticks: 1, MinWidth: 10, MaxWidth: 9, MinHeight: 10, MaxHeight: 10},
+		{AI: "SurvivalAI", Ticks: 1, MinWidth: 10, MaxWidth: 10, MinHeight: 10, MaxHeight: 9},
+		{AI: "SurvivalAI", Ticks: 1, MinWidth: 10, MaxWidth: 10, MinHeight: 10, MaxHeight: 10, Holes: HoleRules{Speed: -1}},
+		{AI: "NoSuchAI", Ticks: 1, MinWidth: 10, MaxWidth: 10, MinHeight: 10, MaxHeight: 10},
+	} {
+		var b bytes.Buffer
+		if _, err := RunDryRun(config, &b); err == nil {
+			t.Errorf("%+v accepted", config)
+		}
+	}
+}
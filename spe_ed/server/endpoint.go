@@ -42,6 +42,24 @@ func init() {
 }
 
 func endpoint(w http.ResponseWriter, r *http.Request) {
+	if isShuttingDown() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	// A name is optional and only used with -chosennames, so it is checked before the key is claimed
+	var name string
+	if chosenNames {
+		name = r.URL.Query().Get("name")
+	}
+	if name != "" {
+		err := ValidatePlayerName(name)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
 	// Check API key
 	key := r.URL.Query().Get("key")
 	switch ClaimKey(key) {
@@ -62,7 +80,7 @@ func endpoint(w http.ResponseWriter, r *http.Request) {
 	if currentGame != nil {
 		if currentGame.ContainsAPI(key) {
 			currentGameLock.Unlock()
-			log.Println("keys (in game):", "ratelimit", key)
+			log.Println("keys (in game):", "ratelimit", RedactKey(key))
 			w.WriteHeader(http.StatusTooManyRequests)
 			ReleaseKey(key)
 			return
@@ -70,7 +88,7 @@ func endpoint(w http.ResponseWriter, r *http.Request) {
 	}
 	currentGameLock.Unlock()
 
-	log.Printf("connection metadata %s: %s", key, r.Header)
+	log.Printf("connection metadata %s: %s", RedactKey(key), r.Header)
 
 	// Upgrade connection
 	conn, err := upgrader.Upgrade(w, r, nil)
@@ -86,15 +104,21 @@ func endpoint(w http.ResponseWriter, r *http.Request) {
 
 	p := new(Player)
 	p.realName = GlobalPseudonym.Get(key)
+	p.chosenName = name
 	p.ws = conn
 	p.api = key
-	p.Input = make(chan string, 5)
+	p.Input = make(chan Action, 5)
 	go p.readWorker()
 
 	// Attach to game
 	currentGameLock.Lock()
 	defer currentGameLock.Unlock()
 
+	if isShuttingDown() {
+		p.Close()
+		return
+	}
+
 	if currentGame == nil {
 		currentGame = new(Game)
 		newGameTime = time.Now()
@@ -128,7 +152,7 @@ func gameStarterWorker() {
 	for {
 		time.Sleep(1 * time.Second)
 		currentGameLock.Lock()
-		if currentGame == nil {
+		if currentGame == nil || isShuttingDown() {
 			currentGameLock.Unlock()
 			continue
 		}
@@ -141,7 +165,7 @@ func gameStarterWorker() {
 					p := new(Player)
 					p.realName = ais[i].API
 					p.underlyingAI = ais[i].AI
-					p.Input = make(chan string, 5)
+					p.Input = make(chan Action, 5)
 					p.underlyingAI.GetChannel(p.Input)
 					currentGame.AddPlayer(p)
 				}
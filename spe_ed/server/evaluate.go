@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// ActionScore contains the result of evaluating a single action with EvaluateActions.
+type ActionScore struct {
+	Action Action
+	// Score is only meaningful if Valid is true.
+	Score int
+	// Valid is false if the action was not evaluated or the evaluation reported the action as invalid (e.g. because it crashes).
+	Valid bool
+}
+
+// EvaluateActions evaluates all actions concurrently, each on its own copy of the game, and returns the results in the order of the actions.
+// evaluate is called with the index of the action, so it can use per-action state without synchronisation. It must not access state shared with other calls.
+// The copy is acquired with AcquireClone and released after evaluate returns, so evaluate must not keep references to it.
+// g is not modified. At most runtime.GOMAXPROCS(0) evaluations run at the same time. If the context is cancelled, no further evaluations are started,
+// all running evaluations are expected to return quickly, and the error of the context is returned.
+func EvaluateActions(ctx context.Context, g *Game, actions []Action, evaluate func(ctx context.Context, c *Game, index int, action Action) (score int, valid bool)) ([]ActionScore, error) {
+	results := make([]ActionScore, len(actions))
+	workers := make(chan struct{}, runtime.GOMAXPROCS(0))
+	var wg sync.WaitGroup
+
+	for i := range actions {
+		results[i].Action = actions[i]
+
+		select {
+		case workers <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-workers }()
+			// g is only read, so cloning concurrently is safe
+			c := AcquireClone(g)
+			defer ReleaseClone(c)
+			results[i].Score, results[i].Valid = evaluate(ctx, c, i, actions[i])
+		}(i)
+	}
+
+	wg.Wait()
+	return results, ctx.Err()
+}
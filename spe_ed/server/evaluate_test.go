@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"runtime"
+	"sync/atomic"
+	"testing"
+)
+
+func TestEvaluateActions(t *testing.T) {
+	g := randomGame(rand.New(rand.NewSource(7)), 20, 20, 0.2)
+	orig := g.Clone()
+	var running, maxRunning int32
+	results, err := EvaluateActions(context.Background(), g, Actions[:], func(ctx context.Context, c *Game, index int, action Action) (int, bool) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		if Actions[index] != action {
+			t.Errorf("index %d for action %s", index, action)
+		}
+		// Every evaluation must get its own copy
+		c.Cells[0][0] = CellCrash
+		ApplyAction(c, c.You, action)
+		return index * 10, index%2 == 0
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(g.Cells, orig.Cells) || !reflect.DeepEqual(g.Players, orig.Players) {
+		t.Error("game was modified")
+	}
+	if int(maxRunning) > runtime.GOMAXPROCS(0) {
+		t.Errorf("%d evaluations at the same time with GOMAXPROCS %d", maxRunning, runtime.GOMAXPROCS(0))
+	}
+	for i, r := range results {
+		if r.Action != Actions[i] || r.Score != i*10 || r.Valid != (i%2 == 0) {
+			t.Errorf("result %d is %+v", i, r)
+		}
+	}
+}
+
+func TestEvaluateActionsCancelled(t *testing.T) {
+	g := randomGame(rand.New(rand.NewSource(7)), 5, 5, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var calls int32
+	results, err := EvaluateActions(ctx, g, Actions[:], func(ctx context.Context, c *Game, index int, action Action) (int, bool) {
+		atomic.AddInt32(&calls, 1)
+		return 1, true
+	})
+	if err != context.Canceled {
+		t.Errorf("got error %v", err)
+	}
+	if calls != 0 || len(results) != len(Actions) {
+		t.Errorf("%d evaluations and %d results after cancelling", calls, len(results))
+	}
+	for _, r := range results {
+		if r.Valid {
+			t.Errorf("%s valid without evaluation", r.Action)
+		}
+	}
+}
+
+// benchmarkMinimaxSearch runs a search of depth 3 on a 60x60 board with at most procs evaluations at the same time.
+func benchmarkMinimaxSearch(b *testing.B, procs int) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(procs))
+	g := floodFillTickGame()
+	m := new(MinimaxAI)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, ok := m.Search(context.Background(), g, 3); !ok {
+			b.Fatal("search aborted")
+		}
+	}
+}
+
+func BenchmarkMinimaxSearchSerial(b *testing.B) {
+	benchmarkMinimaxSearch(b, 1)
+}
+
+func BenchmarkMinimaxSearchParallel(b *testing.B) {
+	benchmarkMinimaxSearch(b, runtime.NumCPU())
+}
@@ -0,0 +1,166 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Evaluator scores a state of the game for a player. Larger values are better for the player.
+// An evaluator must not modify the game and is only called for active players.
+type Evaluator func(g *Game, playerID int) float64
+
+var evaluatorMap = make(map[string]Evaluator)
+var evaluatorLock sync.RWMutex
+
+func init() {
+	MustRegisterEvaluator("reachable_space", func(g *Game, playerID int) float64 {
+		p := g.Players[playerID]
+		return float64(FloodFill(g, p.X, p.Y))
+	})
+	MustRegisterEvaluator("voronoi", func(g *Game, playerID int) float64 {
+		return float64(Voronoi(g, playerID))
+	})
+	MustRegisterEvaluator("opponent_distance", func(g *Game, playerID int) float64 {
+		return float64(opponentDistanceOf(g, playerID))
+	})
+	MustRegisterEvaluator("corridor_width", func(g *Game, playerID int) float64 {
+		return float64(corridorWidth(g, g.Players[playerID]))
+	})
+	MustRegisterEvaluator("chamber", func(g *Game, playerID int) float64 {
+		p := g.Players[playerID]
+		return float64(ChamberSize(g, p.X, p.Y, ChamberRadius))
+	})
+	MustRegisterEvaluator("mobility", func(g *Game, playerID int) float64 {
+		return float64(len(g.LegalActions(playerID)))
+	})
+	MustRegisterEvaluator("wall_distance", func(g *Game, playerID int) float64 {
+		return float64(wallDistance(g, g.Players[playerID]))
+	})
+}
+
+// RegisterEvaluator registers an evaluator under the given name, so it can be weighted by name (see WeightedHeuristicWeights.Evaluators).
+// Name must be unique and not empty.
+func RegisterEvaluator(name string, fn Evaluator) error {
+	if name == "" {
+		return errors.New("evaluator name must not be empty")
+	}
+	if fn == nil {
+		return errors.New("Evaluator must not be nil")
+	}
+
+	evaluatorLock.Lock()
+	defer evaluatorLock.Unlock()
+	if _, ok := evaluatorMap[name]; ok {
+		return fmt.Errorf("evaluator name %s already registered", name)
+	}
+	evaluatorMap[name] = fn
+	return nil
+}
+
+// MustRegisterEvaluator is like RegisterEvaluator, but panics if the evaluator can not be registered.
+// It is intended to be used in init functions.
+func MustRegisterEvaluator(name string, fn Evaluator) {
+	err := RegisterEvaluator(name, fn)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// GetEvaluator returns the evaluator registered under the given name.
+// An error listing all known evaluators is returned for an unknown name.
+func GetEvaluator(name string) (Evaluator, error) {
+	evaluatorLock.RLock()
+	defer evaluatorLock.RUnlock()
+	fn, ok := evaluatorMap[name]
+	if !ok {
+		return nil, fmt.Errorf("evaluator name %s not known (known evaluators: %s)", name, strings.Join(listEvaluators(), ", "))
+	}
+	return fn, nil
+}
+
+// ListEvaluators returns a list of all registered evaluators in alphabetical order.
+func ListEvaluators() []string {
+	evaluatorLock.RLock()
+	defer evaluatorLock.RUnlock()
+	return listEvaluators()
+}
+
+// listEvaluators returns a list of all registered evaluators in alphabetical order.
+// Caller must hold evaluatorLock.
+func listEvaluators() []string {
+	s := make([]string, 0, len(evaluatorMap))
+	for k := range evaluatorMap {
+		s = append(s, k)
+	}
+	sort.Strings(s)
+	return s
+}
+
+// WeightedEvaluator is an evaluator together with its weight.
+type WeightedEvaluator struct {
+	Name     string
+	Weight   float64
+	Evaluate Evaluator
+}
+
+// EvaluateWeighted returns the sum of all evaluators multiplied by their weight for the player.
+// Evaluators with a weight of zero are not called.
+func EvaluateWeighted(g *Game, playerID int, terms []WeightedEvaluator) float64 {
+	score := 0.0
+	for _, t := range terms {
+		if t.Weight == 0 {
+			continue
+		}
+		score += t.Weight * t.Evaluate(g, playerID)
+	}
+	return score
+}
+
+// opponentDistanceOf returns the smallest manhattan distance between the head of the player and the head of an active opponent.
+// If there is no active opponent, 0 is returned.
+func opponentDistanceOf(g *Game, playerID int) int {
+	p := g.Players[playerID]
+	distance := -1
+	for k := range g.Players {
+		if k == playerID || !g.Players[k].Active {
+			continue
+		}
+		d := abs(p.X-g.Players[k].X) + abs(p.Y-g.Players[k].Y)
+		if distance == -1 || d < distance {
+			distance = d
+		}
+	}
+	if distance < 0 {
+		return 0
+	}
+	return distance
+}
+
+// wallDistance returns the number of cells between the head of the player and the nearest border of the board.
+func wallDistance(g *Game, p *Player) int {
+	d := p.X
+	for _, v := range []int{p.Y, g.Width - 1 - p.X, g.Height - 1 - p.Y} {
+		if v < d {
+			d = v
+		}
+	}
+	return d
+}
@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func init() {
+	MustRegisterEvaluator("test_constant", func(g *Game, playerID int) float64 { return 7 })
+}
+
+func TestRegisterEvaluator(t *testing.T) {
+	fn := func(g *Game, playerID int) float64 { return 0 }
+	for name, err := range map[string]error{
+		"empty":     RegisterEvaluator("", fn),
+		"nil":       RegisterEvaluator("test_nil", nil),
+		"duplicate": RegisterEvaluator("test_constant", fn),
+	} {
+		if err == nil {
+			t.Errorf("%s: registered", name)
+		}
+	}
+	_, err := GetEvaluator("test_unknown")
+	if err == nil || !strings.Contains(err.Error(), "reachable_space") {
+		t.Errorf("unknown evaluator: got error %v, want the known evaluators", err)
+	}
+	if _, err := GetEvaluator("test_nil"); err == nil {
+		t.Error("nil evaluator registered")
+	}
+}
+
+func TestEvaluateWeighted(t *testing.T) {
+	g := testScenario(t, `{"you": 1, "grid": ["......", ".1>...", "....<2", "......"], "players": {"1": {}, "2": {}}}`)
+	w := WeightedHeuristicWeights{
+		ReachableSpace: 1,
+		Voronoi:        0.5,
+		Evaluators:     map[string]float64{"mobility": 2, "wall_distance": -1, "test_constant": 3, "chamber": 0},
+	}
+	terms, err := w.Terms()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, term := range terms {
+		names = append(names, term.Name)
+	}
+	if got, want := strings.Join(names, " "), "reachable_space voronoi mobility test_constant wall_distance"; got != want {
+		t.Errorf("terms %s, want %s", got, want)
+	}
+
+	p := g.Players[1]
+	want := float64(FloodFill(g, p.X, p.Y)) + 0.5*float64(Voronoi(g, 1)) + 2*float64(len(g.LegalActions(1))) - float64(wallDistance(g, p)) + 3*7
+	if got := EvaluateWeighted(g, 1, terms); got != want {
+		t.Errorf("got %f, want %f", got, want)
+	}
+
+	w.Evaluators["test_unknown"] = 1
+	if _, err := w.Terms(); err == nil {
+		t.Error("unknown evaluator accepted")
+	}
+}
@@ -0,0 +1,57 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+)
+
+// FallbackFirstLegal contains the fallback selecting the first legal action (see Game.LegalActions) instead of a fixed action.
+const FallbackFirstLegal = "first_legal"
+
+// ValidateFallback returns an error if the fallback is neither a valid action nor FallbackFirstLegal.
+func ValidateFallback(fallback string) error {
+	if fallback != FallbackFirstLegal && !IsValidAction(Action(fallback)) {
+		return fmt.Errorf("unknown fallback %q (must be an action or %s)", fallback, FallbackFirstLegal)
+	}
+	return nil
+}
+
+// FallbackAction returns the action sent on behalf of Game.You if the AI did not answer in time.
+// For FallbackFirstLegal, the first legal action is returned or ActionNOOP if every action crashes. An empty fallback results in ActionNOOP, every other fallback is returned unchanged.
+func FallbackAction(g *Game, fallback string) Action {
+	switch fallback {
+	case "":
+		return ActionNOOP
+	case FallbackFirstLegal:
+		legal := g.LegalActions(g.You)
+		if len(legal) == 0 {
+			return ActionNOOP
+		}
+		return legal[0]
+	default:
+		return Action(fallback)
+	}
+}
+
+// StaticFallbackAction works like FallbackAction, but does not need a game. This allows answering if the state can not be used.
+// For FallbackFirstLegal and an empty fallback, ActionNOOP is returned.
+func StaticFallbackAction(fallback string) Action {
+	if !IsValidAction(Action(fallback)) {
+		return ActionNOOP
+	}
+	return Action(fallback)
+}
@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestFallbackAction(t *testing.T) {
+	// change_nothing crashes, turn_left is the first legal action
+	g := testScenario(t, `{"you": 1, "grid": ["....", "11>x", "...."], "players": {"1": {}}}`)
+	for fallback, want := range map[string]Action{
+		"":                 ActionNOOP,
+		FallbackFirstLegal: ActionTurnLeft,
+		"speed_up":         ActionFaster,
+	} {
+		if fallback != "" && ValidateFallback(fallback) != nil {
+			t.Errorf("%q rejected", fallback)
+		}
+		if got := FallbackAction(g, fallback); got != want {
+			t.Errorf("%q: got %s, want %s", fallback, got, want)
+		}
+	}
+
+	g = testScenario(t, `{"you": 1, "grid": [".x.", "x>x", ".x."], "players": {"1": {"x": 1, "y": 1}}}`)
+	if got := FallbackAction(g, FallbackFirstLegal); got != ActionNOOP {
+		t.Errorf("got %s without legal actions, want %s", got, ActionNOOP)
+	}
+
+	if ValidateFallback("jump") == nil {
+		t.Error("unknown fallback accepted")
+	}
+	for fallback, want := range map[string]Action{"": ActionNOOP, FallbackFirstLegal: ActionNOOP, "turn_right": ActionTurnRight} {
+		if got := StaticFallbackAction(fallback); got != want {
+			t.Errorf("static %q: got %s, want %s", fallback, got, want)
+		}
+	}
+}
+
+func TestClientFallback(t *testing.T) {
+	answers := make(chan Action, 1)
+	ws := fakeServer(t, func(ws *websocket.Conn) {
+		ws.WriteMessage(websocket.TextMessage, serverState(t, 200*time.Millisecond))
+		var a ActionMessage
+		if err := ws.ReadJSON(&a); err != nil {
+			t.Error(err)
+			return
+		}
+		answers <- a.Action
+		ws.WriteMessage(websocket.TextMessage, serverState(t, 0))
+	})
+	// The AI never answers in time
+	ai := &scriptedAI{delays: map[int]time.Duration{1: time.Second}}
+	c := make(chan Action, 1)
+	ai.GetChannel(c)
+	_, err := clientPlay(ws, ai, newAnswerSequencer(ai, c), nil, string(ActionTurnLeft), 50*time.Millisecond, nil, NewOpponentModel(), nil, new(StateWatchdog))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a := <-answers; a != ActionTurnLeft {
+		t.Errorf("sent %s instead of the fallback", a)
+	}
+}
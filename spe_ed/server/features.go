@@ -0,0 +1,201 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	// DefaultFeaturePatchRadius contains the radius of the occupancy patch of FeatureExtractor if none is set.
+	DefaultFeaturePatchRadius = 3
+	// DefaultFeatureOpponents contains the number of opponents encoded by FeatureExtractor if none is set.
+	DefaultFeatureOpponents = PlayersPerGame - 1
+)
+
+// FeatureExtractor turns a game into a vector of numbers describing the situation of Game.You, e.g. as input of learned AIs or for logging.
+// The layout of the vector only depends on the configuration and is described by Names. All positions are relative to the player (egocentric): ahead is the direction the player is heading to, right is to its right.
+// The vector contains, in this order:
+// - the occupancy patch: (2*PatchRadius+1)^2 cells around the head, row by row starting with the row furthest ahead and every row from left to right (1 for filled or outside of the board, 0 for free; the head itself is 1),
+// - the relative positions of the nearest Opponents active opponents (cells ahead, cells to the right and 1 if the opponent exists or 0 for missing opponents), nearest first,
+// - the speed as one-hot encoding of 1..MaxSpeed,
+// - the direction as one-hot encoding of up, down, left, right (in absolute terms, see Directions),
+// - the reachable space (see FloodFill) as share of all cells of the board,
+// - the distance to the border of the board ahead, to the left, to the right and behind in cells.
+// An unknown or inactive player results in a vector of zeros.
+type FeatureExtractor struct {
+	// PatchRadius is the number of cells around the head in every direction included in the occupancy patch. DefaultFeaturePatchRadius is used if it is zero.
+	PatchRadius int
+	// Opponents is the number of opponents encoded. DefaultFeatureOpponents is used if it is zero.
+	Opponents int
+}
+
+// Validate returns an error if the configuration contains negative values.
+func (f FeatureExtractor) Validate() error {
+	if f.PatchRadius < 0 || f.Opponents < 0 {
+		return fmt.Errorf("invalid feature configuration (patch radius %d, opponents %d)", f.PatchRadius, f.Opponents)
+	}
+	return nil
+}
+
+// radius returns the radius of the occupancy patch.
+func (f FeatureExtractor) radius() int {
+	if f.PatchRadius == 0 {
+		return DefaultFeaturePatchRadius
+	}
+	return f.PatchRadius
+}
+
+// opponents returns the number of opponents encoded.
+func (f FeatureExtractor) opponents() int {
+	if f.Opponents == 0 {
+		return DefaultFeatureOpponents
+	}
+	return f.Opponents
+}
+
+// Size returns the number of features returned by Extract.
+func (f FeatureExtractor) Size() int {
+	window := 2*f.radius() + 1
+	return window*window + 3*f.opponents() + MaxSpeed + len(Directions) + 1 + 4
+}
+
+// Names returns the names of all features in the order of Extract.
+// Patch cells are named patch_<ahead>_<right> (negative values are behind or to the left), opponent features opponent_<n>_ahead, opponent_<n>_right and opponent_<n>_present (n starting with 1).
+func (f FeatureExtractor) Names() []string {
+	r := f.radius()
+	names := make([]string, 0, f.Size())
+	for ahead := r; ahead >= -r; ahead-- {
+		for right := -r; right <= r; right++ {
+			names = append(names, fmt.Sprintf("patch_%d_%d", ahead, right))
+		}
+	}
+	for i := 1; i <= f.opponents(); i++ {
+		names = append(names, fmt.Sprintf("opponent_%d_ahead", i), fmt.Sprintf("opponent_%d_right", i), fmt.Sprintf("opponent_%d_present", i))
+	}
+	for s := 1; s <= MaxSpeed; s++ {
+		names = append(names, fmt.Sprintf("speed_%d", s))
+	}
+	for _, d := range Directions {
+		names = append(names, "direction_"+d.String())
+	}
+	names = append(names, "reachable_space", "wall_ahead", "wall_left", "wall_right", "wall_behind")
+	return names
+}
+
+// Extract returns the features of the game for Game.You (see FeatureExtractor). It always returns Size() features. The game is not modified.
+func (f FeatureExtractor) Extract(g *Game) []float32 {
+	features := make([]float32, 0, f.Size())
+	p, ok := g.Players[g.You]
+	if !ok || !p.Active {
+		return features[:f.Size()]
+	}
+	fx, fy, rx, ry := egocentricAxes(p.Direction)
+
+	features = appendPatch(features, g, p, f.radius())
+
+	type opponent struct {
+		id, ahead, right, distance int
+	}
+	opponents := make([]opponent, 0, len(g.Players))
+	for id, o := range g.Players {
+		if id == g.You || !o.Active {
+			continue
+		}
+		dx, dy := o.X-p.X, o.Y-p.Y
+		opponents = append(opponents, opponent{id: id, ahead: dx*fx + dy*fy, right: dx*rx + dy*ry, distance: abs(dx) + abs(dy)})
+	}
+	sort.Slice(opponents, func(i, j int) bool {
+		if opponents[i].distance != opponents[j].distance {
+			return opponents[i].distance < opponents[j].distance
+		}
+		return opponents[i].id < opponents[j].id
+	})
+	for i := 0; i < f.opponents(); i++ {
+		if i >= len(opponents) {
+			features = append(features, 0, 0, 0)
+			continue
+		}
+		features = append(features, float32(opponents[i].ahead), float32(opponents[i].right), 1)
+	}
+
+	for s := 1; s <= MaxSpeed; s++ {
+		features = append(features, oneHot(p.Speed == s))
+	}
+	for _, d := range Directions {
+		features = append(features, oneHot(p.Direction == d))
+	}
+
+	features = append(features, float32(FloodFill(g, p.X, p.Y))/float32(g.Width*g.Height))
+
+	// Cells until the border: ahead, left (opposite of right), right, behind
+	for _, v := range [4][2]int{{fx, fy}, {-rx, -ry}, {rx, ry}, {-fx, -fy}} {
+		features = append(features, float32(borderDistance(g, p.X, p.Y, v[0], v[1])))
+	}
+	return features
+}
+
+// egocentricAxes returns the vector pointing ahead (fx, fy) and to the right (rx, ry) of a player heading in the given direction.
+// An unknown direction is treated like DirectionUp.
+func egocentricAxes(d Direction) (fx, fy, rx, ry int) {
+	fx, fy = 0, -1
+	switch d {
+	case DirectionDown:
+		fx, fy = 0, 1
+	case DirectionLeft:
+		fx, fy = -1, 0
+	case DirectionRight:
+		fx, fy = 1, 0
+	}
+	return fx, fy, -fy, fx
+}
+
+// appendPatch appends the occupancy of the (2*radius+1)^2 cells around the head of the player to features and returns the result.
+// The patch is rotated so that the player heads upwards and is appended row by row, starting with the row furthest ahead and every row from left to right. Filled cells and cells outside of the board are 1, free cells 0.
+func appendPatch(features []float32, g *Game, p *Player, radius int) []float32 {
+	fx, fy, rx, ry := egocentricAxes(p.Direction)
+	for ahead := radius; ahead >= -radius; ahead-- {
+		for right := -radius; right <= radius; right++ {
+			x := p.X + ahead*fx + right*rx
+			y := p.Y + ahead*fy + right*ry
+			features = append(features, oneHot(x < 0 || x >= g.Width || y < 0 || y >= g.Height || !IsEmpty(g.Cells[y][x])))
+		}
+	}
+	return features
+}
+
+// oneHot returns 1 if b is true and 0 otherwise.
+func oneHot(b bool) float32 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// borderDistance returns the number of cells between (x, y) and the border of the board in direction (dx, dy).
+func borderDistance(g *Game, x, y, dx, dy int) int {
+	switch {
+	case dx > 0:
+		return g.Width - 1 - x
+	case dx < 0:
+		return x
+	case dy > 0:
+		return g.Height - 1 - y
+	default:
+		return y
+	}
+}
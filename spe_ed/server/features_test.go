@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFeatureExtractor(t *testing.T) {
+	g := testScenario(t, `{"you": 1, "grid": ["...<2..", ".......", ".1>....", "..x....", ".......", "......."], "players": {"1": {"speed": 2}, "2": {}}}`)
+	f := FeatureExtractor{PatchRadius: 1, Opponents: 2}
+
+	names := strings.Join(f.Names(), " ")
+	if want := "patch_1_-1 patch_1_0 patch_1_1 patch_0_-1 patch_0_0 patch_0_1 patch_-1_-1 patch_-1_0 patch_-1_1 " +
+		"opponent_1_ahead opponent_1_right opponent_1_present opponent_2_ahead opponent_2_right opponent_2_present " +
+		"speed_1 speed_2 speed_3 speed_4 speed_5 speed_6 speed_7 speed_8 speed_9 speed_10 " +
+		"direction_up direction_down direction_left direction_right " +
+		"reachable_space wall_ahead wall_left wall_right wall_behind"; names != want {
+		t.Errorf("got names\n%s\nwant\n%s", names, want)
+	}
+
+	want := []float32{
+		0, 0, 0, // ahead: (3, 1), (3, 2), (3, 3)
+		0, 1, 1, // head row: (2, 1), head, wall at (2, 3)
+		0, 1, 0, // behind: (1, 1), body, (1, 3)
+		1, -2, 1, // opponent at (3, 0)
+		0, 0, 0, // no second opponent
+		0, 1, 0, 0, 0, 0, 0, 0, 0, 0,
+		0, 0, 0, 1,
+		37.0 / 42,
+		4, 2, 3, 2,
+	}
+	got := f.Extract(g)
+	if len(got) != f.Size() || len(got) != len(want) {
+		t.Fatalf("got %d features, want %d (size %d)", len(got), len(want), f.Size())
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("%s: got %g, want %g", f.Names()[i], got[i], want[i])
+		}
+	}
+
+	g.Players[1].Active = false
+	for i, v := range f.Extract(g) {
+		if v != 0 {
+			t.Errorf("inactive player: %s is %g", f.Names()[i], v)
+		}
+	}
+
+	if n := (FeatureExtractor{}).Size(); n != len((FeatureExtractor{}).Names()) || n != 49+3*DefaultFeatureOpponents+MaxSpeed+4+5 {
+		t.Errorf("default extractor has %d features", n)
+	}
+	if err := (FeatureExtractor{PatchRadius: -1}).Validate(); err == nil {
+		t.Error("negative patch radius accepted")
+	}
+}
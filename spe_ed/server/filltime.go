@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "sync"
+
+// FillTimeTracker records in which round each cell of a game was filled, derived from consecutive states (see Observe).
+// A cell which is filled in the state of round t was filled in round t-1, so all fill times of cells filled while watching are at least 1.
+// Cells filled before the first observed state get 0 like free cells, since their fill time is unknown. A new FillTimeTracker (or one after Reset) must be used for every game.
+// FillTimeTracker is safe for concurrent use.
+type FillTimeTracker struct {
+	l sync.Mutex
+
+	times  [][]int
+	filled [][]bool
+}
+
+// NewFillTimeTracker returns an empty tracker.
+func NewFillTimeTracker() *FillTimeTracker {
+	return new(FillTimeTracker)
+}
+
+// Reset removes all observations, e.g. before a new game starts.
+func (t *FillTimeTracker) Reset() {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	t.times = nil
+	t.filled = nil
+}
+
+// Observe records the state of the given round (starting with 1). States must be given in the order of the rounds.
+// If the size of the board changed, all previous observations are removed first.
+func (t *FillTimeTracker) Observe(g *Game, turn int) {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	first := len(t.times) != g.Height || (g.Height != 0 && len(t.times[0]) != g.Width)
+	if first {
+		t.times = make([][]int, g.Height)
+		t.filled = make([][]bool, g.Height)
+		for y := range t.times {
+			t.times[y] = make([]int, g.Width)
+			t.filled[y] = make([]bool, g.Width)
+		}
+	}
+
+	for y := range g.Cells {
+		for x := range g.Cells[y] {
+			if t.filled[y][x] || IsEmpty(g.Cells[y][x]) {
+				continue
+			}
+			t.filled[y][x] = true
+			if !first {
+				t.times[y][x] = turn - 1
+			}
+		}
+	}
+}
+
+// Grid returns a copy of the fill times of all cells (see Game.FillTimes) or nil if nothing was observed.
+func (t *FillTimeTracker) Grid() [][]int {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	if t.times == nil {
+		return nil
+	}
+	grid := make([][]int, len(t.times))
+	for y := range t.times {
+		grid[y] = make([]int, len(t.times[y]))
+		copy(grid[y], t.times[y])
+	}
+	return grid
+}
+
+// FillTime returns the round in which the cell (x, y) was filled (see Game.FillTimes).
+// The second return value is false if the fill time is unknown, i.e. the fill times are not tracked, the cell is outside of the board, free or was filled before the first observed state.
+func (g *Game) FillTime(x, y int) (int, bool) {
+	if y < 0 || y >= len(g.FillTimes) || x < 0 || x >= len(g.FillTimes[y]) {
+		return 0, false
+	}
+	t := g.FillTimes[y][x]
+	return t, t != 0
+}
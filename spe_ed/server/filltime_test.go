@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "testing"
+
+func TestFillTimeTracker(t *testing.T) {
+	s, err := NewSimulator(20, 20, 8, "SurvivalAI", "SurvivalAI")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker := NewFillTimeTracker()
+	tracker.Observe(s.Game, 1)
+	g := s.Game.Clone()
+	g.FillTimes = tracker.Grid()
+	for y := range g.Cells {
+		for x := range g.Cells[y] {
+			if ft, ok := g.FillTime(x, y); ok {
+				t.Fatalf("initial cell (%d, %d) has fill time %d", x, y, ft)
+			}
+		}
+	}
+
+	heads := 0
+	for turn := 2; s.Step(); turn++ {
+		tracker.Observe(s.Game, turn)
+		g := s.Game.Clone()
+		g.FillTimes = tracker.Grid()
+		for _, p := range g.Players {
+			if !p.Active {
+				continue
+			}
+			heads++
+			if ft, ok := g.FillTime(p.X, p.Y); !ok || ft != turn-1 {
+				t.Fatalf("round %d: head (%d, %d) has fill time %d (known %t), want %d", turn-1, p.X, p.Y, ft, ok, turn-1)
+			}
+		}
+		for y := range g.Cells {
+			for x := range g.Cells[y] {
+				ft, ok := g.FillTime(x, y)
+				if IsEmpty(g.Cells[y][x]) && ok || ft >= turn {
+					t.Fatalf("round %d: cell (%d, %d) has fill time %d (known %t)", turn-1, x, y, ft, ok)
+				}
+			}
+		}
+		if c := g.Clone(); len(c.FillTimes) != g.Height || &c.FillTimes[0][0] != &g.FillTimes[0][0] {
+			t.Fatal("clone does not share the fill times")
+		}
+	}
+	if heads == 0 {
+		t.Fatal("no head checked")
+	}
+	if _, ok := (&Game{}).FillTime(0, 0); ok {
+		t.Error("fill time known without tracking")
+	}
+
+	tracker.Reset()
+	if tracker.Grid() != nil {
+		t.Error("Reset kept the fill times")
+	}
+}
@@ -0,0 +1,192 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+// coordinate represents a single cell of the game board.
+type coordinate struct {
+	X, Y int
+}
+
+// FloodFill returns the number of free cells reachable from (x, y) using a breadth-first search over g.Cells.
+// The start cell is not counted and does not need to be free, so the position of a head can be used directly.
+// Any non-zero cell is treated as a wall, as are the bounds of the board.
+// If multiple flood fills are run on the same game, building the Bitboard once and using Bitboard.FloodFill is faster.
+func FloodFill(g *Game, x, y int) int {
+	if x < 0 || x >= g.Width || y < 0 || y >= g.Height {
+		return 0
+	}
+	return NewBitboard(g).FloodFill(x, y)
+}
+
+// WeightedReachable works like FloodFill, but weights every reachable free cell by the inverse of its distance from the start cell (1 for a neighbour, 1/2 for a cell two steps away and so on).
+// Cells further away than horizon steps are not counted, a horizon below 1 counts all reachable cells. Near cells, which can be filled before opponents contest them, therefore dominate the result.
+// The start cell is not counted and does not need to be free.
+func WeightedReachable(g *Game, from coordinate, horizon int) float64 {
+	if from.X < 0 || from.X >= g.Width || from.Y < 0 || from.Y >= g.Height {
+		return 0
+	}
+	b := NewBitboard(g)
+
+	// Breadth-first search layer by layer, so all cells of a layer have the same distance
+	visited := make([]bool, g.Width*g.Height)
+	visited[from.Y*g.Width+from.X] = true
+	layer := []coordinate{from}
+	weight := 0.0
+	for d := 1; len(layer) > 0 && (horizon < 1 || d <= horizon); d++ {
+		next := make([]coordinate, 0, len(layer)*2)
+		for _, c := range layer {
+			for _, n := range [4]coordinate{{c.X + 1, c.Y}, {c.X - 1, c.Y}, {c.X, c.Y + 1}, {c.X, c.Y - 1}} {
+				if b.IsOccupied(n.X, n.Y) || visited[n.Y*g.Width+n.X] {
+					continue
+				}
+				visited[n.Y*g.Width+n.X] = true
+				next = append(next, n)
+			}
+		}
+		weight += float64(len(next)) / float64(d)
+		layer = next
+	}
+	return weight
+}
+
+// FloodFillCacheSize contains the number of distinct occupancies a FloodFillCache stores before it is cleared.
+const FloodFillCacheSize = 64
+
+// FloodFillCache memorises the connected free regions of the boards it has seen, so repeated flood fills on the same occupancy reuse a single breadth-first search per region.
+// Boards are identified by a hash of their bitboard and compared bit by bit, so a cached result is only used if the occupancy is unchanged.
+// It is meant to be reset at the start of every round. Not safe for concurrent use.
+type FloodFillCache struct {
+	boards map[uint64][]*floodFillRegions
+
+	// Searches contains the number of breadth-first searches performed since the last reset.
+	Searches int
+	// Lookups contains the number of flood fills answered since the last reset.
+	Lookups int
+}
+
+// floodFillRegions contains the free regions of a single board.
+// region holds 1 + the index of the region in size for each cell, or 0 if the region of the cell was not computed yet.
+type floodFillRegions struct {
+	b      *Bitboard
+	region []int
+	size   []int
+}
+
+// NewFloodFillCache returns an empty cache.
+func NewFloodFillCache() *FloodFillCache {
+	return &FloodFillCache{boards: make(map[uint64][]*floodFillRegions)}
+}
+
+// Reset removes all stored boards and counters.
+func (c *FloodFillCache) Reset() {
+	c.boards = make(map[uint64][]*floodFillRegions)
+	c.Searches = 0
+	c.Lookups = 0
+}
+
+// FloodFill returns the same result as FloodFill, but reuses regions computed for the same occupancy.
+func (c *FloodFillCache) FloodFill(g *Game, x, y int) int {
+	if x < 0 || x >= g.Width || y < 0 || y >= g.Height {
+		return 0
+	}
+	c.Lookups++
+
+	r := c.regions(NewBitboard(g))
+	if !r.b.IsOccupied(x, y) {
+		// The start cell is part of the region, but not counted
+		return r.sizeOf(c, x, y) - 1
+	}
+
+	count := 0
+	seen := [4]int{}
+	for n, nc := range [4]coordinate{{x + 1, y}, {x - 1, y}, {x, y + 1}, {x, y - 1}} {
+		if r.b.IsOccupied(nc.X, nc.Y) {
+			continue
+		}
+		size := r.sizeOf(c, nc.X, nc.Y)
+		seen[n] = r.region[nc.Y*r.b.Width+nc.X]
+		duplicate := false
+		for i := 0; i < n; i++ {
+			if seen[i] == seen[n] {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			count += size
+		}
+	}
+	return count
+}
+
+// regions returns the stored regions of the bitboard, adding it to the cache if it is not known.
+func (c *FloodFillCache) regions(b *Bitboard) *floodFillRegions {
+	if c.boards == nil {
+		c.boards = make(map[uint64][]*floodFillRegions)
+	}
+
+	// FNV-1a over the words of the bitboard
+	h := uint64(14695981039346656037)
+	h = (h ^ uint64(b.Width)) * 1099511628211
+	h = (h ^ uint64(b.Height)) * 1099511628211
+	for _, w := range b.bits {
+		h = (h ^ w) * 1099511628211
+	}
+
+	for _, r := range c.boards[h] {
+		if r.b.Equal(b) {
+			return r
+		}
+	}
+
+	if len(c.boards) >= FloodFillCacheSize {
+		c.boards = make(map[uint64][]*floodFillRegions)
+	}
+	r := &floodFillRegions{b: b, region: make([]int, b.Width*b.Height)}
+	c.boards[h] = append(c.boards[h], r)
+	return r
+}
+
+// sizeOf returns the size of the region of the free cell (x, y), running a breadth-first search if it is not known yet.
+func (r *floodFillRegions) sizeOf(c *FloodFillCache, x, y int) int {
+	start := y*r.b.Width + x
+	if r.region[start] != 0 {
+		return r.size[r.region[start]-1]
+	}
+
+	c.Searches++
+	r.size = append(r.size, 0)
+	label := len(r.size)
+	r.region[start] = label
+	queue := make([]coordinate, 1, 64)
+	queue[0] = coordinate{x, y}
+	count := 1
+
+	for len(queue) != 0 {
+		cc := queue[0]
+		queue = queue[1:]
+		for _, n := range [4]coordinate{{cc.X + 1, cc.Y}, {cc.X - 1, cc.Y}, {cc.X, cc.Y + 1}, {cc.X, cc.Y - 1}} {
+			if r.b.IsOccupied(n.X, n.Y) || r.region[n.Y*r.b.Width+n.X] != 0 {
+				continue
+			}
+			r.region[n.Y*r.b.Width+n.X] = label
+			count++
+			queue = append(queue, n)
+		}
+	}
+	r.size[label-1] = count
+	return count
+}
@@ -0,0 +1,163 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestFloodFill(t *testing.T) {
+	g := testScenario(t, `{"you": 1, "grid": [
+		"11>.x..",
+		"....x..",
+		"xxxxx..",
+		"......."
+	], "players": {"1": {}}}`)
+	for _, tc := range []struct{ x, y, want int }{
+		{2, 0, 5},  // head, start is not counted
+		{3, 0, 4},  // free start cell is not counted either
+		{5, 0, 12}, // other region
+		{4, 0, 18}, // filled start, both neighbouring regions count
+		{-1, 0, 0}, // outside
+		{7, 3, 0},
+	} {
+		if got := FloodFill(g, tc.x, tc.y); got != tc.want {
+			t.Errorf("FloodFill(%d, %d) is %d, want %d", tc.x, tc.y, got, tc.want)
+		}
+	}
+}
+
+func TestWeightedReachable(t *testing.T) {
+	g := testScenario(t, `{"you": 1, "grid": [
+		"xxxxxxxxxxxx",
+		"............",
+		"xxxxxxxxxxxx",
+		"...x........",
+		"...x........",
+		"...x....1>.."
+	], "players": {"1": {}}}`)
+	corridor, room := coordinate{0, 1}, coordinate{1, 4}
+
+	// The corridor has more cells, but the room has more cells close to the start
+	if FloodFill(g, corridor.X, corridor.Y) != 11 || FloodFill(g, room.X, room.Y) != 8 {
+		t.Fatalf("unweighted: corridor %d, room %d", FloodFill(g, corridor.X, corridor.Y), FloodFill(g, room.X, room.Y))
+	}
+	harmonic := func(n int) float64 {
+		sum := 0.0
+		for d := 1; d <= n; d++ {
+			sum += 1 / float64(d)
+		}
+		return sum
+	}
+	for _, tc := range []struct {
+		name    string
+		from    coordinate
+		horizon int
+		want    float64
+	}{
+		{"corridor", corridor, 0, harmonic(11)},
+		{"corridor within 3", corridor, 3, harmonic(3)},
+		{"corridor beyond its end", corridor, 20, harmonic(11)},
+		{"room", room, 0, 4 + 4.0/2},
+		{"room within 1", room, 1, 4},
+		{"outside", coordinate{-1, 0}, 0, 0},
+	} {
+		if got := WeightedReachable(g, tc.from, tc.horizon); math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("%s: got %f, want %f", tc.name, got, tc.want)
+		}
+	}
+	if WeightedReachable(g, corridor, 0) >= WeightedReachable(g, room, 0) {
+		t.Error("weighted count prefers the corridor")
+	}
+}
+
+func TestFloodFillCache(t *testing.T) {
+	r := rand.New(rand.NewSource(5))
+	c := NewFloodFillCache()
+	for i := 0; i < 100; i++ {
+		g := randomGame(r, 1+r.Intn(40), 1+r.Intn(40), r.Float64()*0.6)
+		for j := 0; j < 20; j++ {
+			x, y := r.Intn(g.Width+2)-1, r.Intn(g.Height+2)-1
+			if got, want := c.FloodFill(g, x, y), FloodFill(g, x, y); got != want {
+				t.Fatalf("%dx%d: cached flood fill from (%d, %d) is %d, want %d", g.Width, g.Height, x, y, got, want)
+			}
+		}
+	}
+	if c.Searches >= c.Lookups {
+		t.Errorf("%d searches for %d lookups", c.Searches, c.Lookups)
+	}
+
+	c.Reset()
+	g := randomGame(r, 10, 10, 0)
+	for i := 0; i < 10; i++ {
+		c.FloodFill(g, i, i)
+	}
+	// All free cells form a single region
+	if c.Searches != 1 || c.Lookups != 10 {
+		t.Errorf("%d searches for %d lookups after reset, want 1 search", c.Searches, c.Lookups)
+	}
+}
+
+// floodFillTick runs the flood fills of a round of a two player search: both heads after every combination of actions.
+// ff is the flood fill used.
+func floodFillTick(g *Game, ff func(g *Game, x, y int) int) {
+	for _, a := range Actions {
+		for _, o := range Actions {
+			c := g.Clone()
+			ApplyAction(c, 1, a)
+			ApplyAction(c, 2, o)
+			for _, p := range c.Players {
+				ff(c, p.X, p.Y)
+			}
+		}
+	}
+}
+
+// floodFillTickGame returns a 60x60 game with two players in the same region.
+func floodFillTickGame() *Game {
+	g := randomGame(rand.New(rand.NewSource(6)), 60, 60, 0.1)
+	g.Players[2] = &Player{X: 20, Y: 20, Direction: DirectionUp, Speed: 1, Active: true}
+	g.Cells[20][20] = PlayerCell(2)
+	return g
+}
+
+func BenchmarkFloodFillTick(b *testing.B) {
+	g := floodFillTickGame()
+	searches := 0
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		floodFillTick(g, func(g *Game, x, y int) int {
+			searches++
+			return FloodFill(g, x, y)
+		})
+	}
+	b.ReportMetric(float64(searches)/float64(b.N), "searches/tick")
+}
+
+func BenchmarkFloodFillCacheTick(b *testing.B) {
+	g := floodFillTickGame()
+	c := NewFloodFillCache()
+	searches := 0
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Reset()
+		floodFillTick(g, c.FloodFill)
+		searches += c.Searches
+	}
+	b.ReportMetric(float64(searches)/float64(b.N), "searches/tick")
+}
@@ -20,6 +20,8 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 )
@@ -34,7 +36,7 @@ const (
 	PlayersPerGame = 6
 	// MaxSpeed holds the maximum speed.
 	MaxSpeed = 10
-	// HolesEachStep holds after how many steps a hole might occur (if the preconditions are met).
+	// HolesEachStep holds after how many steps a hole might occur (if the preconditions are met) following the official rules (see HoleRules).
 	HolesEachStep = 6
 	// RoundTimeoutMin has the minimum time a round has (in seconds).
 	RoundTimeoutMin = 5
@@ -42,7 +44,7 @@ const (
 	RoundTimeoutMax = 15
 	// RoundTimeoutGrace has the time after which an answer is accepted even if the deadline has passed. It is a grace period for players.
 	RoundTimeoutGrace = 2
-	// HoleSpeed contains the minimum speed needed for a hole.
+	// HoleSpeed contains the minimum speed needed for a hole following the official rules (see HoleRules).
 	HoleSpeed = 3
 )
 
@@ -61,13 +63,19 @@ type Game struct {
 	Running  bool            `json:"running"`
 	Deadline string          `json:"deadline,omitempty"` // RFC3339
 
+	// Holes contains the hole rules of the game. They are not part of the protocol, so games received from a server always follow the official rules.
+	Holes HoleRules `json:"-"`
+	// FillTimes contains for every cell (FillTimes[y][x]) the round in which it was filled as observed by the client (see FillTimeTracker and Game.FillTime).
+	// It is nil unless the client tracks the fill times. Clones share it, so it must not be modified.
+	FillTimes [][]int `json:"-"`
+
 	l   sync.Mutex
-	log *Logger
+	log *GameLogger
 
 	MaxPlayer     int `json:"-"`
 	numberPlayer  int
-	playerAnswer  []string
-	playerChannel []chan string
+	playerAnswer  []Action
+	playerChannel []chan Action
 }
 
 // AddPlayer adds a player to the game. Will return ErrFullGame instead if game is full.
@@ -102,7 +110,7 @@ func (g *Game) IsReady() bool {
 }
 
 // RunGame will (completely) run a game. You have to make sure that IsReady returns true before calling this method.
-// It will return the winning player or -1 for a draw. If errors occur, this value is undefined.
+// It will return the winning player or -1 if there is no winner (a draw or an aborted game, see Game.Result). If errors occur, this value is undefined.
 func (g *Game) RunGame() (int, error) {
 	g.l.Lock()
 	defer g.l.Unlock()
@@ -111,7 +119,7 @@ func (g *Game) RunGame() (int, error) {
 	var gameID string
 	var statLock sync.Mutex
 
-	g.log, gameID, err = GetLogger()
+	g.log, gameID, err = GetGameLogger()
 	log.Println("game:", "starting", gameID)
 
 	if err != nil {
@@ -129,10 +137,15 @@ func (g *Game) RunGame() (int, error) {
 		return -100, errors.New("not enough player")
 	}
 
+	gameStarted()
+	defer gameFinished()
+
 	// Initialise
 	//// Initialise board
-	g.Width = rand.Intn(FieldMaxSize-FieldMinSize) + FieldMinSize + 1
-	g.Height = rand.Intn(FieldMaxSize-FieldMinSize) + FieldMinSize + 1
+	config := GetGameConfig()
+	g.Width = rand.Intn(config.MaxWidth-config.MinWidth+1) + config.MinWidth
+	g.Height = rand.Intn(config.MaxHeight-config.MinHeight+1) + config.MinHeight
+	g.Holes = config.Holes
 
 	g.Cells = make([][]int8, g.Height)
 	for i := range g.Cells {
@@ -140,69 +153,10 @@ func (g *Game) RunGame() (int, error) {
 	}
 
 	//// Initialise players
-	// Quadrantenphysik
-	quarterSelect := []int{0, 1, 2, 3, 4, 5, 6, 7}
-	rand.Shuffle(len(quarterSelect), func(i, j int) { quarterSelect[j], quarterSelect[i] = quarterSelect[i], quarterSelect[j] })
-
-	quarterWidth := g.Width / 4
-	quarterHeight := g.Height / 2
-
-	quarterNum := 0
-	for i := range g.Players {
-		g.Players[i].Speed = 1
-		g.Players[i].Active = true
-		x := 0
-		y := 0
-		switch quarterNum {
-		case 0:
-			x = 0
-			y = 0
-		case 1:
-			x = quarterWidth
-			y = 0
-		case 2:
-			x = quarterWidth * 2
-			y = 0
-		case 3:
-			x = quarterWidth * 3
-			y = 0
-		case 4:
-			x = 0
-			y = quarterHeight
-		case 5:
-			x = quarterWidth
-			y = quarterHeight
-		case 6:
-			x = quarterWidth * 2
-			y = quarterHeight
-		case 7:
-			x = quarterWidth
-			y = quarterHeight * 3
-		}
-		g.Players[i].X = x + rand.Intn(quarterWidth)
-		g.Players[i].Y = y + rand.Intn(quarterHeight)
-
-		g.Cells[g.Players[i].Y][g.Players[i].X] = int8(i)
-
-		switch {
-		case g.Players[i].X > g.Width/2 && g.Players[i].Y > g.Height/2:
-			g.Players[i].Direction = DirectionUp
-		case g.Players[i].X <= g.Width/2 && g.Players[i].Y > g.Height/2:
-			g.Players[i].Direction = DirectionRight
-		case g.Players[i].X > g.Width/2 && g.Players[i].Y <= g.Height/2:
-			g.Players[i].Direction = DirectionLeft
-		case g.Players[i].X <= g.Width/2 && g.Players[i].Y <= g.Height/2:
-			g.Players[i].Direction = DirectionDown
-		default:
-			// Just give some direction
-			g.Players[i].Direction = DirectionUp
-		}
-
-		quarterNum++
-	}
+	placePlayers(g)
 
 	//// Initialise game
-	g.playerChannel = make([]chan string, PlayersPerGame)
+	g.playerChannel = make([]chan Action, PlayersPerGame)
 	for i := 1; i <= g.numberPlayer; i++ {
 		g.playerChannel[i-1] = g.Players[i].Input // Used for communicating later
 	}
@@ -247,7 +201,7 @@ mainGame:
 		g.sendState()
 		deadline = deadline.Add(time.Duration(RoundTimeoutGrace) * time.Second)
 		ctx, cancel := context.WithDeadline(context.Background(), deadline)
-		g.playerAnswer = make([]string, PlayersPerGame)
+		g.playerAnswer = make([]Action, PlayersPerGame)
 	innerGame:
 		for { // Loop used for input
 			select { // IMPORTANT: This has to be changed when the number of player changes
@@ -257,7 +211,7 @@ mainGame:
 				if !ok {
 					g.invalidatePlayer(player)
 				} else if a == "" || g.playerAnswer[player-1] != "" || !IsValidAction(a) {
-					log.Printf("Invalid answer from %s (%s)", g.Players[player].api, a)
+					log.Printf("Invalid answer from %s (%s)", RedactKey(g.Players[player].api), a)
 					g.invalidatePlayer(player)
 				} else {
 					g.playerAnswer[player-1] = a
@@ -271,7 +225,7 @@ mainGame:
 				if !ok {
 					g.invalidatePlayer(player)
 				} else if a == "" || g.playerAnswer[player-1] != "" || !IsValidAction(a) {
-					log.Printf("Invalid answer from %s (%s)", g.Players[player].api, a)
+					log.Printf("Invalid answer from %s (%s)", RedactKey(g.Players[player].api), a)
 					g.invalidatePlayer(player)
 				} else {
 					g.playerAnswer[player-1] = a
@@ -285,7 +239,7 @@ mainGame:
 				if !ok {
 					g.invalidatePlayer(player)
 				} else if a == "" || g.playerAnswer[player-1] != "" || !IsValidAction(a) {
-					log.Printf("Invalid answer from %s (%s)", g.Players[player].api, a)
+					log.Printf("Invalid answer from %s (%s)", RedactKey(g.Players[player].api), a)
 					g.invalidatePlayer(player)
 				} else {
 					g.playerAnswer[player-1] = a
@@ -299,7 +253,7 @@ mainGame:
 				if !ok {
 					g.invalidatePlayer(player)
 				} else if a == "" || g.playerAnswer[player-1] != "" || !IsValidAction(a) {
-					log.Printf("Invalid answer from %s (%s)", g.Players[player].api, a)
+					log.Printf("Invalid answer from %s (%s)", RedactKey(g.Players[player].api), a)
 					g.invalidatePlayer(player)
 				} else {
 					g.playerAnswer[player-1] = a
@@ -313,7 +267,7 @@ mainGame:
 				if !ok {
 					g.invalidatePlayer(player)
 				} else if a == "" || g.playerAnswer[player-1] != "" || !IsValidAction(a) {
-					log.Printf("Invalid answer from %s (%s)", g.Players[player].api, a)
+					log.Printf("Invalid answer from %s (%s)", RedactKey(g.Players[player].api), a)
 					g.invalidatePlayer(player)
 				} else {
 					g.playerAnswer[player-1] = a
@@ -327,7 +281,7 @@ mainGame:
 				if !ok {
 					g.invalidatePlayer(player)
 				} else if a == "" || g.playerAnswer[player-1] != "" || !IsValidAction(a) {
-					log.Printf("Invalid answer from %s (%s)", g.Players[player].api, a)
+					log.Printf("Invalid answer from %s (%s)", RedactKey(g.Players[player].api), a)
 					g.invalidatePlayer(player)
 				} else {
 					g.playerAnswer[player-1] = a
@@ -337,121 +291,28 @@ mainGame:
 				}
 			case <-ctx.Done():
 				break innerGame
+			case <-abortGames:
+				cancel()
+				log.Println("game:", "aborting", gameID)
+				break mainGame
 			}
 		}
 		cancel()
 
-		// Process Actions
+		// Process actions, do movement and check crash
+		actions := make(map[int]Action, len(g.Players))
 		for i := range g.Players {
-			switch g.playerAnswer[i-1] {
-			case "":
-				g.invalidatePlayer(i)
-			case ActionTurnLeft:
-				switch g.Players[i].Direction {
-				case DirectionLeft:
-					g.Players[i].Direction = DirectionDown
-				case DirectionRight:
-					g.Players[i].Direction = DirectionUp
-				case DirectionUp:
-					g.Players[i].Direction = DirectionLeft
-				case DirectionDown:
-					g.Players[i].Direction = DirectionRight
-				}
-			case ActionTurnRight:
-				switch g.Players[i].Direction {
-				case DirectionLeft:
-					g.Players[i].Direction = DirectionUp
-				case DirectionRight:
-					g.Players[i].Direction = DirectionDown
-				case DirectionUp:
-					g.Players[i].Direction = DirectionRight
-				case DirectionDown:
-					g.Players[i].Direction = DirectionLeft
-				}
-			case ActionFaster:
-				g.Players[i].Speed++
-				if g.Players[i].Speed > MaxSpeed {
-					g.invalidatePlayer(i)
-				}
-			case ActionSlower:
-				g.Players[i].Speed--
-				if g.Players[i].Speed < 1 {
-					g.invalidatePlayer(i)
-				}
-			case ActionNOOP:
-				// Do nothing
-			default:
-				g.invalidatePlayer(i)
-			}
-		}
-
-		// Do Movement
-		for i := range g.Players {
-			if !g.Players[i].Active {
-				continue
-			}
-			var dostep func(x, y int) (int, int)
-			switch g.Players[i].Direction {
-			case DirectionUp:
-				dostep = func(x, y int) (int, int) { return x, y - 1 }
-			case DirectionDown:
-				dostep = func(x, y int) (int, int) { return x, y + 1 }
-			case DirectionLeft:
-				dostep = func(x, y int) (int, int) { return x - 1, y }
-			case DirectionRight:
-				dostep = func(x, y int) (int, int) { return x + 1, y }
-			}
-
-			g.Players[i].stepCounter++
-
-			for s := 0; s < g.Players[i].Speed; s++ {
-				g.Players[i].X, g.Players[i].Y = dostep(g.Players[i].X, g.Players[i].Y)
-				if g.Players[i].X < 0 || g.Players[i].X >= g.Width || g.Players[i].Y < 0 || g.Players[i].Y >= g.Height {
-					g.invalidatePlayer(i)
-					break
-				}
-				if g.Players[i].Speed >= HoleSpeed && g.Players[i].stepCounter%HolesEachStep == 0 && s != 0 && s != g.Players[i].Speed-1 {
-					continue
-				}
-				if g.Cells[g.Players[i].Y][g.Players[i].X] != 0 {
-					g.Cells[g.Players[i].Y][g.Players[i].X] = -1
+			actions[i] = g.playerAnswer[i-1]
+			if g.Players[i].Active {
+				if actions[i] == "" {
+					metricTimeouts.WithLabelValues(metricPlayerName(g.Players[i])).Inc()
 				} else {
-					g.Cells[g.Players[i].Y][g.Players[i].X] = int8(i)
+					metricActions.WithLabelValues(actions[i].String()).Inc()
 				}
 			}
 		}
-
-		// Check crash
-		for i := range g.Players {
-			if !g.Players[i].Active {
-				continue
-			}
-			var dostepback func(x, y int) (int, int)
-			switch g.Players[i].Direction {
-			case DirectionUp:
-				dostepback = func(x, y int) (int, int) { return x, y + 1 }
-			case DirectionDown:
-				dostepback = func(x, y int) (int, int) { return x, y - 1 }
-			case DirectionLeft:
-				dostepback = func(x, y int) (int, int) { return x + 1, y }
-			case DirectionRight:
-				dostepback = func(x, y int) (int, int) { return x - 1, y }
-			}
-
-			backX := g.Players[i].X
-			backY := g.Players[i].Y
-			for s := 0; s < g.Players[i].Speed; s++ {
-				if g.Cells[backY][backX] == -1 {
-					// Crash - check hole
-					if g.Players[i].Speed >= HoleSpeed && g.Players[i].stepCounter%HolesEachStep == 0 && s != 0 && s != g.Players[i].Speed-1 {
-						// No crash - is hole
-					} else {
-						g.invalidatePlayer(i)
-						break
-					}
-				}
-				backX, backY = dostepback(backX, backY)
-			}
+		for _, i := range resolveTick(g, actions) {
+			g.invalidatePlayer(i)
 		}
 
 		// Check end game
@@ -469,24 +330,37 @@ mainGame:
 	g.Deadline = ""
 	g.sendState()
 
+	matchResult := g.Result()
 	winner := -1
+	if matchResult.Outcome == OutcomeWin {
+		winner = matchResult.Winner
+	}
+
+	metricGamesPlayed.Inc()
 	for i := range g.Players {
-		if g.Players[i].Active {
-			winner = i
-			break
+		result := "lost"
+		if i == winner {
+			result = "won"
 		}
+		metricGameResults.WithLabelValues(metricPlayerName(g.Players[i]), result).Inc()
 	}
 
 	winnerString := "none"
+	if matchResult.Outcome == OutcomeDraw {
+		winnerString = "none (draw)"
+	}
 	if winner != -1 {
 		if g.Players[winner].underlyingAI != nil {
 			winnerString = fmt.Sprintf("#AI#-%s", g.Players[winner].underlyingAI.Name())
 		} else {
-			winnerString = fmt.Sprintf("#Player#-%s", g.Players[winner].api)
+			winnerString = fmt.Sprintf("#Player#-%s", RedactKey(g.Players[winner].api))
 		}
 	}
 
 	for i := range g.Players {
+		if g.Players[i].underlyingAI != nil {
+			notifyGameEnd(g.Players[i].underlyingAI, i == winner)
+		}
 		err := g.Players[i].Close()
 		if err != nil {
 			log.Println("closing player in game:", err)
@@ -522,6 +396,8 @@ func (g *Game) sendState() {
 	if g.log != nil {
 		g.log.LogState(g)
 	}
+
+	broadcastSpectators(g)
 }
 
 // checkEndRound checks whether the round has finished (all players have answered or are not active).
@@ -582,7 +458,8 @@ func (g *Game) MissingPlayer() int {
 // Caller has to lock the game.
 func (g *Game) setMaxPlayer() {
 	if g.MaxPlayer == 0 {
-		g.MaxPlayer = rand.Intn(PlayersPerGame-1) + 2
+		config := GetGameConfig()
+		g.MaxPlayer = rand.Intn(config.MaxPlayers-config.MinPlayers+1) + config.MinPlayers
 	}
 }
 
@@ -599,26 +476,100 @@ func (g *Game) ContainsAPI(api string) bool {
 	return false
 }
 
-// PublicCopy returns a copy of the game with all private fields set to zero.
-// As an exception for AIs, Player.stepCounter is also copied.
-func (g *Game) PublicCopy() *Game {
-	newG := Game{
-		Width:    g.Width,
-		Height:   g.Height,
-		Cells:    make([][]int8, len(g.Cells)),
-		Players:  make(map[int]*Player, len(g.Players)),
-		You:      g.You,
-		Running:  g.Running,
-		Deadline: g.Deadline,
+// DeadlineTime returns the parsed deadline of the current round.
+// The second return value is false if no (valid) deadline is set.
+func (g *Game) DeadlineTime() (time.Time, bool) {
+	if g.Deadline == "" {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, g.Deadline)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// RemainingTime returns the time until the deadline of the current round, measured with the clock of the game server (see ServerNow).
+// The second return value is false if no (valid) deadline is set.
+func (g *Game) RemainingTime() (time.Duration, bool) {
+	deadline, ok := g.DeadlineTime()
+	if !ok {
+		return 0, false
+	}
+	return deadline.Sub(ServerNow()), true
+}
+
+// Clone returns a deep copy of the game state, which can be modified independently of the original game.
+// Cells, Players (including Player.stepCounter and the position history) and all scalar fields describing the game are copied.
+// FillTimes is not copied but shared with the original game, so it must be treated as read-only.
+// Locks, logger, connections and channels are not copied, so the clone can not be used to run a game.
+func (g *Game) Clone() *Game {
+	return g.cloneInto(new(Game))
+}
+
+// clonePool contains released clones (see ReleaseClone).
+var clonePool = sync.Pool{New: func() interface{} { return new(Game) }}
+
+// AcquireClone works like Clone, but reuses the memory of a clone released with ReleaseClone if possible.
+// This reduces allocations of search code which clones the game many times per round. Every field of the reused clone is overwritten, so it is independent of its previous use.
+// Like with Clone, FillTimes is shared with g and must not be modified.
+func AcquireClone(g *Game) *Game {
+	return g.cloneInto(clonePool.Get().(*Game))
+}
+
+// ReleaseClone returns a clone acquired with AcquireClone for reuse.
+// Neither the clone nor its cells and players may be used afterwards. Releasing a game which is still used anywhere else corrupts it.
+func ReleaseClone(c *Game) {
+	if c == nil {
+		return
+	}
+	clonePool.Put(c)
+}
+
+// cloneInto makes newG a deep copy of the game (see Clone) and returns it.
+// The cell rows and players of newG are reused where possible, all other fields are reset.
+func (g *Game) cloneInto(newG *Game) *Game {
+	cells := newG.Cells
+	players := newG.Players
+	*newG = Game{
+		Width:     g.Width,
+		Height:    g.Height,
+		You:       g.You,
+		Running:   g.Running,
+		Deadline:  g.Deadline,
+		Holes:     g.Holes,
+		FillTimes: g.FillTimes,
+		MaxPlayer: g.MaxPlayer,
 	}
 
+	if cap(cells) < len(g.Cells) {
+		cells = make([][]int8, len(g.Cells))
+	}
+	cells = cells[:len(g.Cells)]
 	for i := range g.Cells {
-		newG.Cells[i] = make([]int8, len(g.Cells[i]))
-		copy(newG.Cells[i], g.Cells[i])
+		if cap(cells[i]) < len(g.Cells[i]) {
+			cells[i] = make([]int8, len(g.Cells[i]))
+		}
+		cells[i] = cells[i][:len(g.Cells[i])]
+		copy(cells[i], g.Cells[i])
 	}
+	newG.Cells = cells
 
+	if players == nil {
+		players = make(map[int]*Player, len(g.Players))
+	}
+	for k := range players {
+		if _, ok := g.Players[k]; !ok {
+			delete(players, k)
+		}
+	}
 	for k := range g.Players {
-		newG.Players[k] = &Player{
+		p := players[k]
+		if p == nil {
+			p = new(Player)
+			players[k] = p
+		}
+		*p = Player{
 			X:           g.Players[k].X,
 			Y:           g.Players[k].Y,
 			Direction:   g.Players[k].Direction,
@@ -626,7 +577,138 @@ func (g *Game) PublicCopy() *Game {
 			Active:      g.Players[k].Active,
 			Name:        g.Players[k].Name,
 			stepCounter: g.Players[k].stepCounter,
+			history:     g.Players[k].history,
+		}
+	}
+	newG.Players = players
+	return newG
+}
+
+// PublicCopy returns a copy of the game with all private fields set to zero.
+// As an exception for AIs, Player.stepCounter is also copied.
+func (g *Game) PublicCopy() *Game {
+	newG := g.Clone()
+	newG.MaxPlayer = 0
+	return newG
+}
+
+// ViewFor returns the state as seen by the given player: a copy of the game (see PublicCopy) with You set to the player.
+// Every AI must get its own view, so it can neither see the wrong You nor change the state of the game or of other AIs by modifying the copy.
+func (g *Game) ViewFor(player int) *Game {
+	newG := g.PublicCopy()
+	newG.You = player
+	return newG
+}
+
+// Validate returns an error if the game state is inconsistent, e.g. because the format of the server changed.
+// It checks that Cells has Height rows of Width cells, that You refers to an existing player and that all players have a number which fits into a cell (see MaxPlayerID) and a valid direction.
+// The speed must be within 1..MaxSpeed and the head must be on the board for active players only, since the server does not change crashed players any more.
+func (g *Game) Validate() error {
+	if g.Width < 0 || g.Height < 0 {
+		return fmt.Errorf("invalid size %dx%d", g.Width, g.Height)
+	}
+	if len(g.Cells) != g.Height {
+		return fmt.Errorf("game has height %d, but %d rows", g.Height, len(g.Cells))
+	}
+	for i := range g.Cells {
+		if len(g.Cells[i]) != g.Width {
+			return fmt.Errorf("game has width %d, but row %d has %d cells", g.Width, i, len(g.Cells[i]))
+		}
+	}
+	if _, ok := g.Players[g.You]; !ok {
+		return fmt.Errorf("player %d not in game", g.You)
+	}
+	for k, p := range g.Players {
+		if p == nil {
+			return fmt.Errorf("player %d is null", k)
 		}
+		if k < 1 || k > MaxPlayerID {
+			return fmt.Errorf("invalid player number %d", k)
+		}
+		switch p.Direction {
+		case DirectionUp, DirectionDown, DirectionLeft, DirectionRight:
+		default:
+			return fmt.Errorf("player %d: unknown direction %s", k, p.Direction)
+		}
+		if !p.Active {
+			continue
+		}
+		if p.Speed < 1 || p.Speed > MaxSpeed {
+			return fmt.Errorf("player %d: invalid speed %d", k, p.Speed)
+		}
+		if p.X < 0 || p.X >= g.Width || p.Y < 0 || p.Y >= g.Height {
+			return fmt.Errorf("player %d: position (%d,%d) outside of the game", k, p.X, p.Y)
+		}
+	}
+	return nil
+}
+
+// String returns an ASCII map of the game for debugging, using the characters of LoadScenario: '.' for free cells, the number of the player for trails, 'x' for cells of a crash and '^', 'v', '<', '>' for heads.
+// Unexpected cell values are shown as '?'. Below the map, every player is listed with position, direction and speed. Players who are out are marked as crashed.
+func (g *Game) String() string {
+	var b strings.Builder
+	b.Grow((g.Width+1)*g.Height + 64*len(g.Players))
+
+	ids := make([]int, 0, len(g.Players))
+	for id := range g.Players {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	// Several heads can share a cell (e.g. after a head-on crash), the active player with the lowest number is drawn
+	heads := make(map[coordinate]*Player, len(g.Players))
+	for _, id := range ids {
+		p := g.Players[id]
+		c := coordinate{p.X, p.Y}
+		if o, ok := heads[c]; !ok || (p.Active && !o.Active) {
+			heads[c] = p
+		}
+	}
+
+	for y := range g.Cells {
+		for x, c := range g.Cells[y] {
+			if p, ok := heads[coordinate{x, y}]; ok && c > 0 {
+				switch p.Direction {
+				case DirectionUp:
+					b.WriteByte('^')
+				case DirectionDown:
+					b.WriteByte('v')
+				case DirectionLeft:
+					b.WriteByte('<')
+				case DirectionRight:
+					b.WriteByte('>')
+				default:
+					b.WriteByte('?')
+				}
+				continue
+			}
+			switch {
+			case IsEmpty(c):
+				b.WriteByte('.')
+			case c == CellCrash:
+				b.WriteByte('x')
+			case c > 0 && c <= 9:
+				b.WriteByte(byte('0' + c))
+			default:
+				b.WriteByte('?')
+			}
+		}
+		b.WriteByte('\n')
+	}
+
+	for _, id := range ids {
+		p := g.Players[id]
+		fmt.Fprintf(&b, "%d: (%d, %d) %s speed %d", id, p.X, p.Y, p.Direction, p.Speed)
+		if id == g.You {
+			b.WriteString(" (you)")
+		}
+		if !p.Active {
+			b.WriteString(" crashed")
+		}
+		b.WriteByte('\n')
+	}
+	if !g.Running {
+		b.WriteString("game ended\n")
 	}
-	return &newG
+	return b.String()
 }
@@ -0,0 +1,124 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestGameString(t *testing.T) {
+	grid := []string{
+		"11111...",
+		"....v...",
+		"..x.....",
+		"..<2222.",
+		"........",
+		"....3>..",
+	}
+	g := testScenario(t, `{"you": 2, "grid": ["`+strings.Join(grid, `", "`)+`"], "players": {"1": {"speed": 2}, "2": {}, "3": {"active": false}}}`)
+	want := strings.Join(grid, "\n") + "\n" +
+		"1: (4, 1) down speed 2\n" +
+		"2: (2, 3) left speed 1 (you)\n" +
+		"3: (5, 5) right speed 1 crashed\n"
+	if got := g.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	g.Running = false
+	g.Cells[4][0] = 42
+	got := g.String()
+	if lines := strings.Split(got, "\n"); lines[4] != "?......." {
+		t.Errorf("unexpected cell shown as %q", lines[4])
+	}
+	if !strings.HasSuffix(got, "crashed\ngame ended\n") {
+		t.Errorf("ended game shown as\n%s", got)
+	}
+}
+
+func TestGameStringSharedHead(t *testing.T) {
+	g := testScenario(t, `{"you": 1, "grid": ["....", ".^..", ".1..", "..<2", "3>.."], "players": {"1": {}, "2": {}, "3": {}}}`)
+	for id, d := range map[int]Direction{2: DirectionDown, 3: DirectionRight} {
+		g.Players[id].X, g.Players[id].Y, g.Players[id].Direction = 1, 1, d
+	}
+	for _, tc := range []struct {
+		inactive []int
+		want     string
+	}{
+		{nil, "^"},
+		{[]int{1}, "v"},
+		{[]int{1, 2}, ">"},
+		{[]int{1, 2, 3}, "^"},
+	} {
+		for _, id := range tc.inactive {
+			g.Players[id].Active = false
+		}
+		for i := 0; i < 20; i++ {
+			if got := strings.SplitN(g.String(), "\n", 3)[1][1:2]; got != tc.want {
+				t.Fatalf("inactive %v: head shown as %s, want %s", tc.inactive, got, tc.want)
+			}
+		}
+	}
+}
+
+func TestGameStringWide(t *testing.T) {
+	g := testScenario(t, `{"you": 1, "grid": ["1>`+strings.Repeat(".", FieldMaxSize-2)+`"], "players": {"1": {}}}`)
+	row := strings.SplitN(g.String(), "\n", 2)[0]
+	if len(row) != FieldMaxSize {
+		t.Errorf("row of %d cells shown with %d characters", FieldMaxSize, len(row))
+	}
+}
+
+func TestGameValidate(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		change func(g *Game)
+		valid  bool
+	}{
+		{"recorded state", func(g *Game) {}, true},
+		{"negative width", func(g *Game) { g.Width = -1 }, false},
+		{"missing row", func(g *Game) { g.Cells = g.Cells[:g.Height-1] }, false},
+		{"extra row", func(g *Game) { g.Cells = append(g.Cells, make([]int8, g.Width)) }, false},
+		{"short row", func(g *Game) { g.Cells[3] = g.Cells[3][:g.Width-1] }, false},
+		{"long row", func(g *Game) { g.Cells[7] = append(g.Cells[7], 0) }, false},
+		{"you not in game", func(g *Game) { g.You = 4 }, false},
+		{"null player", func(g *Game) { g.Players[2] = nil }, false},
+		{"player 0", func(g *Game) { g.Players[0] = g.Players[2] }, false},
+		{"player number too large", func(g *Game) { g.Players[MaxPlayerID+1] = g.Players[2] }, false},
+		{"unknown direction", func(g *Game) { g.Players[2].Direction = "diagonal" }, false},
+		{"unknown direction of a crashed player", func(g *Game) { g.Players[3].Direction = "" }, false},
+		{"speed 0", func(g *Game) { g.Players[1].Speed = 0 }, false},
+		{"speed too high", func(g *Game) { g.Players[2].Speed = MaxSpeed + 1 }, false},
+		{"head outside", func(g *Game) { g.Players[2].X = g.Width }, false},
+		{"negative head", func(g *Game) { g.Players[1].Y = -1 }, false},
+		{"crashed player outside", func(g *Game) { g.Players[3].X, g.Players[3].Speed = -1, 0 }, true},
+	} {
+		var g Game
+		err := json.Unmarshal(serverMessage(t), &g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tc.change(&g)
+		err = g.Validate()
+		if tc.valid && err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("%s: no error", tc.name)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+)
+
+// GameConfig contains the configuration of the games hosted by the server.
+// All ranges are inclusive, the size and number of players of every game are chosen uniformly from them.
+type GameConfig struct {
+	MinWidth, MaxWidth   int
+	MinHeight, MaxHeight int
+	// MinPlayers and MaxPlayers must be between 2 and PlayersPerGame.
+	MinPlayers, MaxPlayers int
+	// Holes contains the hole rules of all games. The zero value contains the official rules.
+	Holes HoleRules
+}
+
+// DefaultGameConfig contains the configuration of the official server.
+// Like on the official server, width and height are larger than FieldMinSize and at most FieldMaxSize.
+var DefaultGameConfig = GameConfig{
+	MinWidth:   FieldMinSize + 1,
+	MaxWidth:   FieldMaxSize,
+	MinHeight:  FieldMinSize + 1,
+	MaxHeight:  FieldMaxSize,
+	MinPlayers: 2,
+	MaxPlayers: PlayersPerGame,
+}
+
+// gameConfigMinSize contains the minimum width and height of a game, so that every player gets its own start region (see placePlayers).
+const gameConfigMinSize = 4
+
+var gameConfig = DefaultGameConfig
+var gameConfigLock sync.RWMutex
+
+// Validate returns an error if the configuration can not be used.
+func (c GameConfig) Validate() error {
+	if c.MinWidth < gameConfigMinSize || c.MinHeight < gameConfigMinSize {
+		return fmt.Errorf("game config: width and height must be at least %d", gameConfigMinSize)
+	}
+	if c.MaxWidth < c.MinWidth || c.MaxHeight < c.MinHeight {
+		return fmt.Errorf("game config: invalid size range %d-%dx%d-%d", c.MinWidth, c.MaxWidth, c.MinHeight, c.MaxHeight)
+	}
+	if c.MinPlayers < 2 || c.MaxPlayers > PlayersPerGame || c.MaxPlayers < c.MinPlayers {
+		return fmt.Errorf("game config: invalid player range %d-%d (must be between 2 and %d)", c.MinPlayers, c.MaxPlayers, PlayersPerGame)
+	}
+	err := c.Holes.Validate()
+	if err != nil {
+		return fmt.Errorf("game config: %w", err)
+	}
+	return nil
+}
+
+// SetGameConfig sets the configuration used for all games created afterwards.
+func SetGameConfig(c GameConfig) error {
+	err := c.Validate()
+	if err != nil {
+		return err
+	}
+	gameConfigLock.Lock()
+	defer gameConfigLock.Unlock()
+	gameConfig = c
+	return nil
+}
+
+// GetGameConfig returns the configuration set by SetGameConfig (or DefaultGameConfig).
+func GetGameConfig() GameConfig {
+	gameConfigLock.RLock()
+	defer gameConfigLock.RUnlock()
+	return gameConfig
+}
+
+// placePlayers places all players of the game at random, non-overlapping start positions with speed 1.
+// The board is split into 4x2 regions (2x4 for boards higher than wide), each player gets a random region of its own and starts at a random cell inside it, keeping a distance of one cell to the border of the region if possible.
+// Every player faces one of the two directions pointing towards the centre of the board, chosen at random.
+// The size of the game must be set and the cells must be empty.
+func placePlayers(g *Game) {
+	columns, rows := 4, 2
+	if g.Height > g.Width {
+		columns, rows = 2, 4
+	}
+	regionWidth := g.Width / columns
+	regionHeight := g.Height / rows
+
+	regions := rand.Perm(columns * rows)
+	ids := make([]int, 0, len(g.Players))
+	for i := 1; i <= g.numberPlayer; i++ {
+		if _, ok := g.Players[i]; ok {
+			ids = append(ids, i)
+		}
+	}
+
+	position := func(start, size int) int {
+		if size >= 3 {
+			return start + 1 + rand.Intn(size-2)
+		}
+		return start + rand.Intn(size)
+	}
+
+	for n, i := range ids {
+		p := g.Players[i]
+		p.Speed = 1
+		p.Active = true
+
+		region := regions[n%len(regions)]
+		p.X = position((region%columns)*regionWidth, regionWidth)
+		p.Y = position((region/columns)*regionHeight, regionHeight)
+		g.Cells[p.Y][p.X] = PlayerCell(i)
+
+		horizontal := DirectionRight
+		if p.X >= g.Width/2 {
+			horizontal = DirectionLeft
+		}
+		vertical := DirectionDown
+		if p.Y >= g.Height/2 {
+			vertical = DirectionUp
+		}
+		if rand.Intn(2) == 0 {
+			p.Direction = horizontal
+		} else {
+			p.Direction = vertical
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "testing"
+
+func TestDefaultGameConfig(t *testing.T) {
+	c := DefaultGameConfig
+	if c.MinWidth != 41 || c.MaxWidth != 80 || c.MinHeight != 41 || c.MaxHeight != 80 {
+		t.Errorf("default size range %d-%dx%d-%d, want 41-80x41-80", c.MinWidth, c.MaxWidth, c.MinHeight, c.MaxHeight)
+	}
+	if err := c.Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGameConfigValidate(t *testing.T) {
+	for name, c := range map[string]GameConfig{
+		"too small":     {MinWidth: 3, MaxWidth: 10, MinHeight: 4, MaxHeight: 10, MinPlayers: 2, MaxPlayers: 2},
+		"width range":   {MinWidth: 10, MaxWidth: 9, MinHeight: 4, MaxHeight: 10, MinPlayers: 2, MaxPlayers: 2},
+		"height range":  {MinWidth: 4, MaxWidth: 10, MinHeight: 11, MaxHeight: 10, MinPlayers: 2, MaxPlayers: 2},
+		"one player":    {MinWidth: 4, MaxWidth: 10, MinHeight: 4, MaxHeight: 10, MinPlayers: 1, MaxPlayers: 2},
+		"seven players": {MinWidth: 4, MaxWidth: 10, MinHeight: 4, MaxHeight: 10, MinPlayers: 2, MaxPlayers: 7},
+		"player range":  {MinWidth: 4, MaxWidth: 10, MinHeight: 4, MaxHeight: 10, MinPlayers: 3, MaxPlayers: 2},
+		"holes":         {MinWidth: 4, MaxWidth: 10, MinHeight: 4, MaxHeight: 10, MinPlayers: 2, MaxPlayers: 2, Holes: HoleRules{Speed: -1}},
+	} {
+		if c.Validate() == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+	if err := (GameConfig{MinWidth: 4, MaxWidth: 4, MinHeight: 4, MaxHeight: 4, MinPlayers: 6, MaxPlayers: 6}).Validate(); err != nil {
+		t.Errorf("smallest game rejected: %v", err)
+	}
+}
+
+func TestPlacePlayers(t *testing.T) {
+	for _, size := range [][2]int{{4, 4}, {41, 41}, {80, 41}, {41, 80}, {80, 80}} {
+		for players := 2; players <= PlayersPerGame; players++ {
+			for run := 0; run < 20; run++ {
+				g := &Game{Width: size[0], Height: size[1], Players: make(map[int]*Player), Cells: make([][]int8, size[1])}
+				for y := range g.Cells {
+					g.Cells[y] = make([]int8, size[0])
+				}
+				for i := 1; i <= players; i++ {
+					g.Players[i] = new(Player)
+				}
+				g.numberPlayer = players
+				placePlayers(g)
+
+				minDistance := g.Width + g.Height
+				for i, p := range g.Players {
+					if p.X < 0 || p.X >= g.Width || p.Y < 0 || p.Y >= g.Height || g.Cells[p.Y][p.X] != PlayerCell(i) || !p.Active || p.Speed != 1 {
+						t.Fatalf("%dx%d: player %d at (%d, %d) not placed", g.Width, g.Height, i, p.X, p.Y)
+					}
+					towards := (p.Direction == DirectionRight && p.X < g.Width/2) || (p.Direction == DirectionLeft && p.X >= g.Width/2) ||
+						(p.Direction == DirectionDown && p.Y < g.Height/2) || (p.Direction == DirectionUp && p.Y >= g.Height/2)
+					if !towards {
+						t.Fatalf("%dx%d: player %d at (%d, %d) faces %s, away from the centre", g.Width, g.Height, i, p.X, p.Y, p.Direction)
+					}
+					for j, o := range g.Players {
+						if i != j {
+							if d := abs(p.X-o.X) + abs(p.Y-o.Y); d < minDistance {
+								minDistance = d
+							}
+						}
+					}
+				}
+				// Players start in different regions, keeping a distance of one cell to the border of the region
+				if minDistance < 1 || (g.Width >= 40 && g.Height >= 40 && minDistance < 3) {
+					t.Fatalf("%dx%d: players %d cells apart", g.Width, g.Height, minDistance)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// ready is 1 while the server accepts games or the client is connected to a game (see setReady).
+	ready int32
+
+	serviceMuxLock sync.Mutex
+	serviceMuxes   = make(map[string]*http.ServeMux)
+)
+
+// InitHealth starts serving the health endpoints at the given address, which may be the same as the one given to InitMetrics.
+// /healthz always answers with 200 OK once the process is up.
+// /readyz answers with 200 OK while the server accepts games or the client is connected to a game and with 503 Service Unavailable otherwise, including while the server is shutting down.
+func InitHealth(address string) {
+	mux := serviceMux(address)
+	mux.HandleFunc("/healthz", healthEndpoint)
+	mux.HandleFunc("/readyz", readyEndpoint)
+}
+
+// setReady sets whether the process is ready (see InitHealth).
+func setReady(r bool) {
+	var v int32
+	if r {
+		v = 1
+	}
+	atomic.StoreInt32(&ready, v)
+}
+
+// healthEndpoint answers every request with 200 OK.
+func healthEndpoint(rw http.ResponseWriter, r *http.Request) {
+	rw.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	rw.Write([]byte("ok\n"))
+}
+
+// readyEndpoint answers with 200 OK if the process is ready and with 503 Service Unavailable otherwise.
+func readyEndpoint(rw http.ResponseWriter, r *http.Request) {
+	rw.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	if atomic.LoadInt32(&ready) != 1 || isShuttingDown() {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+		rw.Write([]byte("not ready\n"))
+		return
+	}
+	rw.Write([]byte("ready\n"))
+}
+
+// serviceMux returns the mux of the operational endpoints (metrics and health) at the given address.
+// The address is served with the first call, later calls with the same address share the mux.
+func serviceMux(address string) *http.ServeMux {
+	serviceMuxLock.Lock()
+	defer serviceMuxLock.Unlock()
+
+	mux, ok := serviceMuxes[address]
+	if ok {
+		return mux
+	}
+	mux = http.NewServeMux()
+	serviceMuxes[address] = mux
+	go func() {
+		log.Println("service endpoints:", http.ListenAndServe(address, mux))
+	}()
+	return mux
+}
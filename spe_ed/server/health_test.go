@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHealthEndpoints(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := l.Addr().String()
+	l.Close()
+	t.Cleanup(func() { setReady(false) })
+
+	InitHealth(address)
+	InitMetrics(address)
+
+	get := func(path string) int {
+		t.Helper()
+		var resp *http.Response
+		var err error
+		// The endpoints are served in the background
+		for start := time.Now(); time.Since(start) < 2*time.Second; time.Sleep(10 * time.Millisecond) {
+			resp, err = http.Get("http://" + address + path)
+			if err == nil {
+				break
+			}
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	setReady(false)
+	if code := get("/healthz"); code != http.StatusOK {
+		t.Errorf("/healthz: got %d, want %d", code, http.StatusOK)
+	}
+	if code := get("/readyz"); code != http.StatusServiceUnavailable {
+		t.Errorf("/readyz when not ready: got %d, want %d", code, http.StatusServiceUnavailable)
+	}
+	setReady(true)
+	if code := get("/readyz"); code != http.StatusOK {
+		t.Errorf("/readyz when ready: got %d, want %d", code, http.StatusOK)
+	}
+	atomic.StoreInt32(&shuttingDown, 1)
+	code := get("/readyz")
+	atomic.StoreInt32(&shuttingDown, 0)
+	if code != http.StatusServiceUnavailable {
+		t.Errorf("/readyz while shutting down: got %d, want %d", code, http.StatusServiceUnavailable)
+	}
+	if code := get("/metrics"); code != http.StatusOK {
+		t.Errorf("/metrics on the same address: got %d, want %d", code, http.StatusOK)
+	}
+}
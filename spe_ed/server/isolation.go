@@ -0,0 +1,59 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+// Isolated returns whether the free region reachable from the head of the player is disconnected from the heads of all other active players.
+// A region is connected to an opponent if the head of the opponent is next to a reachable cell or next to the head of the player.
+// Holes, which allow jumping over a trail, are not considered. An unknown or inactive player is not isolated.
+func Isolated(g *Game, player int) bool {
+	p, ok := g.Players[player]
+	if !ok || !p.Active {
+		return false
+	}
+
+	b := NewBitboard(g)
+	reached := NewEmptyBitboard(g.Width, g.Height)
+	if b.InBounds(p.X, p.Y) {
+		reached.Set(p.X, p.Y)
+	}
+	visited := b.Clone()
+	queue := []coordinate{{p.X, p.Y}}
+	for len(queue) != 0 {
+		c := queue[0]
+		queue = queue[1:]
+		for _, n := range [4]coordinate{{c.X + 1, c.Y}, {c.X - 1, c.Y}, {c.X, c.Y + 1}, {c.X, c.Y - 1}} {
+			if visited.IsOccupied(n.X, n.Y) {
+				continue
+			}
+			visited.Set(n.X, n.Y)
+			reached.Set(n.X, n.Y)
+			queue = append(queue, n)
+		}
+	}
+
+	for k := range g.Players {
+		o := g.Players[k]
+		if k == player || !o.Active {
+			continue
+		}
+		for _, n := range [4]coordinate{{o.X + 1, o.Y}, {o.X - 1, o.Y}, {o.X, o.Y + 1}, {o.X, o.Y - 1}} {
+			if reached.InBounds(n.X, n.Y) && reached.IsOccupied(n.X, n.Y) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -67,7 +67,7 @@ func InitKeys(filename string) {
 // Keys are loaded from "./keys"
 func ClaimKey(key string) int {
 	if key == "" {
-		log.Println("keys:", "invalid", key)
+		log.Println("keys:", "invalid", RedactKey(key))
 		return KeyInvalid
 	}
 
@@ -76,15 +76,15 @@ func ClaimKey(key string) int {
 
 	available, ok := keymap[key]
 	if !ok {
-		log.Println("keys:", "invalid", key)
+		log.Println("keys:", "invalid", RedactKey(key))
 		return KeyInvalid
 	}
 	if available == 0 {
-		log.Println("keys:", "ratelimit", key)
+		log.Println("keys:", "ratelimit", RedactKey(key))
 		return KeyRateLimit
 	}
 	keymap[key] = available - 1
-	log.Println("keys:", "ok", key)
+	log.Println("keys:", "ok", RedactKey(key))
 	return KeyOK
 }
 
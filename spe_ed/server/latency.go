@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// LatencyEstimatorWeight contains the weight of a new measurement in the moving average of LatencyEstimator.
+	LatencyEstimatorWeight = 0.25
+	// LatencyEstimatorMax contains the maximum latency estimated by LatencyEstimator, so a single slow round trip can not use up the whole round.
+	LatencyEstimatorMax = 500 * time.Millisecond
+)
+
+// EffectiveDeadline returns the time until which an AI may compute its answer.
+// Both the safety margin and the estimated latency of the answer on its way to the server are subtracted from the deadline of the round.
+// Negative values are treated as zero.
+func EffectiveDeadline(deadline time.Time, margin, latency time.Duration) time.Time {
+	if margin < 0 {
+		margin = 0
+	}
+	if latency < 0 {
+		latency = 0
+	}
+	return deadline.Add(-margin - latency)
+}
+
+// LatencyEstimator estimates the time a message needs to reach the server as half of the measured round trip times.
+// The estimate is an exponential moving average (see LatencyEstimatorWeight) limited to LatencyEstimatorMax. It is zero until the first measurement.
+// It is safe for concurrent use.
+type LatencyEstimator struct {
+	l       sync.Mutex
+	latency time.Duration
+	samples int
+}
+
+// Observe adds a measured round trip time. Negative values are ignored.
+func (e *LatencyEstimator) Observe(rtt time.Duration) {
+	if rtt < 0 {
+		return
+	}
+	e.l.Lock()
+	defer e.l.Unlock()
+
+	latency := rtt / 2
+	if e.samples == 0 {
+		e.latency = latency
+	} else {
+		e.latency = time.Duration(LatencyEstimatorWeight*float64(latency) + (1-LatencyEstimatorWeight)*float64(e.latency))
+	}
+	e.samples++
+}
+
+// Latency returns the current estimate.
+func (e *LatencyEstimator) Latency() time.Duration {
+	e.l.Lock()
+	defer e.l.Unlock()
+
+	if e.latency > LatencyEstimatorMax {
+		return LatencyEstimatorMax
+	}
+	return e.latency
+}
+
+// Samples returns the number of measurements.
+func (e *LatencyEstimator) Samples() int {
+	e.l.Lock()
+	defer e.l.Unlock()
+
+	return e.samples
+}
@@ -0,0 +1,63 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEffectiveDeadline(t *testing.T) {
+	deadline := time.Date(2021, 1, 17, 13, 47, 21, 0, time.UTC)
+	for _, tc := range []struct {
+		margin, latency, want time.Duration
+	}{
+		{0, 0, 0},
+		{200 * time.Millisecond, 0, 200 * time.Millisecond},
+		{150 * time.Millisecond, 40 * time.Millisecond, 190 * time.Millisecond},
+		{-time.Second, 40 * time.Millisecond, 40 * time.Millisecond},
+		{150 * time.Millisecond, -time.Second, 150 * time.Millisecond},
+	} {
+		if got := deadline.Sub(EffectiveDeadline(deadline, tc.margin, tc.latency)); got != tc.want {
+			t.Errorf("margin %s, latency %s: deadline moved by %s, want %s", tc.margin, tc.latency, got, tc.want)
+		}
+	}
+}
+
+func TestLatencyEstimator(t *testing.T) {
+	var e LatencyEstimator
+	if e.Latency() != 0 || e.Samples() != 0 {
+		t.Fatalf("new estimator: latency %s, %d samples", e.Latency(), e.Samples())
+	}
+	e.Observe(20 * time.Millisecond)
+	if e.Latency() != 10*time.Millisecond {
+		t.Errorf("first round trip: latency %s, want 10ms", e.Latency())
+	}
+	e.Observe(60 * time.Millisecond)
+	if want := time.Duration(LatencyEstimatorWeight*float64(30*time.Millisecond) + (1-LatencyEstimatorWeight)*float64(10*time.Millisecond)); e.Latency() != want {
+		t.Errorf("second round trip: latency %s, want %s", e.Latency(), want)
+	}
+	e.Observe(-time.Second)
+	if e.Samples() != 2 {
+		t.Errorf("negative round trip counted, %d samples", e.Samples())
+	}
+	for i := 0; i < 50; i++ {
+		e.Observe(time.Minute)
+	}
+	if e.Latency() != LatencyEstimatorMax {
+		t.Errorf("slow round trips: latency %s, want %s", e.Latency(), LatencyEstimatorMax)
+	}
+}
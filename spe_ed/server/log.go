@@ -45,17 +45,17 @@ type playerLog struct {
 	AI        string
 }
 
-// Logger allows for games to be saved to a lz4-compressed file, thus making them analyseable later.
-type Logger struct {
+// GameLogger allows for games to be saved to a lz4-compressed file, thus making them analyseable later.
+type GameLogger struct {
 	file   *os.File
 	w      *lz4.Writer
 	data   chan []byte
 	closed bool
 }
 
-// GetLogger returns a logger and a game name to log a game to. All actions are saved in a lz4-compressed file.
+// GetGameLogger returns a logger and a game name to log a game to. All actions are saved in a lz4-compressed file.
 // If disableLogging is set to true, logger is nil.
-func GetLogger() (*Logger, string, error) {
+func GetGameLogger() (*GameLogger, string, error) {
 	prefix := make([]byte, 10)
 	rand.Read(prefix)
 	id := base32.StdEncoding.EncodeToString(prefix)
@@ -66,7 +66,7 @@ func GetLogger() (*Logger, string, error) {
 	filename = filepath.Join(logPath, filename)
 
 	var err error
-	l := new(Logger)
+	l := new(GameLogger)
 
 	l.file, err = os.Create(filename)
 	if err != nil {
@@ -80,7 +80,7 @@ func GetLogger() (*Logger, string, error) {
 
 // LogPlayer writes the player map to the log file.
 // Should be called once in the beginning.
-func (l *Logger) LogPlayer(p map[int]*Player) {
+func (l *GameLogger) LogPlayer(p map[int]*Player) {
 	metadata := make(map[int]playerLog)
 
 	for k, v := range p {
@@ -105,7 +105,7 @@ func (l *Logger) LogPlayer(p map[int]*Player) {
 }
 
 // LogState writes the game state to the log file.
-func (l *Logger) LogState(g *Game) {
+func (l *GameLogger) LogState(g *Game) {
 	if l.closed {
 		log.Println("logger: writing while closed")
 		return
@@ -119,14 +119,14 @@ func (l *Logger) LogState(g *Game) {
 }
 
 // Close closes the log file.
-func (l *Logger) Close() {
+func (l *GameLogger) Close() {
 	if !l.closed {
 		close(l.data)
 		l.closed = true
 	}
 }
 
-func (l *Logger) worker() {
+func (l *GameLogger) worker() {
 	for b := range l.data {
 		if l.w == nil {
 			// Invalid logger - ignore
@@ -0,0 +1,223 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	golog "log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// LogFormatText contains the log format writing lines with date and time like the package log of the standard library (see NewTextLogger).
+	LogFormatText = "text"
+	// LogFormatJSON contains the log format writing one JSON object per line (see NewJSONLogger).
+	LogFormatJSON = "json"
+)
+
+// Logger is the interface of the logger used by the server, the client and the AIs (see SetLogger).
+// Implementations must be safe for concurrent use.
+type Logger interface {
+	// Printf logs a message at LogLevelInfo. The arguments are handled like in fmt.Printf.
+	Printf(format string, v ...interface{})
+	// Println logs a message at LogLevelInfo. The arguments are handled like in fmt.Println.
+	Println(v ...interface{})
+	// Debugf logs a message at LogLevelDebug. The arguments are handled like in fmt.Printf.
+	Debugf(format string, v ...interface{})
+	// DebugEnabled returns whether messages at LogLevelDebug are logged, so expensive messages can be skipped.
+	DebugEnabled() bool
+}
+
+// log is the logger of the program. It starts with the same output as the default of main.
+var log = newLoggerSwitch(NewTextLogger(os.Stdout, "spe_ed server ", LogLevelInfo))
+
+// SetLogger sets the logger used by the server, the client and the AIs. A nil logger discards all messages (see NopLogger).
+// It is safe to call while other goroutines are logging.
+func SetLogger(l Logger) {
+	if l == nil {
+		l = NopLogger{}
+	}
+	log.v.Store(loggerBox{l})
+}
+
+// ValidateLogFormat returns an error if the format is not LogFormatText or LogFormatJSON.
+func ValidateLogFormat(format string) error {
+	switch format {
+	case LogFormatText, LogFormatJSON:
+		return nil
+	default:
+		return fmt.Errorf("unknown log format %q (must be %s or %s)", format, LogFormatText, LogFormatJSON)
+	}
+}
+
+// NewLogger returns a logger for the given format (see ValidateLogFormat) and level (see ValidateLogLevel) writing to w.
+// prefix is only used by LogFormatText. LogLevelNone returns NopLogger.
+func NewLogger(format string, w io.Writer, prefix, level string) (Logger, error) {
+	err := ValidateLogFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	err = ValidateLogLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	if format == LogFormatJSON {
+		return NewJSONLogger(w, level), nil
+	}
+	return NewTextLogger(w, prefix, level), nil
+}
+
+// NewTextLogger returns a logger writing every message as a line starting with prefix, date and time to w, like the package log of the standard library.
+// Messages at LogLevelDebug are only written if level is LogLevelDebug. LogLevelNone returns NopLogger.
+func NewTextLogger(w io.Writer, prefix, level string) Logger {
+	if level == LogLevelNone {
+		return NopLogger{}
+	}
+	return &textLogger{l: golog.New(w, prefix, golog.LstdFlags), debug: level == LogLevelDebug}
+}
+
+type textLogger struct {
+	l     *golog.Logger
+	debug bool
+}
+
+func (t *textLogger) Printf(format string, v ...interface{}) {
+	t.l.Printf(format, v...)
+}
+
+func (t *textLogger) Println(v ...interface{}) {
+	t.l.Println(v...)
+}
+
+func (t *textLogger) Debugf(format string, v ...interface{}) {
+	if t.debug {
+		t.l.Printf("debug: "+format, v...)
+	}
+}
+
+func (t *textLogger) DebugEnabled() bool {
+	return t.debug
+}
+
+// NewJSONLogger returns a logger writing every message as a JSON object like {"time": "2021-01-01T12:00:00Z", "level": "info", "message": "..."} on its own line to w.
+// Messages at LogLevelDebug are only written if level is LogLevelDebug. LogLevelNone returns NopLogger.
+func NewJSONLogger(w io.Writer, level string) Logger {
+	if level == LogLevelNone {
+		return NopLogger{}
+	}
+	return &jsonLogger{e: json.NewEncoder(w), debug: level == LogLevelDebug}
+}
+
+type jsonLogger struct {
+	l     sync.Mutex
+	e     *json.Encoder
+	debug bool
+}
+
+// jsonLogEntry represents a single message written by NewJSONLogger.
+type jsonLogEntry struct {
+	Time    time.Time `json:"time"`
+	Level   string    `json:"level"`
+	Message string    `json:"message"`
+}
+
+func (j *jsonLogger) write(level, message string) {
+	j.l.Lock()
+	defer j.l.Unlock()
+	// There is nothing sensible to do if the log can not be written
+	j.e.Encode(jsonLogEntry{Time: time.Now(), Level: level, Message: message})
+}
+
+func (j *jsonLogger) Printf(format string, v ...interface{}) {
+	j.write(LogLevelInfo, fmt.Sprintf(format, v...))
+}
+
+func (j *jsonLogger) Println(v ...interface{}) {
+	s := fmt.Sprintln(v...)
+	j.write(LogLevelInfo, s[:len(s)-1])
+}
+
+func (j *jsonLogger) Debugf(format string, v ...interface{}) {
+	if j.debug {
+		j.write(LogLevelDebug, fmt.Sprintf(format, v...))
+	}
+}
+
+func (j *jsonLogger) DebugEnabled() bool {
+	return j.debug
+}
+
+// NopLogger is a logger discarding all messages, e.g. for tests.
+type NopLogger struct{}
+
+// Printf does nothing.
+func (NopLogger) Printf(format string, v ...interface{}) {}
+
+// Println does nothing.
+func (NopLogger) Println(v ...interface{}) {}
+
+// Debugf does nothing.
+func (NopLogger) Debugf(format string, v ...interface{}) {}
+
+// DebugEnabled returns false.
+func (NopLogger) DebugEnabled() bool { return false }
+
+// loggerBox wraps a Logger, since atomic.Value needs values of the same concrete type.
+type loggerBox struct {
+	Logger
+}
+
+// loggerSwitch forwards all messages to the logger set by SetLogger.
+type loggerSwitch struct {
+	v atomic.Value
+}
+
+func newLoggerSwitch(l Logger) *loggerSwitch {
+	s := new(loggerSwitch)
+	s.v.Store(loggerBox{l})
+	return s
+}
+
+func (s *loggerSwitch) current() Logger {
+	return s.v.Load().(loggerBox).Logger
+}
+
+func (s *loggerSwitch) Printf(format string, v ...interface{}) {
+	s.current().Printf(format, v...)
+}
+
+func (s *loggerSwitch) Println(v ...interface{}) {
+	s.current().Println(v...)
+}
+
+func (s *loggerSwitch) Debugf(format string, v ...interface{}) {
+	s.current().Debugf(format, v...)
+}
+
+func (s *loggerSwitch) DebugEnabled() bool {
+	return s.current().DebugEnabled()
+}
+
+// Fatal logs the message at LogLevelInfo and exits the program with status 1.
+func (s *loggerSwitch) Fatal(v ...interface{}) {
+	s.current().Println(v...)
+	os.Exit(1)
+}
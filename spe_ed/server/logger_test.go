@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNopLogger(t *testing.T) {
+	oldLogger := log.current()
+	t.Cleanup(func() { SetLogger(oldLogger) })
+
+	for _, l := range []Logger{nil, NopLogger{}} {
+		var buf bytes.Buffer
+		SetLogger(NewTextLogger(&buf, "", LogLevelDebug))
+		SetLogger(l)
+		log.Println("println")
+		log.Printf("printf %d", 1)
+		log.Debugf("debugf %d", 2)
+		if log.DebugEnabled() || buf.Len() != 0 {
+			t.Errorf("%T: debug enabled %t, logged %q", l, log.DebugEnabled(), buf.String())
+		}
+	}
+	for _, format := range []string{LogFormatText, LogFormatJSON} {
+		l, err := NewLogger(format, new(bytes.Buffer), "", LogLevelNone)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := l.(NopLogger); !ok {
+			t.Errorf("%s: got %T for LogLevelNone, want NopLogger", format, l)
+		}
+	}
+}
+
+func TestLoggerLevels(t *testing.T) {
+	for _, format := range []string{LogFormatText, LogFormatJSON} {
+		for _, level := range []string{LogLevelInfo, LogLevelDebug} {
+			var buf bytes.Buffer
+			l, err := NewLogger(format, &buf, "test ", level)
+			if err != nil {
+				t.Fatal(err)
+			}
+			l.Println("println", 1)
+			l.Printf("printf %d", 2)
+			l.Debugf("debugf %d", 3)
+			lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+			want := []string{"println 1", "printf 2"}
+			if level == LogLevelDebug {
+				want = append(want, "debugf 3")
+			}
+			if len(lines) != len(want) || l.DebugEnabled() != (level == LogLevelDebug) {
+				t.Fatalf("%s at %s: debug enabled %t, got\n%s", format, level, l.DebugEnabled(), buf.String())
+			}
+			for i := range lines {
+				if format == LogFormatJSON {
+					var e jsonLogEntry
+					err := json.Unmarshal([]byte(lines[i]), &e)
+					if err != nil {
+						t.Fatalf("%s at %s: line %q: %v", format, level, lines[i], err)
+					}
+					if e.Message != want[i] || e.Time.IsZero() {
+						t.Errorf("%s at %s: got message %q at %s, want %q", format, level, e.Message, e.Time, want[i])
+					}
+				} else if !strings.HasPrefix(lines[i], "test ") || !strings.HasSuffix(lines[i], want[i]) {
+					t.Errorf("%s at %s: got %q, want %q", format, level, lines[i], want[i])
+				}
+			}
+		}
+	}
+}
@@ -15,19 +15,19 @@
 
 // This package contains the server for the game "spe_ed".
 //
-// Prequisites
+// # Prequisites
 //
 // - Install go
 //
-// Build
+// # Build
 //
 // `go build`
 //
-// Run
+// # Run
 //
 // `./server`
 //
-// Options
+// # Options
 //
 // see `./server -help`
 //
@@ -40,19 +40,21 @@ import (
 	"flag"
 	"fmt"
 	"io"
-	golog "log"
 	"math/rand"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 )
 
 var (
-	log           *golog.Logger
 	disableTime   bool
 	serverAddress = "localhost:10101"
 	statsEnabled  bool
+	chosenNames   bool
 	keyFile       = "./keys"
 	pseudonymFile = "./pseudonyms"
 )
@@ -62,22 +64,214 @@ func init() {
 	rand.Seed(time.Now().Unix())
 }
 
-func main() {
+// run runs the server or the mode selected by the flags and returns the exit code.
+// Errors are returned instead of calling os.Exit, so deferred functions like closing files and stopping the profiling always run.
+func run() int {
 	flag.BoolVar(&disableLogging, "disableLogging", false, "Disables logging of games")
+	shutdownTimeout := flag.Duration("shutdowntimeout", 1*time.Minute, "Time running games may continue after SIGINT or SIGTERM before they are aborted")
 	wait := flag.String("wait", "5m", "Waiting time for new games. Must be at least 0s (0=instant start for debugging). Value must be parseable by time.Duration")
 	flag.BoolVar(&disableTime, "disableTime", false, "Disables time endpoint")
 	flag.StringVar(&serverAddress, "address", serverAddress, "Address of the server")
 	flag.BoolVar(&statsEnabled, "stats", false, "Enables stats on /spe_ed_stats")
+	flag.BoolVar(&chosenNames, "chosennames", false, "Reveals the names chosen by the players (see -name) instead of their pseudonyms at the end of the games hosted by the server")
 	flag.StringVar(&keyFile, "keyfile", keyFile, "Path to key file")
+	spectatorAddress := flag.String("spectatoraddress", "", "If set, a read-only websocket streaming the state of every round of all games is served on /spe_ed_spectator at this address")
 	flag.StringVar(&pseudonymFile, "pseudonymfile", pseudonymFile, "Path to pseudonym file. Will be created if non-existing")
+	minWidth := flag.Int("minwidth", DefaultGameConfig.MinWidth, "Minimum width of the games hosted by the server")
+	maxWidth := flag.Int("maxwidth", DefaultGameConfig.MaxWidth, "Maximum width of the games hosted by the server")
+	minHeight := flag.Int("minheight", DefaultGameConfig.MinHeight, "Minimum height of the games hosted by the server")
+	maxHeight := flag.Int("maxheight", DefaultGameConfig.MaxHeight, "Maximum height of the games hosted by the server")
+	minPlayers := flag.Int("minplayers", DefaultGameConfig.MinPlayers, "Minimum number of players of the games hosted by the server (at least 2)")
+	maxPlayers := flag.Int("maxplayers", DefaultGameConfig.MaxPlayers, fmt.Sprintf("Maximum number of players of the games hosted by the server (at most %d)", PlayersPerGame))
+	noHoles := flag.Bool("noholes", false, "Disables holes in the games hosted by the server and in the games of -compare, -tournament, -sweep, -dryrun and -step")
+	holeSpeed := flag.Int("holespeed", HoleSpeed, "Minimum speed needed for a hole in the games hosted by the server and in the games of -compare, -tournament, -sweep, -dryrun and -step")
+	holesEachStep := flag.Int("holeseachstep", HolesEachStep, "Number of rounds after which holes occur in the games hosted by the server and in the games of -compare, -tournament, -sweep, -dryrun and -step")
 	ais := flag.String("ais", "", fmt.Sprintf("Comma seperated list of ais which should be used. Must be at least %d", PlayersPerGame))
 	listais := flag.Bool("listais", false, "Lists all ai names and exits")
+	list := flag.Bool("list", false, "Lists all ai names (one per line) and exits")
 	logfilename := flag.String("logfile", "", "If set, logging will be done to file instead of to stdout")
+	client := flag.String("client", os.Getenv("URL"), "If set, no server is started. Instead, a single game is played on the server with this websocket url using the ai given by -ai. Defaults to the environment variable URL")
+	clientKey := flag.String("key", "", fmt.Sprintf("API key used by -client. Since command lines are visible to other users, prefer -key-file or the environment variable %s. The key is taken from (highest precedence first) -key, -key-file, %s, the key of -config and the environment variable %s", APIKeyEnv, APIKeyEnv, APIKeyLegacyEnv))
+	clientKeyFile := flag.String("key-file", "", "Path to a file containing the API key used by -client (see -key)")
+	clientName := flag.String("name", "", fmt.Sprintf("Name of our player sent by -client on connect (at most %d characters). This server reveals it instead of the pseudonym at the end of the game if started with -chosennames", PlayerNameMaxLength))
+	clientAI := flag.String("ai", "FloodFillAI", "Name of the ai used by -client, -dryrun, -checkscenarios and -replay and of the opponent used by -sweep")
+	clientTimeURL := flag.String("timeurl", "", "URL of the time endpoint used by -client to correct the local clock. If not set, it is derived from the -client url")
+	clientReconnect := flag.Int("reconnect", 0, "Number of reconnect attempts of -client if the connection is lost before the game has ended")
+	clientFallback := flag.String("fallback", string(ActionNOOP), fmt.Sprintf("Action sent by -client if the ai does not answer shortly before the deadline or answers with an invalid action. Either an action or %s for the first action not crashing immediately", FallbackFirstLegal))
+	clientMargin := flag.Duration("margin", ClientSafetyMargin, "Time before the deadline at which -client sends the fallback action if the ai has not answered yet")
+	clientMeasureLatency := flag.Bool("measurelatency", false, "If set, -client measures the round trip time to the server after every answer and additionally subtracts the estimated latency from the deadline")
+	clientWatchdog := flag.Int("watchdog", ClientWatchdogFactor, fmt.Sprintf("Number of tick intervals -client waits for the next state before the server is considered stalled and the connection is treated as lost (at least %s, 0=disabled)", ClientWatchdogMinTimeout))
+	clientFillTimes := flag.Bool("filltimes", false, "If set, -client tracks in which round each cell was filled and gives the fill times to the ai (see Game.FillTimes)")
+	clientBudget := flag.Duration("budget", 0, "If set, every decision of the ai of -client taking longer than this is logged together with the conditions on the board (0=disabled)")
+	replay := flag.String("replay", "", "Replay file. With -client, the game is recorded to this file. Without -client, the recorded game is stepped through the ai given by -ai and the decisions are compared")
+	contactSheet := flag.String("contactsheet", "", "If set together with -replay (and without -client), every -sheetinterval-th state of the recorded game is rendered as thumbnail into this PNG instead of being stepped through the ai")
+	sheetInterval := flag.Int("sheetinterval", ContactSheetInterval, "Number of rounds between two thumbnails of -contactsheet")
+	sheetColumns := flag.Int("sheetcolumns", ContactSheetColumns, "Number of thumbnails per row of -contactsheet")
+	analyse := flag.Bool("analyse", false, "If set together with -replay (and without -client), the recorded game is analysed instead: for every round the reachable space and the actions of the recording and of the ai given by -ai are printed, and for a crashed player the earliest diverging round in which the ai would have survived is searched")
+	gifFile := flag.String("gif", "", "If set together with -replay (and without -client), the recorded game is rendered to this animated GIF instead of being stepped through the ai")
+	maxBoardCells := flag.Int("maxboardcells", DefaultMaxBoardCells, "Maximum number of cells (width times height) of a board on which the search ais (MinimaxAI, IterativeDeepeningAI, MCTSAI) search. On larger boards they play like FloodFillAI instead (0=no limit)")
+	seed := flag.Int64("seed", 0, "If set, all random decisions of the ais are derived from this seed (0=random)")
+	sweep := flag.String("sweep", "", "If set, no server is started. Instead, WeightedHeuristicAI plays against the ai given by -ai for every weight configuration in this JSON grid file and the win rates are printed as CSV")
+	sweepMatches := flag.Int("sweepmatches", 10, "Number of matches per weight configuration of -sweep")
+	sweepMinSize := flag.Int("sweepminsize", 20, "Minimum width and height of the boards of -sweep")
+	sweepMaxSize := flag.Int("sweepmaxsize", 50, "Maximum width and height of the boards of -sweep")
+	compare := flag.String("compare", "", "If set, no server is started. Instead, the two ais of this comma seperated list (e.g. FloodFillAI,MCTSAI) play -games games against each other with swapped start positions and the win rates are printed")
+	compareGames := flag.Int("games", 100, "Number of games of -compare and of every match of -tournament")
+	exportSelfPlay := flag.String("export-selfplay", "", "If set together with -compare, every decision of the first ai of -compare is written to this file as training sample (one JSON object per line with the board, the action and the outcome of the game)")
+	tournament := flag.String("tournament", "", "If set, no server is started. Instead, every pair of ais of this comma seperated list plays a match of -games games (like -compare) and the standings are printed")
+	tournamentCSV := flag.String("tournamentcsv", "", "If set, the standings of -tournament are additionally written to this CSV file")
+	placement := flag.String("placement", PlacementRandom, fmt.Sprintf("Placement of the players at the start of the games of -sweep, -compare, -tournament and -step. Must be %s, %s or %s", PlacementRandom, PlacementSymmetric, PlacementCorners))
+	dryRun := flag.Bool("dryrun", false, "If set, no server is started. Instead, the ai given by -ai plays alone on a random board for -ticks rounds and the board and the chosen action of every round are printed")
+	dryRunTicks := flag.Int("ticks", 20, "Maximum number of rounds of -dryrun")
+	step := flag.String("step", "", "If set, no server is started. Instead, the ais of this comma seperated list play a single game against each other which advances one round each time enter is pressed, printing the board and the actions of every round")
+	benchmark := flag.String("benchmark", "", "If set, no server is started. Instead, the time a single decision takes is measured for this comma seperated list of ais (or all for all registered ais) on early, mid and late game boards of several sizes and printed as CSV")
+	benchmarkRuns := flag.Int("benchmarkruns", 3, "Number of decisions measured per ai and board of -benchmark")
+	benchmarkDeadline := flag.Duration("benchmarkdeadline", 2*time.Second, "Time until the deadline of every state of -benchmark")
+	checkScenarios := flag.String("checkscenarios", "", "If set, no server is started. Instead, the ai given by -ai decides -scenarioruns times in every scenario (*.json) of this directory, the results are printed and the program exits with an error if the ai chose an action listed in \"avoid\" of a scenario")
+	scenarioRuns := flag.Int("scenarioruns", 20, "Number of decisions per scenario of -checkscenarios")
+	profile := flag.String("profile", "", "If set, a CPU profile is written to this file and an allocation profile to the same file with the suffix .allocs. Additionally, the latency percentiles and allocations per decision of every ai are logged when the program ends")
+	metricsAddress := flag.String("metrics-addr", "", "If set, Prometheus metrics are served on /metrics at this address (e.g. localhost:9100)")
+	healthAddress := flag.String("health-addr", "", "If set, the health endpoints /healthz and /readyz are served at this address (e.g. localhost:9100, may be the same as -metrics-addr). /readyz reports whether the server accepts games or -client is connected to a game")
+	assertMoves := flag.Bool("assert-moves", false, "If set, -client checks every action of the ai before sending it and logs a warning if it is illegal or crashes immediately although another action does not")
+	strictProtocol := flag.Bool("strict-protocol", false, "If set, states received by -client containing unknown fields are rejected (and answered with the fallback) instead of logging each unknown field once")
+	decisionLog := flag.String("decisionlog", "", "If set, the decisions of the ais (chosen action, scores of the candidates, remaining time, position and speed) are logged to this file as one JSON object per line. Use - for stderr")
+	logLevel := flag.String("loglevel", LogLevelInfo, fmt.Sprintf("Log level. Must be %s, %s or %s (additionally logs the decisions of the ais to the log if -decisionlog is not set)", LogLevelNone, LogLevelInfo, LogLevelDebug))
+	logFormat := flag.String("logformat", LogFormatText, fmt.Sprintf("Log format. Must be %s or %s (one JSON object per line)", LogFormatText, LogFormatJSON))
+	configFile := flag.String("config", "", "Path to a JSON file containing the url, key, name, ai, safety margin, latency measurement, watchdog, seed, log level, log format and maximum board size (see client.example.json). Flags given on the command line override the values of the file")
+	utilization := flag.Float64("utilization", DefaultSearchUtilization, "Share of the remaining time until the deadline used by the search ais (MCTSAI, MinimaxAI, IterativeDeepeningAI). Must be within (0, 1]")
+	weights := flag.String("weights", "", fmt.Sprintf("Path to a JSON file containing the weights of WeightedHeuristicAI. Registered evaluators (%s) without their own key are weighted by name in \"evaluators\". If not set, the default weights are used", strings.Join(ListEvaluators(), ", ")))
+	policyNetFile := flag.String("policynet", "", "Path to a JSON file containing the layers of the network of PolicyNetAI. If not set, PolicyNetAI plays like FloodFillAI")
 	flag.Parse()
 
 	if *listais {
-		fmt.Println(GetAINames())
-		return
+		fmt.Println(ListAIs())
+		return 0
+	}
+
+	if *list {
+		for _, name := range ListAIs() {
+			fmt.Println(name)
+		}
+		return 0
+	}
+
+	configKey := ""
+	if *configFile != "" {
+		c, err := LoadConfig(*configFile)
+		if err != nil {
+			panic(err)
+		}
+		set := make(map[string]bool)
+		flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+		if c.URL != "" && !set["client"] {
+			*client = c.URL
+		}
+		configKey = c.Key
+		if c.Name != "" && !set["name"] {
+			*clientName = c.Name
+		}
+		if c.AI != "" && !set["ai"] {
+			*clientAI = c.AI
+		}
+		if c.SafetyMargin != 0 && !set["margin"] {
+			*clientMargin = time.Duration(c.SafetyMargin)
+		}
+		if c.MeasureLatency && !set["measurelatency"] {
+			*clientMeasureLatency = true
+		}
+		if c.Watchdog != 0 && !set["watchdog"] {
+			*clientWatchdog = c.Watchdog
+			if *clientWatchdog < 0 {
+				*clientWatchdog = 0
+			}
+		}
+		if c.MaxBoardCells != 0 && !set["maxboardcells"] {
+			*maxBoardCells = c.MaxBoardCells
+		}
+		if c.Seed != 0 && !set["seed"] {
+			*seed = c.Seed
+		}
+		if c.LogLevel != "" && !set["loglevel"] {
+			*logLevel = c.LogLevel
+		}
+		if c.LogFormat != "" && !set["logformat"] {
+			*logFormat = c.LogFormat
+		}
+	}
+
+	if *seed != 0 {
+		SetAISeed(*seed)
+	}
+
+	{
+		err := SetMaxBoardCells(*maxBoardCells)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	{
+		err := ValidateFallback(*clientFallback)
+		if err != nil {
+			panic(err)
+		}
+		err = ValidatePlacement(*placement)
+		if err != nil {
+			panic(err)
+		}
+		err = ValidateLogLevel(*logLevel)
+		if err != nil {
+			panic(err)
+		}
+		err = ValidateLogFormat(*logFormat)
+		if err != nil {
+			panic(err)
+		}
+		if *clientMargin < 0 {
+			panic("safety margin too small")
+		}
+		if *clientWatchdog < 0 {
+			panic("watchdog factor too small")
+		}
+	}
+
+	{
+		err := SetSearchUtilization(*utilization)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	if *weights != "" {
+		w, err := LoadWeightedHeuristicWeights(*weights)
+		if err != nil {
+			panic(err)
+		}
+		SetWeightedHeuristicWeights(w)
+	}
+
+	if *policyNetFile != "" {
+		n, err := LoadPolicyNet(*policyNetFile)
+		if err != nil {
+			panic(err)
+		}
+		SetPolicyNet(n)
+	}
+
+	holes := HoleRules{Disabled: *noHoles, Speed: *holeSpeed, EachStep: *holesEachStep}
+	{
+		err := SetGameConfig(GameConfig{
+			MinWidth:   *minWidth,
+			MaxWidth:   *maxWidth,
+			MinHeight:  *minHeight,
+			MaxHeight:  *maxHeight,
+			MinPlayers: *minPlayers,
+			MaxPlayers: *maxPlayers,
+			Holes:      holes,
+		})
+		if err != nil {
+			panic(err)
+		}
 	}
 
 	if *ais != "" {
@@ -99,15 +293,352 @@ func main() {
 		}
 	}
 
-	if *logfilename == "" {
-		log = golog.New(os.Stdout, "spe_ed server ", golog.LstdFlags)
+	if *logLevel == LogLevelNone {
+		SetLogger(NopLogger{})
 	} else {
-		f, err := os.OpenFile(*logfilename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		var w io.Writer = os.Stdout
+		prefix := "spe_ed server "
+		if *logfilename != "" {
+			f, err := os.OpenFile(*logfilename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+			if err != nil {
+				panic(err)
+			}
+			defer f.Close()
+			w = f
+			prefix = ""
+		}
+		l, err := NewLogger(*logFormat, w, prefix, *logLevel)
+		if err != nil {
+			panic(err)
+		}
+		SetLogger(l)
+	}
+
+	if *metricsAddress != "" {
+		InitMetrics(*metricsAddress)
+	}
+
+	if *healthAddress != "" {
+		InitHealth(*healthAddress)
+	}
+
+	if *assertMoves {
+		err := SetMoveAssertion(MoveAssertionWarn)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	if *strictProtocol {
+		err := SetProtocolCheck(ProtocolCheckStrict)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	if *decisionLog == "-" {
+		SetDecisionLog(os.Stderr)
+	} else if *decisionLog != "" {
+		f, err := os.OpenFile(*decisionLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 		if err != nil {
 			panic(err)
 		}
 		defer f.Close()
-		log = golog.New(f, "", golog.LstdFlags)
+		SetDecisionLog(f)
+	}
+
+	if *profile != "" {
+		err := StartProfiling(*profile)
+		if err != nil {
+			panic(err)
+		}
+		defer func() {
+			err := StopProfiling()
+			if err != nil {
+				log.Println(err)
+			}
+		}()
+	}
+
+	if *client != "" {
+		key, source, err := ResolveAPIKey(APIKeySources{
+			Flag:      *clientKey,
+			File:      *clientKeyFile,
+			Env:       os.Getenv(APIKeyEnv),
+			Config:    configKey,
+			LegacyEnv: os.Getenv(APIKeyLegacyEnv),
+		})
+		if err != nil {
+			log.Println("client:", err)
+			return 1
+		}
+		log.Printf("client: using api key %s from %s", RedactKey(key), source)
+
+		err = RunClient(ClientConfig{
+			URL:            *client,
+			Key:            key,
+			Name:           *clientName,
+			AI:             *clientAI,
+			TimeURL:        *clientTimeURL,
+			Reconnect:      *clientReconnect,
+			Replay:         *replay,
+			Fallback:       *clientFallback,
+			Budget:         *clientBudget,
+			SafetyMargin:   *clientMargin,
+			MeasureLatency: *clientMeasureLatency,
+			FillTimes:      *clientFillTimes,
+			Watchdog:       *clientWatchdog,
+		})
+		if err != nil {
+			log.Println("client:", err)
+			return 1
+		}
+		return 0
+	}
+
+	if *sweep != "" {
+		grid, err := LoadSweepGrid(*sweep)
+		if err == nil {
+			sweepSeed := *seed
+			if sweepSeed == 0 {
+				sweepSeed = rand.Int63()
+			}
+			log.Println("sweep: using seed", sweepSeed)
+			err = RunSweep(SweepConfig{
+				Grid:      grid,
+				Opponent:  *clientAI,
+				Matches:   *sweepMatches,
+				MinSize:   *sweepMinSize,
+				MaxSize:   *sweepMaxSize,
+				Seed:      sweepSeed,
+				Placement: *placement,
+				Holes:     holes,
+			}, os.Stdout)
+		}
+		if err != nil {
+			log.Println(err)
+			return 1
+		}
+		return 0
+	}
+
+	if *compare != "" {
+		ais := strings.Split(*compare, ",")
+		if len(ais) != 2 {
+			log.Println("compare: exactly two ais are needed")
+			return 1
+		}
+		compareSeed := *seed
+		if compareSeed == 0 {
+			compareSeed = rand.Int63()
+		}
+		log.Println("compare: using seed", compareSeed)
+		var exporter *SelfPlayExporter
+		if *exportSelfPlay != "" {
+			var err error
+			exporter, err = NewSelfPlayExporter(*exportSelfPlay)
+			if err != nil {
+				log.Println("compare: can not create self-play export:", err)
+				return 1
+			}
+		}
+		_, err := RunCompare(CompareConfig{
+			AIs:       [2]string{ais[0], ais[1]},
+			Games:     *compareGames,
+			MinWidth:  DefaultGameConfig.MinWidth,
+			MaxWidth:  DefaultGameConfig.MaxWidth,
+			MinHeight: DefaultGameConfig.MinHeight,
+			MaxHeight: DefaultGameConfig.MaxHeight,
+			Seed:      compareSeed,
+			Placement: *placement,
+			Holes:     holes,
+			SelfPlay:  exporter,
+		}, os.Stdout)
+		if exporter != nil {
+			closeErr := exporter.Close()
+			if closeErr != nil {
+				log.Println("compare: can not close self-play export:", closeErr)
+				return 1
+			}
+		}
+		if err != nil {
+			log.Println(err)
+			return 1
+		}
+		return 0
+	}
+
+	if *tournament != "" {
+		tournamentSeed := *seed
+		if tournamentSeed == 0 {
+			tournamentSeed = rand.Int63()
+		}
+		log.Println("tournament: using seed", tournamentSeed)
+		config := TournamentConfig{
+			AIs:       strings.Split(*tournament, ","),
+			Games:     *compareGames,
+			MinWidth:  DefaultGameConfig.MinWidth,
+			MaxWidth:  DefaultGameConfig.MaxWidth,
+			MinHeight: DefaultGameConfig.MinHeight,
+			MaxHeight: DefaultGameConfig.MaxHeight,
+			Seed:      tournamentSeed,
+			Placement: *placement,
+			Holes:     holes,
+		}
+		if *tournamentCSV != "" {
+			f, err := os.Create(*tournamentCSV)
+			if err != nil {
+				log.Println("tournament:", err)
+				return 1
+			}
+			defer f.Close()
+			config.CSV = f
+		}
+		_, err := RunTournament(config, os.Stdout)
+		if err != nil {
+			log.Println(err)
+			return 1
+		}
+		return 0
+	}
+
+	if *dryRun {
+		_, err := RunDryRun(DryRunConfig{
+			AI:        *clientAI,
+			Ticks:     *dryRunTicks,
+			MinWidth:  DefaultGameConfig.MinWidth,
+			MaxWidth:  DefaultGameConfig.MaxWidth,
+			MinHeight: DefaultGameConfig.MinHeight,
+			MaxHeight: DefaultGameConfig.MaxHeight,
+			Seed:      *seed,
+			Holes:     holes,
+		}, os.Stdout)
+		if err != nil {
+			log.Println(err)
+			return 1
+		}
+		return 0
+	}
+
+	if *step != "" {
+		stepSeed := *seed
+		if stepSeed == 0 {
+			stepSeed = rand.Int63()
+		}
+		r := rand.New(rand.NewSource(stepSeed))
+		width := DefaultGameConfig.MinWidth + r.Intn(DefaultGameConfig.MaxWidth-DefaultGameConfig.MinWidth+1)
+		height := DefaultGameConfig.MinHeight + r.Intn(DefaultGameConfig.MaxHeight-DefaultGameConfig.MinHeight+1)
+		names := strings.Split(*step, ",")
+		ais := make([]AI, len(names))
+		for i := range names {
+			ai, err := CreateAI(names[i])
+			if err != nil {
+				log.Println("step:", err)
+				return 1
+			}
+			ais[i] = ai
+		}
+		s, err := NewSimulatorWithPlacement(width, height, r.Int63(), *placement, ais...)
+		if err != nil {
+			log.Println("step:", err)
+			return 1
+		}
+		s.Game.Holes = holes
+		log.Printf("step: %dx%d, using seed %d", width, height, stepSeed)
+		s.RunInteractive(os.Stdin, os.Stdout)
+		return 0
+	}
+
+	if *benchmark != "" {
+		ais := ListAIs()
+		if *benchmark != "all" {
+			ais = strings.Split(*benchmark, ",")
+		}
+		benchmarkSeed := *seed
+		if benchmarkSeed == 0 {
+			benchmarkSeed = 1
+		}
+		err := RunBenchmark(BenchmarkConfig{
+			AIs:      ais,
+			Runs:     *benchmarkRuns,
+			Deadline: *benchmarkDeadline,
+			Seed:     benchmarkSeed,
+		}, os.Stdout)
+		if err != nil {
+			log.Println(err)
+			return 1
+		}
+		return 0
+	}
+
+	if *checkScenarios != "" {
+		scenarioSeed := *seed
+		if scenarioSeed == 0 {
+			scenarioSeed = 1
+		}
+		failed, err := CheckScenarios(ScenarioCheckConfig{
+			Dir:  *checkScenarios,
+			AI:   *clientAI,
+			Runs: *scenarioRuns,
+			Seed: scenarioSeed,
+		}, os.Stdout)
+		if err != nil {
+			log.Println(err)
+			return 1
+		}
+		if failed > 0 {
+			log.Printf("scenario: %d scenarios failed", failed)
+			return 1
+		}
+		return 0
+	}
+
+	if *replay != "" && *gifFile != "" {
+		f, err := os.Create(*gifFile)
+		if err == nil {
+			err = RenderReplayGIF(*replay, f)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		if err != nil {
+			log.Println("gif:", err)
+			return 1
+		}
+		return 0
+	}
+
+	if *replay != "" && *contactSheet != "" {
+		f, err := os.Create(*contactSheet)
+		if err == nil {
+			err = RenderReplayContactSheet(*replay, *sheetInterval, *sheetColumns, f)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		if err != nil {
+			log.Println("contact sheet:", err)
+			return 1
+		}
+		return 0
+	}
+
+	if *replay != "" && *analyse {
+		err := RunReplayAnalysis(*replay, *clientAI, os.Stdout)
+		if err != nil {
+			log.Println("analyse:", err)
+			return 1
+		}
+		return 0
+	}
+
+	if *replay != "" {
+		err := RunReplay(*replay, *clientAI, os.Stdout)
+		if err != nil {
+			log.Println("replay:", err)
+			return 1
+		}
+		return 0
 	}
 
 	InitPseudonyms(pseudonymFile)
@@ -129,6 +660,14 @@ func main() {
 		})
 	}
 
+	if *spectatorAddress != "" {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/spe_ed_spectator", spectatorEndpoint)
+		go func() {
+			log.Fatal(http.ListenAndServe(*spectatorAddress, mux))
+		}()
+	}
+
 	if !disableTime {
 		http.HandleFunc("/spe_ed_time", func(rw http.ResponseWriter, r *http.Request) {
 			now := time.Now().UTC()
@@ -148,5 +687,34 @@ func main() {
 			rw.Write(b)
 		})
 	}
-	log.Fatal(http.ListenAndServe(serverAddress, nil))
+	server := &http.Server{Addr: serverAddress}
+	stopped := make(chan struct{})
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		s := <-signals
+		log.Println("shutdown: received", s)
+		signal.Stop(signals)
+		Shutdown(server, *shutdownTimeout)
+		close(stopped)
+	}()
+
+	listener, err := net.Listen("tcp", serverAddress)
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+	setReady(true)
+	err = server.Serve(listener)
+	if err != http.ErrServerClosed {
+		log.Println(err)
+		return 1
+	}
+	<-stopped
+	log.Println("shutdown: done")
+	return 0
+}
+
+func main() {
+	os.Exit(run())
 }
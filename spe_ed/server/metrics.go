@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	metricGamesPlayed = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "spe_ed_games_played_total",
+		Help: "Number of games played until the end.",
+	})
+	metricGameResults = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "spe_ed_game_results_total",
+		Help: "Number of won and lost games per player (ai name or \"player\" for players connected through the websocket).",
+	}, []string{"player", "result"})
+	metricDecisionLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "spe_ed_decision_latency_seconds",
+		Help:    "Time an ai needed to compute its answer (GetState) per round.",
+		Buckets: []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2, 4, 8, 16},
+	}, []string{"ai"})
+	metricTimeouts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "spe_ed_timeouts_total",
+		Help: "Number of rounds in which a player did not answer before the deadline.",
+	}, []string{"player"})
+	metricBudgetExceeded = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "spe_ed_budget_exceeded_total",
+		Help: "Number of rounds in which an ai wrapped by TimedAI needed longer than its budget.",
+	}, []string{"ai"})
+	metricPanics = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "spe_ed_ai_panics_total",
+		Help: "Number of decisions in which an ai panicked (see SafeGetState).",
+	}, []string{"ai"})
+	metricActions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "spe_ed_actions_total",
+		Help: "Number of actions per type (sent by the client, accepted by the server).",
+	}, []string{"action"})
+)
+
+// InitMetrics starts serving Prometheus metrics on /metrics at the given address, which may be the same as the one given to InitHealth.
+// Metrics are always collected, but can only be scraped after InitMetrics is called.
+func InitMetrics(address string) {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		metricGamesPlayed,
+		metricGameResults,
+		metricDecisionLatency,
+		metricTimeouts,
+		metricBudgetExceeded,
+		metricPanics,
+		metricActions,
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+	)
+
+	serviceMux(address).Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+}
+
+// metricPlayerName returns the name used as player label for the given player.
+func metricPlayerName(p *Player) string {
+	if p.underlyingAI != nil {
+		return p.underlyingAI.Name()
+	}
+	return "player"
+}
@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+// FutureMobilityHorizon contains the default number of rounds looked ahead by FutureMobility.
+const FutureMobilityHorizon = 3
+
+// FutureMobility estimates how many options Game.You keeps during the next horizon rounds.
+// Starting with the current state, the legal actions of the player (see Game.LegalActions) are counted in every round. Afterwards, the player follows a greedy policy: it plays the legal action leaving the most legal actions in the next round (ties are broken by the order of Actions).
+// The result is the sum of the counts of all rounds, between 0 (no legal action at all) and len(Actions)*horizon. A forced path through a corridor scores lower than open space, even if neither crashes within the horizon.
+// Other players are not moved. The game is not modified.
+func FutureMobility(g *Game, horizon int) int {
+	p, ok := g.Players[g.You]
+	if !ok || !p.Active || horizon < 1 {
+		return 0
+	}
+
+	b := NewBitboard(g)
+	current := mobilityPlayer(p)
+	total := 0
+	for round := 1; ; round++ {
+		var next *Player
+		var nextBoard *Bitboard
+		nextLegal := -1
+		legal := 0
+		for _, a := range Actions {
+			if b.Crashes(current, a) {
+				continue
+			}
+			legal++
+			if round == horizon {
+				continue
+			}
+			np, nb := mobilityPlayer(current), b.Clone()
+			nb.Apply(np, a)
+			n := 0
+			for _, na := range Actions {
+				if !nb.Crashes(np, na) {
+					n++
+				}
+			}
+			if n > nextLegal {
+				next, nextBoard, nextLegal = np, nb, n
+			}
+		}
+		total += legal
+		if legal == 0 || round == horizon {
+			return total
+		}
+		current, b = next, nextBoard
+	}
+}
+
+// mobilityPlayer returns a copy of the fields of the player needed for moving it on a bitboard (see Bitboard.Apply).
+func mobilityPlayer(p *Player) *Player {
+	return &Player{X: p.X, Y: p.Y, Direction: p.Direction, Speed: p.Speed, Active: p.Active, stepCounter: p.stepCounter}
+}
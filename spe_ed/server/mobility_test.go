@@ -0,0 +1,49 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "testing"
+
+func TestFutureMobility(t *testing.T) {
+	g := testScenario(t, `{"you": 1, "grid": [
+		"..........",
+		"..........",
+		"..........",
+		"111>xxxxxx",
+		"xxx.xxxxxx",
+		"xxx.xxxxxx",
+		"xxx.xxxxxx",
+		"xxxxxxxxxx"], "players": {"1": {}}}`)
+	corridor, crashed := Simulate(g, 1, ActionTurnRight)
+	if crashed {
+		t.Fatalf("crashed entering the corridor\n%s", g)
+	}
+	defer ReleaseClone(corridor)
+	open, crashed := Simulate(g, 1, ActionTurnLeft)
+	if crashed {
+		t.Fatalf("crashed turning into open space\n%s", g)
+	}
+	defer ReleaseClone(open)
+
+	c, o := FutureMobility(corridor, FutureMobilityHorizon), FutureMobility(open, FutureMobilityHorizon)
+	if c >= o {
+		t.Errorf("dead-end corridor scores %d, open space %d", c, o)
+	}
+	g.Players[1].Active = false
+	if m := FutureMobility(g, FutureMobilityHorizon); m != 0 {
+		t.Errorf("inactive player scores %d", m)
+	}
+}
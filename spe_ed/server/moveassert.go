@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+const (
+	// MoveAssertionOff contains the move assertion sending every action of the AI without checking it. It is the default.
+	MoveAssertionOff = iota
+	// MoveAssertionWarn contains the move assertion logging a warning for every illegal or suicidal action (see CheckMove), which is sent anyway.
+	MoveAssertionWarn
+	// MoveAssertionAbort contains the move assertion aborting the game on the first illegal or suicidal action (see CheckMove), e.g. for tests.
+	MoveAssertionAbort
+)
+
+// ErrIllegalMove is returned by CheckMove if the action is illegal or leads to an immediate crash although another action does not.
+var ErrIllegalMove = errors.New("illegal move")
+
+var moveAssertion int32 = MoveAssertionOff
+
+// SetMoveAssertion sets how the client checks the actions of the AI before sending them (see MoveAssertionOff, MoveAssertionWarn and MoveAssertionAbort).
+// It is meant for debugging AIs, which should never choose a move the simulation knows to be fatal.
+func SetMoveAssertion(mode int) error {
+	switch mode {
+	case MoveAssertionOff, MoveAssertionWarn, MoveAssertionAbort:
+		atomic.StoreInt32(&moveAssertion, int32(mode))
+		return nil
+	default:
+		return fmt.Errorf("unknown move assertion %d", mode)
+	}
+}
+
+// CheckMove returns an error wrapping ErrIllegalMove if the action of Game.You is not one of its legal actions (see Game.LegalActions).
+// If the player has no legal action left, every action is accepted, since the crash can not be avoided. Other players are not considered to move. The game is not modified.
+func CheckMove(g *Game, action Action) error {
+	legal := g.LegalActions(g.You)
+	if len(legal) == 0 {
+		return nil
+	}
+	for _, a := range legal {
+		if a == action {
+			return nil
+		}
+	}
+
+	// Find out why using the rules of the server
+	c := g.Clone()
+	err := ApplyAction(c, g.You, action)
+	if err != nil {
+		return fmt.Errorf("%w %s: %s", ErrIllegalMove, action, err.Error())
+	}
+	return fmt.Errorf("%w %s: crashes immediately, legal actions are %v", ErrIllegalMove, action, legal)
+}
+
+// assertMove checks the action chosen by the AI as set by SetMoveAssertion.
+// It returns an error only for MoveAssertionAbort, MoveAssertionWarn just logs the problem.
+func assertMove(g *Game, ai string, action Action) error {
+	mode := atomic.LoadInt32(&moveAssertion)
+	if mode == MoveAssertionOff {
+		return nil
+	}
+	err := CheckMove(g, action)
+	if err == nil {
+		return nil
+	}
+	if mode == MoveAssertionAbort {
+		return fmt.Errorf("%s: %w", ai, err)
+	}
+	p := g.Players[g.You]
+	log.Printf("WARNING: move assertion: %s chose %s (turn %d, position %d/%d, speed %d, direction %s)", ai, err.Error(), p.stepCounter+1, p.X, p.Y, p.Speed, p.Direction)
+	return nil
+}
@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestCheckMove(t *testing.T) {
+	g := testScenario(t, `{"you": 1, "grid": ["x....", "11>.x", "x...."], "players": {"1": {}}}`)
+	for _, tc := range []struct {
+		action Action
+		reason string
+	}{
+		{ActionNOOP, ""},
+		{ActionTurnLeft, ""},
+		{ActionSlower, "invalid speed"},
+		{ActionFaster, "crashes immediately"},
+	} {
+		err := CheckMove(g, tc.action)
+		if tc.reason == "" {
+			if err != nil {
+				t.Errorf("%s: %v", tc.action, err)
+			}
+			continue
+		}
+		if !errors.Is(err, ErrIllegalMove) || !strings.Contains(err.Error(), tc.reason) {
+			t.Errorf("%s: got error %v, want ErrIllegalMove with %q", tc.action, err, tc.reason)
+		}
+	}
+
+	trapped := testScenario(t, `{"you": 1, "grid": ["xxx", "1>x", "xxx"], "players": {"1": {}}}`)
+	if err := CheckMove(trapped, ActionSlower); err != nil {
+		t.Errorf("move without legal actions rejected: %v", err)
+	}
+}
+
+func TestClientMoveAssertion(t *testing.T) {
+	var logged strings.Builder
+	oldLogger := log.current()
+	SetLogger(NewTextLogger(&logged, "", LogLevelInfo))
+	t.Cleanup(func() {
+		SetLogger(oldLogger)
+		SetMoveAssertion(MoveAssertionOff)
+	})
+
+	for _, mode := range []int{MoveAssertionWarn, MoveAssertionAbort} {
+		err := SetMoveAssertion(mode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		logged.Reset()
+		answers := make(chan Action, 1)
+		ws := fakeServer(t, func(ws *websocket.Conn) {
+			err := ws.WriteMessage(websocket.TextMessage, serverState(t, time.Second))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			var a ActionMessage
+			// The client does not answer if it aborts
+			if ws.ReadJSON(&a) != nil {
+				return
+			}
+			answers <- a.Action
+			ws.WriteMessage(websocket.TextMessage, serverState(t, 0))
+		})
+
+		// The player of the recorded state has speed 1, so it can not slow down
+		lost, err := playClient(t, &scriptedAI{actions: map[int]Action{1: ActionSlower}}, ws, new(StateWatchdog))
+		if mode == MoveAssertionAbort {
+			if lost || !errors.Is(err, ErrIllegalMove) {
+				t.Errorf("abort: connection lost %t, got error %v, want ErrIllegalMove", lost, err)
+			}
+			continue
+		}
+		if lost || err != nil {
+			t.Fatalf("warn: connection lost %t, error %v", lost, err)
+		}
+		if a := <-answers; a != ActionSlower {
+			t.Errorf("warn: sent %s, want %s", a, ActionSlower)
+		}
+		if !strings.Contains(logged.String(), "move assertion: scriptedAI chose illegal move slow_down") {
+			t.Errorf("warn: no warning logged\n%s", logged.String())
+		}
+	}
+}
@@ -0,0 +1,48 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+// NearestObstacleDistance returns the number of free cells between (x, y) and the nearest filled cell or the border of the board in the given direction (see DirectionUp, ...).
+// The start cell itself is not checked, so the position of a head can be used directly. A return value of 0 means the next cell is blocked.
+// Summing the distances of all four directions gives an estimate of how open a position is. An unknown direction or a start outside the board returns 0.
+func NearestObstacleDistance(g *Game, x, y int, direction Direction) int {
+	if x < 0 || x >= g.Width || y < 0 || y >= g.Height {
+		return 0
+	}
+
+	dx, dy := 0, 0
+	switch direction {
+	case DirectionUp:
+		dy = -1
+	case DirectionDown:
+		dy = 1
+	case DirectionLeft:
+		dx = -1
+	case DirectionRight:
+		dx = 1
+	default:
+		return 0
+	}
+
+	distance := 0
+	for {
+		x, y = x+dx, y+dy
+		if x < 0 || x >= g.Width || y < 0 || y >= g.Height || !IsEmpty(g.Cells[y][x]) {
+			return distance
+		}
+		distance++
+	}
+}
@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "testing"
+
+func TestNearestObstacleDistance(t *testing.T) {
+	g := testScenario(t, `{"you": 1, "grid": [
+		"..x.....",
+		"........",
+		"1>...2..",
+		"..x.....",
+		"........"
+	], "players": {"1": {"x": 1, "y": 2}}}`)
+	for _, tc := range []struct {
+		x, y      int
+		direction Direction
+		want      int
+	}{
+		{1, 2, DirectionUp, 2},
+		{1, 2, DirectionDown, 2},
+		{1, 2, DirectionLeft, 0},
+		{1, 2, DirectionRight, 3},
+		{2, 1, DirectionUp, 0},
+		{2, 1, DirectionDown, 1},
+		{5, 1, DirectionUp, 1},
+		{5, 1, DirectionDown, 0},
+		{6, 2, DirectionLeft, 0},
+		{6, 2, DirectionRight, 1},
+		{7, 4, DirectionRight, 0},
+		{0, 4, DirectionRight, 7},
+		{2, 3, DirectionUp, 2},
+		{1, 2, Direction("diagonal"), 0},
+		{-1, 2, DirectionRight, 0},
+		{8, 2, DirectionLeft, 0},
+	} {
+		if got := NearestObstacleDistance(g, tc.x, tc.y, tc.direction); got != tc.want {
+			t.Errorf("(%d, %d) %s: got %d, want %d", tc.x, tc.y, tc.direction, got, tc.want)
+		}
+	}
+}
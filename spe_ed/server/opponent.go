@@ -0,0 +1,137 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"sort"
+	"sync"
+)
+
+// OpponentModel records the actions and speeds of all players observed over a game, so AIs can predict the next action of an opponent instead of assuming all actions are equally likely.
+// The actions are inferred from consecutive states given to Observe (see InferAction). A new OpponentModel (or one after Reset) must be used for every game.
+// OpponentModel is safe for concurrent use.
+type OpponentModel struct {
+	l sync.Mutex
+
+	last         *Game
+	actions      map[int]map[Action]int
+	speeds       map[int]int
+	observations map[int]int
+}
+
+// OpponentModelAI is an optional interface for AIs which use an OpponentModel.
+// SetOpponentModel is called before the first state of a game is given to the AI. The model is updated with every state before it is given to the AI.
+type OpponentModelAI interface {
+	SetOpponentModel(m *OpponentModel)
+}
+
+// NewOpponentModel returns an empty model.
+func NewOpponentModel() *OpponentModel {
+	m := new(OpponentModel)
+	m.Reset()
+	return m
+}
+
+// Reset removes all observations, e.g. before a new game starts.
+func (m *OpponentModel) Reset() {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	m.reset()
+}
+
+// reset removes all observations. The caller must hold the lock.
+func (m *OpponentModel) reset() {
+	m.last = nil
+	m.actions = make(map[int]map[Action]int)
+	m.speeds = make(map[int]int)
+	m.observations = make(map[int]int)
+}
+
+// Observe records the next state of the game. For every player active in the previous and the current state, the action between both states is counted.
+// The first state only becomes the previous state, so nothing is counted before the second state (see InferAction). A state with another board size belongs to a new game and removes all observations.
+// States must be given in the order of the rounds. The game is copied and can be changed after Observe returns.
+func (m *OpponentModel) Observe(g *Game) {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	if m.last != nil && (m.last.Width != g.Width || m.last.Height != g.Height) {
+		m.reset()
+	}
+	if m.last != nil {
+		for id := range g.Players {
+			prev, ok := m.last.Players[id]
+			cur := g.Players[id]
+			if !ok || !prev.Active || !cur.Active {
+				continue
+			}
+			action, ok := InferAction(m.last, g, id)
+			if !ok {
+				continue
+			}
+			if m.actions[id] == nil {
+				m.actions[id] = make(map[Action]int, 5)
+			}
+			m.actions[id][action]++
+			m.speeds[id] += cur.Speed
+			m.observations[id]++
+		}
+	}
+	m.last = g.Clone()
+}
+
+// Observations returns the number of actions observed for the player. It is 0 until the second state of the game was observed.
+func (m *OpponentModel) Observations(playerID int) int {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	return m.observations[playerID]
+}
+
+// PredictAction returns the action the player performed most often together with a confidence between 0 and 1.
+// The confidence is the share of the action in all observations of the player, smoothed by counting every action once more, so few observations lead to a low confidence.
+// Ties are broken by the order of the action names. Without observations (e.g. on the first tick), ActionNOOP is returned with a confidence of 0, so it must not be relied upon.
+func (m *OpponentModel) PredictAction(playerID int) (Action, float64) {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	n := m.observations[playerID]
+	if n == 0 {
+		return ActionNOOP, 0
+	}
+	actions := []Action{ActionNOOP, ActionTurnLeft, ActionTurnRight, ActionSlower, ActionFaster}
+	sort.Slice(actions, func(i, j int) bool { return actions[i] < actions[j] })
+	var best Action
+	bestCount := -1
+	for _, a := range actions {
+		if m.actions[playerID][a] > bestCount {
+			best = a
+			bestCount = m.actions[playerID][a]
+		}
+	}
+	return best, float64(bestCount+1) / float64(n+len(actions))
+}
+
+// TypicalSpeed returns the average speed of the player over all observations or 0 without observations.
+func (m *OpponentModel) TypicalSpeed(playerID int) float64 {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	if m.observations[playerID] == 0 {
+		return 0
+	}
+	return float64(m.speeds[playerID]) / float64(m.observations[playerID])
+}
@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "testing"
+
+// opponentModelGame returns an empty 20x20 game with player 1 at (5, 10) moving right and player 2 at (15, 5) moving down, both with speed 1.
+func opponentModelGame() *Game {
+	g := &Game{Width: 20, Height: 20, Running: true, You: 1, Players: map[int]*Player{
+		1: {X: 5, Y: 10, Direction: DirectionRight, Speed: 1, Active: true},
+		2: {X: 15, Y: 5, Direction: DirectionDown, Speed: 1, Active: true},
+	}}
+	g.Cells = make([][]int8, g.Height)
+	for y := range g.Cells {
+		g.Cells[y] = make([]int8, g.Width)
+	}
+	for id, p := range g.Players {
+		g.Cells[p.Y][p.X] = PlayerCell(id)
+	}
+	return g
+}
+
+func TestOpponentModel(t *testing.T) {
+	g := opponentModelGame()
+	m := NewOpponentModel()
+	m.Observe(g)
+	if a, confidence := m.PredictAction(1); a != ActionNOOP || confidence != 0 {
+		t.Errorf("predicted %s with confidence %f without observations", a, confidence)
+	}
+
+	for round, actions := range [][2]Action{
+		{ActionFaster, ActionTurnRight},
+		{ActionTurnLeft, ActionTurnRight},
+		{ActionNOOP, ActionTurnLeft},
+		{ActionNOOP, ActionTurnRight},
+	} {
+		for i, a := range actions {
+			if err := ApplyAction(g, i+1, a); err != nil || !g.Players[i+1].Active {
+				t.Fatalf("round %d: player %d crashed playing %s (%v)", round+1, i+1, a, err)
+			}
+		}
+		m.Observe(g)
+	}
+
+	for _, tc := range []struct {
+		id         int
+		action     Action
+		confidence float64
+		speed      float64
+	}{
+		{1, ActionNOOP, 3.0 / 9, 2},
+		{2, ActionTurnRight, 4.0 / 9, 1},
+	} {
+		if a, confidence := m.PredictAction(tc.id); a != tc.action || confidence != tc.confidence {
+			t.Errorf("player %d: predicted %s with confidence %f, want %s with %f", tc.id, a, confidence, tc.action, tc.confidence)
+		}
+		if speed := m.TypicalSpeed(tc.id); speed != tc.speed {
+			t.Errorf("player %d: typical speed %f, want %f", tc.id, speed, tc.speed)
+		}
+	}
+
+	m.Reset()
+	if a, confidence := m.PredictAction(2); a != ActionNOOP || confidence != 0 {
+		t.Errorf("predicted %s with confidence %f after Reset", a, confidence)
+	}
+}
+
+func TestOpponentModelFirstTick(t *testing.T) {
+	s, err := NewSimulator(10, 10, 9, "SurvivalAI", "SurvivalAI")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewOpponentModel()
+	m.Observe(s.Game)
+	for id := range s.Game.Players {
+		if n := m.Observations(id); n != 0 {
+			t.Errorf("player %d: %d observations after the first state", id, n)
+		}
+		if a, confidence := m.PredictAction(id); a != ActionNOOP || confidence != 0 {
+			t.Errorf("player %d: predicted %s with confidence %f without observations", id, a, confidence)
+		}
+	}
+	s.Step()
+	m.Observe(s.Game)
+	for id, p := range s.Game.Players {
+		if n := m.Observations(id); p.Active && n != 1 {
+			t.Errorf("player %d: %d observations after the second state, want 1", id, n)
+		}
+	}
+
+	m.Observe(testScenario(t, `{"you": 1, "grid": ["1>.", "2>."], "players": {"1": {}, "2": {}}}`))
+	for id := range s.Game.Players {
+		if n := m.Observations(id); n != 0 {
+			t.Errorf("player %d: %d observations after the board size changed", id, n)
+		}
+	}
+}
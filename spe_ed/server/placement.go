@@ -0,0 +1,123 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+const (
+	// PlacementRandom contains the placement choosing a random free cell and a random direction for every player.
+	PlacementRandom = "random"
+	// PlacementSymmetric contains the placement mirroring the start of every second player through the center of the board (including the direction), so both players of a pair have the same situation.
+	// With an odd number of players, the last player is placed randomly.
+	PlacementSymmetric = "symmetric"
+	// PlacementCorners contains the placement starting the players near the corners of the board (and the middle of the top and bottom edge for players 5 and 6).
+	// The positions are mirrored through the center like with PlacementSymmetric and do not depend on the seed.
+	PlacementCorners = "corners"
+)
+
+// ValidatePlacement returns an error if the placement is unknown.
+func ValidatePlacement(placement string) error {
+	switch placement {
+	case PlacementRandom, PlacementSymmetric, PlacementCorners:
+		return nil
+	default:
+		return fmt.Errorf("unknown placement %q (must be %s, %s or %s)", placement, PlacementRandom, PlacementSymmetric, PlacementCorners)
+	}
+}
+
+// mirrorDirection returns the direction rotated by 180 degrees.
+func mirrorDirection(direction Direction) Direction {
+	switch direction {
+	case DirectionUp:
+		return DirectionDown
+	case DirectionDown:
+		return DirectionUp
+	case DirectionLeft:
+		return DirectionRight
+	case DirectionRight:
+		return DirectionLeft
+	}
+	return direction
+}
+
+// newPlacer returns a function giving the start position and direction of the i-th of n players (starting with 0) in the game.
+// The function must be called in the order of the players and the caller must fill the start cell before the next call, since only free cells are chosen.
+// The game must be large enough for n players.
+func newPlacer(placement string, g *Game, n int, r *rand.Rand) (func(i int) (int, int, Direction), error) {
+	directions := []Direction{DirectionUp, DirectionDown, DirectionLeft, DirectionRight}
+
+	random := func(i int) (int, int, Direction) {
+		x, y := r.Intn(g.Width), r.Intn(g.Height)
+		for !IsEmpty(g.Cells[y][x]) {
+			x, y = r.Intn(g.Width), r.Intn(g.Height)
+		}
+		return x, y, directions[r.Intn(len(directions))]
+	}
+
+	switch placement {
+	case PlacementRandom:
+		return random, nil
+	case PlacementSymmetric:
+		var last coordinate
+		var lastDirection Direction
+		return func(i int) (int, int, Direction) {
+			if i%2 == 1 {
+				return g.Width - 1 - last.X, g.Height - 1 - last.Y, mirrorDirection(lastDirection)
+			}
+			if i == n-1 {
+				return random(i)
+			}
+			// Both cells of the pair must be free and different. Since all pairs are disjoint, there is always a free pair left.
+			x, y, d := random(i)
+			for !IsEmpty(g.Cells[g.Height-1-y][g.Width-1-x]) || (g.Width-1-x == x && g.Height-1-y == y) {
+				x, y, d = random(i)
+			}
+			last, lastDirection = coordinate{x, y}, d
+			return x, y, d
+		}, nil
+	case PlacementCorners:
+		mx, my := g.Width/8, g.Height/8
+		positions := []struct {
+			c coordinate
+			d Direction
+		}{
+			{coordinate{mx, my}, DirectionRight},
+			{coordinate{g.Width - 1 - mx, g.Height - 1 - my}, DirectionLeft},
+			{coordinate{g.Width - 1 - mx, my}, DirectionLeft},
+			{coordinate{mx, g.Height - 1 - my}, DirectionRight},
+			{coordinate{(g.Width - 1) / 2, my}, DirectionDown},
+			{coordinate{g.Width - 1 - (g.Width-1)/2, g.Height - 1 - my}, DirectionUp},
+		}
+		if n > len(positions) {
+			return nil, fmt.Errorf("placement %s supports at most %d players", placement, len(positions))
+		}
+		seen := make(map[coordinate]bool, n)
+		for i := 0; i < n; i++ {
+			if seen[positions[i].c] {
+				return nil, fmt.Errorf("game is too small for placement %s", placement)
+			}
+			seen[positions[i].c] = true
+		}
+		return func(i int) (int, int, Direction) {
+			return positions[i].c.X, positions[i].c.Y, positions[i].d
+		}, nil
+	default:
+		return nil, ValidatePlacement(placement)
+	}
+}
@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "testing"
+
+// placedSimulator returns a simulator with n players placed with the given placement.
+func placedSimulator(t *testing.T, width, height int, seed int64, placement string, n int) *Simulator {
+	t.Helper()
+	ais := make([]AI, n)
+	for i := range ais {
+		ais[i] = &scriptedAI{}
+	}
+	s, err := NewSimulatorWithPlacement(width, height, seed, placement, ais...)
+	if err != nil {
+		t.Fatalf("%dx%d, %d players: %v", width, height, n, err)
+	}
+	return s
+}
+
+// checkMirrored reports an error if player b does not start at the position of player a mirrored through the center of the board, facing the opposite direction.
+func checkMirrored(t *testing.T, g *Game, a, b int) {
+	t.Helper()
+	pa, pb := g.Players[a], g.Players[b]
+	if pb.X != g.Width-1-pa.X || pb.Y != g.Height-1-pa.Y || pb.Direction != mirrorDirection(pa.Direction) {
+		t.Errorf("%dx%d: player %d at (%d, %d) %s does not mirror player %d at (%d, %d) %s", g.Width, g.Height, b, pb.X, pb.Y, pb.Direction, a, pa.X, pa.Y, pa.Direction)
+	}
+}
+
+func TestPlacementSymmetric(t *testing.T) {
+	for _, size := range [][2]int{{2, 1}, {3, 3}, {41, 41}, {80, 41}, {50, 60}} {
+		for seed := int64(1); seed <= 50; seed++ {
+			g := placedSimulator(t, size[0], size[1], seed, PlacementSymmetric, 2).Game
+			checkMirrored(t, g, 1, 2)
+			if g.Players[1].X == g.Players[2].X && g.Players[1].Y == g.Players[2].Y {
+				t.Errorf("%dx%d: both players start at (%d, %d)", g.Width, g.Height, g.Players[1].X, g.Players[1].Y)
+			}
+		}
+	}
+
+	for n := 3; n <= PlayersPerGame; n++ {
+		g := placedSimulator(t, 41, 41, 7, PlacementSymmetric, n).Game
+		for a := 1; a+1 <= n; a += 2 {
+			checkMirrored(t, g, a, a+1)
+		}
+	}
+}
+
+func TestPlacementCorners(t *testing.T) {
+	first := placedSimulator(t, 41, 50, 1, PlacementCorners, PlayersPerGame).Game
+	second := placedSimulator(t, 41, 50, 2, PlacementCorners, PlayersPerGame).Game
+	for id := 1; id <= PlayersPerGame; id++ {
+		p, o := first.Players[id], second.Players[id]
+		if p.X != o.X || p.Y != o.Y || p.Direction != o.Direction {
+			t.Errorf("player %d starts at (%d, %d) and (%d, %d) with different seeds", id, p.X, p.Y, o.X, o.Y)
+		}
+	}
+	for a := 1; a < PlayersPerGame; a += 2 {
+		checkMirrored(t, first, a, a+1)
+	}
+
+	ais := make([]AI, PlayersPerGame)
+	for i := range ais {
+		ais[i] = &scriptedAI{}
+	}
+	if _, err := NewSimulatorWithPlacement(2, 3, 1, PlacementCorners, ais...); err == nil {
+		t.Error("corners accepted on a 2x3 board with 6 players")
+	}
+}
+
+func TestValidatePlacement(t *testing.T) {
+	for _, placement := range []string{PlacementRandom, PlacementSymmetric, PlacementCorners} {
+		if err := ValidatePlacement(placement); err != nil {
+			t.Errorf("%s: %v", placement, err)
+		}
+	}
+	if err := ValidatePlacement("circle"); err == nil {
+		t.Error("unknown placement accepted")
+	}
+}
@@ -17,55 +17,185 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"sync"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/gorilla/websocket"
 )
 
+// ErrUnknownDirection is returned by ParseDirection if the string is not a valid direction.
+var ErrUnknownDirection = errors.New("unknown direction")
+
+// PlayerNameMaxLength contains the maximum number of characters of a name chosen by a player (see ValidatePlayerName).
+const PlayerNameMaxLength = 32
+
+// ValidatePlayerName returns an error if the name chosen by a player is empty, longer than PlayerNameMaxLength characters or contains characters which are not printable.
+func ValidatePlayerName(name string) error {
+	if name == "" || utf8.RuneCountInString(name) > PlayerNameMaxLength {
+		return fmt.Errorf("player name must have 1 to %d characters", PlayerNameMaxLength)
+	}
+	for _, r := range name {
+		if !unicode.IsPrint(r) {
+			return fmt.Errorf("player name %q contains unprintable characters", name)
+		}
+	}
+	return nil
+}
+
+// Direction represents the direction a player is heading to.
+type Direction string
+
 const (
 	// DirectionUp contains the string value representing "up"
-	DirectionUp = "up"
+	DirectionUp Direction = "up"
 	// DirectionDown contains the string value representing "down"
-	DirectionDown = "down"
+	DirectionDown Direction = "down"
 	// DirectionLeft contains the string value representing "left"
-	DirectionLeft = "left"
+	DirectionLeft Direction = "left"
 	// DirectionRight contains the string value representing "right"
-	DirectionRight = "right"
+	DirectionRight Direction = "right"
 )
 
+// Directions contains all valid directions in the order up, down, left, right.
+var Directions = [...]Direction{DirectionUp, DirectionDown, DirectionLeft, DirectionRight}
+
+// String returns the direction as sent by the server.
+func (d Direction) String() string {
+	return string(d)
+}
+
+// ParseDirection returns the direction represented by the string. ErrUnknownDirection is returned for all other strings.
+func ParseDirection(s string) (Direction, error) {
+	switch d := Direction(s); d {
+	case DirectionUp, DirectionDown, DirectionLeft, DirectionRight:
+		return d, nil
+	default:
+		return "", fmt.Errorf("%w %q", ErrUnknownDirection, s)
+	}
+}
+
+// turnLeft contains the direction after turning left for every direction.
+// Since it is the inverse of turnRight, it also undoes a right turn.
+var turnLeft = map[Direction]Direction{
+	DirectionUp:    DirectionLeft,
+	DirectionLeft:  DirectionDown,
+	DirectionDown:  DirectionRight,
+	DirectionRight: DirectionUp,
+}
+
+// turnRight contains the direction after turning right for every direction.
+// Since it is the inverse of turnLeft, it also undoes a left turn.
+var turnRight = map[Direction]Direction{
+	DirectionUp:    DirectionRight,
+	DirectionRight: DirectionDown,
+	DirectionDown:  DirectionLeft,
+	DirectionLeft:  DirectionUp,
+}
+
+// Turn returns the direction after performing the action.
+// Only ActionTurnLeft and ActionTurnRight change the direction. An unknown direction is returned unchanged.
+func Turn(d Direction, action Action) Direction {
+	var n Direction
+	var ok bool
+	switch action {
+	case ActionTurnLeft:
+		n, ok = turnLeft[d]
+	case ActionTurnRight:
+		n, ok = turnRight[d]
+	}
+	if !ok {
+		return d
+	}
+	return n
+}
+
+// PlayerHistorySize contains the number of rounds for which the positions of a player are remembered (see IsLooping).
+const PlayerHistorySize = 8
+
 // Player represents a player of the game.
 // It might be a player connected through websocket or an AI.
 type Player struct {
-	X         int    `json:"x"`
-	Y         int    `json:"y"`
-	Direction string `json:"direction"`
-	Speed     int    `json:"speed"`
-	Active    bool   `json:"active"`
-	Name      string `json:"name,omitempty"`
+	X         int       `json:"x"`
+	Y         int       `json:"y"`
+	Direction Direction `json:"direction"`
+	Speed     int       `json:"speed"`
+	Active    bool      `json:"active"`
+	Name      string    `json:"name,omitempty"`
 
 	// To know where wholes need to be
 	stepCounter int
 
+	// To detect loops
+	history positionHistory
+
 	// In case of an AI
 	underlyingAI AI
 
 	// Real name - use after game has finished
 	realName string
 
+	// Name chosen by the player, revealed instead of realName if set and enabled by -chosennames
+	chosenName string
+
 	// API key
 	api         string
 	apiReleased bool
 
 	// Websocket
 	inputLock  sync.Mutex
-	Input      chan string `json:"-"` // Must be non-blocking
+	Input      chan Action `json:"-"` // Must be non-blocking
 	writerLock sync.Mutex
 	ws         *websocket.Conn
 	wsclosed   bool
 	workerOnce sync.Once
 }
 
+// AdvanceTurn increases the step counter of the player, which decides in which rounds holes occur (see HoleRules).
+// It must be called exactly once per round for every moving player, after direction and speed are changed and before the cells are traversed.
+// Therefore, the step counter equals the number of the current round (starting with 1) during a move and the number of the last round between moves.
+// AIs simulating future rounds on a copy of the game (see Game.Clone, which keeps the step counter) must call it in the same way to predict holes correctly.
+// It also remembers the position before the move (see IsLooping).
+func (p *Player) AdvanceTurn() {
+	p.stepCounter++
+	p.history.add(coordinate{p.X, p.Y})
+}
+
+// positionHistory is a ring buffer containing the last PlayerHistorySize positions of a player.
+// It is a value type, so copying it copies the whole history.
+type positionHistory struct {
+	positions [PlayerHistorySize]coordinate
+	n, next   int
+}
+
+// add adds a position, overwriting the oldest one if the buffer is full.
+func (h *positionHistory) add(c coordinate) {
+	h.positions[h.next] = c
+	h.next = (h.next + 1) % PlayerHistorySize
+	if h.n < PlayerHistorySize {
+		h.n++
+	}
+}
+
+// contains returns whether the position is part of the history.
+func (h *positionHistory) contains(c coordinate) bool {
+	for i := 0; i < h.n; i++ {
+		if h.positions[i] == c {
+			return true
+		}
+	}
+	return false
+}
+
+// IsLooping returns whether the player is at a position it already had at the start of one of the last PlayerHistorySize rounds, i.e. it moves in a tight cycle.
+// The positions are only known for rounds played through Player.AdvanceTurn. States received from the server do not contain them, so a player without history is never looping.
+func IsLooping(p *Player) bool {
+	return p.history.contains(coordinate{p.X, p.Y})
+}
+
 func (p *Player) readWorker() {
 	defer func() {
 		if p.Input != nil {
@@ -95,7 +225,7 @@ func (p *Player) readWorker() {
 			p.writerLock.Lock()
 			if !p.wsclosed {
 				// Ok, it is not just closed
-				log.Println("player read error:", p.api, "-", err)
+				log.Println("player read error:", RedactKey(p.api), "-", err)
 			}
 			if websocket.IsUnexpectedCloseError(err) {
 				p.wsclosed = true
@@ -105,14 +235,14 @@ func (p *Player) readWorker() {
 			return
 		}
 
-		var a Action
+		var a ActionMessage
 		err = json.Unmarshal(b, &a)
 		if err != nil {
 			// Stop on error - something went wrong
 			p.writerLock.Lock()
 			if !p.wsclosed {
 				// Ok, it is not just closed
-				log.Println("player json error:", p.api, "-", err, "-", string(b))
+				log.Println("player json error:", RedactKey(p.api), "-", err, "-", string(b))
 			}
 			p.writerLock.Unlock()
 			return
@@ -135,7 +265,11 @@ func (p *Player) WriteState(g *Game) error {
 
 	if p.underlyingAI != nil {
 		// Pass copy
-		go p.underlyingAI.GetState(g.PublicCopy())
+		var deadline time.Time
+		if remaining, ok := g.RemainingTime(); ok {
+			deadline = time.Now().Add(remaining)
+		}
+		go getAIState(p.underlyingAI, g.PublicCopy(), deadline)
 		return nil
 	}
 
@@ -158,10 +292,14 @@ func (p *Player) WriteState(g *Game) error {
 }
 
 // RevealName will make the pseudonym visible to everyone.
+// If the server was started with -chosennames, the name chosen by the player is revealed instead.
 func (p *Player) RevealName() {
 	p.writerLock.Lock()
 	defer p.writerLock.Unlock()
 	p.Name = p.realName
+	if chosenNames && p.chosenName != "" {
+		p.Name = p.chosenName
+	}
 }
 
 // Close will close the player, releasing all ressources (like the websocket).
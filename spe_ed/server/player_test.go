@@ -0,0 +1,193 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAdvanceTurnHoles(t *testing.T) {
+	const speed = 4
+	const rounds = 2*HolesEachStep + 1
+	g := testScenario(t, `{"you": 1, "grid": ["1>`+strings.Repeat(".", rounds*speed)+`"], "players": {"1": {"speed": 4}}}`)
+	for round := 1; round <= rounds; round++ {
+		if round == 4 {
+			// Continue on a copy, which must keep the step counter.
+			g = g.Clone()
+		}
+		err := ApplyAction(g, 1, ActionNOOP)
+		if err != nil {
+			t.Fatalf("round %d: %v", round, err)
+		}
+		if g.Players[1].stepCounter != round {
+			t.Fatalf("round %d: step counter %d", round, g.Players[1].stepCounter)
+		}
+	}
+
+	var got strings.Builder
+	for x := 2; x < g.Width; x++ {
+		if IsEmpty(g.Cells[0][x]) {
+			got.WriteByte('.')
+		} else {
+			got.WriteByte('1')
+		}
+	}
+	// Only the first and the last cell are filled in rounds 6 and 12.
+	want := strings.Repeat("1111", 5) + "1..1" + strings.Repeat("1111", 5) + "1..1" + "1111"
+	if got.String() != want {
+		t.Errorf("trail %s, want %s", got.String(), want)
+	}
+}
+
+func TestIsLooping(t *testing.T) {
+	p := &Player{Active: true, Speed: 1}
+	if IsLooping(p) {
+		t.Fatal("player without history is looping")
+	}
+
+	// A 4-cycle around a square of 2x2 cells
+	cycle := []coordinate{{1, 0}, {1, 1}, {0, 1}, {0, 0}}
+	for i, c := range cycle {
+		p.AdvanceTurn()
+		p.X, p.Y = c.X, c.Y
+		if looping := IsLooping(p); looping != (i == len(cycle)-1) {
+			t.Errorf("round %d at (%d, %d): looping %t", i+1, p.X, p.Y, looping)
+		}
+	}
+
+	g := &Game{Players: map[int]*Player{1: p}}
+	if !IsLooping(g.Clone().Players[1]) {
+		t.Error("clone lost the history")
+	}
+
+	// Moving straight on, the cycle leaves the history after PlayerHistorySize rounds
+	for i := 1; i <= PlayerHistorySize; i++ {
+		p.AdvanceTurn()
+		p.X = 10 + i
+		if IsLooping(p) {
+			t.Errorf("looping at (%d, %d) after leaving the cycle", p.X, p.Y)
+		}
+	}
+	p.AdvanceTurn()
+	p.X, p.Y = 0, 0
+	if IsLooping(p) {
+		t.Errorf("start of the cycle still in the history after %d rounds", PlayerHistorySize+1)
+	}
+}
+
+func TestParseDirection(t *testing.T) {
+	for _, d := range Directions {
+		got, err := ParseDirection(d.String())
+		if err != nil || got != d {
+			t.Errorf("%s parsed as %q (%v)", d, got, err)
+		}
+	}
+	for _, s := range []string{"", "Up", "north", "diagonal"} {
+		if _, err := ParseDirection(s); !errors.Is(err, ErrUnknownDirection) {
+			t.Errorf("%q: %v", s, err)
+		}
+	}
+}
+
+func TestTurn(t *testing.T) {
+	want := map[Direction][2]Direction{
+		DirectionUp:    {DirectionLeft, DirectionRight},
+		DirectionDown:  {DirectionRight, DirectionLeft},
+		DirectionLeft:  {DirectionDown, DirectionUp},
+		DirectionRight: {DirectionUp, DirectionDown},
+	}
+	for _, d := range Directions {
+		left, right := Turn(d, ActionTurnLeft), Turn(d, ActionTurnRight)
+		if left != want[d][0] || right != want[d][1] {
+			t.Errorf("%s: left %s, right %s", d, left, right)
+		}
+		if Turn(left, ActionTurnRight) != d || Turn(right, ActionTurnLeft) != d {
+			t.Errorf("%s: turns are not inverse", d)
+		}
+		if turnLeft[turnRight[d]] != d || turnRight[turnLeft[d]] != d {
+			t.Errorf("%s: tables are not inverse", d)
+		}
+		if Turn(Turn(left, ActionTurnLeft), ActionTurnLeft) != right {
+			t.Errorf("%s: three left turns are not a right turn", d)
+		}
+		for _, a := range []Action{ActionNOOP, ActionSlower, ActionFaster, "jump"} {
+			if Turn(d, a) != d {
+				t.Errorf("%s: %s changes the direction", d, a)
+			}
+		}
+	}
+	if Turn("diagonal", ActionTurnLeft) != "diagonal" {
+		t.Error("unknown direction changed")
+	}
+}
+
+func TestPlayerName(t *testing.T) {
+	for name, valid := range map[string]bool{
+		"Team Tester":  true,
+		"Größenwahn ✓": true,
+		strings.Repeat("ä", PlayerNameMaxLength): true,
+		"": false,
+		strings.Repeat("a", PlayerNameMaxLength+1): false,
+		"tab\tname": false,
+		"new\nline": false,
+	} {
+		if err := ValidatePlayerName(name); (err == nil) != valid {
+			t.Errorf("%q: got error %v, want valid %t", name, err, valid)
+		}
+	}
+
+	g := testScenario(t, `{"you": 1, "grid": ["1>..<2", "3>...."], "players": {"1": {"name": "Team Tester"}, "2": {"name": "Größenwahn ✓"}, "3": {}}}`)
+	want := map[int]string{1: "Team Tester", 2: "Größenwahn ✓", 3: ""}
+	check := func(how string, g *Game) {
+		t.Helper()
+		for id, name := range want {
+			if g.Players[id].Name != name {
+				t.Errorf("%s: player %d has name %q, want %q", how, id, g.Players[id].Name, name)
+			}
+		}
+	}
+	b, err := json.Marshal(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r Game
+	err = json.Unmarshal(b, &r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check("JSON", &r)
+	check("Clone", g.Clone())
+	check("ViewFor", g.ViewFor(2))
+}
+
+func TestRevealName(t *testing.T) {
+	defer func(old bool) { chosenNames = old }(chosenNames)
+	for _, enabled := range []bool{false, true} {
+		chosenNames = enabled
+		p := Player{realName: "Holunderbaum-Xenolith-Ingenieur", chosenName: "Team Tester"}
+		p.RevealName()
+		want := p.realName
+		if enabled {
+			want = p.chosenName
+		}
+		if p.Name != want {
+			t.Errorf("chosennames %t: revealed %q, want %q", enabled, p.Name, want)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"sync"
+)
+
+// PolicyNetRadius contains the number of cells around the head of the player encoded by Encode in every direction.
+const PolicyNetRadius = 5
+
+// PolicyNetWindow contains the width and height of the window around the head encoded by Encode.
+const PolicyNetWindow = 2*PolicyNetRadius + 1
+
+// PolicyNetInputs contains the number of features returned by Encode: PolicyNetWindow*PolicyNetWindow cells followed by the speed and the hole flag.
+const PolicyNetInputs = PolicyNetWindow*PolicyNetWindow + 2
+
+// Encode returns the features of the game for Game.You used as input of PolicyNet. It is a pure function, the game is not modified.
+// The features are a window of PolicyNetWindow x PolicyNetWindow cells centred on the head of the player, rotated so that the player heads upwards (the occupancy patch of FeatureExtractor with PolicyNetRadius).
+// The window is encoded row by row, starting with the row furthest ahead, and every row from left to right (as seen by the player). A cell is 1 if it is filled or outside of the board and 0 if it is free; the head itself is 1.
+// They are followed by the speed divided by MaxSpeed and by 1 if the next move leaves holes when keeping the speed (see HoleRules) or 0 otherwise.
+// The result always has PolicyNetInputs elements. An unknown or inactive player is encoded as a completely filled window with speed 0.
+func Encode(g *Game) []float32 {
+	p, ok := g.Players[g.You]
+	if !ok || !p.Active {
+		features := make([]float32, PolicyNetInputs)
+		for i := 0; i < PolicyNetWindow*PolicyNetWindow; i++ {
+			features[i] = 1
+		}
+		return features
+	}
+
+	features := appendPatch(make([]float32, 0, PolicyNetInputs), g, p, PolicyNetRadius)
+	features = append(features, float32(p.Speed)/MaxSpeed)
+	return append(features, oneHot(!g.Holes.Disabled && p.Speed >= g.Holes.MinSpeed() && (p.stepCounter+1)%g.Holes.Interval() == 0))
+}
+
+// PolicyNetLayer is a fully connected layer of a PolicyNet.
+type PolicyNetLayer struct {
+	// Weights contains one row per output, each row contains one weight per input.
+	Weights [][]float32 `json:"weights"`
+	// Biases contains one bias per output.
+	Biases []float32 `json:"biases"`
+}
+
+// PolicyNet is a small feed-forward network scoring the actions of a player (see PolicyNetAI).
+// The input is the encoding of the game (see Encode), every hidden layer uses ReLU as activation and the last layer returns one score per action in the order of Actions.
+// The network is only used for inference, it is trained offline (e.g. on samples written by SelfPlayExporter).
+type PolicyNet struct {
+	Layers []PolicyNetLayer `json:"layers"`
+}
+
+// Validate returns an error if the layers do not fit together, the first layer does not take PolicyNetInputs inputs, the last layer does not return one score per action or a value is not a finite number.
+func (n *PolicyNet) Validate() error {
+	if len(n.Layers) == 0 {
+		return fmt.Errorf("policy net: no layers")
+	}
+	inputs := PolicyNetInputs
+	for l := range n.Layers {
+		layer := n.Layers[l]
+		if len(layer.Weights) == 0 || len(layer.Weights) != len(layer.Biases) {
+			return fmt.Errorf("policy net: layer %d has %d weight rows and %d biases", l+1, len(layer.Weights), len(layer.Biases))
+		}
+		for o := range layer.Weights {
+			if len(layer.Weights[o]) != inputs {
+				return fmt.Errorf("policy net: layer %d needs %d weights per output, but output %d has %d", l+1, inputs, o+1, len(layer.Weights[o]))
+			}
+			if !finite(layer.Biases[o]) {
+				return fmt.Errorf("policy net: layer %d contains a value which is not a finite number", l+1)
+			}
+			for _, v := range layer.Weights[o] {
+				if !finite(v) {
+					return fmt.Errorf("policy net: layer %d contains a value which is not a finite number", l+1)
+				}
+			}
+		}
+		inputs = len(layer.Weights)
+	}
+	if inputs != len(Actions) {
+		return fmt.Errorf("policy net: last layer must have %d outputs, but has %d", len(Actions), inputs)
+	}
+	return nil
+}
+
+// finite returns whether v is neither NaN nor infinite.
+func finite(v float32) bool {
+	return !math.IsNaN(float64(v)) && !math.IsInf(float64(v), 0)
+}
+
+// Forward returns the scores of all actions in the order of Actions for the given features (see Encode).
+// The network must be valid (see Validate).
+func (n *PolicyNet) Forward(features []float32) []float32 {
+	values := features
+	for l := range n.Layers {
+		layer := n.Layers[l]
+		out := make([]float32, len(layer.Weights))
+		for o := range layer.Weights {
+			sum := layer.Biases[o]
+			for i, w := range layer.Weights[o] {
+				sum += w * values[i]
+			}
+			if l != len(n.Layers)-1 && sum < 0 {
+				// ReLU
+				sum = 0
+			}
+			out[o] = sum
+		}
+		values = out
+	}
+	return values
+}
+
+// LoadPolicyNet reads a network from a JSON file, e.g. {"layers": [{"weights": [[...], ...], "biases": [...]}, ...]}.
+// Unknown keys and invalid networks (see PolicyNet.Validate) are an error.
+func LoadPolicyNet(path string) (*PolicyNet, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("policy net: %w", err)
+	}
+	n := new(PolicyNet)
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.DisallowUnknownFields()
+	err = d.Decode(n)
+	if err != nil {
+		return nil, fmt.Errorf("policy net: can not parse %s: %w", path, err)
+	}
+	err = n.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("%w (%s)", err, path)
+	}
+	return n, nil
+}
+
+var policyNet *PolicyNet
+var policyNetLock sync.Mutex
+
+// SetPolicyNet sets the network used by all PolicyNetAI created through the AI registry afterwards. The network must be valid (see PolicyNet.Validate) and must not be modified afterwards, nil removes it.
+func SetPolicyNet(n *PolicyNet) {
+	policyNetLock.Lock()
+	defer policyNetLock.Unlock()
+	policyNet = n
+}
+
+// GetPolicyNet returns the network set by SetPolicyNet or nil if none is set.
+func GetPolicyNet() *PolicyNet {
+	policyNetLock.Lock()
+	defer policyNetLock.Unlock()
+	return policyNet
+}
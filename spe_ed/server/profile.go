@@ -0,0 +1,159 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var profilingEnabled int32
+var profileLock sync.Mutex
+var profilePath string
+var profileFile *os.File
+var profileStats map[string]*decisionProfile
+
+// decisionProfile contains the measurements of all decisions of a single AI.
+type decisionProfile struct {
+	durations []time.Duration
+	mallocs   uint64
+	bytes     uint64
+}
+
+// StartProfiling starts recording a CPU profile to path (see runtime/pprof) and measuring the time and allocations of every decision (see getAIState).
+// StopProfiling must be called at the end of the program. Only a single profile can be recorded at a time.
+func StartProfiling(path string) error {
+	profileLock.Lock()
+	defer profileLock.Unlock()
+
+	if profileFile != nil {
+		return errors.New("profile: already running")
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("profile: %w", err)
+	}
+	err = pprof.StartCPUProfile(f)
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("profile: %w", err)
+	}
+	profilePath = path
+	profileFile = f
+	profileStats = make(map[string]*decisionProfile)
+	atomic.StoreInt32(&profilingEnabled, 1)
+	return nil
+}
+
+// ProfilingEnabled returns whether decisions are profiled. It is cheap, so profiling has no overhead while it is disabled.
+func ProfilingEnabled() bool {
+	return atomic.LoadInt32(&profilingEnabled) == 1
+}
+
+// StopProfiling stops the CPU profile, writes the allocation profile to the profile path with the suffix .allocs and logs a summary of all decisions per AI (latency percentiles and allocations per decision).
+// The allocations are counted for the whole program, so they are only exact if a single AI decides at a time (e.g. with -client, -dryrun or -benchmark).
+// Nothing is done if profiling is not running.
+func StopProfiling() error {
+	profileLock.Lock()
+	defer profileLock.Unlock()
+
+	if profileFile == nil {
+		return nil
+	}
+	atomic.StoreInt32(&profilingEnabled, 0)
+	pprof.StopCPUProfile()
+	err := profileFile.Close()
+	profileFile = nil
+	stats := profileStats
+	profileStats = nil
+	if err != nil {
+		return fmt.Errorf("profile: %w", err)
+	}
+
+	names := make([]string, 0, len(stats))
+	for name := range stats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p := stats[name]
+		sort.Slice(p.durations, func(i, j int) bool { return p.durations[i] < p.durations[j] })
+		n := float64(len(p.durations))
+		log.Printf("profile: %s: %d decisions, latency p50 %s, p95 %s, p99 %s, max %s, %.0f allocs and %.0f bytes per decision", name, len(p.durations), p.percentile(0.5), p.percentile(0.95), p.percentile(0.99), p.percentile(1), float64(p.mallocs)/n, float64(p.bytes)/n)
+	}
+	if len(names) == 0 {
+		log.Println("profile: no decisions")
+	}
+
+	f, err := os.Create(profilePath + ".allocs")
+	if err != nil {
+		return fmt.Errorf("profile: %w", err)
+	}
+	defer f.Close()
+	err = pprof.Lookup("allocs").WriteTo(f, 0)
+	if err != nil {
+		return fmt.Errorf("profile: %w", err)
+	}
+	log.Printf("profile: cpu profile written to %s, allocation profile to %s.allocs", profilePath, profilePath)
+	return nil
+}
+
+// profileDecision starts measuring a decision of the AI with the given name. The returned function must be called once the decision is done.
+func profileDecision(ai string) func() {
+	var before runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	return func() {
+		d := time.Since(start)
+		var after runtime.MemStats
+		runtime.ReadMemStats(&after)
+
+		profileLock.Lock()
+		defer profileLock.Unlock()
+		if profileStats == nil {
+			// Stopped in the meantime
+			return
+		}
+		p := profileStats[ai]
+		if p == nil {
+			p = new(decisionProfile)
+			profileStats[ai] = p
+		}
+		p.durations = append(p.durations, d)
+		p.mallocs += after.Mallocs - before.Mallocs
+		p.bytes += after.TotalAlloc - before.TotalAlloc
+	}
+}
+
+// percentile returns the duration below or equal to which the share q of all decisions lies. The durations must be sorted.
+func (p *decisionProfile) percentile(q float64) time.Duration {
+	if len(p.durations) == 0 {
+		return 0
+	}
+	i := int(math.Ceil(q*float64(len(p.durations)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	return p.durations[i].Round(time.Microsecond)
+}
@@ -0,0 +1,234 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "sort"
+
+// RegionGraph is a reduced graph of the free cells of a game (see BuildRegionGraph).
+// The nodes are chambers, the open areas of free cells, and the edges are corridors, the bottlenecks through which a player has to pass to get from one chamber to another.
+type RegionGraph struct {
+	// Chambers contains all chambers. The index of a chamber is its number.
+	Chambers []RegionChamber
+	// Corridors contains all corridors. The index of a corridor is its number.
+	Corridors []RegionCorridor
+
+	width, height int
+	// cells contains for every cell (y*width+x) the number of its chamber, -2-c for cells of corridor c and -1 for cells which are not free.
+	cells []int
+}
+
+// RegionChamber is a connected area of free cells without choke cells.
+type RegionChamber struct {
+	// Size is the number of cells of the chamber.
+	Size int
+	// Corridors contains the numbers of all corridors leading to the chamber in ascending order.
+	Corridors []int
+}
+
+// RegionCorridor is a connected group of choke cells.
+// A choke cell is a free cell which splits the free space into several parts once it is filled (an articulation point), e.g. every cell of a corridor with a width of one.
+// Usually a corridor connects two chambers. A corridor leading to a single chamber is a dead end, a corridor connecting more than two chambers is a junction of several corridors.
+type RegionCorridor struct {
+	// Cells contains the choke cells of the corridor.
+	Cells []struct{ X, Y int }
+	// Chambers contains the numbers of all chambers next to the corridor in ascending order.
+	Chambers []int
+}
+
+// BuildRegionGraph builds the region graph of the free cells of the game.
+// First, all choke cells are found with Tarjan's articulation point algorithm. Connected choke cells form corridors, the remaining connected free cells form chambers.
+// Since heads are filled cells, they belong to no chamber. The chamber of a player is the one next to its head (see RegionGraph.ChamberAt).
+// Unlike TrapCheck, the graph does not depend on a player, so it can be reused for all players in the same round.
+func BuildRegionGraph(g *Game) *RegionGraph {
+	r := &RegionGraph{
+		width:  g.Width,
+		height: g.Height,
+		cells:  make([]int, g.Width*g.Height),
+	}
+	free := func(c coordinate) bool {
+		return c.X >= 0 && c.X < g.Width && c.Y >= 0 && c.Y < g.Height && IsEmpty(g.Cells[c.Y][c.X])
+	}
+
+	// Tarjan's articulation point algorithm over the free cells.
+	// discovered is 0 for unvisited cells, so the discovery time starts with 1.
+	discovered := make([]int, g.Width*g.Height)
+	low := make([]int, g.Width*g.Height)
+	choke := make([]bool, g.Width*g.Height)
+	counter := 0
+
+	var dfs func(c coordinate, root bool)
+	dfs = func(c coordinate, root bool) {
+		i := c.Y*g.Width + c.X
+		counter++
+		discovered[i] = counter
+		low[i] = counter
+		children := 0
+		for _, n := range [4]coordinate{{c.X + 1, c.Y}, {c.X - 1, c.Y}, {c.X, c.Y + 1}, {c.X, c.Y - 1}} {
+			if !free(n) {
+				continue
+			}
+			j := n.Y*g.Width + n.X
+			if discovered[j] != 0 {
+				if discovered[j] < low[i] {
+					low[i] = discovered[j]
+				}
+				continue
+			}
+			children++
+			dfs(n, false)
+			if low[j] < low[i] {
+				low[i] = low[j]
+			}
+			if !root && low[j] >= discovered[i] {
+				choke[i] = true
+			}
+		}
+		if root && children > 1 {
+			choke[i] = true
+		}
+	}
+
+	for y := 0; y < g.Height; y++ {
+		for x := 0; x < g.Width; x++ {
+			r.cells[y*g.Width+x] = -1
+			if discovered[y*g.Width+x] == 0 && free(coordinate{x, y}) {
+				dfs(coordinate{x, y}, true)
+			}
+		}
+	}
+
+	// Group the cells into chambers and corridors.
+	// Corridors are numbered in a first pass, so the chambers next to them can be collected afterwards.
+	var queue []coordinate
+	fill := func(start coordinate, number int, isChoke bool) []coordinate {
+		var group []coordinate
+		r.cells[start.Y*g.Width+start.X] = number
+		queue = append(queue[:0], start)
+		for len(queue) != 0 {
+			c := queue[0]
+			queue = queue[1:]
+			group = append(group, c)
+			for _, n := range [4]coordinate{{c.X + 1, c.Y}, {c.X - 1, c.Y}, {c.X, c.Y + 1}, {c.X, c.Y - 1}} {
+				if !free(n) {
+					continue
+				}
+				j := n.Y*g.Width + n.X
+				if r.cells[j] != -1 || choke[j] != isChoke {
+					continue
+				}
+				r.cells[j] = number
+				queue = append(queue, n)
+			}
+		}
+		return group
+	}
+
+	for y := 0; y < g.Height; y++ {
+		for x := 0; x < g.Width; x++ {
+			i := y*g.Width + x
+			if !choke[i] || r.cells[i] != -1 {
+				continue
+			}
+			group := fill(coordinate{x, y}, -2-len(r.Corridors), true)
+			corridor := RegionCorridor{Cells: make([]struct{ X, Y int }, len(group))}
+			for k := range group {
+				corridor.Cells[k] = struct{ X, Y int }(group[k])
+			}
+			r.Corridors = append(r.Corridors, corridor)
+		}
+	}
+
+	for y := 0; y < g.Height; y++ {
+		for x := 0; x < g.Width; x++ {
+			i := y*g.Width + x
+			if r.cells[i] != -1 || !free(coordinate{x, y}) {
+				continue
+			}
+			number := len(r.Chambers)
+			group := fill(coordinate{x, y}, number, false)
+			chamber := RegionChamber{Size: len(group)}
+			for _, c := range group {
+				for _, n := range [4]coordinate{{c.X + 1, c.Y}, {c.X - 1, c.Y}, {c.X, c.Y + 1}, {c.X, c.Y - 1}} {
+					if !free(n) || r.cells[n.Y*g.Width+n.X] > -2 {
+						continue
+					}
+					corridor := -2 - r.cells[n.Y*g.Width+n.X]
+					known := false
+					for _, k := range chamber.Corridors {
+						known = known || k == corridor
+					}
+					if !known {
+						chamber.Corridors = append(chamber.Corridors, corridor)
+						// Chambers are numbered in ascending order, so the list of the corridor stays sorted
+						r.Corridors[corridor].Chambers = append(r.Corridors[corridor].Chambers, number)
+					}
+				}
+			}
+			sort.Ints(chamber.Corridors)
+			r.Chambers = append(r.Chambers, chamber)
+		}
+	}
+
+	return r
+}
+
+// ChamberAt returns the number of the chamber containing the cell (x, y) or -1 if the cell is not part of a chamber (it is outside the board, not free or a choke cell).
+func (r *RegionGraph) ChamberAt(x, y int) int {
+	if x < 0 || x >= r.width || y < 0 || y >= r.height || r.cells[y*r.width+x] < 0 {
+		return -1
+	}
+	return r.cells[y*r.width+x]
+}
+
+// CorridorAt returns the number of the corridor containing the cell (x, y) or -1 if the cell is not a choke cell.
+func (r *RegionGraph) CorridorAt(x, y int) int {
+	if x < 0 || x >= r.width || y < 0 || y >= r.height || r.cells[y*r.width+x] > -2 {
+		return -1
+	}
+	return -2 - r.cells[y*r.width+x]
+}
+
+// Reachable returns the number of free cells reachable from the chamber without passing the corridor blocked, i.e. the space left if an opponent fills a cell of that corridor.
+// The cells of all passed corridors are counted. Use -1 as blocked to get the whole connected free space. An unknown chamber has no reachable cells.
+func (r *RegionGraph) Reachable(chamber, blocked int) int {
+	if chamber < 0 || chamber >= len(r.Chambers) {
+		return 0
+	}
+	seenChamber := make([]bool, len(r.Chambers))
+	seenCorridor := make([]bool, len(r.Corridors))
+	seenChamber[chamber] = true
+	queue := []int{chamber}
+	count := 0
+	for len(queue) != 0 {
+		c := queue[0]
+		queue = queue[1:]
+		count += r.Chambers[c].Size
+		for _, k := range r.Chambers[c].Corridors {
+			if k == blocked || seenCorridor[k] {
+				continue
+			}
+			seenCorridor[k] = true
+			count += len(r.Corridors[k].Cells)
+			for _, n := range r.Corridors[k].Chambers {
+				if !seenChamber[n] {
+					seenChamber[n] = true
+					queue = append(queue, n)
+				}
+			}
+		}
+	}
+	return count
+}
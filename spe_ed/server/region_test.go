@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestRegionGraphDumbbell(t *testing.T) {
+	g := testScenario(t, `{"you": 1, "grid": [
+		"...xxxx...",
+		"..........",
+		"...xxxx...",
+		"1>xxxxxxxx"], "players": {"1": {}}}`)
+	r := BuildRegionGraph(g)
+	if len(r.Chambers) != 2 || len(r.Corridors) != 1 {
+		t.Fatalf("got %d chambers and %d corridors, want 2 and 1", len(r.Chambers), len(r.Corridors))
+	}
+	left, right := r.ChamberAt(0, 0), r.ChamberAt(9, 2)
+	if left == -1 || right == -1 || left == right {
+		t.Fatalf("rooms are chambers %d and %d", left, right)
+	}
+	for _, c := range []int{left, right} {
+		if r.Chambers[c].Size != 8 || len(r.Chambers[c].Corridors) != 1 || r.Chambers[c].Corridors[0] != 0 {
+			t.Errorf("chamber %d: size %d, corridors %v", c, r.Chambers[c].Size, r.Chambers[c].Corridors)
+		}
+	}
+	// The mouths of both rooms are choke cells as well
+	if c := r.Corridors[0]; len(c.Cells) != 6 || len(c.Chambers) != 2 {
+		t.Errorf("corridor has %d cells and chambers %v, want 6 cells and 2 chambers", len(c.Cells), c.Chambers)
+	}
+	for x := 2; x <= 7; x++ {
+		if r.CorridorAt(x, 1) != 0 || r.ChamberAt(x, 1) != -1 {
+			t.Errorf("(%d, 1) is in corridor %d and chamber %d", x, r.CorridorAt(x, 1), r.ChamberAt(x, 1))
+		}
+	}
+	if n := r.Reachable(left, 0); n != 8 {
+		t.Errorf("blocking the corridor leaves %d cells, want 8", n)
+	}
+	if n := r.Reachable(left, -1); n != 22 {
+		t.Errorf("%d cells reachable, want 22", n)
+	}
+}
+
+func TestRegionGraphRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(6))
+	for i := 0; i < 500; i++ {
+		g := randomGame(r, 1+r.Intn(30), 1+r.Intn(20), r.Float64()*0.5)
+		rg := BuildRegionGraph(g)
+		for y := range g.Cells {
+			for x := range g.Cells[y] {
+				if !IsEmpty(g.Cells[y][x]) {
+					continue
+				}
+				chamber, corridor := rg.ChamberAt(x, y), rg.CorridorAt(x, y)
+				if (chamber == -1) == (corridor == -1) {
+					t.Fatalf("(%d, %d) is in chamber %d and corridor %d\n%s", x, y, chamber, corridor, g)
+				}
+				if chamber == -1 {
+					continue
+				}
+				if got, want := rg.Reachable(chamber, -1), FloodFill(g, x, y)+1; got != want {
+					t.Fatalf("(%d, %d): %d cells reachable, flood fill found %d\n%s", x, y, got, want, g)
+				}
+			}
+		}
+	}
+}
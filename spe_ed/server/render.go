@@ -0,0 +1,271 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"io"
+	"sync"
+)
+
+const (
+	// RenderCellSize contains the size of a single cell in pixels in all rendered images.
+	RenderCellSize = 8
+	// GIFRecorderDelay contains the default delay between two frames of GIFRecorder in 100ths of a second.
+	GIFRecorderDelay = 20
+	// ContactSheetCellSize contains the size of a single cell in pixels in the thumbnails of RenderContactSheet.
+	ContactSheetCellSize = 2
+	// ContactSheetGap contains the gap between two thumbnails of RenderContactSheet in pixels.
+	ContactSheetGap = 4
+	// ContactSheetInterval contains the default number of rounds between two thumbnails of RenderContactSheet.
+	ContactSheetInterval = 10
+	// ContactSheetColumns contains the default number of thumbnails per row of RenderContactSheet.
+	ContactSheetColumns = 6
+)
+
+// Palette indices used by renderImage.
+const (
+	renderBackground = iota
+	renderCrash
+	renderUnknown
+	renderGrid
+	renderPlayers
+)
+
+// renderPalette contains all colours of the rendered images.
+// For every player, three colours follow renderPlayers: trail, head and hole.
+var renderPalette = func() color.Palette {
+	p := color.Palette{
+		color.RGBA{0xff, 0xff, 0xff, 0xff}, // background
+		color.RGBA{0x00, 0x00, 0x00, 0xff}, // crash
+		color.RGBA{0x80, 0x80, 0x80, 0xff}, // unknown cell value
+		color.RGBA{0xee, 0xee, 0xee, 0xff}, // grid
+	}
+	players := []color.RGBA{
+		{0xe6, 0x19, 0x4b, 0xff},
+		{0x3c, 0xb4, 0x4b, 0xff},
+		{0x43, 0x63, 0xd8, 0xff},
+		{0xf5, 0x82, 0x31, 0xff},
+		{0x91, 0x1e, 0xb4, 0xff},
+		{0x42, 0xd4, 0xf4, 0xff},
+	}
+	for _, c := range players {
+		trail := c
+		head := color.RGBA{c.R / 2, c.G / 2, c.B / 2, 0xff}
+		hole := color.RGBA{0xff - (0xff-c.R)/4, 0xff - (0xff-c.G)/4, 0xff - (0xff-c.B)/4, 0xff}
+		p = append(p, trail, head, hole)
+	}
+	return p
+}()
+
+// renderPlayerColour returns the palette index of the trail of the player. The head is the next index, the hole the one after.
+func renderPlayerColour(player int) uint8 {
+	return uint8(renderPlayers + 3*((player-1)%6))
+}
+
+// renderImage renders the game. Each cell is RenderCellSize pixels wide, heads use a darker colour than the trails.
+// holes contains the player which left a hole in a cell. Free cells in holes are drawn in a light colour of the player. holes might be nil.
+func renderImage(g *Game, holes map[coordinate]int) *image.Paletted {
+	img := image.NewPaletted(image.Rect(0, 0, g.Width*RenderCellSize, g.Height*RenderCellSize), renderPalette)
+	renderInto(img, image.Point{}, RenderCellSize, g, holes)
+	return img
+}
+
+// renderInto renders the game like renderImage into img, starting at the pixel origin and with cells of the given size in pixels.
+// The grid is only drawn for cells of at least 4 pixels, since it would hide smaller cells.
+func renderInto(img *image.Paletted, origin image.Point, cellSize int, g *Game, holes map[coordinate]int) {
+	fill := func(x, y int, c uint8) {
+		for py := 0; py < cellSize; py++ {
+			for px := 0; px < cellSize; px++ {
+				idx := c
+				if c == renderBackground && cellSize >= 4 && (px == 0 || py == 0) {
+					idx = renderGrid
+				}
+				img.SetColorIndex(origin.X+x*cellSize+px, origin.Y+y*cellSize+py, idx)
+			}
+		}
+	}
+
+	for y := 0; y < g.Height && y < len(g.Cells); y++ {
+		for x := 0; x < g.Width && x < len(g.Cells[y]); x++ {
+			v := g.Cells[y][x]
+			switch {
+			case IsEmpty(v):
+				if p, ok := holes[coordinate{x, y}]; ok {
+					fill(x, y, renderPlayerColour(p)+2)
+				} else {
+					fill(x, y, renderBackground)
+				}
+			case v == CellCrash:
+				fill(x, y, renderCrash)
+			case CellOwner(v) > 0:
+				fill(x, y, renderPlayerColour(CellOwner(v)))
+			default:
+				fill(x, y, renderUnknown)
+			}
+		}
+	}
+
+	for k, p := range g.Players {
+		if p.X < 0 || p.X >= g.Width || p.Y < 0 || p.Y >= g.Height {
+			continue
+		}
+		if !p.Active && g.Cells[p.Y][p.X] == CellCrash {
+			// Keep the crash visible
+			continue
+		}
+		fill(p.X, p.Y, renderPlayerColour(k)+1)
+	}
+}
+
+// RenderPNG renders the game as PNG to w. Every player has its own colour, heads are darker than the trails and crashes (cells marked with -1) are black.
+// Holes can not be distinguished from free cells in a single state and are drawn as free cells (see GIFRecorder).
+func RenderPNG(g *Game, w io.Writer) error {
+	return png.Encode(w, renderImage(g, nil))
+}
+
+// RenderContactSheet renders every interval-th state of a game (starting with the first one) and the last state as thumbnails into a single PNG written to w.
+// The thumbnails are arranged left to right and top to bottom in rows of the given number of columns, each cell is ContactSheetCellSize pixels wide and the colours are the ones of RenderPNG.
+// So the whole game can be reviewed at a glance, which is faster than watching an animated GIF (see GIFRecorder).
+// ContactSheetInterval and ContactSheetColumns are used for values below 1. The states must not be empty.
+func RenderContactSheet(states []*Game, interval, columns int, w io.Writer) error {
+	if len(states) == 0 {
+		return errors.New("no states")
+	}
+	if interval < 1 {
+		interval = ContactSheetInterval
+	}
+	if columns < 1 {
+		columns = ContactSheetColumns
+	}
+
+	selected := make([]*Game, 0, len(states)/interval+2)
+	for i := 0; i < len(states); i += interval {
+		selected = append(selected, states[i])
+	}
+	if (len(states)-1)%interval != 0 {
+		selected = append(selected, states[len(states)-1])
+	}
+
+	// All thumbnails get the size of the largest board
+	width, height := 0, 0
+	for _, g := range selected {
+		if g.Width > width {
+			width = g.Width
+		}
+		if g.Height > height {
+			height = g.Height
+		}
+	}
+	if len(selected) < columns {
+		columns = len(selected)
+	}
+	rows := (len(selected) + columns - 1) / columns
+	thumbWidth := width*ContactSheetCellSize + ContactSheetGap
+	thumbHeight := height*ContactSheetCellSize + ContactSheetGap
+
+	img := image.NewPaletted(image.Rect(0, 0, columns*thumbWidth+ContactSheetGap, rows*thumbHeight+ContactSheetGap), renderPalette)
+	for i := range img.Pix {
+		img.Pix[i] = renderUnknown
+	}
+	for i, g := range selected {
+		origin := image.Point{ContactSheetGap + (i%columns)*thumbWidth, ContactSheetGap + (i/columns)*thumbHeight}
+		renderInto(img, origin, ContactSheetCellSize, g, nil)
+	}
+	return png.Encode(w, img)
+}
+
+// GIFRecorder collects states of a game as frames of an animated GIF.
+// Unlike RenderPNG, it shows holes: a free cell a player jumped over between two consecutive frames is drawn in a light colour of the player.
+// Safe for concurrent use.
+type GIFRecorder struct {
+	// Delay is the delay after every frame in 100ths of a second. GIFRecorderDelay is used if it is zero.
+	Delay int
+
+	l     sync.Mutex
+	gif   gif.GIF
+	last  *Game
+	holes map[coordinate]int
+}
+
+// Add appends a frame showing the game. The game is not retained.
+func (r *GIFRecorder) Add(g *Game) {
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	if r.holes == nil {
+		r.holes = make(map[coordinate]int)
+	}
+
+	if r.last != nil && r.last.Width == g.Width && r.last.Height == g.Height {
+		for k, p := range g.Players {
+			old, ok := r.last.Players[k]
+			if !ok || !old.Active {
+				continue
+			}
+			dx, dy := 0, 0
+			switch p.Direction {
+			case DirectionUp:
+				dy = -1
+			case DirectionDown:
+				dy = 1
+			case DirectionLeft:
+				dx = -1
+			case DirectionRight:
+				dx = 1
+			}
+			if old.X+dx*p.Speed != p.X || old.Y+dy*p.Speed != p.Y {
+				// Not a straight move (e.g. crashed at the border)
+				continue
+			}
+			for s := 1; s < p.Speed; s++ {
+				x, y := old.X+dx*s, old.Y+dy*s
+				if IsEmpty(g.Cells[y][x]) {
+					r.holes[coordinate{x, y}] = k
+				}
+			}
+		}
+	}
+	for c := range r.holes {
+		if c.Y < len(g.Cells) && c.X < len(g.Cells[c.Y]) && !IsEmpty(g.Cells[c.Y][c.X]) {
+			// Filled later
+			delete(r.holes, c)
+		}
+	}
+
+	delay := r.Delay
+	if delay <= 0 {
+		delay = GIFRecorderDelay
+	}
+	r.gif.Image = append(r.gif.Image, renderImage(g, r.holes))
+	r.gif.Delay = append(r.gif.Delay, delay)
+	r.last = g.Clone()
+}
+
+// Encode writes all frames as animated GIF to w.
+func (r *GIFRecorder) Encode(w io.Writer) error {
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	if len(r.gif.Image) == 0 {
+		return errors.New("no frames")
+	}
+	return gif.EncodeAll(w, &r.gif)
+}
@@ -0,0 +1,144 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"testing"
+)
+
+// cellColour returns the colour of the centre of the cell (x, y) in an image rendered with cells of the given size.
+func cellColour(img image.Image, cellSize, x, y int) color.RGBA {
+	return color.RGBAModel.Convert(img.At(x*cellSize+cellSize/2, y*cellSize+cellSize/2)).(color.RGBA)
+}
+
+// paletteColour returns the colour of the palette index.
+func paletteColour(i uint8) color.RGBA {
+	return renderPalette[i].(color.RGBA)
+}
+
+func TestRenderPNG(t *testing.T) {
+	g := testScenario(t, `{"you": 1, "grid": ["x...", "11>.", "...."], "players": {"1": {}}}`)
+	var b bytes.Buffer
+	err := RenderPNG(g, &b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := img.Bounds().Size(); s.X != 4*RenderCellSize || s.Y != 3*RenderCellSize {
+		t.Fatalf("image has size %v", s)
+	}
+	for _, tc := range []struct {
+		name   string
+		x, y   int
+		colour uint8
+	}{
+		{"head", 2, 1, renderPlayerColour(1) + 1},
+		{"trail", 0, 1, renderPlayerColour(1)},
+		{"crash", 0, 0, renderCrash},
+		{"free", 3, 0, renderBackground},
+	} {
+		if got, want := cellColour(img, RenderCellSize, tc.x, tc.y), paletteColour(tc.colour); got != want {
+			t.Errorf("%s at (%d, %d) has colour %v, want %v", tc.name, tc.x, tc.y, got, want)
+		}
+	}
+	if got := img.At(3*RenderCellSize, 0); color.RGBAModel.Convert(got) != paletteColour(renderGrid) {
+		t.Errorf("grid has colour %v", got)
+	}
+}
+
+func TestGIFRecorderHoles(t *testing.T) {
+	g := testScenario(t, `{"you": 1, "round": 6, "grid": ["........", "11>.....", "........"], "players": {"1": {"speed": 2}}}`)
+	var r GIFRecorder
+	r.Add(g)
+	err := ApplyAction(g, 1, ActionFaster)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Add(g)
+
+	var b bytes.Buffer
+	err = r.Encode(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	anim, err := gif.DecodeAll(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(anim.Image) != 2 || anim.Delay[1] != GIFRecorderDelay {
+		t.Fatalf("%d frames with delay %v", len(anim.Image), anim.Delay)
+	}
+	frame := anim.Image[1]
+	for x, colour := range map[int]uint8{3: renderPlayerColour(1), 4: renderPlayerColour(1) + 2, 5: renderPlayerColour(1) + 1} {
+		if got, want := cellColour(frame, RenderCellSize, x, 1), paletteColour(colour); got != want {
+			t.Errorf("cell (%d, 1) has colour %v, want %v", x, got, want)
+		}
+	}
+
+	if (&GIFRecorder{}).Encode(&b) == nil {
+		t.Error("encoded GIF without frames")
+	}
+}
+
+func TestRenderReplayContactSheet(t *testing.T) {
+	path, s := recordReplay(t, 3, "SurvivalAI", "SurvivalAI")
+	const interval, columns = 4, 3
+	states := s.Round + 1
+	thumbnails := (states + interval - 1) / interval
+	if (states-1)%interval != 0 {
+		// The last state is added
+		thumbnails++
+	}
+	if thumbnails <= columns {
+		t.Fatalf("game with %d states is too short for more than one row", states)
+	}
+
+	var b bytes.Buffer
+	err := RenderReplayContactSheet(path, interval, columns, &b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	thumbWidth := 20*ContactSheetCellSize + ContactSheetGap
+	thumbHeight := 20*ContactSheetCellSize + ContactSheetGap
+	rows := (thumbnails + columns - 1) / columns
+	if size, want := img.Bounds().Size(), (image.Point{columns*thumbWidth + ContactSheetGap, rows*thumbHeight + ContactSheetGap}); size != want {
+		t.Fatalf("image has size %v, want %v for %d thumbnails", size, want, thumbnails)
+	}
+
+	// The last thumbnail shows the final state
+	last := thumbnails - 1
+	origin := image.Point{ContactSheetGap + (last%columns)*thumbWidth, ContactSheetGap + (last/columns)*thumbHeight}
+	for y := range s.Game.Cells {
+		for x := range s.Game.Cells[y] {
+			got := color.RGBAModel.Convert(img.At(origin.X+x*ContactSheetCellSize, origin.Y+y*ContactSheetCellSize))
+			if free := IsEmpty(s.Game.Cells[y][x]); free != (got == paletteColour(renderBackground)) {
+				t.Fatalf("cell (%d, %d) of the last thumbnail has colour %v, free %t", x, y, got, free)
+			}
+		}
+	}
+}
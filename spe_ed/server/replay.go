@@ -0,0 +1,248 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ReplayEntry represents a single round in a replay file.
+type ReplayEntry struct {
+	// Turn contains the number of the round, starting with 1.
+	Turn int `json:"turn"`
+	// Game contains the state at the beginning of the round in the format of the official spe_ed server.
+	Game *Game `json:"game"`
+	// Actions contains the recorded actions of the round by player number. Missing answers are not included.
+	Actions map[int]Action `json:"actions,omitempty"`
+	// Durations contains the time each recorded player needed for its answer in milliseconds.
+	Durations map[int]float64 `json:"durations_ms,omitempty"`
+}
+
+// ReplayRecorder writes a game as a replay file to disk. Each round is written as a single line of JSON (see ReplayEntry).
+// ReplayRecorder is safe for concurrent use.
+type ReplayRecorder struct {
+	l    sync.Mutex
+	file *os.File
+	w    *bufio.Writer
+	e    *json.Encoder
+}
+
+// NewReplayRecorder creates a new replay file at path. An existing file is overwritten.
+func NewReplayRecorder(path string) (*ReplayRecorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &ReplayRecorder{file: f, w: bufio.NewWriter(f)}
+	r.e = json.NewEncoder(r.w)
+	return r, nil
+}
+
+// Record writes a single round. The game is written immediately, so it can be changed after Record returns.
+// actions and durations might be nil.
+func (r *ReplayRecorder) Record(turn int, g *Game, actions map[int]Action, durations map[int]time.Duration) error {
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	e := ReplayEntry{
+		Turn:    turn,
+		Game:    g,
+		Actions: actions,
+	}
+	if len(durations) != 0 {
+		e.Durations = make(map[int]float64, len(durations))
+		for k := range durations {
+			e.Durations[k] = float64(durations[k]) / float64(time.Millisecond)
+		}
+	}
+	err := r.e.Encode(e)
+	if err != nil {
+		return err
+	}
+	return r.w.Flush()
+}
+
+// Close closes the replay file.
+func (r *ReplayRecorder) Close() error {
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	err := r.w.Flush()
+	if err != nil {
+		r.file.Close()
+		return err
+	}
+	return r.file.Close()
+}
+
+// ReadReplay reads all entries of a replay file written by ReplayRecorder.
+// Since the step counter is not part of the format, it is reconstructed from the turn.
+func ReadReplay(path string) ([]ReplayEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	entries := make([]ReplayEntry, 0)
+	d := json.NewDecoder(bufio.NewReader(f))
+	for {
+		var e ReplayEntry
+		err = d.Decode(&e)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: %w", len(entries)+1, err)
+		}
+		if e.Game == nil {
+			return nil, fmt.Errorf("entry %d: no game", len(entries)+1)
+		}
+		for k := range e.Game.Players {
+			e.Game.Players[k].stepCounter = e.Turn - 1
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// RunReplay steps through a replay file and lets the AI with the given name decide at every recorded state.
+// For each player with recorded actions, a separate instance of the AI is used, which gets the state in the order of the replay. The deadline is removed, so the AI uses its own time budget.
+// Every decision is written to w together with the recorded action. At the end, a summary with the first diverging turn of each player is written.
+func RunReplay(path, aiName string, w io.Writer) error {
+	entries, err := ReadReplay(path)
+	if err != nil {
+		return err
+	}
+
+	ais := make(map[int]AI)
+	answers := make(map[int]chan Action)
+	diverged := make(map[int]int)
+	decisions := make(map[int]int)
+
+	for _, e := range entries {
+		if !e.Game.Running {
+			continue
+		}
+
+		players := make([]int, 0, len(e.Actions))
+		for k := range e.Actions {
+			players = append(players, k)
+		}
+		sort.Ints(players)
+
+		for _, id := range players {
+			p, ok := e.Game.Players[id]
+			if !ok || !p.Active {
+				continue
+			}
+			if ais[id] == nil {
+				ais[id], err = CreateAI(aiName)
+				if err != nil {
+					return err
+				}
+				answers[id] = make(chan Action, 1)
+				ais[id].GetChannel(answers[id])
+			}
+
+			g := e.Game.ViewFor(id)
+			g.Deadline = ""
+
+			action, duration := replayDecide(ais[id], answers[id], g)
+
+			decisions[id]++
+			marker := ""
+			if action != e.Actions[id] {
+				marker = " (diverged)"
+				if diverged[id] == 0 {
+					diverged[id] = e.Turn
+				}
+			}
+			fmt.Fprintf(w, "turn %d player %d: recorded %s, %s chose %s in %s%s\n", e.Turn, id, e.Actions[id], aiName, action, duration.Round(time.Microsecond), marker)
+		}
+	}
+
+	players := make([]int, 0, len(decisions))
+	for k := range decisions {
+		players = append(players, k)
+	}
+	sort.Ints(players)
+
+	fmt.Fprintln(w, "summary:")
+	if len(players) == 0 {
+		fmt.Fprintln(w, "no recorded actions")
+	}
+	for _, id := range players {
+		if diverged[id] == 0 {
+			fmt.Fprintf(w, "player %d: %d decisions, no divergence\n", id, decisions[id])
+		} else {
+			fmt.Fprintf(w, "player %d: %d decisions, first divergence in turn %d\n", id, decisions[id], diverged[id])
+		}
+	}
+	return nil
+}
+
+// replayDecide gives the state to the AI and waits up to SimulatorAnswerTimeout for its answer (answer is the channel given to the AI).
+// It returns the action ("" if the AI did not answer) and the time the AI needed.
+func replayDecide(ai AI, answer chan Action, g *Game) (Action, time.Duration) {
+	// Remove old answers
+	select {
+	case <-answer:
+	default:
+	}
+
+	start := time.Now()
+	go getAIState(ai, g, start.Add(SimulatorAnswerTimeout))
+	var action Action
+	select {
+	case action = <-answer:
+	case <-time.After(SimulatorAnswerTimeout):
+	}
+	return action, time.Since(start)
+}
+
+// RenderReplayGIF renders all states of a replay file as animated GIF to w (see GIFRecorder).
+func RenderReplayGIF(path string, w io.Writer) error {
+	entries, err := ReadReplay(path)
+	if err != nil {
+		return err
+	}
+	var r GIFRecorder
+	for i := range entries {
+		r.Add(entries[i].Game)
+	}
+	return r.Encode(w)
+}
+
+// RenderReplayContactSheet renders the states of a replay file as contact sheet to w (see RenderContactSheet).
+func RenderReplayContactSheet(path string, interval, columns int, w io.Writer) error {
+	entries, err := ReadReplay(path)
+	if err != nil {
+		return err
+	}
+	states := make([]*Game, len(entries))
+	for i := range entries {
+		states[i] = entries[i].Game
+	}
+	return RenderContactSheet(states, interval, columns, w)
+}
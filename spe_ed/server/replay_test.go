@@ -0,0 +1,103 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// recordReplay plays a simulated game between the AIs and records it to a replay file in a temporary directory.
+func recordReplay(t *testing.T, seed int64, names ...string) (string, *Simulator) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "replay.json")
+	r, err := NewReplayRecorder(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewSimulator(20, 20, seed, names...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Recorder = r
+	s.Run()
+	err = r.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path, s
+}
+
+func TestReadReplay(t *testing.T) {
+	path, s := recordReplay(t, 3, "SurvivalAI", "SurvivalAI")
+	entries, err := ReadReplay(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != s.Round+1 {
+		t.Fatalf("%d entries for %d rounds", len(entries), s.Round)
+	}
+	for i, e := range entries {
+		if e.Turn != i+1 {
+			t.Errorf("entry %d has turn %d", i, e.Turn)
+		}
+		for id, p := range e.Game.Players {
+			if p.stepCounter != e.Turn-1 {
+				t.Errorf("turn %d: player %d has step counter %d", e.Turn, id, p.stepCounter)
+			}
+		}
+		if last := i == len(entries)-1; last != !e.Game.Running || last != (len(e.Actions) == 0) {
+			t.Errorf("turn %d: running %t with %d actions", e.Turn, e.Game.Running, len(e.Actions))
+		}
+	}
+}
+
+func TestRunReplay(t *testing.T) {
+	path, s := recordReplay(t, 3, "SurvivalAI", "SurvivalAI")
+
+	var b bytes.Buffer
+	err := RunReplay(path, "SurvivalAI", &b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	if n := strings.Count(out, " (diverged)"); n != 0 {
+		t.Errorf("the recorded AI diverged %d times\n%s", n, out)
+	}
+	for _, want := range []string{"player 1: ", "player 2: ", "decisions, no divergence"} {
+		if !strings.Contains(out[strings.Index(out, "summary:"):], want) {
+			t.Errorf("summary misses %q\n%s", want, out)
+		}
+	}
+	if n := strings.Count(out, "turn "); n < s.Round {
+		t.Errorf("%d decisions for %d rounds", n, s.Round)
+	}
+
+	b.Reset()
+	err = RunReplay(path, "StupidAI", &b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "first divergence in turn ") {
+		t.Errorf("no divergence of StupidAI\n%s", b.String())
+	}
+
+	if RunReplay(path, "UnknownAI", &b) == nil {
+		t.Error("unknown ai accepted")
+	}
+}
@@ -0,0 +1,189 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// ReplayAnalysisHorizon contains the number of rounds before the crash of a player in which RunReplayAnalysis tries whether the new AI would have survived.
+const ReplayAnalysisHorizon = 30
+
+// RunReplayAnalysis re-runs a recorded game through the AI with the given name and writes a report to w to find out where a lost game could have been saved.
+// For each player with recorded actions, a table lists every round with the free space reachable from the head (see FloodFill), the recorded action and the action of the new AI, which gets the states in the order of the replay like in RunReplay.
+// If the player crashed, every round within ReplayAnalysisHorizon rounds before the crash in which the new AI diverges is played out until the round of the crash:
+// The new AI (a fresh instance) controls the player, all other players repeat their recorded actions, or the actions inferred from the recorded states if they were not recorded (see InferAction).
+// The rollout column shows whether the player would have survived the round of the crash, and the earliest such round is reported in the summary.
+// Since the other players do not react to the new moves, the rollout is only an estimate.
+func RunReplayAnalysis(path, aiName string, w io.Writer) error {
+	entries, err := ReadReplay(path)
+	if err != nil {
+		return err
+	}
+
+	recorded := make(map[int]bool)
+	for _, e := range entries {
+		for id := range e.Actions {
+			recorded[id] = true
+		}
+	}
+	players := make([]int, 0, len(recorded))
+	for id := range recorded {
+		players = append(players, id)
+	}
+	sort.Ints(players)
+	if len(players) == 0 {
+		fmt.Fprintln(w, "no recorded actions")
+		return nil
+	}
+
+	for _, id := range players {
+		err = analyseReplayPlayer(entries, id, aiName, w)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// analyseReplayPlayer writes the report of RunReplayAnalysis for a single player.
+func analyseReplayPlayer(entries []ReplayEntry, id int, aiName string, w io.Writer) error {
+	// The player crashed in the round of entries[crash] (-1 if it did not crash)
+	crash := -1
+	for i := 0; i+1 < len(entries); i++ {
+		p, ok := entries[i].Game.Players[id]
+		next, nextOK := entries[i+1].Game.Players[id]
+		if ok && nextOK && p.Active && entries[i].Game.Running && !next.Active {
+			crash = i
+			break
+		}
+	}
+
+	ai, err := CreateAI(aiName)
+	if err != nil {
+		return err
+	}
+	answer := make(chan Action, 1)
+	ai.GetChannel(answer)
+
+	fmt.Fprintf(w, "player %d:\n", id)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join([]string{"turn", "space", "recorded", aiName, "rollout"}, "\t"))
+
+	saved := -1
+	for i, e := range entries {
+		p, ok := e.Game.Players[id]
+		if !e.Game.Running || !ok || !p.Active {
+			continue
+		}
+		if crash != -1 && i > crash {
+			break
+		}
+
+		g := e.Game.ViewFor(id)
+		g.Deadline = ""
+		action, _ := replayDecide(ai, answer, g)
+
+		recordedAction, ok := e.Actions[id]
+		if !ok {
+			recordedAction = "-"
+		}
+		marker := ""
+		if action != e.Actions[id] {
+			marker = " (diverged)"
+		}
+
+		rollout := "-"
+		if crash != -1 && action != e.Actions[id] && crash-i < ReplayAnalysisHorizon {
+			survived, turn, err := replayRollout(entries, i, crash, id, aiName, action)
+			if err != nil {
+				return err
+			}
+			if survived {
+				rollout = "survived"
+				if saved == -1 {
+					saved = e.Turn
+					rollout = "survived (earliest)"
+				}
+			} else {
+				rollout = fmt.Sprintf("crashed in turn %d", turn)
+			}
+		}
+		fmt.Fprintln(tw, strings.Join([]string{strconv.Itoa(e.Turn), strconv.Itoa(FloodFill(e.Game, p.X, p.Y)), string(recordedAction), string(action) + marker, rollout}, "\t"))
+	}
+	err = tw.Flush()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case crash == -1:
+		fmt.Fprintf(w, "summary: player %d did not crash\n", id)
+	case saved == -1:
+		fmt.Fprintf(w, "summary: player %d crashed in turn %d, %s would not have survived with any diverging decision in the last %d rounds\n", id, entries[crash].Turn, aiName, ReplayAnalysisHorizon)
+	default:
+		fmt.Fprintf(w, "summary: player %d crashed in turn %d, %s would have survived by diverging in turn %d\n", id, entries[crash].Turn, aiName, saved)
+	}
+	return nil
+}
+
+// replayRollout plays the recorded game from entries[start] until the round of entries[crash] with a fresh instance of the AI controlling the player, starting with the given action.
+// All other players play their recorded actions or the actions inferred from the following entry.
+// It returns whether the player is still active after the round of entries[crash] and otherwise the turn of its crash.
+func replayRollout(entries []ReplayEntry, start, crash, id int, aiName string, first Action) (bool, int, error) {
+	ai, err := CreateAI(aiName)
+	if err != nil {
+		return false, 0, err
+	}
+	answer := make(chan Action, 1)
+	ai.GetChannel(answer)
+
+	g := entries[start].Game.Clone()
+	for i := start; i <= crash; i++ {
+		actions := make(map[int]Action, len(g.Players))
+		for k := range g.Players {
+			if k == id || !g.Players[k].Active {
+				continue
+			}
+			a, ok := entries[i].Actions[k]
+			if !ok && i+1 < len(entries) {
+				// A player crashed in the recorded game is not inferred and crashes here as well
+				a, _ = InferAction(entries[i].Game, entries[i+1].Game, k)
+			}
+			actions[k] = a
+		}
+		if i == start {
+			actions[id] = first
+		} else {
+			v := g.ViewFor(id)
+			v.Deadline = ""
+			actions[id], _ = replayDecide(ai, answer, v)
+		}
+
+		for _, k := range resolveTick(g, actions) {
+			g.Players[k].Active = false
+		}
+		if !g.Players[id].Active {
+			return false, entries[i].Turn, nil
+		}
+	}
+	return true, 0, nil
+}
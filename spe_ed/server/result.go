@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+// Outcome describes how a game ended.
+type Outcome int
+
+const (
+	// OutcomeNone describes a game without a result: It is still running, was aborted with several active players or only had a single player.
+	OutcomeNone Outcome = iota
+	// OutcomeWin describes a game in which exactly one player survived.
+	OutcomeWin
+	// OutcomeDraw describes a game in which all remaining players crashed in the same round, so nobody survived.
+	OutcomeDraw
+)
+
+// String returns the name of the outcome.
+func (o Outcome) String() string {
+	switch o {
+	case OutcomeWin:
+		return "win"
+	case OutcomeDraw:
+		return "draw"
+	default:
+		return "none"
+	}
+}
+
+// MatchResult contains the result of a game (see Game.Result).
+type MatchResult struct {
+	// Outcome describes how the game ended.
+	Outcome Outcome
+	// Winner contains the number of the winning player for OutcomeWin and 0 otherwise.
+	Winner int
+}
+
+// Result returns the result of the game, based on the active players once the game is not running any more.
+// Since a game ends as soon as at most one player is active, a game without active players always ended with all remaining players crashing in the same round, which is a draw.
+func (g *Game) Result() MatchResult {
+	if g.Running || len(g.Players) < 2 {
+		return MatchResult{Outcome: OutcomeNone}
+	}
+	winner := 0
+	for id := range g.Players {
+		if !g.Players[id].Active {
+			continue
+		}
+		if winner != 0 {
+			// Several players active, e.g. the game was aborted
+			return MatchResult{Outcome: OutcomeNone}
+		}
+		winner = id
+	}
+	if winner == 0 {
+		return MatchResult{Outcome: OutcomeDraw}
+	}
+	return MatchResult{Outcome: OutcomeWin, Winner: winner}
+}
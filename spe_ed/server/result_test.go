@@ -0,0 +1,63 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "testing"
+
+func TestGameResult(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		running bool
+		grid    string
+		players string
+		want    MatchResult
+	}{
+		{"running", true, `"1>...", "...<2"`, `"1": {}, "2": {"active": false}`, MatchResult{Outcome: OutcomeNone}},
+		{"win", false, `"1>...", "...<2"`, `"1": {"active": false}, "2": {}`, MatchResult{Outcome: OutcomeWin, Winner: 2}},
+		{"draw", false, `"1>...", "...<2"`, `"1": {"active": false}, "2": {"active": false}`, MatchResult{Outcome: OutcomeDraw}},
+		{"aborted", false, `"1>...", "...<2"`, `"1": {}, "2": {}`, MatchResult{Outcome: OutcomeNone}},
+		{"single player", false, `"1>...", "....."`, `"1": {"active": false}`, MatchResult{Outcome: OutcomeNone}},
+	} {
+		g := testScenario(t, `{"you": 1, "grid": [`+tc.grid+`], "players": {`+tc.players+`}}`)
+		g.Running = tc.running
+		if got := g.Result(); got != tc.want {
+			t.Errorf("%s: got %v (%s), want %v (%s)", tc.name, got, got.Outcome, tc.want, tc.want.Outcome)
+		}
+	}
+}
+
+func TestSimulatorDraw(t *testing.T) {
+	s, err := NewSimulatorWithAIs(40, 40, 1, &scriptedAI{}, &scriptedAI{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Both heads move into (11, 5) in the first round
+	placePlayer(s, 1, 10, 5, DirectionRight)
+	placePlayer(s, 2, 12, 5, DirectionLeft)
+
+	if winner := s.Run(); winner != 0 {
+		t.Errorf("winner %d", winner)
+	}
+	if s.Round != 1 {
+		t.Errorf("game ended after %d rounds", s.Round)
+	}
+	if r := s.Result(); r.Outcome != OutcomeDraw || r.Winner != 0 {
+		t.Errorf("result %v (%s), want a draw\n%s", r, r.Outcome, s.Game)
+	}
+	if s.Game.Cells[5][11] != CellCrash {
+		t.Errorf("no crash at the meeting cell\n%s", s.Game)
+	}
+}
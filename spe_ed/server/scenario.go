@@ -0,0 +1,188 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+// scenarioFile represents the file format read by LoadScenario.
+type scenarioFile struct {
+	You     int                    `json:"you"`
+	Running *bool                  `json:"running"`
+	Round   int                    `json:"round"`
+	Grid    []string               `json:"grid"`
+	Players map[int]scenarioPlayer `json:"players"`
+	Avoid   []string               `json:"avoid"`
+}
+
+// scenarioPlayer represents a single player in the file format read by LoadScenario.
+type scenarioPlayer struct {
+	Speed  int    `json:"speed"`
+	Active *bool  `json:"active"`
+	Name   string `json:"name"`
+	X      *int   `json:"x"`
+	Y      *int   `json:"y"`
+}
+
+// LoadScenario reads a handcrafted game state from a JSON file, e.g. for checking which action an AI chooses in a given situation.
+// The board is given as a list of rows in "grid" using '.' for free cells, '1' to '6' for the trail of a player, 'x' for cells of a crash (-1) and '^', 'v', '<', '>' for the heads of the players with their direction.
+// A head belongs to the player whose trail is directly behind it. A head without trail behind it (e.g. in the first round) must be assigned by setting "x" and "y" of the player.
+// "players" contains every player by number with "speed" (default 1), "active" (default true) and optionally "name". Every player needs exactly one head.
+// "you" is the number of the own player, "round" the number of the current round (default 1, used for holes) and "running" whether the game is running (default true).
+// "avoid" optionally lists the actions the own player must not choose. It is ignored by LoadScenario, but checked by CheckScenarios.
+// An example:
+//
+//	{"you": 1, "grid": ["....", "11>.", "...."], "players": {"1": {"speed": 1}}}
+func LoadScenario(path string) (*Game, error) {
+	g, _, err := loadScenario(path)
+	return g, err
+}
+
+// loadScenario works like LoadScenario, but additionally returns the actions listed in "avoid".
+func loadScenario(path string) (*Game, []Action, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("scenario: %w", err)
+	}
+	var s scenarioFile
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.DisallowUnknownFields()
+	err = d.Decode(&s)
+	if err != nil {
+		return nil, nil, fmt.Errorf("scenario: can not parse %s: %w", path, err)
+	}
+	avoid := make([]Action, len(s.Avoid))
+	for i := range s.Avoid {
+		avoid[i], err = ParseAction(s.Avoid[i])
+		if err != nil {
+			return nil, nil, fmt.Errorf("scenario: %s: %w in avoid", path, err)
+		}
+	}
+
+	if len(s.Grid) == 0 || len(s.Grid[0]) == 0 {
+		return nil, nil, fmt.Errorf("scenario: %s: empty grid", path)
+	}
+	if s.Round == 0 {
+		s.Round = 1
+	}
+	if s.Round < 1 {
+		return nil, nil, fmt.Errorf("scenario: %s: invalid round %d", path, s.Round)
+	}
+	if _, ok := s.Players[s.You]; !ok {
+		return nil, nil, fmt.Errorf("scenario: %s: you (%d) is not a player", path, s.You)
+	}
+
+	g := &Game{
+		Width:     len(s.Grid[0]),
+		Height:    len(s.Grid),
+		Cells:     make([][]int8, len(s.Grid)),
+		Players:   make(map[int]*Player, len(s.Players)),
+		You:       s.You,
+		Running:   s.Running == nil || *s.Running,
+		MaxPlayer: len(s.Players),
+	}
+
+	type head struct {
+		coordinate
+		direction Direction
+	}
+	heads := make([]head, 0, len(s.Players))
+	for y, row := range s.Grid {
+		if len(row) != g.Width {
+			return nil, nil, fmt.Errorf("scenario: %s: row %d has %d cells instead of %d", path, y, len(row), g.Width)
+		}
+		g.Cells[y] = make([]int8, g.Width)
+		for x, c := range []byte(row) {
+			switch {
+			case c == '.':
+				// Free
+			case c >= '1' && c <= '0'+PlayersPerGame:
+				g.Cells[y][x] = PlayerCell(int(c - '0'))
+			case c == 'x':
+				g.Cells[y][x] = CellCrash
+			case c == '^':
+				heads = append(heads, head{coordinate{x, y}, DirectionUp})
+			case c == 'v':
+				heads = append(heads, head{coordinate{x, y}, DirectionDown})
+			case c == '<':
+				heads = append(heads, head{coordinate{x, y}, DirectionLeft})
+			case c == '>':
+				heads = append(heads, head{coordinate{x, y}, DirectionRight})
+			default:
+				return nil, nil, fmt.Errorf("scenario: %s: unknown cell %q at (%d, %d)", path, c, x, y)
+			}
+		}
+	}
+
+	for _, h := range heads {
+		owner := 0
+		for id, p := range s.Players {
+			if p.X != nil && p.Y != nil && *p.X == h.X && *p.Y == h.Y {
+				owner = id
+			}
+		}
+		if owner == 0 {
+			bx, by := h.X, h.Y
+			switch h.direction {
+			case DirectionUp:
+				by++
+			case DirectionDown:
+				by--
+			case DirectionLeft:
+				bx++
+			case DirectionRight:
+				bx--
+			}
+			if bx >= 0 && bx < g.Width && by >= 0 && by < g.Height {
+				owner = CellOwner(g.Cells[by][bx])
+			}
+		}
+		p, ok := s.Players[owner]
+		if owner <= 0 || !ok {
+			return nil, nil, fmt.Errorf("scenario: %s: head at (%d, %d) belongs to no player", path, h.X, h.Y)
+		}
+		if g.Players[owner] != nil {
+			return nil, nil, fmt.Errorf("scenario: %s: player %d has more than one head", path, owner)
+		}
+		if p.Speed == 0 {
+			p.Speed = 1
+		}
+		if p.Speed < 1 || p.Speed > MaxSpeed {
+			return nil, nil, fmt.Errorf("scenario: %s: invalid speed %d of player %d", path, p.Speed, owner)
+		}
+		g.Cells[h.Y][h.X] = PlayerCell(owner)
+		g.Players[owner] = &Player{
+			X:           h.X,
+			Y:           h.Y,
+			Direction:   h.direction,
+			Speed:       p.Speed,
+			Active:      p.Active == nil || *p.Active,
+			Name:        p.Name,
+			stepCounter: s.Round - 1,
+		}
+	}
+
+	for id := range s.Players {
+		if g.Players[id] == nil {
+			return nil, nil, fmt.Errorf("scenario: %s: player %d has no head", path, id)
+		}
+	}
+	return g, avoid, nil
+}
@@ -0,0 +1,135 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testScenario returns the game described by the scenario src (see LoadScenario).
+func testScenario(t testing.TB, src string) *Game {
+	t.Helper()
+	g, err := loadTestScenario(t, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+// loadTestScenario writes the scenario src to a temporary file and loads it with LoadScenario.
+func loadTestScenario(t testing.TB, src string) (*Game, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "scenario.json")
+	err := os.WriteFile(path, []byte(src), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return LoadScenario(path)
+}
+
+// decide lets the AI decide once on the game with a deadline of one second and returns the answer.
+func decide(t testing.TB, ai AI, g *Game) Action {
+	t.Helper()
+	c := make(chan Action, 1)
+	ai.GetChannel(c)
+	g.Deadline = ServerNow().Add(time.Second).UTC().Format(time.RFC3339Nano)
+	go ai.GetState(g)
+	select {
+	case a := <-c:
+		return a
+	case <-time.After(2 * time.Second):
+		t.Fatalf("%s did not answer", ai.Name())
+		return ""
+	}
+}
+
+func TestLoadScenario(t *testing.T) {
+	g, err := LoadScenario("scenarios/trap.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Width != 10 || g.Height != 7 || g.You != 1 || !g.Running || len(g.Players) != 2 {
+		t.Fatalf("wrong game %dx%d, you %d, running %t, %d players", g.Width, g.Height, g.You, g.Running, len(g.Players))
+	}
+	want := map[int]*Player{
+		1: {X: 3, Y: 3, Direction: DirectionRight, Speed: 1, Active: true},
+		2: {X: 3, Y: 4, Direction: DirectionLeft, Speed: 1, Active: true},
+	}
+	for id, w := range want {
+		p := g.Players[id]
+		if p.X != w.X || p.Y != w.Y || p.Direction != w.Direction || p.Speed != w.Speed || p.Active != w.Active || p.stepCounter != 0 {
+			t.Errorf("player %d: got (%d,%d) %s speed %d, want (%d,%d) %s speed %d", id, p.X, p.Y, p.Direction, p.Speed, w.X, w.Y, w.Direction, w.Speed)
+		}
+		if g.Cells[p.Y][p.X] != PlayerCell(id) {
+			t.Errorf("head of player %d not filled", id)
+		}
+	}
+	for _, c := range []struct{ x, y int }{{0, 3}, {2, 3}, {4, 2}, {7, 3}, {6, 4}} {
+		if IsEmpty(g.Cells[c.y][c.x]) {
+			t.Errorf("cell (%d,%d) is free", c.x, c.y)
+		}
+	}
+	if err := g.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	g = testScenario(t, `{"you": 2, "round": 6, "running": false, "grid": ["x.v", "...", "<.^"], "players": {"1": {"x": 2, "y": 0, "speed": 3, "name": "a"}, "2": {"x": 0, "y": 2, "active": false}, "3": {"x": 2, "y": 2}}}`)
+	if g.Running || g.Cells[0][0] != CellCrash || g.Players[1].Speed != 3 || g.Players[1].Name != "a" || g.Players[2].Active || g.Players[3].Direction != DirectionUp || g.Players[1].stepCounter != 5 {
+		t.Errorf("wrong game\n%s", g)
+	}
+}
+
+func TestLoadScenarioErrors(t *testing.T) {
+	for name, src := range map[string]string{
+		"empty grid":    `{"you": 1, "grid": [], "players": {"1": {}}}`,
+		"unknown field": `{"you": 1, "grid": ["11>"], "players": {"1": {}}, "width": 3}`,
+		"unknown cell":  `{"you": 1, "grid": ["11>?"], "players": {"1": {}}}`,
+		"short row":     `{"you": 1, "grid": ["11>", ".."], "players": {"1": {}}}`,
+		"you missing":   `{"you": 2, "grid": ["11>"], "players": {"1": {}}}`,
+		"no head":       `{"you": 1, "grid": ["11>", "2.."], "players": {"1": {}, "2": {}}}`,
+		"two heads":     `{"you": 1, "grid": ["11>", "11>"], "players": {"1": {}}}`,
+		"free head":     `{"you": 1, "grid": ["..>"], "players": {"1": {}}}`,
+		"invalid speed": `{"you": 1, "grid": ["11>"], "players": {"1": {"speed": 11}}}`,
+		"invalid round": `{"you": 1, "round": -1, "grid": ["11>"], "players": {"1": {}}}`,
+		"avoid":         `{"you": 1, "grid": ["11>"], "players": {"1": {}}, "avoid": ["jump"]}`,
+	} {
+		_, err := loadTestScenario(t, src)
+		if err == nil || !strings.HasPrefix(err.Error(), "scenario: ") {
+			t.Errorf("%s: got error %v", name, err)
+		}
+	}
+}
+
+func TestLoadScenarioAvoid(t *testing.T) {
+	_, avoid, err := loadScenario("scenarios/headon_gap.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(avoid) != 3 || avoid[0] != ActionNOOP || avoid[1] != ActionFaster || avoid[2] != ActionSlower {
+		t.Fatalf("wrong actions to avoid %v", avoid)
+	}
+}
+
+func TestScenarioTrap(t *testing.T) {
+	g := testScenario(t, `{"you": 1, "grid": ["......", "......", "11>x..", "xx.x..", "xxxx.."], "players": {"1": {}}}`)
+	if a := decide(t, &SurvivalAI{}, g); a != ActionTurnLeft {
+		t.Errorf("SurvivalAI chose %s instead of leaving the trap with turn_left", a)
+	}
+}
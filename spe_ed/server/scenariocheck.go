@@ -0,0 +1,148 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ScenarioCheckDeadline contains the time until the deadline of every decision of CheckScenarios. AIs not answering within it fail the run.
+// It must be longer than the margin reserved by the search AIs (e.g. MinimaxAIMargin), otherwise they have no time left to search.
+const ScenarioCheckDeadline = 3 * time.Second
+
+// ScenarioCheckConfig contains the configuration of CheckScenarios.
+type ScenarioCheckConfig struct {
+	// Dir is the directory containing the scenarios (all *.json files, see LoadScenario).
+	Dir string
+	// AI is the name of the checked AI.
+	AI string
+	// Runs is the number of decisions per scenario. Every run uses a new instance of the AI.
+	Runs int
+	// Seed is used to derive the seed of every run if the AI implements SeedableAI.
+	Seed int64
+}
+
+// CheckScenarios lets the AI decide config.Runs times in every scenario and checks the chosen actions against the actions listed in "avoid" (see LoadScenario).
+// Running the same scenario several times with different seeds covers the random decisions of AIs like BadRandomAI.
+// A run fails if the AI chooses an action to avoid, does not answer within ScenarioCheckDeadline or panics. Scenarios without "avoid" are only loaded.
+// The result of every scenario is written to w. It returns the number of failed scenarios and an error if the check itself is not possible, e.g. because a scenario can not be loaded.
+func CheckScenarios(config ScenarioCheckConfig, w io.Writer) (int, error) {
+	if config.Runs < 1 {
+		return 0, errors.New("scenario: at least one run is needed")
+	}
+	if _, err := CreateAI(config.AI); err != nil {
+		return 0, fmt.Errorf("scenario: %w", err)
+	}
+	files, err := filepath.Glob(filepath.Join(config.Dir, "*.json"))
+	if err != nil {
+		return 0, fmt.Errorf("scenario: %w", err)
+	}
+	if len(files) == 0 {
+		return 0, fmt.Errorf("scenario: no scenarios in %s", config.Dir)
+	}
+
+	failed := 0
+	for _, f := range files {
+		g, avoid, err := loadScenario(f)
+		if err != nil {
+			return failed, err
+		}
+		if len(avoid) == 0 {
+			fmt.Fprintf(w, "SKIP %s: no actions to avoid\n", f)
+			continue
+		}
+		forbidden := make(map[Action]bool, len(avoid))
+		for _, a := range avoid {
+			forbidden[a] = true
+		}
+
+		chosen := make(map[string]int)
+		fail := false
+		for run := 0; run < config.Runs; run++ {
+			action, err := scenarioDecision(config.AI, g, config.Seed+int64(run))
+			switch {
+			case err != nil:
+				action = "panic"
+				fail = true
+			case action == "":
+				action = "timeout"
+				fail = true
+			case forbidden[action]:
+				fail = true
+			}
+			chosen[action.String()]++
+		}
+
+		actions := make([]string, 0, len(chosen))
+		for a := range chosen {
+			actions = append(actions, fmt.Sprintf("%s %d", a, chosen[a]))
+		}
+		sort.Strings(actions)
+		result := "PASS"
+		if fail {
+			result = "FAIL"
+			failed++
+		}
+		names := make([]string, len(avoid))
+		for i := range avoid {
+			names[i] = avoid[i].String()
+		}
+		fmt.Fprintf(w, "%s %s: %s (avoid %s)\n", result, f, strings.Join(actions, ", "), strings.Join(names, ", "))
+	}
+	return failed, nil
+}
+
+// scenarioDecision lets a new instance of the AI decide on a copy of the scenario and returns the answer.
+// An empty answer is returned if the AI does not answer before ScenarioCheckDeadline, an error if it panics (see SafeGetState).
+func scenarioDecision(name string, scenario *Game, seed int64) (Action, error) {
+	ai, err := CreateAI(name)
+	if err != nil {
+		return "", err
+	}
+	if sai, ok := ai.(SeedableAI); ok {
+		sai.Seed(seed)
+	}
+	answer := make(chan Action, 1)
+	ai.GetChannel(answer)
+
+	g := scenario.Clone()
+	g.Deadline = ServerNow().Add(ScenarioCheckDeadline).UTC().Format(time.RFC3339Nano)
+
+	timer := time.NewTimer(ScenarioCheckDeadline)
+	defer timer.Stop()
+	panicked := make(chan error, 1)
+	go func() {
+		err := getAIState(ai, g, time.Now().Add(ScenarioCheckDeadline))
+		if err != nil {
+			panicked <- err
+		}
+	}()
+
+	select {
+	case a := <-answer:
+		return a, nil
+	case err := <-panicked:
+		return "", err
+	case <-timer.C:
+		return "", nil
+	}
+}
@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScenarios(t *testing.T) {
+	var b bytes.Buffer
+	failed, err := CheckScenarios(ScenarioCheckConfig{Dir: "scenarios", AI: "BadRandomAIPessimistic", Runs: 20, Seed: 1}, &b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if failed != 0 {
+		t.Errorf("%d scenarios failed\n%s", failed, b.String())
+	}
+	for _, name := range []string{"headon_gap", "headon_hole", "headon_perpendicular", "headon_speed"} {
+		if !strings.Contains(b.String(), "PASS "+filepath.Join("scenarios", name+".json")) {
+			t.Errorf("%s not checked\n%s", name, b.String())
+		}
+	}
+
+	// Without the pessimistic check, the charge into the cell of the opponent is taken
+	b.Reset()
+	failed, err = CheckScenarios(ScenarioCheckConfig{Dir: "scenarios", AI: "BadRandomAI", Runs: 20, Seed: 1}, &b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if failed != 4 {
+		t.Errorf("BadRandomAI failed %d scenarios\n%s", failed, b.String())
+	}
+
+	// The searches have to resolve both moves of a round at the same time to see the head-on collisions
+	for _, name := range []string{"MinimaxAI", "MinimaxAIParanoid", "MinimaxAIMaxN"} {
+		b.Reset()
+		failed, err = CheckScenarios(ScenarioCheckConfig{Dir: "scenarios", AI: name, Runs: 3, Seed: 1}, &b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if failed != 0 {
+			t.Errorf("%s failed %d scenarios\n%s", name, failed, b.String())
+		}
+	}
+}
+
+// TestScenarioFixtures checks that every action to avoid in the fixtures crashes for at least one action of the opponents (see resolveTick).
+func TestScenarioFixtures(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("scenarios", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		g, avoid, err := loadScenario(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, a := range avoid {
+			crashes := false
+			for _, o := range Actions {
+				actions := map[int]Action{g.You: a}
+				for id := range g.Players {
+					if id != g.You {
+						actions[id] = o
+					}
+				}
+				for _, id := range resolveTick(g.Clone(), actions) {
+					crashes = crashes || id == g.You
+				}
+			}
+			if !crashes {
+				t.Errorf("%s: %s never crashes", f, a)
+			}
+		}
+	}
+}
+
+func TestCheckScenariosFailure(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "all.json"), []byte(`{"you": 1, "grid": ["1>..", "....", "...."], "players": {"1": {}}, "avoid": ["change_nothing", "turn_left", "turn_right", "speed_up", "slow_down"]}`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	failed, err := CheckScenarios(ScenarioCheckConfig{Dir: dir, AI: "BadRandomAI", Runs: 3, Seed: 1}, &b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if failed != 1 || !strings.HasPrefix(b.String(), "FAIL ") {
+		t.Errorf("%d failed\n%s", failed, b.String())
+	}
+
+	if _, err := CheckScenarios(ScenarioCheckConfig{Dir: t.TempDir(), AI: "BadRandomAI", Runs: 1}, &b); err == nil {
+		t.Error("empty directory accepted")
+	}
+	if _, err := CheckScenarios(ScenarioCheckConfig{Dir: dir, AI: "NoSuchAI", Runs: 1}, &b); err == nil {
+		t.Error("unknown ai accepted")
+	}
+}
@@ -0,0 +1,207 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sort"
+	"sync"
+)
+
+// SelfPlaySample represents a single decision of a player in a self-play dataset (see SelfPlayExporter).
+// It contains the state at the beginning of the round, the action played and the label of the game from the view of the player.
+type SelfPlaySample struct {
+	// Game is the number of the game in the dataset, starting with 1.
+	Game int `json:"game"`
+	// Turn is the number of the round, starting with 1.
+	Turn int `json:"turn"`
+	// Player is the number of the deciding player.
+	Player int `json:"player"`
+	// Width and Height contain the size of the board.
+	Width  int `json:"width"`
+	Height int `json:"height"`
+	// Cells contains all cells row by row (Cells[y*Width+x]), using the values of Game.Cells.
+	Cells []int8 `json:"cells"`
+	// Players contains the state of all players at the beginning of the round by player number.
+	Players map[int]SelfPlayPlayer `json:"players"`
+	// Action is the action played by the player.
+	Action Action `json:"action"`
+	// Result is the outcome of the game for the player: win, loss or draw (none for a game with a single player).
+	Result string `json:"result"`
+	// Rounds is the number of rounds the game lasted.
+	Rounds int `json:"rounds"`
+}
+
+// SelfPlayPlayer contains the state of a player in a SelfPlaySample.
+type SelfPlayPlayer struct {
+	X         int       `json:"x"`
+	Y         int       `json:"y"`
+	Direction Direction `json:"direction"`
+	Speed     int       `json:"speed"`
+	Active    bool      `json:"active"`
+}
+
+// SelfPlayExporter writes decisions of AIs in simulated games as training dataset to disk. Each decision is written as a single line of JSON (see SelfPlaySample).
+// The games are recorded separately (see SelfPlayExporter.NewGame) and only written once they have ended, so every sample is labelled with the outcome of its game.
+// SelfPlayExporter is safe for concurrent use, so games played in parallel can share one exporter.
+type SelfPlayExporter struct {
+	l     sync.Mutex
+	file  *os.File
+	w     *bufio.Writer
+	e     *json.Encoder
+	games int
+}
+
+// NewSelfPlayExporter creates a new dataset file at path. An existing file is overwritten.
+func NewSelfPlayExporter(path string) (*SelfPlayExporter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	e := &SelfPlayExporter{file: f, w: bufio.NewWriter(f)}
+	e.e = json.NewEncoder(e.w)
+	return e, nil
+}
+
+// NewGame returns a recorder for a single game, which gets the next game number of the dataset.
+// Only the decisions of the given players are recorded. If no player is given, the decisions of all players are recorded.
+func (e *SelfPlayExporter) NewGame(players ...int) *SelfPlayGame {
+	e.l.Lock()
+	defer e.l.Unlock()
+
+	e.games++
+	s := &SelfPlayGame{exporter: e, game: e.games}
+	if len(players) != 0 {
+		s.players = make(map[int]bool, len(players))
+		for _, p := range players {
+			s.players[p] = true
+		}
+	}
+	return s
+}
+
+// Games returns the number of games started with NewGame.
+func (e *SelfPlayExporter) Games() int {
+	e.l.Lock()
+	defer e.l.Unlock()
+
+	return e.games
+}
+
+// write writes all samples.
+func (e *SelfPlayExporter) write(samples []SelfPlaySample) error {
+	e.l.Lock()
+	defer e.l.Unlock()
+
+	for i := range samples {
+		err := e.e.Encode(samples[i])
+		if err != nil {
+			return err
+		}
+	}
+	return e.w.Flush()
+}
+
+// Close closes the dataset file. Games which have not been finished are not written.
+func (e *SelfPlayExporter) Close() error {
+	e.l.Lock()
+	defer e.l.Unlock()
+
+	err := e.w.Flush()
+	if err != nil {
+		e.file.Close()
+		return err
+	}
+	return e.file.Close()
+}
+
+// SelfPlayGame collects the decisions of a single game until it has ended (see SelfPlayExporter.NewGame).
+// It must not be used concurrently.
+type SelfPlayGame struct {
+	exporter *SelfPlayExporter
+	game     int
+	players  map[int]bool
+	samples  []SelfPlaySample
+}
+
+// Record records the decisions of all recorded players of a single round.
+// g is the state at the beginning of the round and actions contains the actions of the round by player number. Players without an action are not recorded.
+// The state is copied, so it can be changed after Record returns.
+func (s *SelfPlayGame) Record(turn int, g *Game, actions map[int]Action) {
+	var cells []int8
+	var players map[int]SelfPlayPlayer
+	for id, action := range actions {
+		if s.players != nil && !s.players[id] {
+			continue
+		}
+		if p, ok := g.Players[id]; !ok || !p.Active {
+			continue
+		}
+		if cells == nil {
+			// All samples of a round share the state, it is never modified
+			cells = make([]int8, 0, g.Width*g.Height)
+			for y := range g.Cells {
+				cells = append(cells, g.Cells[y]...)
+			}
+			players = make(map[int]SelfPlayPlayer, len(g.Players))
+			for k, p := range g.Players {
+				players[k] = SelfPlayPlayer{X: p.X, Y: p.Y, Direction: p.Direction, Speed: p.Speed, Active: p.Active}
+			}
+		}
+		s.samples = append(s.samples, SelfPlaySample{
+			Game:    s.game,
+			Turn:    turn,
+			Player:  id,
+			Width:   g.Width,
+			Height:  g.Height,
+			Cells:   cells,
+			Players: players,
+			Action:  action,
+		})
+	}
+}
+
+// Samples returns the number of decisions recorded so far.
+func (s *SelfPlayGame) Samples() int {
+	return len(s.samples)
+}
+
+// Finish labels all recorded decisions with the result of the game after the given number of rounds and writes them to the dataset.
+// The samples are written in the order of the rounds and player numbers. Finish must be called only once.
+func (s *SelfPlayGame) Finish(result MatchResult, rounds int) error {
+	for i := range s.samples {
+		switch {
+		case result.Outcome == OutcomeWin && result.Winner == s.samples[i].Player:
+			s.samples[i].Result = "win"
+		case result.Outcome == OutcomeWin:
+			s.samples[i].Result = "loss"
+		default:
+			s.samples[i].Result = result.Outcome.String()
+		}
+		s.samples[i].Rounds = rounds
+	}
+	sort.Slice(s.samples, func(i, j int) bool {
+		if s.samples[i].Turn != s.samples[j].Turn {
+			return s.samples[i].Turn < s.samples[j].Turn
+		}
+		return s.samples[i].Player < s.samples[j].Player
+	})
+	err := s.exporter.write(s.samples)
+	s.samples = nil
+	return err
+}
@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSelfPlayExport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "selfplay.ndjson")
+	e, err := NewSelfPlayExporter(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewSimulator(20, 20, 1, "SurvivalAI", "SurvivalAI")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.SelfPlay = e.NewGame(1)
+
+	ticks := 0
+	for running := true; running; {
+		running = s.Step()
+		if _, ok := s.Actions[1]; ok {
+			ticks++
+		}
+	}
+	if ticks == 0 {
+		t.Fatal("player 1 did not play")
+	}
+	e.NewGame().Record(1, s.Game, map[int]Action{1: ActionNOOP, 2: ActionNOOP}) // never finished, so never written
+	err = e.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	result := s.Result()
+	records := 0
+	for sc := bufio.NewScanner(f); sc.Scan(); {
+		var sample SelfPlaySample
+		err := json.Unmarshal(sc.Bytes(), &sample)
+		if err != nil {
+			t.Fatal(err)
+		}
+		records++
+		want := "loss"
+		if result.Outcome != OutcomeWin {
+			want = result.Outcome.String()
+		} else if result.Winner == 1 {
+			want = "win"
+		}
+		switch {
+		case sample.Game != 1 || sample.Player != 1:
+			t.Errorf("record %d: game %d, player %d", records, sample.Game, sample.Player)
+		case sample.Turn != records:
+			t.Errorf("record %d: turn %d", records, sample.Turn)
+		case sample.Result != want || sample.Rounds != s.Round:
+			t.Errorf("record %d: result %s after %d rounds, want %s after %d rounds", records, sample.Result, sample.Rounds, want, s.Round)
+		case len(sample.Cells) != 20*20 || len(sample.Players) != 2:
+			t.Errorf("record %d: %d cells, %d players", records, len(sample.Cells), len(sample.Players))
+		}
+	}
+	if records != ticks {
+		t.Errorf("%d records for %d ticks", records, ticks)
+	}
+}
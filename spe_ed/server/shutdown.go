@@ -0,0 +1,113 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ShutdownAbortTimeout contains the time the server waits for aborted games to finish after the shutdown timeout has passed.
+const ShutdownAbortTimeout = 5 * time.Second
+
+var (
+	shuttingDown    int32
+	runningGames    sync.WaitGroup
+	runningGamesNum int32
+
+	// abortGames is closed when all running games should end immediately.
+	abortGames     = make(chan struct{})
+	abortGamesOnce sync.Once
+
+	// aiContext is the base of the contexts of all ContextAI and is cancelled when games are aborted, so no AI keeps computing.
+	aiContext, cancelAIContext = context.WithCancel(context.Background())
+)
+
+// isShuttingDown returns whether the server is shutting down and does not accept new players.
+func isShuttingDown() bool {
+	return atomic.LoadInt32(&shuttingDown) == 1
+}
+
+// gameStarted must be called when a game starts running. gameFinished must be called afterwards.
+func gameStarted() {
+	runningGames.Add(1)
+	atomic.AddInt32(&runningGamesNum, 1)
+}
+
+// gameFinished marks a game started with gameStarted as finished.
+func gameFinished() {
+	atomic.AddInt32(&runningGamesNum, -1)
+	runningGames.Done()
+}
+
+// Shutdown stops the server gracefully.
+// New connections are refused and players waiting for a game are disconnected. Running games may finish for up to timeout.
+// Afterwards, all remaining games are aborted: they end in the current round, all players get the final state and are disconnected.
+func Shutdown(server *http.Server, timeout time.Duration) {
+	atomic.StoreInt32(&shuttingDown, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	err := server.Shutdown(ctx)
+	if err != nil {
+		log.Println("shutdown: http server:", err)
+	}
+
+	// Players waiting in the lobby will never get a game
+	currentGameLock.Lock()
+	if currentGame != nil {
+		currentGame.l.Lock()
+		for i := range currentGame.Players {
+			err := currentGame.Players[i].Close()
+			if err != nil {
+				log.Println("shutdown: closing waiting player:", err)
+			}
+		}
+		currentGame.l.Unlock()
+		currentGame = nil
+	}
+	currentGameLock.Unlock()
+
+	running := atomic.LoadInt32(&runningGamesNum)
+	log.Printf("shutdown: waiting up to %s for %d running games", timeout, running)
+
+	done := make(chan struct{})
+	go func() {
+		runningGames.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		log.Printf("shutdown: %d games drained", running)
+		return
+	case <-ctx.Done():
+	}
+
+	aborted := atomic.LoadInt32(&runningGamesNum)
+	log.Printf("shutdown: %d games drained, aborting %d games", running-aborted, aborted)
+	abortGamesOnce.Do(func() { close(abortGames) })
+	cancelAIContext()
+
+	select {
+	case <-done:
+	case <-time.After(ShutdownAbortTimeout):
+		log.Printf("shutdown: %d games did not end after abort", atomic.LoadInt32(&runningGamesNum))
+	}
+}
@@ -0,0 +1,448 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var (
+	// ErrInvalidSpeed is returned by ApplyAction when the action would result in a speed outside of 1..MaxSpeed.
+	ErrInvalidSpeed = errors.New("invalid speed")
+)
+
+// HoleRules contains the rules for holes of a game.
+// The zero value contains the official rules (see HoleSpeed and HolesEachStep), so games received from the server and games created without setting the rules follow them.
+type HoleRules struct {
+	// Disabled turns off holes completely.
+	Disabled bool
+	// Speed is the minimum speed needed for a hole. HoleSpeed is used if it is zero.
+	Speed int
+	// EachStep is the number of rounds after which holes occur. HolesEachStep is used if it is zero.
+	EachStep int
+}
+
+// Validate returns an error if the rules contain negative values.
+func (h HoleRules) Validate() error {
+	if h.Speed < 0 || h.EachStep < 0 {
+		return fmt.Errorf("invalid hole rules (speed %d, each step %d)", h.Speed, h.EachStep)
+	}
+	return nil
+}
+
+// MinSpeed returns the minimum speed needed for a hole.
+func (h HoleRules) MinSpeed() int {
+	if h.Speed == 0 {
+		return HoleSpeed
+	}
+	return h.Speed
+}
+
+// Interval returns the number of rounds after which holes occur.
+func (h HoleRules) Interval() int {
+	if h.EachStep == 0 {
+		return HolesEachStep
+	}
+	return h.EachStep
+}
+
+// IsHole returns whether the s-th cell (starting with 0) traversed by a move with the given speed is left empty.
+// stepCounter is the step counter of the player after it was increased for the move (see Player.AdvanceTurn), i.e. the number of the round starting with 1.
+// Following the official rules, in every Interval()-th round only the first and the last cell are filled if the speed is at least MinSpeed().
+// The cells in between are neither filled nor checked for collisions. If the rules are disabled, there are no holes.
+func (h HoleRules) IsHole(speed, stepCounter, s int) bool {
+	return !h.Disabled && speed >= h.MinSpeed() && stepCounter%h.Interval() == 0 && s != 0 && s != speed-1
+}
+
+// ApplyAction applies a single action of a player to the game, following the rules of the server.
+// The direction and speed of the player are updated, then the player moves Speed cells and fills the cells with its number (leaving holes where the rules require them).
+// If the player leaves the board or moves into a filled cell, the player is set inactive and the movement stops. A filled cell is marked with -1 like on the server.
+// An action leading to a speed outside of 1..MaxSpeed, an unknown action or an unknown direction of the player returns an error and sets the player inactive.
+// Unlike the server, ApplyAction handles one player at a time, so players moving into the same cell in the same round are not detected (see resolveTick). Inactive players are not moved.
+func ApplyAction(g *Game, playerID int, action Action) error {
+	return applyAction(g, playerID, action, nil)
+}
+
+// Move contains everything changed by a single action applied with ApplyMove, so UndoMove can restore the game.
+type Move struct {
+	player      int
+	x, y        int
+	speed       int
+	stepCounter int
+	direction   Direction
+	active      bool
+	history     positionHistory
+	cells       []moveCell
+	jumped      bool
+}
+
+// moveCell is a cell changed by a move together with its previous value.
+type moveCell struct {
+	x, y int
+	prev int8
+}
+
+// Jumped returns whether the move jumped over a filled cell, i.e. a cell skipped by a hole was not empty.
+func (m Move) Jumped() bool {
+	return m.jumped
+}
+
+// set changes the cell and records its previous value. m might be nil, in which case nothing is recorded.
+func (m *Move) set(g *Game, x, y int, value int8) {
+	if m != nil {
+		m.cells = append(m.cells, moveCell{x, y, g.Cells[y][x]})
+	}
+	g.Cells[y][x] = value
+}
+
+// ApplyMove applies the action like ApplyAction and returns the move, which can be undone with UndoMove.
+// The second return value is false if the player crashed (see Simulate), in which case the changes are recorded as well and must be undone, too.
+// It is meant for searches trying many actions on the same game without copying it. Not safe for concurrent use on the same game.
+func ApplyMove(g *Game, playerID int, action Action) (Move, bool) {
+	p, ok := g.Players[playerID]
+	if !ok {
+		return Move{player: playerID}, false
+	}
+	m := Move{
+		player:      playerID,
+		x:           p.X,
+		y:           p.Y,
+		speed:       p.Speed,
+		stepCounter: p.stepCounter,
+		direction:   p.Direction,
+		active:      p.Active,
+		history:     p.history,
+		cells:       make([]moveCell, 0, p.Speed+1),
+	}
+	err := applyAction(g, playerID, action, &m)
+	return m, err == nil && p.Active
+}
+
+// UndoMove restores the game to the state before the move was applied with ApplyMove.
+// Moves must be undone in the reverse order they were applied. Not safe for concurrent use on the same game.
+func UndoMove(g *Game, m Move) {
+	p, ok := g.Players[m.player]
+	if !ok {
+		return
+	}
+	for i := len(m.cells) - 1; i >= 0; i-- {
+		g.Cells[m.cells[i].y][m.cells[i].x] = m.cells[i].prev
+	}
+	p.X, p.Y = m.x, m.y
+	p.Speed = m.speed
+	p.stepCounter = m.stepCounter
+	p.Direction = m.direction
+	p.Active = m.active
+	p.history = m.history
+}
+
+// undoMoves undoes all moves in reverse order (see UndoMove), so the moves must be given in the order they were applied.
+func undoMoves(g *Game, moves []Move) {
+	for i := len(moves) - 1; i >= 0; i-- {
+		UndoMove(g, moves[i])
+	}
+}
+
+// applyAction implements ApplyAction. If m is not nil, all changed cells are recorded in it.
+func applyAction(g *Game, playerID int, action Action, m *Move) error {
+	p, ok := g.Players[playerID]
+	if !ok {
+		return fmt.Errorf("unknown player %d", playerID)
+	}
+	if !p.Active {
+		return nil
+	}
+
+	direction, speed, err := steer(p.Direction, p.Speed, action)
+	p.Direction, p.Speed = direction, speed
+	if err != nil {
+		p.Active = false
+		return err
+	}
+	dx, dy, ok := directionStep(p.Direction)
+	if !ok {
+		p.Active = false
+		return fmt.Errorf("unknown direction %s", p.Direction)
+	}
+
+	p.AdvanceTurn()
+
+	for s := 0; s < p.Speed; s++ {
+		p.X, p.Y = p.X+dx, p.Y+dy
+		if p.X < 0 || p.X >= g.Width || p.Y < 0 || p.Y >= g.Height {
+			p.Active = false
+			return nil
+		}
+		if g.Holes.IsHole(p.Speed, p.stepCounter, s) {
+			if m != nil && !IsEmpty(g.Cells[p.Y][p.X]) {
+				m.jumped = true
+			}
+			continue
+		}
+		if !IsEmpty(g.Cells[p.Y][p.X]) {
+			m.set(g, p.X, p.Y, CellCrash)
+			p.Active = false
+			return nil
+		}
+		m.set(g, p.X, p.Y, PlayerCell(playerID))
+	}
+	return nil
+}
+
+// steer returns the direction and speed after the action, following the rules of the server.
+// An unknown action returns an error together with the unchanged direction and speed.
+// A resulting speed outside of 1..MaxSpeed returns ErrInvalidSpeed together with that speed.
+func steer(direction Direction, speed int, action Action) (Direction, int, error) {
+	switch action {
+	case ActionTurnLeft, ActionTurnRight:
+		direction = Turn(direction, action)
+	case ActionFaster:
+		speed++
+	case ActionSlower:
+		speed--
+	case ActionNOOP:
+		// Do nothing
+	default:
+		return direction, speed, fmt.Errorf("unknown action %s", action)
+	}
+	if speed < 1 || speed > MaxSpeed {
+		return direction, speed, ErrInvalidSpeed
+	}
+	return direction, speed, nil
+}
+
+// directionStep returns the change of the coordinates for a single cell moved in the direction.
+// ok is false for an unknown direction.
+func directionStep(direction Direction) (dx, dy int, ok bool) {
+	switch direction {
+	case DirectionUp:
+		return 0, -1, true
+	case DirectionDown:
+		return 0, 1, true
+	case DirectionLeft:
+		return -1, 0, true
+	case DirectionRight:
+		return 1, 0, true
+	}
+	return 0, 0, false
+}
+
+// Simulate answers what happens if the player performs the action: it returns a copy of the game after applying the action (see ApplyAction) and whether the player crashed.
+// The game is never modified. Leaving the board, moving into a filled cell (cells skipped by a hole are neither filled nor checked, see HoleRules), an invalid speed and an unknown action count as crash.
+// An unknown player counts as crashed, an inactive player does not move and counts as crashed as well.
+// The copy is acquired with AcquireClone, so code simulating many moves can return it with ReleaseClone once it is not needed any more.
+func Simulate(g *Game, playerID int, action Action) (*Game, bool) {
+	c := AcquireClone(g)
+	p, ok := c.Players[playerID]
+	if !ok {
+		return c, true
+	}
+	err := ApplyAction(c, playerID, action)
+	return c, err != nil || !p.Active
+}
+
+// LegalActions returns all actions which do not immediately crash the given player, following the rules of ApplyAction.
+// The actions are returned in the order change_nothing, turn_left, turn_right, slow_down, speed_up. Other players are not considered to move.
+// An unknown or inactive player has no legal actions. The game is not modified.
+func (g *Game) LegalActions(playerID int) []Action {
+	p, ok := g.Players[playerID]
+	if !ok || !p.Active {
+		return nil
+	}
+
+	b := NewBitboard(g)
+	legal := make([]Action, 0, 5)
+	for _, a := range []Action{ActionNOOP, ActionTurnLeft, ActionTurnRight, ActionSlower, ActionFaster} {
+		if !b.Crashes(p, a) {
+			legal = append(legal, a)
+		}
+	}
+	return legal
+}
+
+// InferAction infers the action the player performed between two consecutive states by applying every action to prev (see ApplyAction) and comparing the result with cur.
+// Since every action changes either direction or speed in its own way, the action is identified by the new direction and speed. Only the head of the player is compared, so holes in the trail do not matter.
+// The result is unambiguous (second return value true) if the player is still active and exactly one action leads to the direction, speed and position of cur.
+// If the player became inactive, the action matching direction and speed is returned, but never as unambiguous, since the player might also have crashed by not answering.
+// If no action matches, "" is returned. A matching action at a different position is returned as ambiguous, which indicates that the simulation does not agree with the server.
+// Without a previous state (prev is nil, e.g. on the first tick) or if both states have different board sizes, the action is unknown and "" is returned as ambiguous.
+func InferAction(prev, cur *Game, playerID int) (Action, bool) {
+	if prev == nil || cur == nil || prev.Width != cur.Width || prev.Height != cur.Height {
+		return "", false
+	}
+	p, ok := prev.Players[playerID]
+	if !ok || !p.Active {
+		return "", false
+	}
+	c, ok := cur.Players[playerID]
+	if !ok {
+		return "", false
+	}
+
+	var action Action
+	matches := 0
+	exact := false
+	for _, a := range []Action{ActionNOOP, ActionTurnLeft, ActionTurnRight, ActionSlower, ActionFaster} {
+		n := prev.Clone()
+		ApplyAction(n, playerID, a)
+		np := n.Players[playerID]
+		if np.Direction != c.Direction || np.Speed != c.Speed {
+			continue
+		}
+		matches++
+		action = a
+		exact = np.Active && np.X == c.X && np.Y == c.Y
+	}
+	return action, matches == 1 && c.Active && exact
+}
+
+// resolveTick plays a single round for all active players of the game with the given actions, resolving all moves simultaneously.
+// First, the new direction and speed and the traversed cells (excluding holes) of all players are computed. Afterwards, a player crashes if it
+// - answered with no or an unknown action, reached an invalid speed or has an unknown direction,
+// - left the board,
+// - moved into a cell which was already filled before the round or
+// - moved into a cell which was traversed by another player in the same round (this includes head-on collisions).
+// All traversed cells are filled with the number of the player, cells involved in a crash are filled with -1.
+// The players are not set inactive. Instead, all crashed players are returned in ascending order, so the caller can handle them.
+func resolveTick(g *Game, actions map[int]Action) []int {
+	ids := make([]int, 0, len(g.Players))
+	for id := range g.Players {
+		if g.Players[id].Active {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+
+	round := make([]Action, len(ids))
+	for i, id := range ids {
+		round[i] = actions[id]
+	}
+	return resolveRound(g, ids, round, nil)
+}
+
+// ApplyRound plays a single round for the given players with the given actions (actions[i] is the action of ids[i]), resolving all moves simultaneously like the server (see resolveTick).
+// Unlike resolveTick, crashed players are set inactive. The crashed players are returned in the order of ids together with the moves of all players, which can be undone with undoMoves.
+// Unknown and inactive players do not move. It is meant for searches predicting head-on collisions without copying the game. Not safe for concurrent use on the same game.
+func ApplyRound(g *Game, ids []int, actions []Action) ([]Move, []int) {
+	moves := make([]Move, len(ids))
+	crashed := resolveRound(g, ids, actions, moves)
+	for _, id := range crashed {
+		g.Players[id].Active = false
+	}
+	return moves, crashed
+}
+
+// resolveRound implements resolveTick for the given players, which do not need to be sorted. The crashed players are returned in the order of ids.
+// If moves is not nil, it must have the same length as ids and all changes of the player ids[i] are recorded in moves[i].
+func resolveRound(g *Game, ids []int, actions []Action, moves []Move) []int {
+	crashed := make([]bool, len(ids))
+	paths := make([][]coordinate, len(ids))
+
+	for i, id := range ids {
+		p, ok := g.Players[id]
+		var m *Move
+		if moves != nil {
+			moves[i] = Move{player: id}
+			if ok {
+				moves[i] = Move{player: id, x: p.X, y: p.Y, speed: p.Speed, stepCounter: p.stepCounter, direction: p.Direction, active: p.Active, history: p.history, cells: make([]moveCell, 0, p.Speed+1)}
+			}
+			m = &moves[i]
+		}
+		if !ok || !p.Active {
+			continue
+		}
+
+		direction, speed, err := steer(p.Direction, p.Speed, actions[i])
+		p.Direction, p.Speed = direction, speed
+		if err != nil {
+			crashed[i] = true
+			continue
+		}
+		dx, dy, ok := directionStep(p.Direction)
+		if !ok {
+			crashed[i] = true
+			continue
+		}
+
+		p.AdvanceTurn()
+
+		path := make([]coordinate, 0, p.Speed)
+		for s := 0; s < p.Speed; s++ {
+			p.X, p.Y = p.X+dx, p.Y+dy
+			if p.X < 0 || p.X >= g.Width || p.Y < 0 || p.Y >= g.Height {
+				crashed[i] = true
+				break
+			}
+			if g.Holes.IsHole(p.Speed, p.stepCounter, s) {
+				if m != nil && !IsEmpty(g.Cells[p.Y][p.X]) {
+					m.jumped = true
+				}
+				continue
+			}
+			path = append(path, coordinate{p.X, p.Y})
+		}
+		paths[i] = path
+	}
+
+	// A cell is contested if it is traversed by more than one player. A single path never contains a cell twice.
+	contested := func(i int, c coordinate) bool {
+		for j := range paths {
+			if j == i {
+				continue
+			}
+			for _, o := range paths[j] {
+				if o == c {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	bad := make([][]bool, len(ids))
+	for i := range ids {
+		bad[i] = make([]bool, len(paths[i]))
+		for k, c := range paths[i] {
+			if !IsEmpty(g.Cells[c.Y][c.X]) || contested(i, c) {
+				bad[i][k] = true
+				crashed[i] = true
+			}
+		}
+	}
+
+	for i, id := range ids {
+		var m *Move
+		if moves != nil {
+			m = &moves[i]
+		}
+		for k, c := range paths[i] {
+			if bad[i][k] {
+				m.set(g, c.X, c.Y, CellCrash)
+			} else {
+				m.set(g, c.X, c.Y, PlayerCell(id))
+			}
+		}
+	}
+
+	result := make([]int, 0, len(ids))
+	for i, id := range ids {
+		if crashed[i] {
+			result = append(result, id)
+		}
+	}
+	return result
+}
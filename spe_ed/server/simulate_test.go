@@ -0,0 +1,382 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestResolveTick(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		grid    string
+		speeds  string
+		actions map[int]Action
+		crashed []int
+		crash   []coordinate
+	}{
+		{
+			name:    "head-on across a gap",
+			grid:    `"........", "111>.<22", "........"`,
+			actions: map[int]Action{1: ActionNOOP, 2: ActionNOOP},
+			crashed: []int{1, 2},
+			crash:   []coordinate{{4, 1}},
+		},
+		{
+			name:    "swap",
+			grid:    `"........", "111><222", "........"`,
+			actions: map[int]Action{1: ActionNOOP, 2: ActionNOOP},
+			crashed: []int{1, 2},
+			crash:   []coordinate{{3, 1}, {4, 1}},
+		},
+		{
+			name:    "crossing paths",
+			grid:    `".......", ".......", "1>.....", ".......", "...^...", "...2..."`,
+			speeds:  `"1": {"speed": 2}, "2": {"speed": 2}`,
+			actions: map[int]Action{1: ActionFaster, 2: ActionFaster},
+			crashed: []int{1, 2},
+			crash:   []coordinate{{3, 2}},
+		},
+		{
+			name:    "crossing a trail of the same round",
+			grid:    `".......", ".......", "1>.....", "...^...", "...2...", "......."`,
+			speeds:  `"1": {"speed": 4}, "2": {"speed": 1}`,
+			actions: map[int]Action{1: ActionNOOP, 2: ActionNOOP},
+			crashed: []int{1, 2},
+			crash:   []coordinate{{3, 2}},
+		},
+		{
+			name:    "parallel",
+			grid:    `"........", "111>....", "222>....", "........"`,
+			actions: map[int]Action{1: ActionTurnLeft, 2: ActionTurnRight},
+		},
+		{
+			name:    "wall and no answer",
+			grid:    `"111>", "....", "222>"`,
+			actions: map[int]Action{1: ActionNOOP},
+			crashed: []int{1, 2},
+		},
+	} {
+		speeds := `"1": {}, "2": {}`
+		if tc.speeds != "" {
+			speeds = tc.speeds
+		}
+		g := testScenario(t, `{"you": 1, "grid": [`+tc.grid+`], "players": {`+speeds+`}}`)
+		before := g.Clone()
+
+		// ApplyRound agrees with resolveTick, sets the crashed players inactive and can be undone
+		round := g.Clone()
+		ids := []int{2, 1}
+		moves, roundCrashed := ApplyRound(round, ids, []Action{tc.actions[2], tc.actions[1]})
+		for _, id := range ids {
+			want := false
+			for _, c := range tc.crashed {
+				want = want || c == id
+			}
+			if round.Players[id].Active == want {
+				t.Errorf("%s: ApplyRound left player %d active %t (crashed %v)", tc.name, id, round.Players[id].Active, roundCrashed)
+			}
+		}
+		applied := round.Clone()
+		undoMoves(round, moves)
+		if !reflect.DeepEqual(round.Cells, before.Cells) || !reflect.DeepEqual(round.Players, before.Players) {
+			t.Errorf("%s: ApplyRound not undone\n%s", tc.name, round)
+		}
+
+		crashed := resolveTick(g, tc.actions)
+		if !reflect.DeepEqual(applied.Cells, g.Cells) {
+			t.Errorf("%s: ApplyRound does not agree with resolveTick\n%s\n%s", tc.name, applied, g)
+		}
+		if !reflect.DeepEqual(crashed, tc.crashed) && (len(crashed) != 0 || len(tc.crashed) != 0) {
+			t.Errorf("%s: crashed %v, want %v\n%s", tc.name, crashed, tc.crashed, g)
+		}
+		crash := make(map[coordinate]bool, len(tc.crash))
+		for _, c := range tc.crash {
+			crash[c] = true
+		}
+		for y := range g.Cells {
+			for x := range g.Cells[y] {
+				if (g.Cells[y][x] == CellCrash) != (crash[coordinate{x, y}] || before.Cells[y][x] == CellCrash) {
+					t.Errorf("%s: cell (%d,%d) is %d\n%s", tc.name, x, y, g.Cells[y][x], g)
+				}
+			}
+		}
+		for id, p := range g.Players {
+			if !p.Active {
+				t.Errorf("%s: resolveTick set player %d inactive", tc.name, id)
+			}
+		}
+	}
+}
+
+func FuzzApplyAction(f *testing.F) {
+	msg := serverMessage(f)
+	for _, a := range []Action{ActionNOOP, ActionTurnLeft, ActionTurnRight, ActionSlower, ActionFaster, "jump"} {
+		f.Add(msg, string(a), uint8(1))
+		f.Add(msg, string(a), uint8(HolesEachStep))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte, action string, round uint8) {
+		var g Game
+		if json.Unmarshal(data, &g) != nil || g.Validate() != nil {
+			return
+		}
+		ids := make([]int, 0, len(g.Players))
+		for id, p := range g.Players {
+			p.stepCounter = int(round)
+			ids = append(ids, id)
+		}
+		sort.Ints(ids)
+
+		for _, id := range ids {
+			c := g.Clone()
+			p := c.Players[id]
+			wasActive := p.Active
+			crashes := NewBitboard(&g).Crashes(g.Players[id], Action(action))
+			err := ApplyAction(c, id, Action(action))
+
+			for other := range g.Players {
+				if other != id && !reflect.DeepEqual(g.Players[other], c.Players[other]) {
+					t.Fatalf("player %d changed by action of player %d", other, id)
+				}
+			}
+			if !wasActive {
+				if err != nil || !reflect.DeepEqual(g.Cells, c.Cells) || !reflect.DeepEqual(g.Players[id], p) {
+					t.Fatalf("inactive player %d moved", id)
+				}
+				continue
+			}
+
+			if crashes != (err != nil || !p.Active) {
+				t.Fatalf("player %d, action %q: bitboard says crash %t, ApplyAction says active %t (error %v)", id, action, crashes, p.Active, err)
+			}
+			if s, crashed := Simulate(&g, id, Action(action)); crashed != crashes || !reflect.DeepEqual(s.Cells, c.Cells) {
+				t.Fatalf("player %d, action %q: Simulate does not agree with ApplyAction", id, action)
+			}
+			if p.Active {
+				if p.Speed < 1 || p.Speed > MaxSpeed {
+					t.Fatalf("player %d active with speed %d", id, p.Speed)
+				}
+				if p.X < 0 || p.X >= c.Width || p.Y < 0 || p.Y >= c.Height || c.Cells[p.Y][p.X] != PlayerCell(id) {
+					t.Fatalf("head of player %d at (%d,%d) not filled", id, p.X, p.Y)
+				}
+			}
+
+			changed, crashCells := 0, 0
+			for y := range c.Cells {
+				for x := range c.Cells[y] {
+					if c.Cells[y][x] == g.Cells[y][x] {
+						continue
+					}
+					changed++
+					switch {
+					case c.Cells[y][x] == CellCrash:
+						crashCells++
+					case c.Cells[y][x] != PlayerCell(id) || !IsEmpty(g.Cells[y][x]):
+						t.Fatalf("cell (%d,%d) changed from %d to %d by player %d", x, y, g.Cells[y][x], c.Cells[y][x], id)
+					}
+				}
+			}
+			if changed > MaxSpeed || crashCells > 1 || (crashCells == 1 && p.Active) {
+				t.Fatalf("player %d changed %d cells (%d crashes)", id, changed, crashCells)
+			}
+		}
+	})
+}
+
+func TestIsHole(t *testing.T) {
+	for speed := 2; speed <= MaxSpeed; speed++ {
+		for round := 1; round <= 2*HolesEachStep+1; round++ {
+			g := testScenario(t, fmt.Sprintf(`{"you": 1, "round": %d, "grid": ["1>..........", "............"], "players": {"1": {"speed": %d}}}`, round, speed))
+			err := ApplyAction(g, 1, ActionNOOP)
+			if err != nil {
+				t.Fatalf("speed %d, round %d: %v", speed, round, err)
+			}
+			if !g.Players[1].Active || g.Players[1].stepCounter != round {
+				t.Fatalf("speed %d, round %d: player active %t, step counter %d\n%s", speed, round, g.Players[1].Active, g.Players[1].stepCounter, g)
+			}
+			for s := 0; s < speed; s++ {
+				want := speed >= HoleSpeed && round%HolesEachStep == 0 && s != 0 && s != speed-1
+				if got := g.Holes.IsHole(speed, round, s); got != want {
+					t.Errorf("speed %d, round %d, cell %d: IsHole %t, want %t", speed, round, s, got, want)
+				}
+				if got := IsEmpty(g.Cells[0][2+s]); got != want {
+					t.Errorf("speed %d, round %d, cell %d: empty %t, want %t\n%s", speed, round, s, got, want, g)
+				}
+			}
+			if (HoleRules{Disabled: true}).IsHole(speed, round, speed/2) {
+				t.Errorf("speed %d, round %d: hole with disabled rules", speed, round)
+			}
+		}
+	}
+}
+
+func TestInferActionFirstTick(t *testing.T) {
+	s, err := NewSimulator(10, 10, 9, "SurvivalAI", "SurvivalAI", "SurvivalAI")
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := s.Game.Clone()
+	for id := range first.Players {
+		if a, ok := InferAction(nil, first, id); ok || a != "" {
+			t.Errorf("player %d: inferred %q (unambiguous %t) without a previous state", id, a, ok)
+		}
+	}
+	resized := testScenario(t, `{"you": 1, "grid": ["1>.", "2>."], "players": {"1": {}, "2": {}}}`)
+	for id := range resized.Players {
+		if a, ok := InferAction(first, resized, id); ok || a != "" {
+			t.Errorf("player %d: inferred %q (unambiguous %t) after the board size changed", id, a, ok)
+		}
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	g := testScenario(t, `{"you": 1, "grid": ["x....", "1>.x.", "...<2"], "players": {"1": {}, "2": {"active": false}}}`)
+	before, err := json.Marshal(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		player  int
+		action  Action
+		crashed bool
+	}{
+		{1, ActionNOOP, false},
+		{1, ActionTurnLeft, false},
+		{1, ActionTurnRight, false},
+		{1, ActionFaster, true},
+		{1, ActionSlower, true},
+		{1, Action("jump"), true},
+		{2, ActionNOOP, true},
+		{3, ActionNOOP, true},
+	} {
+		c, crashed := Simulate(g, tc.player, tc.action)
+		if crashed != tc.crashed {
+			t.Errorf("player %d, %s: crashed %t, want %t", tc.player, tc.action, crashed, tc.crashed)
+		}
+		ReleaseClone(c)
+		after, err := json.Marshal(g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(before, after) || g.Players[1].stepCounter != 0 {
+			t.Fatalf("player %d, %s: game modified", tc.player, tc.action)
+		}
+	}
+	wall := testScenario(t, `{"you": 1, "grid": ["1>"], "players": {"1": {}}}`)
+	if c, crashed := Simulate(wall, 1, ActionNOOP); !crashed {
+		t.Errorf("no crash into the wall\n%s", c)
+	}
+
+	// At speed 3, the middle cell is a hole in every HolesEachStep-th round
+	for round, crashed := range map[int]bool{HolesEachStep - 1: true, HolesEachStep: false} {
+		g := testScenario(t, fmt.Sprintf(`{"you": 1, "round": %d, "grid": ["1>.x.."], "players": {"1": {"speed": 3}}}`, round))
+		c, got := Simulate(g, 1, ActionNOOP)
+		if got != crashed {
+			t.Errorf("round %d: jump over a filled cell crashed %t, want %t\n%s", round, got, crashed, c)
+		}
+		ReleaseClone(c)
+	}
+}
+
+func TestApplyMove(t *testing.T) {
+	g := testScenario(t, `{"you": 1, "grid": ["x.....", "1>.x..", "....<2"], "players": {"1": {"speed": 2}, "2": {}}}`)
+	orig := g.Clone()
+	unchanged := func(format string, args ...interface{}) {
+		t.Helper()
+		if !reflect.DeepEqual(g.Cells, orig.Cells) || !reflect.DeepEqual(g.Players, orig.Players) {
+			t.Fatalf(format+": game not restored\n%s", append(args, g)...)
+		}
+	}
+
+	for _, id := range []int{1, 2, 3} {
+		for _, a := range append(Actions[:], Action("jump")) {
+			want, crashed := Simulate(g, id, a)
+			move, ok := ApplyMove(g, id, a)
+			if ok == crashed {
+				t.Errorf("player %d, %s: ApplyMove says ok %t, Simulate says crashed %t", id, a, ok, crashed)
+			}
+			if !reflect.DeepEqual(g.Cells, want.Cells) || (id != 3 && !reflect.DeepEqual(g.Players[id], want.Players[id])) {
+				t.Errorf("player %d, %s: ApplyMove does not agree with ApplyAction\n%s\n%s", id, a, g, want)
+			}
+			ReleaseClone(want)
+			UndoMove(g, move)
+			unchanged("player %d, %s", id, a)
+		}
+	}
+
+	// Moves of several players, including crashes, are undone in reverse order
+	var moves []Move
+	for _, step := range []struct {
+		id int
+		a  Action
+	}{{2, ActionNOOP}, {2, ActionNOOP}, {1, ActionSlower}, {1, ActionTurnRight}, {2, ActionTurnLeft}} {
+		move, _ := ApplyMove(g, step.id, step.a)
+		moves = append(moves, move)
+	}
+	if g.Players[1].Active || g.Players[2].Active || g.Cells[2][2] != CellCrash {
+		t.Fatalf("players did not crash as expected\n%s", g)
+	}
+	undoMoves(g, moves)
+	unchanged("sequence")
+
+	// At speed 3, the middle cell is a hole in every HolesEachStep-th round
+	for round, jumped := range map[int]bool{HolesEachStep - 1: false, HolesEachStep: true} {
+		g := testScenario(t, fmt.Sprintf(`{"you": 1, "round": %d, "grid": ["1>.x..", "......"], "players": {"1": {"speed": 3}}}`, round))
+		move, ok := ApplyMove(g, 1, ActionNOOP)
+		if ok != jumped || move.Jumped() != jumped {
+			t.Errorf("round %d: jump over a filled cell ok %t, jumped %t, want %t\n%s", round, ok, move.Jumped(), jumped, g)
+		}
+		if move, _ := ApplyMove(g, 1, ActionTurnRight); move.Jumped() {
+			t.Errorf("round %d: jumped without a filled cell", round)
+		}
+	}
+}
+
+func TestInferAction(t *testing.T) {
+	prev := opponentModelGame()
+	prev.Players[1].Speed = 2
+	for _, a := range []Action{ActionNOOP, ActionTurnLeft, ActionTurnRight, ActionSlower, ActionFaster} {
+		cur := prev.Clone()
+		ApplyAction(cur, 1, a)
+		if got, ok := InferAction(prev, cur, 1); got != a || !ok {
+			t.Errorf("%s: inferred %q (unambiguous %t)", a, got, ok)
+		}
+	}
+
+	// A head at a different position than simulated means the simulation does not agree with the server
+	cur := prev.Clone()
+	ApplyAction(cur, 1, ActionTurnLeft)
+	cur.Players[1].Y--
+	if got, ok := InferAction(prev, cur, 1); got != ActionTurnLeft || ok {
+		t.Errorf("moved too far: inferred %q (unambiguous %t)", got, ok)
+	}
+
+	// A crashed player might also have missed the deadline
+	prev.Cells[6][15] = 1
+	cur = prev.Clone()
+	ApplyAction(cur, 2, ActionNOOP)
+	if got, ok := InferAction(prev, cur, 2); got != ActionNOOP || ok {
+		t.Errorf("crashed: inferred %q (unambiguous %t)", got, ok)
+	}
+	if got, ok := InferAction(cur, cur, 2); got != "" || ok {
+		t.Errorf("inactive: inferred %q (unambiguous %t)", got, ok)
+	}
+}
@@ -0,0 +1,400 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SimulatorAnswerTimeout contains the maximum time the simulator waits for an answer of an AI if no Timeout is set.
+const SimulatorAnswerTimeout = 10 * time.Second
+
+// Simulator runs a complete game between AIs in-process, without a server or websockets.
+// The game is run round by round with the rules of the server. All AIs get their own view of the game (with the correct Game.You) and all answers are applied simultaneously like on the server.
+// All AIs implementing OpponentModelAI share a single OpponentModel observing every round.
+//
+// If Seed is not zero, the start positions and the seeds of all AIs implementing SeedableAI are derived from it, so the same seed and the same AIs lead to the same game.
+// This only holds as long as no AI exceeds the timeout.
+type Simulator struct {
+	// Game contains the current state of the game.
+	Game *Game
+	// Round contains the number of rounds played.
+	Round int
+	// Timeout is the time each AI has per round. If it is not zero, each round has a deadline. If it is zero, no deadline is set and the simulator waits up to SimulatorAnswerTimeout.
+	// AIs not answering in time are set inactive unless Fallback is set.
+	Timeout time.Duration
+	// Fallback is the action played for an AI which does not answer in time (see FallbackAction). If it is empty, these AIs crash instead.
+	Fallback string
+	// Recorder records every round if it is not nil. All actions of the round are recorded together with the state all AIs got (with You set to 0).
+	Recorder *ReplayRecorder
+	// SelfPlay records the decisions of every round as training samples if it is not nil. The samples are written once the game has ended (see SelfPlayGame.Finish).
+	SelfPlay *SelfPlayGame
+	// Actions contains the actions played in the last round (see Step). Players which did not answer in time are missing.
+	Actions map[int]Action
+
+	ais       map[int]AI
+	deciders  map[int]*answerSequencer
+	order     []int
+	opponents *OpponentModel
+}
+
+// NewSimulator returns a simulator for a game of the given size between the AIs with the given names.
+// The AIs are numbered in the order of the names, starting with 1. A seed of 0 results in a random game.
+func NewSimulator(width, height int, seed int64, names ...string) (*Simulator, error) {
+	ais := make([]AI, len(names))
+	for i := range names {
+		ai, err := CreateAI(names[i])
+		if err != nil {
+			return nil, err
+		}
+		ais[i] = ai
+	}
+	return NewSimulatorWithAIs(width, height, seed, ais...)
+}
+
+// NewSimulatorWithAIs works like NewSimulator, but uses the given AIs directly. This allows using AIs which are configured differently from the AI registry.
+// The AIs must not be used anywhere else.
+func NewSimulatorWithAIs(width, height int, seed int64, ais ...AI) (*Simulator, error) {
+	return NewSimulatorWithPlacement(width, height, seed, PlacementRandom, ais...)
+}
+
+// NewSimulatorWithPlacement works like NewSimulatorWithAIs, but chooses the start positions with the given placement (see PlacementRandom, PlacementSymmetric and PlacementCorners).
+// All players start on different cells, which are filled with their number.
+func NewSimulatorWithPlacement(width, height int, seed int64, placement string, ais ...AI) (*Simulator, error) {
+	err := ValidatePlacement(placement)
+	if err != nil {
+		return nil, err
+	}
+	if width < 1 || height < 1 {
+		return nil, fmt.Errorf("invalid size %dx%d", width, height)
+	}
+	if len(ais) == 0 || len(ais) > PlayersPerGame {
+		return nil, fmt.Errorf("number of ais must be between 1 and %d", PlayersPerGame)
+	}
+	if len(ais) > width*height {
+		return nil, errors.New("game is too small for all ais")
+	}
+
+	if seed == 0 {
+		seed = rand.Int63()
+	}
+	r := rand.New(rand.NewSource(seed))
+
+	s := &Simulator{
+		Game: &Game{
+			Width:   width,
+			Height:  height,
+			Cells:   make([][]int8, height),
+			Players: make(map[int]*Player, len(ais)),
+			Running: true,
+		},
+		ais:       make(map[int]AI, len(ais)),
+		deciders:  make(map[int]*answerSequencer, len(ais)),
+		order:     make([]int, 0, len(ais)),
+		opponents: NewOpponentModel(),
+	}
+	for i := range s.Game.Cells {
+		s.Game.Cells[i] = make([]int8, width)
+	}
+
+	place, err := newPlacer(placement, s.Game, len(ais), r)
+	if err != nil {
+		return nil, err
+	}
+	for i, ai := range ais {
+		id := i + 1
+		if sai, ok := ai.(SeedableAI); ok {
+			sai.Seed(r.Int63())
+		}
+		if mai, ok := ai.(OpponentModelAI); ok {
+			mai.SetOpponentModel(s.opponents)
+		}
+		s.ais[id] = ai
+		answer := make(chan Action, 1)
+		ai.GetChannel(answer)
+		s.deciders[id] = newAnswerSequencer(ai, answer)
+		s.order = append(s.order, id)
+
+		x, y, direction := place(i)
+		s.Game.Cells[y][x] = PlayerCell(id)
+		s.Game.Players[id] = &Player{
+			X:         x,
+			Y:         y,
+			Direction: direction,
+			Speed:     1,
+			Active:    true,
+			Name:      ai.Name(),
+		}
+	}
+	sort.Ints(s.order)
+	s.Game.MaxPlayer = len(ais)
+	return s, nil
+}
+
+// Step plays a single round. It returns false if the game has already ended or ended in this round.
+func (s *Simulator) Step() bool {
+	if !s.Game.Running {
+		return false
+	}
+
+	var state *Game
+	if s.Recorder != nil {
+		state = s.Game.PublicCopy()
+	}
+
+	actions, durations := s.collectAnswers()
+
+	if s.Fallback != "" {
+		for _, id := range s.order {
+			if _, ok := actions[id]; ok || !s.Game.Players[id].Active {
+				continue
+			}
+			g := s.Game.ViewFor(id)
+			actions[id] = FallbackAction(g, s.Fallback)
+			log.Printf("simulator: ai %d (%s) did not answer in time, playing %s", id, s.ais[id].Name(), actions[id])
+			metricTimeouts.WithLabelValues(s.ais[id].Name()).Inc()
+			LogDecision(g, "simulator", actions[id], nil, "ai did not answer in time")
+		}
+	}
+
+	if s.Recorder != nil {
+		err := s.Recorder.Record(s.Round+1, state, actions, durations)
+		if err != nil {
+			log.Println("simulator: can not record replay:", err)
+		}
+	}
+
+	if s.SelfPlay != nil {
+		s.SelfPlay.Record(s.Round+1, s.Game, actions)
+	}
+
+	s.Actions = actions
+	for _, id := range resolveTick(s.Game, actions) {
+		s.Game.Players[id].Active = false
+	}
+
+	s.Round++
+
+	active := 0
+	for _, id := range s.order {
+		if s.Game.Players[id].Active {
+			active++
+		}
+	}
+	if active == 0 || (active == 1 && len(s.order) > 1) {
+		s.Game.Running = false
+		result := s.Game.Result()
+		for _, id := range s.order {
+			notifyGameEnd(s.ais[id], result.Outcome == OutcomeWin && result.Winner == id)
+		}
+		if s.Recorder != nil {
+			err := s.Recorder.Record(s.Round+1, s.Game.PublicCopy(), nil, nil)
+			if err != nil {
+				log.Println("simulator: can not record replay:", err)
+			}
+		}
+		if s.SelfPlay != nil {
+			err := s.SelfPlay.Finish(result, s.Round)
+			if err != nil {
+				log.Println("simulator: can not export self-play samples:", err)
+			}
+		}
+	}
+	return s.Game.Running
+}
+
+// Run plays the game until it has ended and returns the winner.
+func (s *Simulator) Run() int {
+	for s.Step() {
+	}
+	return s.Winner()
+}
+
+// RunInteractive plays the game like Run, but pauses before every round until a line is read from in, so the game can be followed round by round.
+// An empty line plays a single round, a number n plays n rounds without pausing and q stops the game early. If in reaches its end, the remaining rounds are played without pausing.
+// "? <player> <action>" shows the board after a hypothetical move of the player without playing it (see Simulate).
+// The board (see Game.String) is written to w at the start and after every round together with the actions played and the players crashed in the round.
+// It returns the winner like Run, which is 0 if the game was stopped early.
+func (s *Simulator) RunInteractive(in io.Reader, w io.Writer) int {
+	scanner := bufio.NewScanner(in)
+	interactive := true
+	pending := 0
+	fmt.Fprintf(w, "round %d\n%s", s.Round, s.Game.String())
+	for s.Game.Running {
+		if interactive && pending == 0 {
+			fmt.Fprint(w, "[enter] next round, [n] n rounds, [? player action] what if, [q] quit: ")
+			if !scanner.Scan() {
+				interactive = false
+				fmt.Fprintln(w)
+			} else {
+				line := strings.TrimSpace(scanner.Text())
+				switch line {
+				case "":
+					pending = 1
+				case "q":
+					fmt.Fprintf(w, "stopped after %d rounds\n", s.Round)
+					return s.Winner()
+				default:
+					if strings.HasPrefix(line, "?") {
+						s.whatIf(w, strings.Fields(strings.TrimPrefix(line, "?")))
+						continue
+					}
+					n, err := strconv.Atoi(line)
+					if err != nil || n < 1 {
+						fmt.Fprintf(w, "unknown command %q\n", line)
+						continue
+					}
+					pending = n
+				}
+			}
+		}
+
+		active := make([]int, 0, len(s.order))
+		for _, id := range s.order {
+			if s.Game.Players[id].Active {
+				active = append(active, id)
+			}
+		}
+		s.Step()
+		if pending > 0 {
+			pending--
+		}
+
+		fmt.Fprintf(w, "round %d\n", s.Round)
+		for _, id := range active {
+			action, ok := s.Actions[id]
+			if !ok {
+				action = "no answer"
+			}
+			crashed := ""
+			if !s.Game.Players[id].Active {
+				crashed = " - crashed"
+			}
+			fmt.Fprintf(w, "%d (%s): %s%s\n", id, s.ais[id].Name(), action, crashed)
+		}
+		fmt.Fprint(w, s.Game.String())
+	}
+
+	result := s.Result()
+	switch result.Outcome {
+	case OutcomeWin:
+		fmt.Fprintf(w, "game ended after %d rounds: %d (%s) won\n", s.Round, result.Winner, s.ais[result.Winner].Name())
+	case OutcomeDraw:
+		fmt.Fprintf(w, "game ended after %d rounds: draw\n", s.Round)
+	default:
+		fmt.Fprintf(w, "game ended after %d rounds\n", s.Round)
+	}
+	return result.Winner
+}
+
+// whatIf writes the board after the player given in args performs the action given in args to w (see Simulate).
+func (s *Simulator) whatIf(w io.Writer, args []string) {
+	if len(args) != 2 {
+		fmt.Fprintln(w, "usage: ? <player> <action>")
+		return
+	}
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		fmt.Fprintf(w, "unknown player %q\n", args[0])
+		return
+	}
+	c, crashed := Simulate(s.Game, id, Action(args[1]))
+	defer ReleaseClone(c)
+	result := "survives"
+	if crashed {
+		result = "crashes"
+	}
+	fmt.Fprintf(w, "%d: %s %s\n%s", id, args[1], result, c.String())
+}
+
+// Result returns the result of the game (see Game.Result).
+// In a game with a single AI, there is never a result.
+func (s *Simulator) Result() MatchResult {
+	return s.Game.Result()
+}
+
+// Winner returns the number of the winning player after the game has ended or 0 if there is no winner (all players crashed in the same round or the game is still running).
+// Use Result to distinguish a draw from a game without a result.
+func (s *Simulator) Winner() int {
+	return s.Game.Result().Winner
+}
+
+// collectAnswers sends the current state to all active AIs and waits for their answers.
+// Missing answers are not included. The second return value contains the time each AI needed for its answer.
+// An AI still deciding on an earlier round answers that round, which is discarded (see answerSequencer), so it misses this round as well.
+func (s *Simulator) collectAnswers() (map[int]Action, map[int]time.Duration) {
+	wait := SimulatorAnswerTimeout
+	deadline := ""
+	if s.Timeout > 0 {
+		wait = s.Timeout
+		deadline = ServerNow().Add(s.Timeout).UTC().Format(time.RFC3339Nano)
+	}
+
+	s.opponents.Observe(s.Game)
+
+	type answer struct {
+		id     int
+		action Action
+		t      time.Duration
+	}
+	results := make(chan answer, len(s.order))
+	done := make(chan struct{})
+	defer close(done)
+
+	start := time.Now()
+	cancelAt := start.Add(wait)
+	waiting := 0
+	for _, id := range s.order {
+		if !s.Game.Players[id].Active {
+			continue
+		}
+		waiting++
+		g := s.Game.ViewFor(id)
+		g.Deadline = deadline
+		c, _ := s.deciders[id].decide(g, cancelAt, done)
+		go func(id int, c <-chan Action) {
+			select {
+			case a := <-c:
+				results <- answer{id: id, action: a, t: time.Since(start)}
+			case <-done:
+			}
+		}(id, c)
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	actions := make(map[int]Action, len(s.order))
+	durations := make(map[int]time.Duration, len(s.order))
+	for ; waiting > 0; waiting-- {
+		select {
+		case a := <-results:
+			actions[a.id] = a.action
+			durations[a.id] = a.t
+		case <-timer.C:
+			return actions, durations
+		}
+	}
+	return actions, durations
+}
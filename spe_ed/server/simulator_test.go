@@ -0,0 +1,250 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// scriptedAI answers with a fixed action per round (ActionNOOP if none is set) after an optional delay.
+type scriptedAI struct {
+	l       sync.Mutex
+	i       chan Action
+	round   int
+	actions map[int]Action
+	delays  map[int]time.Duration
+	states  []*Game
+}
+
+func (s *scriptedAI) GetChannel(c chan Action) {
+	s.l.Lock()
+	defer s.l.Unlock()
+	s.i = c
+}
+
+func (s *scriptedAI) GetState(g *Game) {
+	s.l.Lock()
+	defer s.l.Unlock()
+	s.round++
+	s.states = append(s.states, g)
+	time.Sleep(s.delays[s.round])
+	a, ok := s.actions[s.round]
+	if !ok {
+		a = ActionNOOP
+	}
+	select {
+	case s.i <- a:
+	default:
+	}
+}
+
+func (s *scriptedAI) Name() string {
+	return "scriptedAI"
+}
+
+// endingAI is a scriptedAI recording the results given to GameEnd.
+type endingAI struct {
+	scriptedAI
+	results []bool
+}
+
+func (e *endingAI) GameEnd(won bool) {
+	e.l.Lock()
+	defer e.l.Unlock()
+	e.results = append(e.results, won)
+}
+
+// placePlayer moves the player of the simulator to the given position.
+func placePlayer(s *Simulator, id, x, y int, direction Direction) {
+	p := s.Game.Players[id]
+	s.Game.Cells[p.Y][p.X] = CellEmpty
+	p.X, p.Y, p.Direction = x, y, direction
+	s.Game.Cells[y][x] = PlayerCell(id)
+}
+
+func TestSimulatorLateAnswer(t *testing.T) {
+	ai := &scriptedAI{
+		actions: map[int]Action{1: ActionFaster},
+		delays:  map[int]time.Duration{1: 150 * time.Millisecond},
+	}
+	s, err := NewSimulatorWithAIs(40, 40, 1, ai)
+	if err != nil {
+		t.Fatal(err)
+	}
+	placePlayer(s, 1, 5, 20, DirectionRight)
+	s.Timeout = 100 * time.Millisecond
+	s.Fallback = string(ActionNOOP)
+
+	s.Step()
+	if s.Game.Players[1].Speed != 1 {
+		t.Fatal("late answer used in its own round")
+	}
+	// The decision of the first round has not returned yet, its answer must not count for the second round
+	s.Step()
+	if a := s.Actions[1]; a != ActionNOOP || s.Game.Players[1].Speed != 1 {
+		t.Errorf("second round played %s with speed %d, want the answer of the second round", a, s.Game.Players[1].Speed)
+	}
+	if ai.round != 2 {
+		t.Errorf("ai decided %d times", ai.round)
+	}
+}
+
+func TestSimulatorDeadline(t *testing.T) {
+	ai := new(scriptedAI)
+	s, err := NewSimulatorWithAIs(40, 40, 1, ai)
+	if err != nil {
+		t.Fatal(err)
+	}
+	placePlayer(s, 1, 5, 20, DirectionRight)
+	s.Timeout = time.Second
+	before := ServerNow()
+	s.Step()
+
+	d, err := time.Parse(time.RFC3339Nano, ai.states[0].Deadline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Format(time.RFC3339Nano) != ai.states[0].Deadline {
+		t.Errorf("deadline %s not in RFC 3339 with nanoseconds", ai.states[0].Deadline)
+	}
+	if d.Before(before.Add(s.Timeout)) || d.After(ServerNow().Add(s.Timeout)) {
+		t.Errorf("deadline %s not %s after the start of the round", ai.states[0].Deadline, s.Timeout)
+	}
+}
+
+func TestSimulatorViews(t *testing.T) {
+	ais := []*scriptedAI{{}, {}, {}}
+	s, err := NewSimulatorWithAIs(40, 40, 1, ais[0], ais[1], ais[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for round := 1; round <= 2; round++ {
+		s.Step()
+		for i, ai := range ais {
+			v := ai.states[round-1]
+			if v.You != i+1 {
+				t.Errorf("round %d: ai %d got You %d", round, i+1, v.You)
+			}
+			if v == s.Game || &v.Cells[0][0] == &s.Game.Cells[0][0] || v.Players[1] == s.Game.Players[1] {
+				t.Errorf("round %d: ai %d shares its view with the game", round, i+1)
+			}
+			for j, other := range ais[:i] {
+				if other.states[round-1] == v {
+					t.Errorf("round %d: ais %d and %d share a view", round, j+1, i+1)
+				}
+			}
+		}
+	}
+
+	// Modifying a view must not change the game
+	before := s.Game.Clone()
+	v := ais[0].states[1]
+	for y := range v.Cells {
+		for x := range v.Cells[y] {
+			v.Cells[y][x] = CellCrash
+		}
+	}
+	v.Players[1].X++
+	if !reflect.DeepEqual(s.Game.Cells, before.Cells) || s.Game.Players[1].X != before.Players[1].X {
+		t.Error("modifying a view changed the game")
+	}
+}
+
+func TestSimulatorGameEnd(t *testing.T) {
+	ais := []*endingAI{{}, {}}
+	s, err := NewSimulatorWithAIs(40, 40, 1, ais[0], ais[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Player 2 hits the border in the second round, player 1 keeps moving
+	placePlayer(s, 1, 5, 20, DirectionRight)
+	placePlayer(s, 2, 38, 5, DirectionRight)
+
+	if winner := s.Run(); winner != 1 {
+		t.Fatalf("winner %d\n%s", winner, s.Game)
+	}
+	s.Step()
+	s.Run()
+	for i, ai := range ais {
+		if len(ai.results) != 1 || ai.results[0] != (i == 0) {
+			t.Errorf("ai %d notified with %v", i+1, ai.results)
+		}
+	}
+}
+
+func TestSimulatorPanic(t *testing.T) {
+	s, err := NewSimulatorWithAIs(40, 40, 1, panicAI{}, &scriptedAI{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	placePlayer(s, 1, 5, 20, DirectionRight)
+	placePlayer(s, 2, 30, 5, DirectionLeft)
+	s.Timeout = 100 * time.Millisecond
+	s.Fallback = string(ActionTurnLeft)
+
+	s.Step()
+	p := s.Game.Players[1]
+	if !p.Active || p.Direction != DirectionUp || p.X != 5 || p.Y != 19 {
+		t.Errorf("panicking ai did not play the fallback\n%s", s.Game)
+	}
+}
+
+// holeRounds plays seeded games of JumpAI against SurvivalAI with the given hole rules.
+// It returns the number of rounds without a crash in which fewer cells were filled than the players moved.
+func holeRounds(t *testing.T, rules HoleRules) int {
+	t.Helper()
+	holes := 0
+	for seed := int64(1); seed <= 5; seed++ {
+		s, err := NewSimulatorWithAIs(30, 30, seed, &JumpAI{}, &SurvivalAI{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.Game.Holes = rules
+		for {
+			free := freeCells(s.Game)
+			running := s.Step()
+			moved, crashed := 0, false
+			for _, p := range s.Game.Players {
+				if !p.Active {
+					crashed = true
+				}
+				moved += p.Speed
+			}
+			if crashed {
+				break
+			}
+			if filled := free - freeCells(s.Game); filled != moved {
+				holes++
+			}
+			if !running {
+				break
+			}
+		}
+	}
+	return holes
+}
+
+func TestSimulatorNoHoles(t *testing.T) {
+	if n := holeRounds(t, HoleRules{Disabled: true}); n != 0 {
+		t.Errorf("%d rounds with holes, want none", n)
+	}
+	if holeRounds(t, HoleRules{}) == 0 {
+		t.Error("no rounds with holes with the official rules")
+	}
+}
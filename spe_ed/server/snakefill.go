@@ -0,0 +1,104 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+// SnakeFill computes a serpentine (boustrophedon) path through the region of the player and returns its actions.
+// The player first slows down to speed 1, so every round fills exactly one cell and no holes occur afterwards. Then it moves straight until it is blocked and makes a U-turn (two turns in the same direction), alternating the direction of the U-turns, so the region is filled line by line.
+// An action is only taken if it leaves a pocket (see TrapCheck) as large as the best legal action, otherwise the next preferred action is used. This keeps the path from cutting off parts of irregular regions.
+// Every action is checked with the rules of ApplyAction (including holes while slowing down). The path ends when every action crashes, so all returned actions can be played if the other players do not interfere.
+// The game is not modified.
+func SnakeFill(g *Game, player int) []Action {
+	p, ok := g.Players[player]
+	if !ok || !p.Active {
+		return nil
+	}
+
+	c := g.Clone()
+	p = c.Players[player]
+	actions := make([]Action, 0)
+	turn := ActionTurnRight
+	uturn := false
+
+	for {
+		var preferred []Action
+		switch {
+		case p.Speed > 1:
+			preferred = []Action{ActionSlower, ActionNOOP, turn, snakeFillOpposite(turn)}
+		case uturn:
+			preferred = []Action{turn, ActionNOOP, snakeFillOpposite(turn)}
+		default:
+			preferred = []Action{ActionNOOP, turn, snakeFillOpposite(turn)}
+		}
+
+		b := NewBitboard(c)
+		pockets := make([]int, len(preferred))
+		best := -1
+		for i := range preferred {
+			pockets[i] = -1
+			if b.Crashes(p, preferred[i]) {
+				continue
+			}
+			n, crashed := Simulate(c, player, preferred[i])
+			if crashed {
+				ReleaseClone(n)
+				continue
+			}
+			np := n.Players[player]
+			pockets[i], _ = TrapCheck(n, np.X, np.Y, np.Speed)
+			ReleaseClone(n)
+			if pockets[i] > best {
+				best = pockets[i]
+			}
+		}
+		if best == -1 {
+			return actions
+		}
+
+		var action Action
+		for i := range preferred {
+			if pockets[i] == best {
+				action = preferred[i]
+				break
+			}
+		}
+
+		switch {
+		case action == ActionNOOP || action == ActionSlower:
+			// Keep the state of the U-turn
+		case uturn && action == turn:
+			// U-turn completed, the next one goes the other way
+			uturn = false
+			turn = snakeFillOpposite(turn)
+		case uturn:
+			// The U-turn is blocked, so the player turned back into its old direction one line further
+			uturn = false
+		default:
+			uturn = true
+			turn = action
+		}
+
+		ApplyAction(c, player, action)
+		actions = append(actions, action)
+	}
+}
+
+// snakeFillOpposite returns the turn into the other direction.
+func snakeFillOpposite(turn Action) Action {
+	if turn == ActionTurnLeft {
+		return ActionTurnRight
+	}
+	return ActionTurnLeft
+}
@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestSnakeFill(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		grid  string
+		speed int
+	}{
+		{"rectangle", `"1>......", "........", "........", "........", "........", "........"`, 1},
+		{"odd width", `"1>.....", ".......", ".......", ".......", ".......", "......."`, 1},
+		{"middle", `"........", "........", "........", "...1>...", "........", "........", "........"`, 1},
+		{"irregular", `"1>......", "........", "xxx.....", "xxx.....", "........", "......xx"`, 1},
+		{"fast", `"111>......", "..........", "..........", "..........", "..........", ".........."`, 3},
+	} {
+		g := testScenario(t, `{"you": 1, "grid": [`+tc.grid+`], "players": {"1": {"speed": `+strconv.Itoa(tc.speed)+`}}}`)
+		before := g.Clone()
+		pocket := freeCells(g)
+
+		actions := SnakeFill(g, 1)
+		if !reflect.DeepEqual(g.Cells, before.Cells) || g.Players[1].X != before.Players[1].X || g.Players[1].Y != before.Players[1].Y {
+			t.Fatalf("%s: game modified", tc.name)
+		}
+		if tc.speed > 1 && (len(actions) == 0 || actions[0] != ActionSlower) {
+			t.Errorf("%s: path %v does not start by slowing down", tc.name, actions)
+		}
+
+		for i, a := range actions {
+			err := ApplyAction(g, 1, a)
+			if err != nil || !g.Players[1].Active {
+				t.Fatalf("%s: action %d (%s) of %v crashed\n%s", tc.name, i, a, actions, g)
+			}
+		}
+		if len(g.LegalActions(1)) != 0 {
+			t.Errorf("%s: path ends although %v are legal\n%s", tc.name, g.LegalActions(1), g)
+		}
+		visited := pocket - freeCells(g)
+		if visited < pocket*9/10 {
+			t.Errorf("%s: visited %d of %d cells\n%s", tc.name, visited, pocket, g)
+		}
+	}
+}
+
+func TestSnakeFillInactive(t *testing.T) {
+	g := testScenario(t, `{"you": 1, "grid": ["1>..", "...."], "players": {"1": {"active": false}}}`)
+	if actions := SnakeFill(g, 1); actions != nil {
+		t.Errorf("inactive player: %v", actions)
+	}
+	if actions := SnakeFill(g, 2); actions != nil {
+		t.Errorf("unknown player: %v", actions)
+	}
+}
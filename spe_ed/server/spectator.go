@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// SpectatorBuffer contains the number of states buffered for each spectator. Spectators falling further behind are disconnected.
+	// Games between AIs only can play many rounds per second, so the buffer is rather large.
+	SpectatorBuffer = 256
+	// SpectatorWriteTimeout contains the maximum time for sending a single state to a spectator.
+	SpectatorWriteTimeout = 5 * time.Second
+)
+
+// spectator is a connected spectator. It gets snapshots of the games through c.
+// The game loop owns the *Game and mutates it between rounds, so spectators must never see it.
+// Instead, sendState calls broadcastSpectators once per round while holding the game lock, which encodes the game into a snapshot (the JSON message) before any spectator gets it.
+// The same snapshot is shared by all spectators. It is never modified after it was published, so writers can use it without locking.
+// A snapshot lives until every spectator has written it or was disconnected, afterwards it is garbage collected.
+type spectator struct {
+	c chan []byte
+}
+
+var (
+	spectatorLock = sync.Mutex{}
+	spectators    = make(map[*spectator]bool)
+)
+
+// spectatorEndpoint upgrades the connection and streams the state of every round of all running games as JSON (the same format the players get, with you set to 0).
+// Messages from spectators are ignored.
+func spectatorEndpoint(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Println("spectator: upgrade:", err)
+		return
+	}
+
+	s := &spectator{c: make(chan []byte, SpectatorBuffer)}
+	spectatorLock.Lock()
+	spectators[s] = true
+	spectatorLock.Unlock()
+
+	go func() {
+		// Detect closed connections
+		for {
+			_, _, err := conn.ReadMessage()
+			if err != nil {
+				removeSpectator(s)
+				return
+			}
+		}
+	}()
+
+	for b := range s.c {
+		conn.SetWriteDeadline(time.Now().Add(SpectatorWriteTimeout))
+		err := conn.WriteMessage(websocket.TextMessage, b)
+		if err != nil {
+			removeSpectator(s)
+			// Drain until the channel is closed
+			for range s.c {
+			}
+		}
+	}
+	conn.Close()
+}
+
+// removeSpectator removes the spectator and closes its channel. It is safe to call it multiple times.
+func removeSpectator(s *spectator) {
+	spectatorLock.Lock()
+	defer spectatorLock.Unlock()
+	if !spectators[s] {
+		return
+	}
+	delete(spectators, s)
+	close(s.c)
+}
+
+// broadcastSpectators publishes a snapshot of the game to all spectators. It never blocks: spectators which can not keep up are disconnected.
+// Caller has to lock the game. The game is only read until broadcastSpectators returns.
+func broadcastSpectators(g *Game) {
+	spectatorLock.Lock()
+	n := len(spectators)
+	spectatorLock.Unlock()
+	if n == 0 {
+		return
+	}
+
+	// Encode without holding spectatorLock, so connecting spectators are not blocked by large boards
+	b, err := json.Marshal(g)
+	if err != nil {
+		log.Println("spectator:", err)
+		return
+	}
+
+	spectatorLock.Lock()
+	defer spectatorLock.Unlock()
+	for s := range spectators {
+		select {
+		case s.c <- b:
+		default:
+			log.Println("spectator: too slow, disconnecting")
+			delete(spectators, s)
+			close(s.c)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Run with go test -race: the game loop and the spectator writers share the snapshots.
+func TestSpectators(t *testing.T) {
+	oldConfig := GetGameConfig()
+	oldLogging := disableLogging
+	disableLogging = true
+	t.Cleanup(func() {
+		disableLogging = oldLogging
+		SetGameConfig(oldConfig)
+	})
+	config := DefaultGameConfig
+	config.MinWidth, config.MaxWidth, config.MinHeight, config.MaxHeight = 12, 12, 12, 12
+	config.MinPlayers, config.MaxPlayers = 2, 2
+	err := SetGameConfig(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := httptest.NewServer(http.HandlerFunc(spectatorEndpoint))
+	t.Cleanup(s.Close)
+
+	const numSpectators = 2
+	states := make([]chan [][]byte, numSpectators)
+	for i := range states {
+		ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(s.URL, "http"), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { ws.Close() })
+		states[i] = make(chan [][]byte, 1)
+		go func(c chan [][]byte) {
+			var received [][]byte
+			defer func() { c <- received }()
+			for {
+				ws.SetReadDeadline(time.Now().Add(10 * time.Second))
+				_, b, err := ws.ReadMessage()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				received = append(received, b)
+				var g Game
+				err = json.Unmarshal(b, &g)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if !g.Running {
+					return
+				}
+			}
+		}(states[i])
+	}
+
+	// Wait until the endpoint registered all spectators
+	for start := time.Now(); ; time.Sleep(time.Millisecond) {
+		spectatorLock.Lock()
+		n := len(spectators)
+		spectatorLock.Unlock()
+		if n == numSpectators {
+			break
+		}
+		if time.Since(start) > 2*time.Second {
+			t.Fatalf("%d spectators registered, want %d", n, numSpectators)
+		}
+	}
+
+	g := new(Game)
+	for _, ai := range []AI{&BadRandomAI{Pessimistic: true}, &SurvivalAI{}} {
+		p := new(Player)
+		p.underlyingAI = ai
+		p.Input = make(chan Action, 5)
+		ai.GetChannel(p.Input)
+		err := g.AddPlayer(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !g.IsReady() {
+		t.Fatal("game not ready")
+	}
+	_, err = g.RunGame()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := <-states[0]
+	if len(first) < 2 {
+		t.Fatalf("spectator received %d states, want at least 2", len(first))
+	}
+	for i := 1; i < numSpectators; i++ {
+		received := <-states[i]
+		if len(received) != len(first) {
+			t.Fatalf("spectator %d received %d states, spectator 0 received %d", i, len(received), len(first))
+		}
+		for j := range received {
+			if !bytes.Equal(received[j], first[j]) {
+				t.Errorf("spectator %d: state %d differs from spectator 0", i, j)
+			}
+		}
+	}
+}
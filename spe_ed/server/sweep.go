@@ -0,0 +1,226 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+// SweepConfig contains the configuration of a parameter sweep (see RunSweep).
+type SweepConfig struct {
+	// Grid contains all weight configurations which are evaluated.
+	Grid []WeightedHeuristicWeights
+	// Opponent is the name of the AI playing against WeightedHeuristicAI.
+	Opponent string
+	// Matches is the number of matches per configuration.
+	Matches int
+	// MinSize and MaxSize limit the width and height of the boards. Both are chosen independently for every match.
+	MinSize, MaxSize int
+	// Seed is used to derive the seeds and board sizes of all matches.
+	Seed int64
+	// Workers is the number of matches run in parallel. runtime.GOMAXPROCS(0) is used if it is zero.
+	Workers int
+	// Placement is the placement of the players at the start of every match (see NewSimulatorWithPlacement). PlacementRandom is used if it is empty.
+	Placement string
+	// Holes contains the hole rules of all matches. The zero value contains the official rules.
+	Holes HoleRules
+}
+
+// sweepGrid represents the file format of a sweep grid: every weight has a list of values.
+type sweepGrid struct {
+	ReachableSpace   []float64 `json:"reachable_space"`
+	Voronoi          []float64 `json:"voronoi"`
+	OpponentDistance []float64 `json:"opponent_distance"`
+	CorridorWidth    []float64 `json:"corridor_width"`
+	Chamber          []float64 `json:"chamber"`
+}
+
+// LoadSweepGrid reads a JSON file containing a list of values for each weight, e.g. {"voronoi": [0, 0.5, 1], "corridor_width": [0, 2]}, and returns all combinations.
+// Weights which are missing or have no values only use their default value. Unknown keys are an error.
+func LoadSweepGrid(path string) ([]WeightedHeuristicWeights, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("sweep: %w", err)
+	}
+	var grid sweepGrid
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.DisallowUnknownFields()
+	err = d.Decode(&grid)
+	if err != nil {
+		return nil, fmt.Errorf("sweep: can not parse %s: %w", path, err)
+	}
+
+	values := func(v []float64, def float64) []float64 {
+		if len(v) == 0 {
+			return []float64{def}
+		}
+		return v
+	}
+	def := DefaultWeightedHeuristicWeights
+
+	result := make([]WeightedHeuristicWeights, 0)
+	for _, rs := range values(grid.ReachableSpace, def.ReachableSpace) {
+		for _, v := range values(grid.Voronoi, def.Voronoi) {
+			for _, od := range values(grid.OpponentDistance, def.OpponentDistance) {
+				for _, cw := range values(grid.CorridorWidth, def.CorridorWidth) {
+					for _, ch := range values(grid.Chamber, def.Chamber) {
+						result = append(result, WeightedHeuristicWeights{ReachableSpace: rs, Voronoi: v, OpponentDistance: od, CorridorWidth: cw, Chamber: ch})
+					}
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// RunSweep plays config.Matches matches of WeightedHeuristicAI against config.Opponent in the simulator for every configuration of the grid and writes the results as CSV to w.
+// All configurations play the same matches (same seeds and board sizes), so the results only depend on the weights and config.Seed, independent of the number of workers.
+// Matches are run in parallel.
+func RunSweep(config SweepConfig, w io.Writer) error {
+	if len(config.Grid) == 0 {
+		return errors.New("sweep: empty grid")
+	}
+	if config.Matches < 1 {
+		return errors.New("sweep: at least one match per configuration is needed")
+	}
+	if config.MinSize < 2 || config.MaxSize < config.MinSize {
+		return fmt.Errorf("sweep: invalid board size range %d-%d", config.MinSize, config.MaxSize)
+	}
+	if _, err := CreateAI(config.Opponent); err != nil {
+		return fmt.Errorf("sweep: %w", err)
+	}
+	placement := config.Placement
+	if placement == "" {
+		placement = PlacementRandom
+	}
+	if err := ValidatePlacement(placement); err != nil {
+		return fmt.Errorf("sweep: %w", err)
+	}
+	if err := config.Holes.Validate(); err != nil {
+		return fmt.Errorf("sweep: %w", err)
+	}
+	workers := config.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	type match struct {
+		seed          int64
+		width, height int
+	}
+	r := rand.New(rand.NewSource(config.Seed))
+	matches := make([]match, config.Matches)
+	for i := range matches {
+		matches[i] = match{
+			seed:   r.Int63(),
+			width:  config.MinSize + r.Intn(config.MaxSize-config.MinSize+1),
+			height: config.MinSize + r.Intn(config.MaxSize-config.MinSize+1),
+		}
+	}
+
+	wins := make([]int, len(config.Grid))
+	draws := make([]int, len(config.Grid))
+	var resultLock sync.Mutex
+	var firstErr error
+
+	type job struct{ config, match int }
+	jobs := make(chan job)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				m := matches[j.match]
+				opponent, err := CreateAI(config.Opponent)
+				var s *Simulator
+				if err == nil {
+					s, err = NewSimulatorWithPlacement(m.width, m.height, m.seed, placement, &WeightedHeuristicAI{Weights: config.Grid[j.config]}, opponent)
+				}
+				if err != nil {
+					resultLock.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					resultLock.Unlock()
+					continue
+				}
+				s.Game.Holes = config.Holes
+				s.Run()
+				r := s.Result()
+
+				resultLock.Lock()
+				switch {
+				case r.Outcome == OutcomeWin && r.Winner == 1:
+					wins[j.config]++
+				case r.Outcome == OutcomeDraw:
+					draws[j.config]++
+				}
+				resultLock.Unlock()
+			}
+		}()
+	}
+	for c := range config.Grid {
+		for m := range matches {
+			jobs <- job{c, m}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return fmt.Errorf("sweep: %w", firstErr)
+	}
+
+	f := func(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) }
+	cw := csv.NewWriter(w)
+	err := cw.Write([]string{"reachable_space", "voronoi", "opponent_distance", "corridor_width", "chamber", "matches", "wins", "draws", "losses", "winrate"})
+	if err != nil {
+		return fmt.Errorf("sweep: %w", err)
+	}
+	for c, weights := range config.Grid {
+		err = cw.Write([]string{
+			f(weights.ReachableSpace),
+			f(weights.Voronoi),
+			f(weights.OpponentDistance),
+			f(weights.CorridorWidth),
+			f(weights.Chamber),
+			strconv.Itoa(config.Matches),
+			strconv.Itoa(wins[c]),
+			strconv.Itoa(draws[c]),
+			strconv.Itoa(config.Matches - wins[c] - draws[c]),
+			f(float64(wins[c]) / float64(config.Matches)),
+		})
+		if err != nil {
+			return fmt.Errorf("sweep: %w", err)
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("sweep: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// TimedAIStats contains the cumulative statistics of a TimedAIWrapper.
+type TimedAIStats struct {
+	// Calls contains the number of states given to the AI.
+	Calls int
+	// Exceeded contains the number of states for which the AI needed longer than the budget.
+	Exceeded int
+	// Total contains the time the AI needed for all states.
+	Total time.Duration
+	// Max contains the longest time the AI needed for a single state.
+	Max time.Duration
+}
+
+// Mean returns the mean time the AI needed for a state or 0 if no state was given to it.
+func (s TimedAIStats) Mean() time.Duration {
+	if s.Calls == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Calls)
+}
+
+// TimedAIWrapper measures how long an AI needs for each state and reports every state exceeding a budget.
+// Apart from this, it behaves like the wrapped AI: The answers are sent by the wrapped AI on the channel given to GetChannel, Name returns the name of the wrapped AI and all optional interfaces (ContextAI, SeedableAI, OpponentModelAI, GameEndAI, Explainable) are forwarded.
+// Use TimedAI to create it.
+type TimedAIWrapper struct {
+	inner  AI
+	budget time.Duration
+
+	l     sync.Mutex
+	stats TimedAIStats
+}
+
+// TimedAI wraps an AI in a TimedAIWrapper. Every state taking longer than budget is logged together with the conditions on the board and counted in the metrics.
+// A budget of 0 disables the report, but the statistics are still collected.
+func TimedAI(inner AI, budget time.Duration) AI {
+	return &TimedAIWrapper{inner: inner, budget: budget}
+}
+
+// GetChannel gives the channel to the wrapped AI.
+func (t *TimedAIWrapper) GetChannel(c chan Action) {
+	t.inner.GetChannel(c)
+}
+
+// GetState gives the game to the wrapped AI and measures the time until it returns.
+func (t *TimedAIWrapper) GetState(g *Game) {
+	t.GetStateContext(context.Background(), g)
+}
+
+// GetStateContext gives the game to the wrapped AI and measures the time until it returns.
+// The context is only used if the wrapped AI implements ContextAI.
+func (t *TimedAIWrapper) GetStateContext(ctx context.Context, g *Game) {
+	// The AI might modify the game, so the conditions are collected beforehand
+	turn, speed, active, filled := 0, 0, 0, 0
+	if p, ok := g.Players[g.You]; ok {
+		turn = p.stepCounter + 1
+		speed = p.Speed
+	}
+	for k := range g.Players {
+		if g.Players[k].Active {
+			active++
+		}
+	}
+	for y := range g.Cells {
+		for x := range g.Cells[y] {
+			if !IsEmpty(g.Cells[y][x]) {
+				filled++
+			}
+		}
+	}
+
+	start := time.Now()
+	if cai, ok := t.inner.(ContextAI); ok {
+		cai.GetStateContext(ctx, g)
+	} else {
+		t.inner.GetState(g)
+	}
+	d := time.Since(start)
+
+	t.l.Lock()
+	t.stats.Calls++
+	t.stats.Total += d
+	if d > t.stats.Max {
+		t.stats.Max = d
+	}
+	exceeded := t.budget > 0 && d > t.budget
+	if exceeded {
+		t.stats.Exceeded++
+	}
+	t.l.Unlock()
+
+	if exceeded {
+		metricBudgetExceeded.WithLabelValues(t.inner.Name()).Inc()
+		log.Printf("timedai: %s needed %s (budget %s) in turn %d at speed %d with %d active players and %d/%d cells filled", t.inner.Name(), d.Round(time.Microsecond), t.budget, turn, speed, active, filled, g.Width*g.Height)
+	}
+}
+
+// Name returns the name of the wrapped AI.
+func (t *TimedAIWrapper) Name() string {
+	return t.inner.Name()
+}
+
+// Seed seeds the wrapped AI if it implements SeedableAI.
+func (t *TimedAIWrapper) Seed(seed int64) {
+	if sai, ok := t.inner.(SeedableAI); ok {
+		sai.Seed(seed)
+	}
+}
+
+// SetOpponentModel gives the model to the wrapped AI if it implements OpponentModelAI.
+func (t *TimedAIWrapper) SetOpponentModel(m *OpponentModel) {
+	if mai, ok := t.inner.(OpponentModelAI); ok {
+		mai.SetOpponentModel(m)
+	}
+}
+
+// GameEnd forwards the result to the wrapped AI if it implements GameEndAI.
+func (t *TimedAIWrapper) GameEnd(won bool) {
+	notifyGameEnd(t.inner, won)
+}
+
+// Explain returns the scores of the wrapped AI if it implements Explainable and nil otherwise.
+func (t *TimedAIWrapper) Explain(g *Game) map[Action]float64 {
+	if eai, ok := t.inner.(Explainable); ok {
+		return eai.Explain(g)
+	}
+	return nil
+}
+
+// Inner returns the wrapped AI.
+func (t *TimedAIWrapper) Inner() AI {
+	return t.inner
+}
+
+// Stats returns the statistics collected so far. Safe for concurrent use.
+func (t *TimedAIWrapper) Stats() TimedAIStats {
+	t.l.Lock()
+	defer t.l.Unlock()
+	return t.stats
+}
@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var serverTimeOffset time.Duration
+var serverTimeLock sync.RWMutex
+
+// ServerNow returns the current time of the game server.
+// It is the local time corrected by the offset measured by SyncServerTime (or the local time if there was no synchronisation).
+func ServerNow() time.Time {
+	serverTimeLock.RLock()
+	defer serverTimeLock.RUnlock()
+	return time.Now().Add(serverTimeOffset)
+}
+
+// SyncServerTime queries the time endpoint of a spe_ed server (e.g. /spe_ed_time) and stores the offset between the server clock and the local clock for ServerNow.
+// Half of the round trip time is assumed as the delay of the answer.
+// If an error occurs, the stored offset is unchanged.
+func SyncServerTime(timeURL string) error {
+	client := http.Client{Timeout: 5 * time.Second}
+	start := time.Now()
+	resp, err := client.Get(timeURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	end := time.Now()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("time endpoint returned %s", resp.Status)
+	}
+
+	var t struct {
+		Time         string `json:"time"`
+		Milliseconds int    `json:"milliseconds"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&t)
+	if err != nil {
+		return err
+	}
+	server, err := time.Parse(time.RFC3339, t.Time)
+	if err != nil {
+		return err
+	}
+	server = server.Add(time.Duration(t.Milliseconds) * time.Millisecond)
+	local := start.Add(end.Sub(start) / 2)
+
+	serverTimeLock.Lock()
+	defer serverTimeLock.Unlock()
+	serverTimeOffset = server.Sub(local)
+	return nil
+}
@@ -0,0 +1,170 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	// TournamentPointsWin contains the points an AI gets for winning a match of RunTournament.
+	TournamentPointsWin = 3
+	// TournamentPointsDraw contains the points both AIs get for a drawn match of RunTournament.
+	TournamentPointsDraw = 1
+)
+
+// TournamentConfig contains the configuration of a round-robin tournament between several AIs (see RunTournament).
+type TournamentConfig struct {
+	// AIs contains the names of all participating AIs.
+	AIs []string
+	// Games is the number of games of every match.
+	Games int
+	// MinWidth, MaxWidth, MinHeight and MaxHeight limit the width and height of the boards (see CompareConfig).
+	MinWidth, MaxWidth, MinHeight, MaxHeight int
+	// Seed is used to derive the seeds and board sizes of all games. All matches are played on the same boards.
+	Seed int64
+	// Placement is the placement of the players at the start of every game (see NewSimulatorWithPlacement). PlacementRandom is used if it is empty.
+	Placement string
+	// Workers is the number of games run in parallel. runtime.GOMAXPROCS(0) is used if it is zero.
+	Workers int
+	// Holes contains the hole rules of all games (see CompareConfig).
+	Holes HoleRules
+	// CSV receives the standings as CSV if it is not nil.
+	CSV io.Writer
+}
+
+// TournamentStanding contains the results of a single AI in a tournament.
+type TournamentStanding struct {
+	// AI is the name of the AI.
+	AI string
+	// Wins, Draws and Losses count the matches by their outcome for the AI.
+	Wins, Draws, Losses int
+	// Points is the sum of the points of all matches (see TournamentPointsWin and TournamentPointsDraw).
+	Points int
+	// GameWins, GameDraws and GameLosses count the single games of all matches by their outcome for the AI.
+	GameWins, GameDraws, GameLosses int
+}
+
+// RunTournament lets every pair of AIs play a match of config.Games games (see RunCompare) and writes the standings as table to w.
+// The AI winning more games of a match wins the match, an equal number of won games is a drawn match.
+// The standings are sorted by points, then by won games and then by name.
+// The matches are played one after another, but the games of each match are run in parallel.
+func RunTournament(config TournamentConfig, w io.Writer) ([]TournamentStanding, error) {
+	if len(config.AIs) < 2 {
+		return nil, errors.New("tournament: at least two ais are needed")
+	}
+	standings := make([]TournamentStanding, len(config.AIs))
+	seen := make(map[string]bool, len(config.AIs))
+	for i, name := range config.AIs {
+		if seen[name] {
+			return nil, fmt.Errorf("tournament: %s participates more than once", name)
+		}
+		seen[name] = true
+		if _, err := CreateAI(name); err != nil {
+			return nil, fmt.Errorf("tournament: %w", err)
+		}
+		standings[i].AI = name
+	}
+
+	for i := range config.AIs {
+		for j := i + 1; j < len(config.AIs); j++ {
+			r, err := RunCompare(CompareConfig{
+				AIs:       [2]string{config.AIs[i], config.AIs[j]},
+				Games:     config.Games,
+				MinWidth:  config.MinWidth,
+				MaxWidth:  config.MaxWidth,
+				MinHeight: config.MinHeight,
+				MaxHeight: config.MaxHeight,
+				Seed:      config.Seed,
+				Placement: config.Placement,
+				Workers:   config.Workers,
+				Holes:     config.Holes,
+			}, ioutil.Discard)
+			if err != nil {
+				return nil, fmt.Errorf("tournament: %w", err)
+			}
+			log.Printf("tournament: %s vs %s: %d-%d (%d draws)", config.AIs[i], config.AIs[j], r.Wins[0], r.Wins[1], r.Draws)
+
+			a, b := &standings[i], &standings[j]
+			a.GameWins += r.Wins[0]
+			a.GameLosses += r.Wins[1]
+			a.GameDraws += r.Draws
+			b.GameWins += r.Wins[1]
+			b.GameLosses += r.Wins[0]
+			b.GameDraws += r.Draws
+			switch {
+			case r.Wins[0] > r.Wins[1]:
+				a.Wins++
+				a.Points += TournamentPointsWin
+				b.Losses++
+			case r.Wins[0] < r.Wins[1]:
+				b.Wins++
+				b.Points += TournamentPointsWin
+				a.Losses++
+			default:
+				a.Draws++
+				a.Points += TournamentPointsDraw
+				b.Draws++
+				b.Points += TournamentPointsDraw
+			}
+		}
+	}
+
+	sort.Slice(standings, func(i, j int) bool {
+		if standings[i].Points != standings[j].Points {
+			return standings[i].Points > standings[j].Points
+		}
+		if standings[i].GameWins != standings[j].GameWins {
+			return standings[i].GameWins > standings[j].GameWins
+		}
+		return standings[i].AI < standings[j].AI
+	})
+
+	header := []string{"rank", "ai", "wins", "draws", "losses", "points", "game_wins", "game_draws", "game_losses"}
+	rows := make([][]string, len(standings))
+	for i, s := range standings {
+		rows[i] = []string{strconv.Itoa(i + 1), s.AI, strconv.Itoa(s.Wins), strconv.Itoa(s.Draws), strconv.Itoa(s.Losses), strconv.Itoa(s.Points), strconv.Itoa(s.GameWins), strconv.Itoa(s.GameDraws), strconv.Itoa(s.GameLosses)}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, row := range append([][]string{header}, rows...) {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	err := tw.Flush()
+	if err != nil {
+		return nil, fmt.Errorf("tournament: %w", err)
+	}
+
+	if config.CSV != nil {
+		cw := csv.NewWriter(config.CSV)
+		err = cw.Write(header)
+		if err == nil {
+			err = cw.WriteAll(rows)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("tournament: %w", err)
+		}
+	}
+	return standings, nil
+}
@@ -0,0 +1,94 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+// TrapCheckSpeedFactor contains the factor of the speed which a pocket must at least have so that TrapCheck does not consider the player trapped.
+const TrapCheckSpeedFactor = 10
+
+// TrapCheck estimates the space a player with its head at (x, y) can actually fill and reports whether the player is trapped.
+// Unlike FloodFill it respects that a player entering a region behind an articulation point (a cell splitting the free space) can not come back.
+// The estimate assumes the player fills the region it is in and then commits to the largest region behind one of its articulation points.
+// trapped is true if the resulting pocket is smaller than TrapCheckSpeedFactor times speed.
+// The start cell does not need to be free, so the position of a head can be used directly.
+func TrapCheck(g *Game, x, y, speed int) (pocket int, trapped bool) {
+	if x < 0 || x >= g.Width || y < 0 || y >= g.Height {
+		return 0, true
+	}
+
+	// Tarjan's articulation point algorithm over the free cells.
+	// discovered is 0 for unvisited cells, so the discovery time starts with 1.
+	discovered := make([]int, g.Width*g.Height)
+	low := make([]int, g.Width*g.Height)
+	counter := 0
+
+	// base is the size of the region of the cell (within its subtree), extra the size of the largest region behind an articulation point.
+	var dfs func(c coordinate) (base, extra int)
+	dfs = func(c coordinate) (base, extra int) {
+		i := c.Y*g.Width + c.X
+		counter++
+		discovered[i] = counter
+		low[i] = counter
+		base = 1
+		for _, n := range [4]coordinate{{c.X + 1, c.Y}, {c.X - 1, c.Y}, {c.X, c.Y + 1}, {c.X, c.Y - 1}} {
+			if n.X < 0 || n.X >= g.Width || n.Y < 0 || n.Y >= g.Height || !IsEmpty(g.Cells[n.Y][n.X]) {
+				continue
+			}
+			j := n.Y*g.Width + n.X
+			if discovered[j] != 0 {
+				if discovered[j] < low[i] {
+					low[i] = discovered[j]
+				}
+				continue
+			}
+			childBase, childExtra := dfs(n)
+			if low[j] < low[i] {
+				low[i] = low[j]
+			}
+			if low[j] >= discovered[i] {
+				// c is an articulation point - the subtree of n is a separate region
+				if childBase+childExtra > extra {
+					extra = childBase + childExtra
+				}
+				continue
+			}
+			base += childBase
+			if childExtra > extra {
+				extra = childExtra
+			}
+		}
+		return base, extra
+	}
+
+	start := y*g.Width + x
+	counter++
+	discovered[start] = counter
+	low[start] = counter
+	for _, n := range [4]coordinate{{x + 1, y}, {x - 1, y}, {x, y + 1}, {x, y - 1}} {
+		if n.X < 0 || n.X >= g.Width || n.Y < 0 || n.Y >= g.Height || !IsEmpty(g.Cells[n.Y][n.X]) {
+			continue
+		}
+		if discovered[n.Y*g.Width+n.X] != 0 {
+			continue
+		}
+		// The head is left in one direction, so only one subtree of the start can be used
+		base, extra := dfs(n)
+		if base+extra > pocket {
+			pocket = base + extra
+		}
+	}
+
+	return pocket, pocket < TrapCheckSpeedFactor*speed
+}
@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+// Voronoi returns the number of free cells the given player reaches strictly before all other active players.
+// It runs a multi-source breadth-first search starting at the heads of all active players, where every step costs one tick.
+// Cells reached by several players at the same time belong to nobody and are not expanded further.
+// Players with a number below 1 are ignored, since they can not be distinguished from free or contested cells.
+func Voronoi(g *Game, player int) int {
+	const contested = -1
+
+	owner := make([]int, g.Width*g.Height)
+	dist := make([]int, g.Width*g.Height)
+	frontier := make([]coordinate, 0, len(g.Players))
+	for k := range g.Players {
+		p := g.Players[k]
+		if k < 1 || !p.Active || p.X < 0 || p.X >= g.Width || p.Y < 0 || p.Y >= g.Height {
+			continue
+		}
+		if owner[p.Y*g.Width+p.X] != 0 {
+			// Two heads on one cell - nobody owns it
+			owner[p.Y*g.Width+p.X] = contested
+			continue
+		}
+		owner[p.Y*g.Width+p.X] = k
+		frontier = append(frontier, coordinate{p.X, p.Y})
+	}
+
+	count := 0
+	step := 0
+	next := make([]coordinate, 0, 64)
+	for len(frontier) != 0 {
+		step++
+		next = next[:0]
+		for _, c := range frontier {
+			o := owner[c.Y*g.Width+c.X]
+			if o == contested {
+				continue
+			}
+			for _, n := range [4]coordinate{{c.X + 1, c.Y}, {c.X - 1, c.Y}, {c.X, c.Y + 1}, {c.X, c.Y - 1}} {
+				if n.X < 0 || n.X >= g.Width || n.Y < 0 || n.Y >= g.Height {
+					continue
+				}
+				if !IsEmpty(g.Cells[n.Y][n.X]) {
+					continue
+				}
+				switch owner[n.Y*g.Width+n.X] {
+				case 0:
+					owner[n.Y*g.Width+n.X] = o
+					dist[n.Y*g.Width+n.X] = step
+					next = append(next, n)
+					if o == player {
+						count++
+					}
+				case o, contested:
+					// Already known
+				default:
+					// Reached by somebody else - contested if it was reached in the same step
+					if dist[n.Y*g.Width+n.X] == step {
+						if owner[n.Y*g.Width+n.X] == player {
+							count--
+						}
+						owner[n.Y*g.Width+n.X] = contested
+					}
+				}
+			}
+		}
+		frontier, next = next, frontier
+	}
+	return count
+}
@@ -0,0 +1,81 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrServerStalled is returned by the client if the server did not send a new state in time (see StateWatchdog).
+var ErrServerStalled = errors.New("server stalled")
+
+const (
+	// ClientWatchdogFactor contains the default factor of StateWatchdog, i.e. the number of tick intervals the client waits for the next state.
+	ClientWatchdogFactor = 10
+	// ClientWatchdogMinTimeout contains the minimum time StateWatchdog waits for the next state, so a few fast rounds do not make a slower round look like a stalled server.
+	ClientWatchdogMinTimeout = 5 * time.Second
+)
+
+// StateWatchdog detects a server which stopped sending states while the game is still running.
+// The next state is expected within Factor times the interval between the last two states, but at least ClientWatchdogMinTimeout.
+// Since the server waits for the answers of all players, the next state might arrive only at the deadline of the current one. Therefore, the time remaining until the deadline is used instead of the interval if it is longer.
+// Before the second state the interval is unknown, so waiting for the start of the game never stalls.
+// A Factor of zero or less disables the watchdog.
+type StateWatchdog struct {
+	// Factor is the number of tick intervals to wait for the next state.
+	Factor int
+
+	last      time.Time
+	interval  time.Duration
+	remaining time.Duration
+}
+
+// Observe records that a state arrived at t with the given time remaining until its deadline (zero if unknown) and returns the time until which the next state must arrive.
+// The zero time is returned if the watchdog is disabled or the interval is not known yet.
+func (w *StateWatchdog) Observe(t time.Time, remaining time.Duration) time.Time {
+	if !w.last.IsZero() {
+		w.interval = t.Sub(w.last)
+	}
+	w.last = t
+	w.remaining = remaining
+	timeout := w.Timeout()
+	if timeout == 0 {
+		return time.Time{}
+	}
+	return t.Add(timeout)
+}
+
+// Timeout returns the time the watchdog currently waits for the next state or zero if the watchdog is disabled or the interval is not known yet.
+func (w *StateWatchdog) Timeout() time.Duration {
+	if w.Factor <= 0 || w.interval <= 0 {
+		return 0
+	}
+	expected := w.interval
+	if w.remaining > expected {
+		expected = w.remaining
+	}
+	timeout := time.Duration(w.Factor) * expected
+	if timeout < ClientWatchdogMinTimeout {
+		timeout = ClientWatchdogMinTimeout
+	}
+	return timeout
+}
+
+// Interval returns the last observed interval between two states or zero if it is not known yet.
+func (w *StateWatchdog) Interval() time.Duration {
+	return w.interval
+}
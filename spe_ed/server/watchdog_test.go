@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestStateWatchdog(t *testing.T) {
+	start := time.Date(2021, 1, 17, 13, 47, 21, 0, time.UTC)
+	w := &StateWatchdog{Factor: 10}
+	if next := w.Observe(start, time.Second); !next.IsZero() || w.Timeout() != 0 {
+		t.Fatalf("first state: next state expected until %s", next)
+	}
+	if next := w.Observe(start.Add(time.Second), 0); w.Interval() != time.Second || !next.Equal(start.Add(11*time.Second)) {
+		t.Errorf("interval %s: next state expected until %s", w.Interval(), next)
+	}
+	if w.Observe(start.Add(time.Second+100*time.Millisecond), 0); w.Timeout() != ClientWatchdogMinTimeout {
+		t.Errorf("fast rounds: timeout %s, want %s", w.Timeout(), ClientWatchdogMinTimeout)
+	}
+	if w.Observe(start.Add(time.Second+200*time.Millisecond), 2*time.Second); w.Timeout() != 20*time.Second {
+		t.Errorf("deadline far away: timeout %s, want 20s", w.Timeout())
+	}
+
+	disabled := new(StateWatchdog)
+	disabled.Observe(start, 0)
+	if next := disabled.Observe(start.Add(time.Second), 0); !next.IsZero() {
+		t.Errorf("disabled watchdog: next state expected until %s", next)
+	}
+}
+
+func TestClientServerStalled(t *testing.T) {
+	if testing.Short() {
+		t.Skip("waits for ClientWatchdogMinTimeout")
+	}
+	ws := fakeServer(t, func(ws *websocket.Conn) {
+		for i := 0; i < 3; i++ {
+			err := ws.WriteMessage(websocket.TextMessage, serverState(t, 100*time.Millisecond))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			var a ActionMessage
+			err = ws.ReadJSON(&a)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		// Stall until the client gives up
+		ws.ReadMessage()
+	})
+
+	start := time.Now()
+	lost, err := playClient(t, &scriptedAI{}, ws, &StateWatchdog{Factor: ClientWatchdogFactor})
+	if !lost || !errors.Is(err, ErrServerStalled) {
+		t.Fatalf("connection lost %t, got error %v, want ErrServerStalled", lost, err)
+	}
+	if d := time.Since(start); d < ClientWatchdogMinTimeout || d > ClientWatchdogMinTimeout+2*time.Second {
+		t.Errorf("stalled server detected after %s", d)
+	}
+}
@@ -0,0 +1,290 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// ProtocolCheckOff contains the protocol check ignoring unknown fields in received states, like encoding/json does.
+	ProtocolCheckOff = iota
+	// ProtocolCheckLog contains the protocol check logging every unknown field once. It is the default.
+	ProtocolCheckLog
+	// ProtocolCheckStrict contains the protocol check rejecting states with unknown fields (see ErrUnknownField).
+	ProtocolCheckStrict
+)
+
+// ErrUnknownField is returned when reading a state with unknown fields if the protocol check is ProtocolCheckStrict.
+var ErrUnknownField = errors.New("unknown field")
+
+var (
+	protocolCheck       int32 = ProtocolCheckLog
+	loggedUnknownFields sync.Map
+
+	wireGameFields   = jsonFieldNames(wireGame{})
+	wirePlayerFields = jsonFieldNames(wirePlayer{})
+)
+
+// SetProtocolCheck sets how unknown fields in received states are handled (see ProtocolCheckOff, ProtocolCheckLog and ProtocolCheckStrict).
+// Unknown fields indicate that the format of the server changed, which would be ignored silently otherwise.
+func SetProtocolCheck(check int) error {
+	switch check {
+	case ProtocolCheckOff, ProtocolCheckLog, ProtocolCheckStrict:
+		atomic.StoreInt32(&protocolCheck, int32(check))
+		return nil
+	default:
+		return fmt.Errorf("unknown protocol check %d", check)
+	}
+}
+
+// wireGame is the representation of a game in the JSON format of the official spe_ed server.
+// The order of the fields is the order of the official server.
+type wireGame struct {
+	Width    int                 `json:"width"`
+	Height   int                 `json:"height"`
+	Cells    [][]int8            `json:"cells"`
+	Players  map[int]*wirePlayer `json:"players"` // encoding/json uses the string representation of the numbers as keys
+	You      int                 `json:"you"`
+	Running  bool                `json:"running"`
+	Deadline string              `json:"deadline,omitempty"` // RFC3339
+}
+
+// wirePlayer is the representation of a player in the JSON format of the official spe_ed server.
+type wirePlayer struct {
+	X         int    `json:"x"`
+	Y         int    `json:"y"`
+	Direction string `json:"direction"`
+	Speed     int    `json:"speed"`
+	Active    bool   `json:"active"`
+	Name      string `json:"name,omitempty"`
+}
+
+// MarshalJSON returns the game in the JSON format of the official spe_ed server.
+func (g *Game) MarshalJSON() ([]byte, error) {
+	w := wireGame{
+		Width:    g.Width,
+		Height:   g.Height,
+		Cells:    g.Cells,
+		Players:  make(map[int]*wirePlayer, len(g.Players)),
+		You:      g.You,
+		Running:  g.Running,
+		Deadline: g.Deadline,
+	}
+	for k := range g.Players {
+		w.Players[k] = g.Players[k].wire()
+	}
+	return json.Marshal(w)
+}
+
+// UnmarshalJSON reads a game in the JSON format of the official spe_ed server.
+// Only public fields are set, all other fields are left untouched.
+// Since the format does not contain the number of steps of the players, Player.stepCounter is left untouched as well.
+// Unknown fields of the game and the players are handled as set by SetProtocolCheck.
+func (g *Game) UnmarshalJSON(b []byte) error {
+	var w wireGame
+	err := json.Unmarshal(b, &w)
+	if err != nil {
+		return err
+	}
+	if atomic.LoadInt32(&protocolCheck) != ProtocolCheckOff {
+		err = checkGameFields(b)
+		if err != nil {
+			return err
+		}
+	}
+	if w.Width < 0 || w.Height < 0 {
+		return fmt.Errorf("invalid size %dx%d", w.Width, w.Height)
+	}
+	if len(w.Cells) != w.Height {
+		return fmt.Errorf("game has height %d, but %d rows", w.Height, len(w.Cells))
+	}
+	for i := range w.Cells {
+		if len(w.Cells[i]) != w.Width {
+			return fmt.Errorf("game has width %d, but row %d has %d cells", w.Width, i, len(w.Cells[i]))
+		}
+	}
+
+	players := make(map[int]*Player, len(w.Players))
+	for k := range w.Players {
+		if k < 1 {
+			return fmt.Errorf("invalid player number %d", k)
+		}
+		if w.Players[k] == nil {
+			return fmt.Errorf("player %d is null", k)
+		}
+		p := new(Player)
+		err = p.fromWire(w.Players[k])
+		if err != nil {
+			return fmt.Errorf("player %d: %w", k, err)
+		}
+		players[k] = p
+	}
+
+	g.Width = w.Width
+	g.Height = w.Height
+	g.Cells = w.Cells
+	g.Players = players
+	g.You = w.You
+	g.Running = w.Running
+	g.Deadline = w.Deadline
+	return nil
+}
+
+// MarshalJSON returns the player in the JSON format of the official spe_ed server.
+func (p *Player) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.wire())
+}
+
+// UnmarshalJSON reads a player in the JSON format of the official spe_ed server.
+// Only public fields are set, all other fields are left untouched.
+// Unknown fields are handled as set by SetProtocolCheck.
+func (p *Player) UnmarshalJSON(b []byte) error {
+	var w wirePlayer
+	err := json.Unmarshal(b, &w)
+	if err != nil {
+		return err
+	}
+	if atomic.LoadInt32(&protocolCheck) != ProtocolCheckOff {
+		var fields map[string]json.RawMessage
+		err = json.Unmarshal(b, &fields)
+		if err != nil {
+			return err
+		}
+		err = reportUnknownFields(unknownFields(fields, wirePlayerFields, "players."))
+		if err != nil {
+			return err
+		}
+	}
+	return p.fromWire(&w)
+}
+
+func (p *Player) wire() *wirePlayer {
+	return &wirePlayer{
+		X:         p.X,
+		Y:         p.Y,
+		Direction: p.Direction.String(),
+		Speed:     p.Speed,
+		Active:    p.Active,
+		Name:      p.Name,
+	}
+}
+
+func (p *Player) fromWire(w *wirePlayer) error {
+	direction, err := ParseDirection(w.Direction)
+	if err != nil {
+		return err
+	}
+	p.X = w.X
+	p.Y = w.Y
+	p.Direction = direction
+	p.Speed = w.Speed
+	p.Active = w.Active
+	p.Name = w.Name
+	return nil
+}
+
+// checkGameFields reports all unknown fields of a game and its players (see reportUnknownFields).
+// Fields of players are prefixed with "players.", so a field is reported once regardless of the player.
+func checkGameFields(b []byte) error {
+	var fields map[string]json.RawMessage
+	err := json.Unmarshal(b, &fields)
+	if err != nil {
+		return err
+	}
+	unknown := unknownFields(fields, wireGameFields, "")
+
+	var players map[string]map[string]json.RawMessage
+	for name, raw := range fields {
+		if !strings.EqualFold(name, "players") {
+			continue
+		}
+		err = json.Unmarshal(raw, &players)
+		if err != nil {
+			return err
+		}
+	}
+	for k := range players {
+		unknown = append(unknown, unknownFields(players[k], wirePlayerFields, "players.")...)
+	}
+	return reportUnknownFields(unknown)
+}
+
+// unknownFields returns the sorted names of all fields which are not known, prefixed with prefix.
+// Like encoding/json, names are matched case-insensitively, so a field decoded into a known field is never reported.
+func unknownFields(fields map[string]json.RawMessage, known map[string]bool, prefix string) []string {
+	var unknown []string
+	for name := range fields {
+		if !isKnownField(name, known) {
+			unknown = append(unknown, prefix+name)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// isKnownField returns whether the name matches one of the known fields, ignoring case.
+func isKnownField(name string, known map[string]bool) bool {
+	if known[name] {
+		return true
+	}
+	for k := range known {
+		if strings.EqualFold(name, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// reportUnknownFields returns an error if there are unknown fields and the protocol check is ProtocolCheckStrict.
+// Otherwise, every field is logged the first time it is seen.
+func reportUnknownFields(unknown []string) error {
+	if len(unknown) == 0 {
+		return nil
+	}
+	if atomic.LoadInt32(&protocolCheck) == ProtocolCheckStrict {
+		return fmt.Errorf("%w %s (protocol might have changed)", ErrUnknownField, strings.Join(unknown, ", "))
+	}
+	for _, name := range unknown {
+		if _, seen := loggedUnknownFields.LoadOrStore(name, true); !seen {
+			log.Printf("wire: unknown field %q, the protocol of the server might have changed", name)
+		}
+	}
+	return nil
+}
+
+// jsonFieldNames returns the JSON names of all fields of the struct v.
+func jsonFieldNames(v interface{}) map[string]bool {
+	t := reflect.TypeOf(v)
+	names := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name == "" {
+			name = t.Field(i).Name
+		}
+		if name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+}
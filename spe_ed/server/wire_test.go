@@ -0,0 +1,222 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020,2021 Philipp Naumann, Marcus Soll
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// serverMessage returns a state recorded from the official server (testdata/server_message.json).
+func serverMessage(t testing.TB) []byte {
+	t.Helper()
+	b, err := os.ReadFile("testdata/server_message.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestGameJSONRoundTrip(t *testing.T) {
+	msg := serverMessage(t)
+	var g Game
+	err := json.Unmarshal(msg, &g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Width != 10 || g.Height != 8 || g.You != 1 || !g.Running || g.Deadline != "2021-01-17T13:47:21Z" || len(g.Players) != 3 {
+		t.Fatalf("wrong game %dx%d, you %d, running %t, deadline %s, %d players", g.Width, g.Height, g.You, g.Running, g.Deadline, len(g.Players))
+	}
+	if p := g.Players[2]; p.X != 8 || p.Y != 4 || p.Direction != DirectionRight || p.Speed != 1 || !p.Active {
+		t.Errorf("wrong player 2 (%d,%d) %s speed %d active %t", p.X, p.Y, p.Direction, p.Speed, p.Active)
+	}
+	if g.Players[3].Active || g.Cells[5][3] != CellCrash || g.Cells[3][4] != 1 {
+		t.Error("wrong cells or inactive player")
+	}
+
+	b, err := json.Marshal(&g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want bytes.Buffer
+	err = json.Compact(&want, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, want.Bytes()) {
+		t.Errorf("round trip differs from the server\ngot:  %s\nwant: %s", b, want.Bytes())
+	}
+}
+
+func TestPlayerJSONRoundTrip(t *testing.T) {
+	for _, d := range []Direction{DirectionUp, DirectionDown, DirectionLeft, DirectionRight} {
+		p := &Player{X: 3, Y: 4, Direction: d, Speed: 7, Active: true}
+		b, err := json.Marshal(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := `{"x":3,"y":4,"direction":"` + d.String() + `","speed":7,"active":true}`
+		if string(b) != want {
+			t.Errorf("got %s, want %s", b, want)
+		}
+		var r Player
+		err = json.Unmarshal(b, &r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.X != p.X || r.Y != p.Y || r.Direction != d || r.Speed != p.Speed || r.Active != p.Active {
+			t.Errorf("%s: round trip changed the player", d)
+		}
+	}
+	var p Player
+	if json.Unmarshal([]byte(`{"x":3,"y":4,"direction":"north","speed":1,"active":true}`), &p) == nil {
+		t.Error("unknown direction accepted")
+	}
+}
+
+func TestGameJSONInvalid(t *testing.T) {
+	for name, msg := range map[string]string{
+		"negative size":  `{"width": -1, "height": 0, "cells": [], "players": {}}`,
+		"missing row":    `{"width": 1, "height": 2, "cells": [[0]], "players": {}}`,
+		"short row":      `{"width": 2, "height": 1, "cells": [[0]], "players": {}}`,
+		"player number":  `{"width": 1, "height": 1, "cells": [[0]], "players": {"0": {"x": 0, "y": 0, "direction": "up", "speed": 1, "active": true}}}`,
+		"null player":    `{"width": 1, "height": 1, "cells": [[0]], "players": {"1": null}}`,
+		"direction":      `{"width": 1, "height": 1, "cells": [[0]], "players": {"1": {"x": 0, "y": 0, "direction": "north", "speed": 1, "active": true}}}`,
+		"cell too large": `{"width": 1, "height": 1, "cells": [[200]], "players": {}}`,
+	} {
+		var g Game
+		if json.Unmarshal([]byte(msg), &g) == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
+
+func TestProtocolCheck(t *testing.T) {
+	var logged strings.Builder
+	oldLogger := log.current()
+	SetLogger(NewTextLogger(&logged, "", LogLevelInfo))
+	t.Cleanup(func() {
+		SetLogger(oldLogger)
+		SetProtocolCheck(ProtocolCheckLog)
+	})
+
+	var g Game
+	err := json.Unmarshal(serverMessage(t), &g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.Players[2].Direction = DirectionDown
+	b, err := json.Marshal(&g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(b)
+	s = strings.Replace(s, `{"width"`, `{"extra":1,"width"`, 1)
+	s = strings.Replace(s, `"direction":"down"`, `"direction":"down","colour":"red"`, 1)
+	extra := []byte(s)
+	s = strings.Replace(string(b), `"you"`, `"YOU"`, 1)
+	s = strings.Replace(s, `"players"`, `"Players"`, 1)
+	cased := []byte(strings.Replace(s, `"speed"`, `"Speed"`, -1))
+
+	for _, check := range []int{ProtocolCheckOff, ProtocolCheckLog, ProtocolCheckStrict} {
+		err := SetProtocolCheck(check)
+		if err != nil {
+			t.Fatal(err)
+		}
+		loggedUnknownFields.Range(func(k, v interface{}) bool {
+			loggedUnknownFields.Delete(k)
+			return true
+		})
+		logged.Reset()
+
+		var r Game
+		if err := json.Unmarshal(b, &r); err != nil {
+			t.Errorf("check %d: encoded state rejected: %v", check, err)
+		}
+		if err := json.Unmarshal(cased, &r); err != nil || r.You != g.You || r.Players[2].Speed != g.Players[2].Speed {
+			t.Errorf("check %d: differently cased state rejected or misread: %v", check, err)
+		}
+		for i := 0; i < 3; i++ {
+			var r Game
+			err := json.Unmarshal(extra, &r)
+			if got, want := errors.Is(err, ErrUnknownField), check == ProtocolCheckStrict; got != want {
+				t.Errorf("check %d: got error %v, want ErrUnknownField %t", check, err, want)
+			}
+		}
+		var p Player
+		err = json.Unmarshal([]byte(`{"x":0,"y":0,"direction":"up","speed":1,"active":true,"colour":"red"}`), &p)
+		if got, want := errors.Is(err, ErrUnknownField), check == ProtocolCheckStrict; got != want {
+			t.Errorf("check %d: player got error %v, want ErrUnknownField %t", check, err, want)
+		}
+
+		want := 0
+		if check == ProtocolCheckLog {
+			want = 1
+		}
+		for _, field := range []string{`"extra"`, `"players.colour"`} {
+			if n := strings.Count(logged.String(), field); n != want {
+				t.Errorf("check %d: field %s logged %d times, want %d\n%s", check, field, n, want, logged.String())
+			}
+		}
+		if strings.Contains(logged.String(), "YOU") || strings.Contains(logged.String(), "Speed") {
+			t.Errorf("check %d: differently cased known field logged\n%s", check, logged.String())
+		}
+	}
+}
+
+func FuzzUnmarshalGame(f *testing.F) {
+	msg := serverMessage(f)
+	f.Add(msg)
+	f.Add(bytes.Replace(msg, []byte(`"you": 1`), []byte(`"you": 7`), 1))
+	f.Add(bytes.Replace(msg, []byte(`"down"`), []byte(`"diagonal"`), 1))
+	f.Add([]byte(`{"width": 0, "height": 0, "cells": [], "players": {}, "you": 0, "running": false}`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var g Game
+		if json.Unmarshal(data, &g) != nil {
+			return
+		}
+		g.Validate()
+
+		b, err := json.Marshal(&g)
+		if err != nil {
+			t.Fatalf("can not marshal accepted game: %v", err)
+		}
+		var g2 Game
+		err = json.Unmarshal(b, &g2)
+		if err != nil {
+			t.Fatalf("can not unmarshal marshalled game: %v\n%s", err, b)
+		}
+		if !reflect.DeepEqual(g.Cells, g2.Cells) || !reflect.DeepEqual(g.Players, g2.Players) {
+			t.Fatalf("round trip changed the game\n%s", b)
+		}
+		if g.Width != g2.Width || g.Height != g2.Height || g.You != g2.You || g.Running != g2.Running || g.Deadline != g2.Deadline {
+			t.Fatalf("round trip changed the game\n%s", b)
+		}
+		b2, err := json.Marshal(&g2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(b, b2) {
+			t.Fatalf("marshalling is not stable\n%s\n%s", b, b2)
+		}
+	})
+}